@@ -2,18 +2,42 @@ package agent
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"sync"
 
 	"github.com/sashabaranov/go-openai"
 
 	"opencode_nano/config"
+	"opencode_nano/output"
 	"opencode_nano/tools"
+	"opencode_nano/tools/core"
 )
 
+// ErrInterrupted 本轮对话因 ctx 被取消（如用户按下 Ctrl+C）而中止
+var ErrInterrupted = errors.New("interrupted")
+
 type Agent struct {
-	provider     *Provider
-	conversation []openai.ChatCompletionMessage
+	provider         LLMProvider
+	tools            []tools.Tool                 // 可执行的工具
+	disabledTools    map[string]bool              // 配置中禁用的工具，模型仍调用时返回明确的错误
+	logger           core.Logger                  // 调试日志，默认丢弃
+	conversation     []openai.ChatCompletionMessage
+	maxContextTokens int                          // 对话历史的 token 预算，0 表示不限制
+	maxRounds        int       // 每次对话的最大轮次
+	systemPrompt     string                       // 已替换工作目录的系统提示词
+	noStream         bool                         // 单次模式下使用非流式请求
+	jsonOutput       io.Writer                    // 非 nil 时单次模式不输出过程，结束后写入 JSON 结果
+	quiet            bool                         // 单次模式只输出助手回复
+	model            string                       // 当前使用的模型，用于查询价格
+	prices           map[string]config.ModelPrice // 模型价格表
+
+	usageMu    sync.Mutex
+	totalUsage Usage // 整个会话累计的 token 用量
 }
 
 const systemPrompt = `你是 OpenCode Nano，一个乐于助人的 AI 编程助手。你可以通过读取和写入文件以及在必要时执行 bash 命令来帮助用户完成编程任务。
@@ -65,28 +89,103 @@ const systemPrompt = `你是 OpenCode Nano，一个乐于助人的 AI 编程助
 当前工作目录：%s`
 
 func New(cfg *config.Config, toolSet []tools.Tool) (*Agent, error) {
-	provider := NewProvider(cfg, toolSet)
-	
+	return NewWithProvider(cfg, NewOpenAIProvider(cfg, toolSet), toolSet)
+}
+
+// NewWithProvider 使用指定的模型后端创建 Agent，toolSet 中的工具由 Agent 执行
+func NewWithProvider(cfg *config.Config, provider LLMProvider, toolSet []tools.Tool) (*Agent, error) {
+
 	// 获取当前工作目录
 	cwd, _ := os.Getwd()
 	
+	prompt, err := buildSystemPrompt(cfg, cwd)
+	if err != nil {
+		return nil, err
+	}
+	
 	// 初始化对话历史
 	conversation := []openai.ChatCompletionMessage{
 		{
 			Role:    openai.ChatMessageRoleSystem,
-			Content: fmt.Sprintf(systemPrompt, cwd),
+			Content: prompt,
 		},
 	}
 	
+	maxRounds := cfg.MaxRounds
+	if maxRounds <= 0 {
+		maxRounds = config.DefaultMaxRounds
+	}
+	
+	prices := cfg.Prices
+	if prices == nil {
+		prices = config.DefaultPrices()
+	}
+	
+	model := cfg.Model
+	if model == "" {
+		model = config.DefaultModel
+	}
+	
+	// 按注册表展开别名，禁用 rm 时模型调用 delete 同样返回 ErrToolDisabled
+	disabledNames, err := tools.DisabledToolNames(cfg.DisabledTools...)
+	if err != nil {
+		return nil, err
+	}
+	disabled := make(map[string]bool, len(disabledNames))
+	for _, name := range disabledNames {
+		disabled[name] = true
+	}
+	
 	return &Agent{
-		provider:     provider,
-		conversation: conversation,
+		provider:         provider,
+		tools:            toolSet,
+		disabledTools:    disabled,
+		logger:           core.NopLogger{},
+		conversation:     conversation,
+		maxContextTokens: cfg.MaxContextTokens,
+		maxRounds:        maxRounds,
+		systemPrompt:     prompt,
+		model:            model,
+		prices:           prices,
 	}, nil
 }
 
+// buildSystemPrompt 构建系统提示词：配置了 SystemPromptFile 时替换内置提示词，
+// 其中的 %s 替换为 cwd，没有 %s 时在末尾附加工作目录；SystemPromptAppend 追加在最后
+func buildSystemPrompt(cfg *config.Config, cwd string) (string, error) {
+	prompt := fmt.Sprintf(systemPrompt, cwd)
+	if cfg.SystemPromptFile != "" {
+		data, err := os.ReadFile(cfg.SystemPromptFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read system prompt file: %v", err)
+		}
+		prompt = strings.TrimSpace(string(data))
+		if strings.Contains(prompt, "%s") {
+			prompt = strings.ReplaceAll(prompt, "%s", cwd)
+		} else {
+			prompt += "\n\nCurrent working directory: " + cwd
+		}
+	}
+	
+	if extra := strings.TrimSpace(cfg.SystemPromptAppend); extra != "" {
+		prompt += "\n\n" + extra
+	}
+	return prompt, nil
+}
+
 // RunOnce 执行单次对话（用于命令行参数模式）- 支持多轮自主对话
 func (a *Agent) RunOnce(ctx context.Context, prompt string) error {
-	fmt.Printf("🤖 OpenCode Nano is thinking...\n\n")
+	// JSON 模式下不输出过程信息
+	var out io.Writer = os.Stdout
+	if a.jsonOutput != nil {
+		out = io.Discard
+	}
+	// quiet 模式下过程信息（思考提示、工具调用和结果）不输出
+	status := out
+	if a.quiet {
+		status = io.Discard
+	}
+	fmt.Fprintf(status, "%s\n\n", output.Sprintf(output.Assistant, "OpenCode Nano is thinking..."))
 	
 	// 添加用户消息
 	userMsg := openai.ChatCompletionMessage{
@@ -97,19 +196,29 @@ func (a *Agent) RunOnce(ctx context.Context, prompt string) error {
 	messages := append(a.conversation, userMsg)
 	
 	// 最大轮次限制，防止无限循环
-	maxRounds := 10
+	var turnUsage Usage
+	var result RunResult
 	
-	for round := 0; round < maxRounds; round++ {
+	for round := 0; round < a.maxRounds; round++ {
+		if ctx.Err() != nil {
+			a.recordUsage(turnUsage)
+			return ErrInterrupted
+		}
+		
 		var assistantResponse string
 		var toolCalls []openai.ToolCall
 		hasToolCalls := false
+		result.Rounds = round + 1
 		
-		// 流式响应处理
-		err := a.provider.StreamResponseWithTools(
+		// 裁剪超出预算的历史消息
+		messages = a.trimHistory(messages)
+		
+		// 获取响应（默认流式）
+		usage, err := a.respond(
 			ctx,
 			messages,
 			func(delta string) {
-				fmt.Print(delta)
+				fmt.Fprint(out, delta)
 				assistantResponse += delta
 			},
 			func(toolCall openai.ToolCall) {
@@ -118,70 +227,139 @@ func (a *Agent) RunOnce(ctx context.Context, prompt string) error {
 			},
 		)
 		
+		turnUsage.Add(usage)
 		if err != nil {
+			a.recordUsage(turnUsage)
+			if ctx.Err() != nil {
+				return ErrInterrupted
+			}
 			return fmt.Errorf("failed to get response: %v", err)
 		}
 		
-		// 添加助手响应到消息历史
+		// 添加助手响应（包括工具调用）到消息历史
 		messages = append(messages, openai.ChatCompletionMessage{
-			Role:    openai.ChatMessageRoleAssistant,
-			Content: assistantResponse,
+			Role:      openai.ChatMessageRoleAssistant,
+			Content:   assistantResponse,
+			ToolCalls: toolCalls,
 		})
+		result.FinalMessage = assistantResponse
 		
 		// 如果没有工具调用，说明任务完成
 		if !hasToolCalls {
 			break
 		}
+		if round == a.maxRounds-1 {
+			result.MaxRoundsReached = true
+		}
 		
 		// 执行所有工具调用
-		fmt.Printf("\n")
+		if !a.quiet || assistantResponse != "" {
+			fmt.Fprintf(out, "\n")
+		}
 		for _, toolCall := range toolCalls {
-			fmt.Printf("🔧 Executing tool: %s\n", toolCall.Function.Name)
-			result, err := a.provider.ExecuteToolCall(toolCall)
+			if ctx.Err() != nil {
+				a.recordUsage(turnUsage)
+				return ErrInterrupted
+			}
+			fmt.Fprintln(status, output.Sprintf(output.Tool, "Executing tool: %s", toolCall.Function.Name))
+			toolResult, err := a.executeToolCall(toolCall)
 			if err != nil {
-				result = fmt.Sprintf("Error executing tool: %v", err)
+				toolResult = fmt.Sprintf("Error executing tool: %v", err)
 			}
 			
-			// 将工具结果作为用户消息添加到历史
-			toolResultMsg := openai.ChatCompletionMessage{
-				Role:    openai.ChatMessageRoleUser,
-				Content: fmt.Sprintf("Tool [%s] result:\n%s", toolCall.Function.Name, result),
-			}
-			messages = append(messages, toolResultMsg)
+			// 将工具结果作为 tool 消息添加到历史，并关联对应的调用 ID
+			messages = append(messages, toolResultMessage(toolCall, toolResult))
+			result.ToolCalls = append(result.ToolCalls, ToolCallRecord{
+				Name:      toolCall.Function.Name,
+				Arguments: toolCall.Function.Arguments,
+				Result:    toolResult,
+			})
 			
 			// 显示工具结果
-			fmt.Printf("📝 Result: %s\n", result)
+			fmt.Fprintln(status, output.Sprintf(output.Result, "Result: %s", toolResult))
 		}
 		
 		// 继续下一轮对话
-		fmt.Printf("\n🤖 Assistant: ")
+		if !result.MaxRoundsReached {
+			fmt.Fprintf(status, "\n%s", output.Sprintf(output.Assistant, "Assistant: "))
+		}
+	}
+	
+	a.recordUsage(turnUsage)
+	if a.jsonOutput != nil {
+		result.Usage = turnUsage
+		if result.ToolCalls == nil {
+			result.ToolCalls = []ToolCallRecord{}
+		}
+		return writeJSONResult(a.jsonOutput, result)
+	}
+	
+	// quiet 模式只补齐换行，轮次上限的警告写入 stderr
+	if a.quiet {
+		fmt.Println()
+		if result.MaxRoundsReached {
+			printMaxRoundsReached(os.Stderr, a.maxRounds)
+		}
+		return nil
 	}
 	
-	fmt.Printf("\n\n✅ Task completed!\n")
+	if result.MaxRoundsReached {
+		printMaxRoundsReached(os.Stdout, a.maxRounds)
+	} else {
+		fmt.Printf("\n\n%s\n", output.Sprintf(output.Success, "Task completed!"))
+	}
+	printUsage(turnUsage)
+	return nil
+}
+
+// printMaxRoundsReached 提示轮次已用完，任务可能没有完成
+func printMaxRoundsReached(w io.Writer, maxRounds int) {
+	fmt.Fprintf(w, "\n%s\n", output.Sprintf(output.Warning, "Reached max rounds (%d); task may be incomplete. Increase OPENCODE_MAX_ROUNDS to allow more.", maxRounds))
+}
+
+// writeJSONResult 以缩进的 JSON 写入执行结果
+func writeJSONResult(w io.Writer, result RunResult) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		return fmt.Errorf("failed to encode result: %v", err)
+	}
 	return nil
 }
 
 // RunInteractive 执行交互式对话（保持对话历史）- 支持多轮自主对话
 func (a *Agent) RunInteractive(ctx context.Context, prompt string) error {
-	fmt.Printf("\n🤖 Assistant: ")
+	fmt.Printf("\n%s", output.Sprintf(output.Assistant, "Assistant: "))
 	
 	// 添加用户消息到对话历史
 	userMsg := openai.ChatCompletionMessage{
 		Role:    openai.ChatMessageRoleUser,
 		Content: prompt,
 	}
+	// 被中断时恢复到本轮之前的对话历史，避免留下没有结果的工具调用。
+	// 本轮只会 append 消息，trimHistory 也只会原样返回或复制到新切片，都不会修改 saved 中已有的元素，
+	// 因此保存切片本身即可
+	saved := a.conversation
 	a.conversation = append(a.conversation, userMsg)
 	
 	// 最大轮次限制
-	maxRounds := 5 // 交互模式下轮次少一些
+	var turnUsage Usage
+	maxRoundsReached := false
 	
-	for round := 0; round < maxRounds; round++ {
+	for round := 0; round < a.maxRounds; round++ {
+		if ctx.Err() != nil {
+			return a.abortTurn(saved, turnUsage)
+		}
+		
 		var assistantResponse string
 		var toolCalls []openai.ToolCall
 		hasToolCalls := false
 		
+		// 裁剪超出预算的历史消息
+		a.conversation = a.trimHistory(a.conversation)
+		
 		// 流式响应处理
-		err := a.provider.StreamResponseWithTools(
+		usage, err := a.provider.StreamResponseWithTools(
 			ctx,
 			a.conversation,
 			func(delta string) {
@@ -194,14 +372,20 @@ func (a *Agent) RunInteractive(ctx context.Context, prompt string) error {
 			},
 		)
 		
+		turnUsage.Add(usage)
 		if err != nil {
+			if ctx.Err() != nil {
+				return a.abortTurn(saved, turnUsage)
+			}
+			a.recordUsage(turnUsage)
 			return fmt.Errorf("failed to get response: %v", err)
 		}
 		
-		// 添加助手响应到对话历史
+		// 添加助手响应（包括工具调用）到对话历史
 		assistantMsg := openai.ChatCompletionMessage{
-			Role:    openai.ChatMessageRoleAssistant,
-			Content: assistantResponse,
+			Role:      openai.ChatMessageRoleAssistant,
+			Content:   assistantResponse,
+			ToolCalls: toolCalls,
 		}
 		a.conversation = append(a.conversation, assistantMsg)
 		
@@ -209,36 +393,149 @@ func (a *Agent) RunInteractive(ctx context.Context, prompt string) error {
 		if !hasToolCalls {
 			break
 		}
+		maxRoundsReached = round == a.maxRounds-1
 		
 		// 执行所有工具调用
 		fmt.Printf("\n")
 		for _, toolCall := range toolCalls {
-			fmt.Printf("🔧 Executing tool: %s\n", toolCall.Function.Name)
-			result, err := a.provider.ExecuteToolCall(toolCall)
+			if ctx.Err() != nil {
+				return a.abortTurn(saved, turnUsage)
+			}
+			fmt.Println(output.Sprintf(output.Tool, "Executing tool: %s", toolCall.Function.Name))
+			result, err := a.executeToolCall(toolCall)
 			if err != nil {
 				result = fmt.Sprintf("Error executing tool: %v", err)
 			}
 			
-			// 将工具结果作为用户消息添加到历史
-			toolResultMsg := openai.ChatCompletionMessage{
-				Role:    openai.ChatMessageRoleUser,
-				Content: fmt.Sprintf("Tool [%s] result:\n%s", toolCall.Function.Name, result),
-			}
-			a.conversation = append(a.conversation, toolResultMsg)
+			// 将工具结果作为 tool 消息添加到历史，并关联对应的调用 ID
+			a.conversation = append(a.conversation, toolResultMessage(toolCall, result))
 			
 			// 显示工具结果
-			fmt.Printf("📝 Result: %s\n", result)
+			fmt.Println(output.Sprintf(output.Result, "Result: %s", result))
 		}
 		
 		// 如果还有轮次，继续对话
-		if round < maxRounds-1 {
-			fmt.Printf("\n🤖 Assistant: ")
+		if round < a.maxRounds-1 {
+			fmt.Printf("\n%s", output.Sprintf(output.Assistant, "Assistant: "))
 		}
 	}
 	
+	if maxRoundsReached {
+		printMaxRoundsReached(os.Stdout, a.maxRounds)
+	} else {
+		fmt.Println()
+	}
+	a.recordUsage(turnUsage)
+	printUsage(turnUsage)
 	return nil
 }
 
+// abortTurn 中断本轮对话：恢复对话历史并记录已产生的用量
+func (a *Agent) abortTurn(saved []openai.ChatCompletionMessage, usage Usage) error {
+	a.conversation = saved
+	a.recordUsage(usage)
+	return ErrInterrupted
+}
+
+// SetStreaming 设置单次模式是否使用流式请求
+func (a *Agent) SetStreaming(enabled bool) {
+	a.noStream = !enabled
+}
+
+// SetQuiet 设置单次模式是否只输出助手回复
+func (a *Agent) SetQuiet(quiet bool) {
+	a.quiet = quiet
+}
+
+// SetLogger 设置调试日志，记录请求摘要、工具调用参数和结果长度
+func (a *Agent) SetLogger(logger core.Logger) {
+	a.logger = logger
+	// 后端支持时同时记录请求摘要
+	if p, ok := a.provider.(interface{ SetLogger(core.Logger) }); ok {
+		p.SetLogger(logger)
+	}
+}
+
+// SetJSONOutput 设置单次模式的 JSON 输出，w 为 nil 时恢复普通输出
+func (a *Agent) SetJSONOutput(w io.Writer) {
+	a.jsonOutput = w
+}
+
+// SystemPrompt 返回当前使用的系统提示词
+func (a *Agent) SystemPrompt() string {
+	return a.systemPrompt
+}
+
+// respond 请求一轮助手回复，非流式模式下一次性输出完整内容
+func (a *Agent) respond(ctx context.Context, messages []openai.ChatCompletionMessage, onDelta func(string), onToolCall func(openai.ToolCall)) (Usage, error) {
+	if !a.noStream {
+		return a.provider.StreamResponseWithTools(ctx, messages, onDelta, onToolCall)
+	}
+
+	completion, err := a.provider.Complete(ctx, messages)
+	if err != nil {
+		return completion.Usage, err
+	}
+	if completion.Content != "" {
+		onDelta(completion.Content)
+	}
+	for _, toolCall := range completion.ToolCalls {
+		onToolCall(toolCall)
+	}
+	return completion.Usage, nil
+}
+
+// executeToolCall 执行工具调用，被禁用的工具直接返回错误
+func (a *Agent) executeToolCall(toolCall openai.ToolCall) (string, error) {
+	if a.disabledTools[toolCall.Function.Name] {
+		return "", core.ErrToolDisabled(toolCall.Function.Name)
+	}
+	return executeToolCall(a.tools, toolCall, a.logger)
+}
+
+// toolResultMessage 构建与工具调用对应的 tool 角色消息
+func toolResultMessage(toolCall openai.ToolCall, result string) openai.ChatCompletionMessage {
+	return openai.ChatCompletionMessage{
+		Role:       openai.ChatMessageRoleTool,
+		Content:    result,
+		Name:       toolCall.Function.Name,
+		ToolCallID: toolCall.ID,
+	}
+}
+
+// TotalUsage 返回会话累计的 token 用量
+func (a *Agent) TotalUsage() Usage {
+	a.usageMu.Lock()
+	defer a.usageMu.Unlock()
+	return a.totalUsage
+}
+
+// recordUsage 累加一次对话的 token 用量
+func (a *Agent) recordUsage(usage Usage) {
+	a.usageMu.Lock()
+	defer a.usageMu.Unlock()
+	a.totalUsage.Add(usage)
+}
+
+// printUsage 打印 token 用量，接口未返回用量时不输出
+func printUsage(usage Usage) {
+	if usage.Total() > 0 {
+		fmt.Println(output.Sprintf(output.Stats, "%s", usage))
+	}
+}
+
+// UndoLastExchange 撤销最后一轮对话：删除最后一条用户消息及其后的助手和工具消息。
+// 没有可撤销的用户消息时返回 false
+func (a *Agent) UndoLastExchange() bool {
+	for i := len(a.conversation) - 1; i >= 0; i-- {
+		if a.conversation[i].Role == openai.ChatMessageRoleUser {
+			a.conversation = a.conversation[:i]
+			return true
+		}
+	}
+	return false
+}
+
 // ClearConversation 清除对话历史
 func (a *Agent) ClearConversation() {
 	// 保留系统消息，清除其他消息
@@ -246,11 +543,15 @@ func (a *Agent) ClearConversation() {
 		a.conversation = a.conversation[:1]
 	} else {
 		// 重新创建系统消息
-		cwd, _ := os.Getwd()
+		prompt := a.systemPrompt
+		if prompt == "" {
+			cwd, _ := os.Getwd()
+			prompt = fmt.Sprintf(systemPrompt, cwd)
+		}
 		a.conversation = []openai.ChatCompletionMessage{
 			{
 				Role:    openai.ChatMessageRoleSystem,
-				Content: fmt.Sprintf(systemPrompt, cwd),
+				Content: prompt,
 			},
 		}
 	}
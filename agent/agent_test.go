@@ -1,14 +1,26 @@
 package agent
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/sashabaranov/go-openai"
 
 	"opencode_nano/config"
+	"opencode_nano/permission"
 	"opencode_nano/tools"
+	"opencode_nano/tools/core"
 )
 
 // MockTool 用于测试的模拟工具
@@ -38,6 +50,47 @@ func (m *MockTool) Execute(params map[string]any) (string, error) {
 	return "mock result", nil
 }
 
+// fakeProvider 按顺序返回预设回复的模型后端，并记录每次请求收到的消息
+type fakeProvider struct {
+	responses []Completion
+	requests  [][]openai.ChatCompletionMessage
+}
+
+func (f *fakeProvider) next(messages []openai.ChatCompletionMessage) (Completion, error) {
+	f.requests = append(f.requests, append([]openai.ChatCompletionMessage(nil), messages...))
+	if len(f.requests) > len(f.responses) {
+		return Completion{}, fmt.Errorf("unexpected request #%d", len(f.requests))
+	}
+	return f.responses[len(f.requests)-1], nil
+}
+
+func (f *fakeProvider) StreamResponseWithTools(ctx context.Context, messages []openai.ChatCompletionMessage, onDelta func(string), onToolCall func(openai.ToolCall)) (Usage, error) {
+	completion, err := f.next(messages)
+	if err != nil {
+		return Usage{}, err
+	}
+	if completion.Content != "" {
+		onDelta(completion.Content)
+	}
+	for _, toolCall := range completion.ToolCalls {
+		onToolCall(toolCall)
+	}
+	return completion.Usage, nil
+}
+
+func (f *fakeProvider) Complete(ctx context.Context, messages []openai.ChatCompletionMessage) (Completion, error) {
+	return f.next(messages)
+}
+
+// fakeToolCall 构造一个函数类型的工具调用
+func fakeToolCall(id, name, arguments string) openai.ToolCall {
+	return openai.ToolCall{
+		ID:       id,
+		Type:     openai.ToolTypeFunction,
+		Function: openai.FunctionCall{Name: name, Arguments: arguments},
+	}
+}
+
 func TestNew(t *testing.T) {
 	// 设置测试环境变量
 	os.Setenv("OPENAI_API_KEY", "test-key")
@@ -162,6 +215,117 @@ func TestAgent_ClearConversation_NoSystemMessage(t *testing.T) {
 	}
 }
 
+func TestBuildSystemPrompt(t *testing.T) {
+	dir := t.TempDir()
+	withPlaceholder := filepath.Join(dir, "with_cwd.md")
+	os.WriteFile(withPlaceholder, []byte("You are a reviewer.\nWorking in %s.\n"), 0644)
+	withoutPlaceholder := filepath.Join(dir, "plain.md")
+	os.WriteFile(withoutPlaceholder, []byte("You are a reviewer."), 0644)
+
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want string
+	}{
+		{
+			name: "替换并填入工作目录",
+			cfg:  config.Config{SystemPromptFile: withPlaceholder},
+			want: "You are a reviewer.\nWorking in /work.",
+		},
+		{
+			name: "替换后附加工作目录",
+			cfg:  config.Config{SystemPromptFile: withoutPlaceholder},
+			want: "You are a reviewer.\n\nCurrent working directory: /work",
+		},
+		{
+			name: "替换并追加",
+			cfg:  config.Config{SystemPromptFile: withoutPlaceholder, SystemPromptAppend: "Answer in English."},
+			want: "You are a reviewer.\n\nCurrent working directory: /work\n\nAnswer in English.",
+		},
+		{
+			name: "内置提示词追加",
+			cfg:  config.Config{SystemPromptAppend: "  Answer in English.\n"},
+			want: fmt.Sprintf(systemPrompt, "/work") + "\n\nAnswer in English.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildSystemPrompt(&tt.cfg, "/work")
+			if err != nil {
+				t.Fatalf("buildSystemPrompt() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("buildSystemPrompt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// 文件不存在时 New 返回错误
+	_, err := New(&config.Config{SystemPromptFile: filepath.Join(dir, "missing.md")}, nil)
+	if err == nil {
+		t.Error("系统提示词文件不存在时 New() 应返回错误")
+	}
+
+	// 清除对话后仍使用自定义提示词
+	agent, err := New(&config.Config{SystemPromptFile: withoutPlaceholder}, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	agent.conversation = nil
+	agent.ClearConversation()
+	if !strings.HasPrefix(agent.conversation[0].Content, "You are a reviewer.") {
+		t.Errorf("ClearConversation() 后系统提示词 = %q", agent.conversation[0].Content)
+	}
+}
+
+func TestAgent_UndoLastExchange(t *testing.T) {
+	agent, err := New(&config.Config{OpenAIAPIKey: "test-key"}, []tools.Tool{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// 只有系统消息时不做任何修改
+	if agent.UndoLastExchange() {
+		t.Error("只有系统消息时 UndoLastExchange() 应返回 false")
+	}
+	if len(agent.conversation) != 1 {
+		t.Fatalf("对话长度 = %d, want 1", len(agent.conversation))
+	}
+
+	first := []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleUser, Content: "first"},
+		{Role: openai.ChatMessageRoleAssistant, Content: "reply"},
+	}
+	second := []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleUser, Content: "second"},
+		{Role: openai.ChatMessageRoleAssistant, ToolCalls: []openai.ToolCall{{ID: "call_1"}}},
+		{Role: openai.ChatMessageRoleTool, ToolCallID: "call_1", Content: "tool output"},
+		{Role: openai.ChatMessageRoleAssistant, Content: "done"},
+	}
+	agent.conversation = append(agent.conversation, first...)
+	agent.conversation = append(agent.conversation, second...)
+
+	if !agent.UndoLastExchange() {
+		t.Fatal("UndoLastExchange() 应返回 true")
+	}
+	if len(agent.conversation) != 1+len(first) {
+		t.Fatalf("撤销后对话长度 = %d, want %d", len(agent.conversation), 1+len(first))
+	}
+	if last := agent.conversation[len(agent.conversation)-1]; last.Content != "reply" {
+		t.Errorf("撤销后最后一条消息 = %q, want %q", last.Content, "reply")
+	}
+
+	if !agent.UndoLastExchange() {
+		t.Fatal("第二次 UndoLastExchange() 应返回 true")
+	}
+	if len(agent.conversation) != 1 || agent.conversation[0].Role != openai.ChatMessageRoleSystem {
+		t.Errorf("撤销全部对话后应只剩系统消息: %+v", agent.conversation)
+	}
+	if agent.UndoLastExchange() {
+		t.Error("没有用户消息时 UndoLastExchange() 应返回 false")
+	}
+}
+
 func TestSystemPrompt(t *testing.T) {
 	// 验证系统提示词包含必要的内容
 	// 检查系统提示词包含关键内容
@@ -234,4 +398,556 @@ func TestAgent_ConversationManagement(t *testing.T) {
 	if agent.conversation == nil {
 		t.Error("对话历史为 nil")
 	}
-}
\ No newline at end of file
+}
+// toolCallChunk 构造包含完整工具调用的数据块
+func toolCallChunk(index int, id, name, arguments string) string {
+	data, _ := json.Marshal(openai.ChatCompletionStreamResponse{
+		Choices: []openai.ChatCompletionStreamChoice{
+			{Delta: openai.ChatCompletionStreamChoiceDelta{
+				ToolCalls: []openai.ToolCall{{
+					Index:    &index,
+					ID:       id,
+					Type:     openai.ToolTypeFunction,
+					Function: openai.FunctionCall{Name: name, Arguments: arguments},
+				}},
+			}},
+		},
+	})
+	return string(data)
+}
+
+func TestAgent_ToolResultsUseToolRole(t *testing.T) {
+	var requests []openai.ChatCompletionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("解析请求失败: %v", err)
+		}
+		requests = append(requests, req)
+
+		// 第一次返回工具调用，之后返回普通文本
+		chunk := contentChunk("done")
+		if len(requests) == 1 {
+			chunk = toolCallChunk(0, "call_1", "test_tool", `{}`)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "data: %s\n\n", chunk)
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		OpenAIAPIKey:  "test-key",
+		OpenAIBaseURL: server.URL,
+	}
+	mockTool := &MockTool{
+		name: "test_tool",
+		executeFunc: func(params map[string]any) (string, error) {
+			return "tool output", nil
+		},
+	}
+
+	agent, err := New(cfg, []tools.Tool{mockTool})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := agent.RunOnce(context.Background(), "run the tool"); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("请求次数 = %d, want 2", len(requests))
+	}
+
+	messages := requests[1].Messages
+	if len(messages) < 2 {
+		t.Fatalf("第二次请求消息数 = %d, want >= 2", len(messages))
+	}
+	assistantMsg := messages[len(messages)-2]
+	toolMsg := messages[len(messages)-1]
+
+	if assistantMsg.Role != openai.ChatMessageRoleAssistant {
+		t.Errorf("倒数第二条消息角色 = %q, want assistant", assistantMsg.Role)
+	}
+	if len(assistantMsg.ToolCalls) != 1 || assistantMsg.ToolCalls[0].ID != "call_1" {
+		t.Errorf("助手消息工具调用 = %+v, want call_1", assistantMsg.ToolCalls)
+	}
+	if toolMsg.Role != openai.ChatMessageRoleTool {
+		t.Errorf("工具结果消息角色 = %q, want tool", toolMsg.Role)
+	}
+	if toolMsg.ToolCallID != "call_1" {
+		t.Errorf("工具结果 ToolCallID = %q, want call_1", toolMsg.ToolCallID)
+	}
+	if toolMsg.Content != "tool output" {
+		t.Errorf("工具结果内容 = %q, want %q", toolMsg.Content, "tool output")
+	}
+}
+
+func TestAgent_RunOnceJSONOutput(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		chunk := contentChunk("all done")
+		if requests == 1 {
+			chunk = toolCallChunk(0, "call_1", "test_tool", `{"path":"a.txt"}`)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "data: %s\n\n", chunk)
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		OpenAIAPIKey:  "test-key",
+		OpenAIBaseURL: server.URL,
+	}
+	mockTool := &MockTool{
+		name: "test_tool",
+		executeFunc: func(params map[string]any) (string, error) {
+			return "tool output", nil
+		},
+	}
+	agent, err := New(cfg, []tools.Tool{mockTool})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	agent.SetJSONOutput(&buf)
+	if err := agent.RunOnce(context.Background(), "run the tool"); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+
+	// 输出只包含 JSON，不含过程信息
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &raw); err != nil {
+		t.Fatalf("输出不是合法 JSON: %v\n%s", err, buf.String())
+	}
+	for _, key := range []string{"final_message", "tool_calls", "rounds", "usage", "max_rounds_reached"} {
+		if _, ok := raw[key]; !ok {
+			t.Errorf("JSON 输出缺少 %s: %s", key, buf.String())
+		}
+	}
+
+	var result RunResult
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("解析结果失败: %v", err)
+	}
+	if result.FinalMessage != "all done" {
+		t.Errorf("final_message = %q, want %q", result.FinalMessage, "all done")
+	}
+	if result.Rounds != 2 {
+		t.Errorf("rounds = %d, want 2", result.Rounds)
+	}
+	want := []ToolCallRecord{{Name: "test_tool", Arguments: `{"path":"a.txt"}`, Result: "tool output"}}
+	if !reflect.DeepEqual(result.ToolCalls, want) {
+		t.Errorf("tool_calls = %+v, want %+v", result.ToolCalls, want)
+	}
+}
+
+func TestAgent_RunOnceQuiet(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		chunk := contentChunk("all done")
+		if requests%2 == 1 {
+			chunk = toolCallChunk(0, "call_1", "test_tool", `{}`)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "data: %s\n\n", chunk)
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		OpenAIAPIKey:  "test-key",
+		OpenAIBaseURL: server.URL,
+	}
+	toolRuns := 0
+	mockTool := &MockTool{
+		name: "test_tool",
+		executeFunc: func(params map[string]any) (string, error) {
+			toolRuns++
+			return "tool output", nil
+		},
+	}
+
+	run := func(quiet bool) string {
+		agent, err := New(cfg, []tools.Tool{mockTool})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		agent.SetQuiet(quiet)
+		var runErr error
+		output := captureStdout(t, func() { runErr = agent.RunOnce(context.Background(), "run the tool") })
+		if runErr != nil {
+			t.Fatalf("RunOnce() error = %v", runErr)
+		}
+		return output
+	}
+
+	normal := run(false)
+	for _, want := range []string{"is thinking...", "Executing tool: test_tool", "Result: tool output", "Task completed!", "all done"} {
+		if !strings.Contains(normal, want) {
+			t.Errorf("普通输出缺少 %q:\n%s", want, normal)
+		}
+	}
+
+	quiet := run(true)
+	if quiet != "all done\n" {
+		t.Errorf("quiet 输出 = %q, want %q", quiet, "all done\n")
+	}
+	// 工具仍然执行，结果照常发送给模型
+	if toolRuns != 2 {
+		t.Errorf("工具执行次数 = %d, want 2", toolRuns)
+	}
+}
+
+// captureStdout 执行 fn 并返回其间写入 stdout 的内容
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	return captureFile(t, &os.Stdout, fn)
+}
+
+// captureStderr 执行 fn 并返回其间写入 stderr 的内容
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	return captureFile(t, &os.Stderr, fn)
+}
+
+// captureFile 临时把 *file 替换为管道，返回 fn 执行期间写入的内容
+func captureFile(t *testing.T, file **os.File, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := *file
+	*file = w
+	defer func() { *file = old }()
+
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestAgent_DebugLogging(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		chunk := contentChunk("all done")
+		if requests%2 == 1 {
+			chunk = toolCallChunk(0, "call_1", "test_tool", `{"path":"a.txt"}`)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "data: %s\n\n", chunk)
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		OpenAIAPIKey:  "test-key",
+		OpenAIBaseURL: server.URL,
+	}
+	mockTool := &MockTool{
+		name: "test_tool",
+		executeFunc: func(params map[string]any) (string, error) {
+			return "tool output", nil
+		},
+	}
+
+	run := func(debug bool) (stdout, stderr string) {
+		agent, err := New(cfg, []tools.Tool{mockTool})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		var jsonOut bytes.Buffer
+		agent.SetJSONOutput(&jsonOut)
+		var runErr error
+		stderr = captureStderr(t, func() {
+			if debug {
+				agent.SetLogger(core.NewWriterLogger(os.Stderr))
+			}
+			stdout = captureStdout(t, func() { runErr = agent.RunOnce(context.Background(), "run the tool") })
+		})
+		if runErr != nil {
+			t.Fatalf("RunOnce() error = %v", runErr)
+		}
+		if !json.Valid(jsonOut.Bytes()) {
+			t.Errorf("JSON 输出被调试日志污染:\n%s", jsonOut.String())
+		}
+		return stdout, stderr
+	}
+
+	stdout, stderr := run(true)
+	if stdout != "" {
+		t.Errorf("调试日志不应写入 stdout: %q", stdout)
+	}
+	for _, want := range []string{
+		"[DEBUG] request model=" + config.DefaultModel + " messages=2 tools=1 stream=true",
+		`[DEBUG] tool call name=test_tool id=call_1 arguments="{\"path\":\"a.txt\"}"`,
+		"[DEBUG] tool result name=test_tool length=11",
+	} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("调试日志缺少 %q:\n%s", want, stderr)
+		}
+	}
+
+	if _, stderr := run(false); stderr != "" {
+		t.Errorf("未启用调试时不应输出日志: %q", stderr)
+	}
+}
+
+func TestAgent_MaxRounds(t *testing.T) {
+	// 模型每轮都返回工具调用，只能由轮次上限结束
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "data: %s\n\n", toolCallChunk(0, fmt.Sprintf("call_%d", requests), "test_tool", `{}`))
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		OpenAIAPIKey:  "test-key",
+		OpenAIBaseURL: server.URL,
+		MaxRounds:     3,
+	}
+
+	tests := []struct {
+		name string
+		run  func(*Agent) error
+	}{
+		{"RunOnce", func(a *Agent) error { return a.RunOnce(context.Background(), "loop forever") }},
+		{"RunInteractive", func(a *Agent) error { return a.RunInteractive(context.Background(), "loop forever") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = 0
+			agent, err := New(cfg, []tools.Tool{&MockTool{name: "test_tool"}})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			var runErr error
+			output := captureStdout(t, func() { runErr = tt.run(agent) })
+			if runErr != nil {
+				t.Fatalf("%s() error = %v", tt.name, runErr)
+			}
+			if requests != 3 {
+				t.Errorf("请求次数 = %d, want 3", requests)
+			}
+			if !strings.Contains(output, "Reached max rounds (3); task may be incomplete") {
+				t.Errorf("输出缺少轮次上限提示:\n%s", output)
+			}
+			if strings.Contains(output, "Task completed!") {
+				t.Errorf("达到上限时不应提示任务完成:\n%s", output)
+			}
+		})
+	}
+}
+
+func TestAgent_RunInteractiveInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 第一轮返回工具调用，工具执行时取消 context，模拟用户按下 Ctrl+C
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "data: %s\n\n", toolCallChunk(0, "call_1", "test_tool", `{}`))
+		fmt.Fprintf(w, "data: %s\n\n", toolCallChunk(1, "call_2", "test_tool", `{}`))
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	executed := 0
+	mockTool := &MockTool{
+		name: "test_tool",
+		executeFunc: func(params map[string]any) (string, error) {
+			executed++
+			cancel()
+			return "tool output", nil
+		},
+	}
+	cfg := &config.Config{
+		OpenAIAPIKey:  "test-key",
+		OpenAIBaseURL: server.URL,
+	}
+	agent, err := New(cfg, []tools.Tool{mockTool})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	before := len(agent.conversation)
+
+	var runErr error
+	captureStdout(t, func() { runErr = agent.RunInteractive(ctx, "loop") })
+	if !errors.Is(runErr, ErrInterrupted) {
+		t.Fatalf("RunInteractive() error = %v, want ErrInterrupted", runErr)
+	}
+	if requests != 1 || executed != 1 {
+		t.Errorf("请求次数 = %d, 工具执行次数 = %d, want 1, 1", requests, executed)
+	}
+	// 中断后对话历史恢复到本轮之前，可以继续对话
+	if len(agent.conversation) != before {
+		t.Errorf("中断后对话长度 = %d, want %d", len(agent.conversation), before)
+	}
+
+	// 已取消的 context 不再发起请求
+	captureStdout(t, func() { runErr = agent.RunInteractive(ctx, "again") })
+	if !errors.Is(runErr, ErrInterrupted) || requests != 1 {
+		t.Errorf("已取消时 error = %v, 请求次数 = %d", runErr, requests)
+	}
+}
+
+func TestNew_FullToolSet(t *testing.T) {
+	cfg := &config.Config{
+		OpenAIAPIKey:  "test-key",
+		OpenAIBaseURL: "https://api.openai.com/v1",
+	}
+	toolSet, err := tools.CreateFullToolSet(permission.NewAuto(), cfg)
+	if err != nil {
+		t.Fatalf("CreateFullToolSet() error = %v", err)
+	}
+
+	agent, err := New(cfg, toolSet)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	names := make(map[string]bool)
+	for _, def := range agent.provider.(*OpenAIProvider).toolDefinitions() {
+		names[def.Function.Name] = true
+	}
+	if len(names) != len(toolSet) {
+		t.Errorf("工具定义数 = %d, want %d", len(names), len(toolSet))
+	}
+	for _, name := range []string{"read", "write", "edit", "search", "glob", "list", "patch", "env", "process", "pipeline", "bash", "todo"} {
+		if !names[name] {
+			t.Errorf("发送给模型的工具中缺少 %s", name)
+		}
+	}
+}
+
+func TestAgent_RunOnceWithFakeProvider(t *testing.T) {
+	for _, noStream := range []bool{false, true} {
+		t.Run(fmt.Sprintf("noStream=%v", noStream), func(t *testing.T) {
+			provider := &fakeProvider{responses: []Completion{
+				{
+					Content:   "reading",
+					ToolCalls: []openai.ToolCall{fakeToolCall("call_1", "test_tool", `{"path":"a.txt"}`)},
+					Usage:     Usage{PromptTokens: 10, CompletionTokens: 5},
+				},
+				{
+					Content: "all done",
+					Usage:   Usage{PromptTokens: 20, CompletionTokens: 3},
+				},
+			}}
+
+			var gotParams map[string]any
+			mockTool := &MockTool{
+				name: "test_tool",
+				executeFunc: func(params map[string]any) (string, error) {
+					gotParams = params
+					return "file content", nil
+				},
+			}
+			agent, err := NewWithProvider(&config.Config{}, provider, []tools.Tool{mockTool})
+			if err != nil {
+				t.Fatalf("NewWithProvider() error = %v", err)
+			}
+			agent.SetStreaming(!noStream)
+
+			var buf bytes.Buffer
+			agent.SetJSONOutput(&buf)
+			if err := agent.RunOnce(context.Background(), "read a.txt"); err != nil {
+				t.Fatalf("RunOnce() error = %v", err)
+			}
+
+			if gotParams["path"] != "a.txt" {
+				t.Errorf("工具参数 = %v, want path=a.txt", gotParams)
+			}
+			if len(provider.requests) != 2 {
+				t.Fatalf("请求次数 = %d, want 2", len(provider.requests))
+			}
+			// 第二次请求应包含助手的工具调用和对应的工具结果
+			second := provider.requests[1]
+			last := second[len(second)-1]
+			if last.Role != openai.ChatMessageRoleTool || last.ToolCallID != "call_1" || last.Content != "file content" {
+				t.Errorf("最后一条消息 = %+v, want 工具结果", last)
+			}
+			if assistant := second[len(second)-2]; assistant.Role != openai.ChatMessageRoleAssistant || len(assistant.ToolCalls) != 1 {
+				t.Errorf("倒数第二条消息 = %+v, want 带工具调用的助手消息", assistant)
+			}
+
+			var result RunResult
+			if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+				t.Fatalf("解析结果失败: %v\n%s", err, buf.String())
+			}
+			if result.FinalMessage != "all done" || result.Rounds != 2 {
+				t.Errorf("结果 = %+v, want final_message=all done rounds=2", result)
+			}
+			want := []ToolCallRecord{{Name: "test_tool", Arguments: `{"path":"a.txt"}`, Result: "file content"}}
+			if !reflect.DeepEqual(result.ToolCalls, want) {
+				t.Errorf("tool_calls = %+v, want %+v", result.ToolCalls, want)
+			}
+			if total := agent.TotalUsage(); total != (Usage{PromptTokens: 30, CompletionTokens: 8}) {
+				t.Errorf("TotalUsage() = %+v, want 30/8", total)
+			}
+		})
+	}
+}
+
+func TestAgent_DisabledToolCall(t *testing.T) {
+	tests := []struct {
+		name     string
+		disabled string
+		call     string
+	}{
+		{"按名称禁用", "bash", "bash"},
+		{"按别名禁用后调用名称", "rm", "delete"},
+		{"按名称禁用后调用别名", "delete", "rm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeProvider{responses: []Completion{
+				{ToolCalls: []openai.ToolCall{fakeToolCall("call_1", tt.call, `{"path":"a.txt"}`)}},
+				{Content: "ok"},
+			}}
+			executed := false
+			tool := &MockTool{
+				name: tt.call,
+				executeFunc: func(params map[string]any) (string, error) {
+					executed = true
+					return "", nil
+				},
+			}
+			agent, err := NewWithProvider(&config.Config{DisabledTools: []string{tt.disabled}}, provider, []tools.Tool{tool})
+			if err != nil {
+				t.Fatalf("NewWithProvider() error = %v", err)
+			}
+
+			var buf bytes.Buffer
+			agent.SetJSONOutput(&buf)
+			if err := agent.RunOnce(context.Background(), "run"); err != nil {
+				t.Fatalf("RunOnce() error = %v", err)
+			}
+
+			if executed {
+				t.Error("禁用的工具不应执行")
+			}
+			second := provider.requests[1]
+			if last := second[len(second)-1]; !strings.Contains(last.Content, "tool disabled: "+tt.call) {
+				t.Errorf("工具结果 = %q, 应说明工具已禁用", last.Content)
+			}
+		})
+	}
+}
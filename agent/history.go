@@ -0,0 +1,84 @@
+package agent
+
+import (
+	"unicode/utf8"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// messageOverheadTokens 每条消息的固定开销（角色、分隔符等）
+const messageOverheadTokens = 4
+
+// estimateTokens 粗略估算消息的 token 数：ASCII 约 4 字符一个 token，其他字符按 1 个计算
+func estimateTokens(msg openai.ChatCompletionMessage) int {
+	text := msg.Content
+	for _, call := range msg.ToolCalls {
+		text += call.Function.Name + call.Function.Arguments
+	}
+
+	ascii, other := 0, 0
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return messageOverheadTokens + (ascii+3)/4 + other
+}
+
+// trimHistory 保留系统消息以及预算内最近的消息，从最早的消息开始丢弃。
+// 助手的工具调用和紧随其后的工具结果作为一个整体保留或丢弃，不会被拆开。
+func (a *Agent) trimHistory(messages []openai.ChatCompletionMessage) []openai.ChatCompletionMessage {
+	if a.maxContextTokens <= 0 || len(messages) == 0 {
+		return messages
+	}
+
+	var system []openai.ChatCompletionMessage
+	rest := messages
+	if messages[0].Role == openai.ChatMessageRoleSystem {
+		system = messages[:1]
+		rest = messages[1:]
+	}
+
+	budget := a.maxContextTokens
+	for _, msg := range system {
+		budget -= estimateTokens(msg)
+	}
+
+	// 按消息组划分：工具结果归属于它之前的消息
+	var groups [][]openai.ChatCompletionMessage
+	for _, msg := range rest {
+		if msg.Role == openai.ChatMessageRoleTool && len(groups) > 0 {
+			groups[len(groups)-1] = append(groups[len(groups)-1], msg)
+			continue
+		}
+		groups = append(groups, []openai.ChatCompletionMessage{msg})
+	}
+
+	// 从最新的消息组开始向前累加，最新一组总是保留
+	start := len(groups)
+	used := 0
+	for i := len(groups) - 1; i >= 0; i-- {
+		cost := 0
+		for _, msg := range groups[i] {
+			cost += estimateTokens(msg)
+		}
+		if used+cost > budget && start < len(groups) {
+			break
+		}
+		used += cost
+		start = i
+	}
+
+	if start == 0 {
+		return messages
+	}
+
+	trimmed := make([]openai.ChatCompletionMessage, 0, len(messages))
+	trimmed = append(trimmed, system...)
+	for _, group := range groups[start:] {
+		trimmed = append(trimmed, group...)
+	}
+	return trimmed
+}
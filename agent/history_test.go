@@ -0,0 +1,117 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// buildLongHistory 构造包含工具调用的超长对话历史
+func buildLongHistory(exchanges int) []openai.ChatCompletionMessage {
+	messages := []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: "system prompt"},
+	}
+	filler := strings.Repeat("lorem ipsum ", 20)
+	for i := 0; i < exchanges; i++ {
+		callID := fmt.Sprintf("call_%d", i)
+		messages = append(messages,
+			openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: fmt.Sprintf("question %d %s", i, filler)},
+			openai.ChatCompletionMessage{
+				Role: openai.ChatMessageRoleAssistant,
+				ToolCalls: []openai.ToolCall{{
+					ID:       callID,
+					Type:     openai.ToolTypeFunction,
+					Function: openai.FunctionCall{Name: "read_file", Arguments: `{"file_path":"a.go"}`},
+				}},
+			},
+			openai.ChatCompletionMessage{Role: openai.ChatMessageRoleTool, ToolCallID: callID, Content: filler},
+			openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: fmt.Sprintf("answer %d", i)},
+		)
+	}
+	return messages
+}
+
+func totalTokens(messages []openai.ChatCompletionMessage) int {
+	total := 0
+	for _, msg := range messages {
+		total += estimateTokens(msg)
+	}
+	return total
+}
+
+func TestAgent_TrimHistory(t *testing.T) {
+	history := buildLongHistory(50)
+	budget := 1000
+	if totalTokens(history) <= budget {
+		t.Fatalf("测试数据不足以超出预算: %d", totalTokens(history))
+	}
+
+	a := &Agent{maxContextTokens: budget}
+	trimmed := a.trimHistory(history)
+
+	if got := totalTokens(trimmed); got > budget {
+		t.Errorf("裁剪后 token 数 = %d, 超出预算 %d", got, budget)
+	}
+	if len(trimmed) >= len(history) {
+		t.Fatalf("历史未被裁剪: %d >= %d", len(trimmed), len(history))
+	}
+	if trimmed[0].Role != openai.ChatMessageRoleSystem {
+		t.Error("系统消息未被保留")
+	}
+
+	// 最新的消息必须保留
+	if trimmed[len(trimmed)-1].Content != history[len(history)-1].Content {
+		t.Error("最新消息未被保留")
+	}
+
+	// 每个工具结果之前必须有对应的工具调用
+	pending := map[string]bool{}
+	for i, msg := range trimmed[1:] {
+		if i == 0 && msg.Role == openai.ChatMessageRoleTool {
+			t.Fatal("裁剪后的历史以工具结果开头")
+		}
+		for _, call := range msg.ToolCalls {
+			pending[call.ID] = true
+		}
+		if msg.Role == openai.ChatMessageRoleTool {
+			if !pending[msg.ToolCallID] {
+				t.Errorf("工具结果 %s 缺少对应的工具调用", msg.ToolCallID)
+			}
+			delete(pending, msg.ToolCallID)
+		}
+	}
+	if len(pending) > 0 {
+		t.Errorf("存在没有结果的工具调用: %v", pending)
+	}
+}
+
+func TestAgent_TrimHistory_WithinBudget(t *testing.T) {
+	history := buildLongHistory(2)
+
+	a := &Agent{maxContextTokens: 100000}
+	if got := a.trimHistory(history); len(got) != len(history) {
+		t.Errorf("未超出预算时不应裁剪: len = %d, want %d", len(got), len(history))
+	}
+
+	a = &Agent{}
+	if got := a.trimHistory(history); len(got) != len(history) {
+		t.Errorf("预算为 0 时不应裁剪: len = %d, want %d", len(got), len(history))
+	}
+}
+
+func TestAgent_TrimHistory_KeepsLatestGroup(t *testing.T) {
+	history := buildLongHistory(3)
+
+	// 预算小于最后一组消息时，仍然保留最后一组
+	a := &Agent{maxContextTokens: 10}
+	trimmed := a.trimHistory(history)
+
+	if len(trimmed) != 2 {
+		t.Fatalf("len = %d, want 2 (系统消息 + 最后一条消息)", len(trimmed))
+	}
+	if trimmed[1].Content != "answer 2" {
+		t.Errorf("保留的消息 = %q, want %q", trimmed[1].Content, "answer 2")
+	}
+}
@@ -3,37 +3,112 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/sashabaranov/go-openai"
 
 	"opencode_nano/config"
 	"opencode_nano/tools"
+	"opencode_nano/tools/core"
 )
 
-type Provider struct {
-	client *openai.Client
-	tools  []tools.Tool
+// LLMProvider 模型后端接口，Agent 只通过它请求回复，便于接入其他后端或在测试中替换。
+// 消息和工具调用沿用 go-openai 的类型，其他后端在实现中自行转换
+type LLMProvider interface {
+	// StreamResponseWithTools 流式请求一轮回复：文本增量通过 onDelta 输出，
+	// 工具调用在参数完整后通过 onToolCall 通知（不执行），返回本次请求的 token 用量
+	StreamResponseWithTools(ctx context.Context, messages []openai.ChatCompletionMessage, onDelta func(string), onToolCall func(openai.ToolCall)) (Usage, error)
+	// Complete 非流式请求一轮回复，一次性返回完整内容和工具调用
+	Complete(ctx context.Context, messages []openai.ChatCompletionMessage) (Completion, error)
 }
 
-func NewProvider(cfg *config.Config, toolSet []tools.Tool) *Provider {
-	clientConfig := openai.DefaultConfig(cfg.OpenAIAPIKey)
-	clientConfig.BaseURL = cfg.OpenAIBaseURL
-	client := openai.NewClientWithConfig(clientConfig)
-	return &Provider{
-		client: client,
-		tools:  toolSet,
+// OpenAIProvider 基于 go-openai 的模型后端，支持 OpenAI 兼容接口和 Azure OpenAI
+type OpenAIProvider struct {
+	client      *openai.Client
+	tools       []tools.Tool
+	model       string
+	temperature float32
+	maxTokens   int
+	timeout     time.Duration // 0 表示不限制
+	maxRetries  int
+	retryDelay  time.Duration // 首次重试的等待时间，之后指数增长
+	logger      core.Logger   // 调试日志，默认丢弃
+}
+
+// defaultRetryDelay 首次重试前的默认等待时间
+const defaultRetryDelay = 500 * time.Millisecond
+
+// NewOpenAIProvider 创建 OpenAI 后端，toolSet 会作为工具定义随请求发送
+func NewOpenAIProvider(cfg *config.Config, toolSet []tools.Tool) *OpenAIProvider {
+	client := openai.NewClientWithConfig(newClientConfig(cfg))
+
+	model := cfg.Model
+	if model == "" {
+		model = config.DefaultModel
+	}
+
+	return &OpenAIProvider{
+		client:      client,
+		tools:       toolSet,
+		model:       model,
+		temperature: cfg.Temperature,
+		maxTokens:   cfg.MaxTokens,
+		timeout:     time.Duration(cfg.RequestTimeout) * time.Second,
+		maxRetries:  cfg.MaxRetries,
+		retryDelay:  defaultRetryDelay,
+		logger:      core.NopLogger{},
 	}
 }
 
-// StreamResponse 发送消息并处理流式响应
-func (p *Provider) StreamResponse(ctx context.Context, messages []openai.ChatCompletionMessage, onDelta func(string), onToolCall func(openai.ToolCall) (string, error)) error {
-	// 准备工具定义
+// newClientConfig 按配置的接口类型构建客户端配置，Azure 下将模型名映射为部署名
+func newClientConfig(cfg *config.Config) openai.ClientConfig {
+	if cfg.Provider != config.ProviderAzure {
+		clientConfig := openai.DefaultConfig(cfg.OpenAIAPIKey)
+		clientConfig.BaseURL = cfg.OpenAIBaseURL
+		return clientConfig
+	}
+
+	clientConfig := openai.DefaultAzureConfig(cfg.OpenAIAPIKey, cfg.OpenAIBaseURL)
+	if cfg.APIVersion != "" {
+		clientConfig.APIVersion = cfg.APIVersion
+	}
+	defaultMapper := clientConfig.AzureModelMapperFunc
+	clientConfig.AzureModelMapperFunc = func(model string) string {
+		if deployment := cfg.AzureDeployments[model]; deployment != "" {
+			return deployment
+		}
+		if cfg.AzureDeployment != "" {
+			return cfg.AzureDeployment
+		}
+		// 未配置部署名时沿用客户端的默认规则（去掉模型名中的 . 和 :）
+		return defaultMapper(model)
+	}
+	return clientConfig
+}
+
+// SetLogger 设置调试日志，记录请求摘要和工具调用
+func (p *OpenAIProvider) SetLogger(logger core.Logger) {
+	p.logger = logger
+}
+
+// withTimeout 为单次请求附加超时，timeout 为 0 时不限制
+func (p *OpenAIProvider) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, p.timeout)
+}
+
+// toolDefinitions 构建发送给模型的工具定义
+func (p *OpenAIProvider) toolDefinitions() []openai.Tool {
 	var toolDefinitions []openai.Tool
 	for _, tool := range p.tools {
 		toolDef := openai.Tool{
 			Type: openai.ToolTypeFunction,
-			Function: openai.FunctionDefinition{
+			Function: &openai.FunctionDefinition{
 				Name:        tool.Name(),
 				Description: tool.Description(),
 				Parameters:  tool.Parameters(),
@@ -41,13 +116,88 @@ func (p *Provider) StreamResponse(ctx context.Context, messages []openai.ChatCom
 		}
 		toolDefinitions = append(toolDefinitions, toolDef)
 	}
+	return toolDefinitions
+}
+
+// newRequest 构建请求，所有请求共用同一个模型配置
+func (p *OpenAIProvider) newRequest(messages []openai.ChatCompletionMessage) openai.ChatCompletionRequest {
+	return openai.ChatCompletionRequest{
+		Model:       p.model,
+		Messages:    messages,
+		Tools:       p.toolDefinitions(),
+		Temperature: p.temperature,
+		MaxTokens:   p.maxTokens,
+	}
+}
 
-	req := openai.ChatCompletionRequest{
-		Model:    "claude-opus-4-20250514-coder",
-		Messages: messages,
-		Tools:    toolDefinitions,
-		Stream:   true,
+// newStreamRequest 构建流式请求，并要求在流末尾返回用量
+func (p *OpenAIProvider) newStreamRequest(messages []openai.ChatCompletionMessage) openai.ChatCompletionRequest {
+	req := p.newRequest(messages)
+	req.Stream = true
+	req.StreamOptions = &openai.StreamOptions{
+		IncludeUsage: true,
 	}
+	return req
+}
+
+// logRequest 记录发出请求的摘要
+func (p *OpenAIProvider) logRequest(req openai.ChatCompletionRequest) {
+	p.logger.Debug("request", "model", req.Model, "messages", len(req.Messages), "tools", len(req.Tools), "stream", req.Stream)
+}
+
+// Completion 非流式请求的完整结果
+type Completion struct {
+	Content   string
+	ToolCalls []openai.ToolCall
+	Usage     Usage
+}
+
+// Complete 发送非流式请求，一次性返回助手的完整回复和工具调用
+func (p *OpenAIProvider) Complete(ctx context.Context, messages []openai.ChatCompletionMessage) (Completion, error) {
+	var completion Completion
+	err := p.retryStream(ctx, func(ctx context.Context) (bool, error) {
+		var err error
+		completion, err = p.complete(ctx, messages)
+		// 非流式请求没有中途输出，失败时总是可以重试
+		return false, err
+	})
+	return completion, err
+}
+
+func (p *OpenAIProvider) complete(ctx context.Context, messages []openai.ChatCompletionMessage) (Completion, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	req := p.newRequest(messages)
+	p.logRequest(req)
+	resp, err := p.client.CreateChatCompletion(ctx, req)
+	if err != nil {
+		return Completion{}, fmt.Errorf("failed to create completion: %w", err)
+	}
+
+	completion := Completion{
+		Usage: Usage{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+		},
+	}
+	if len(resp.Choices) == 0 {
+		return completion, fmt.Errorf("empty completion response")
+	}
+
+	message := resp.Choices[0].Message
+	completion.Content = message.Content
+	completion.ToolCalls = message.ToolCalls
+	return completion, nil
+}
+
+// StreamResponse 发送消息并处理流式响应
+func (p *OpenAIProvider) StreamResponse(ctx context.Context, messages []openai.ChatCompletionMessage, onDelta func(string), onToolCall func(openai.ToolCall) (string, error)) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	req := p.newStreamRequest(messages)
+	p.logRequest(req)
 
 	stream, err := p.client.CreateChatCompletionStream(ctx, req)
 	if err != nil {
@@ -55,7 +205,7 @@ func (p *Provider) StreamResponse(ctx context.Context, messages []openai.ChatCom
 	}
 	defer stream.Close()
 
-	var currentToolCall *openai.ToolCall
+	var acc toolCallAccumulator
 
 	for {
 		response, err := stream.Recv()
@@ -78,38 +228,14 @@ func (p *Provider) StreamResponse(ctx context.Context, messages []openai.ChatCom
 		}
 
 		// 处理工具调用
-		if len(delta.ToolCalls) > 0 {
-			for _, toolCall := range delta.ToolCalls {
-				if toolCall.ID != "" {
-					// 新的工具调用
-					if currentToolCall != nil {
-						// 执行之前的工具调用
-						result, err := p.executeToolCall(*currentToolCall)
-						if err != nil {
-							onDelta(fmt.Sprintf("\nTool execution error: %v\n", err))
-						} else {
-							onDelta(fmt.Sprintf("\nTool result: %s\n", result))
-						}
-					}
-					currentToolCall = &openai.ToolCall{
-						ID:   toolCall.ID,
-						Type: toolCall.Type,
-						Function: openai.FunctionCall{
-							Name:      toolCall.Function.Name,
-							Arguments: toolCall.Function.Arguments,
-						},
-					}
-				} else if currentToolCall != nil {
-					// 继续构建当前工具调用
-					currentToolCall.Function.Arguments += toolCall.Function.Arguments
-				}
-			}
+		for _, toolCall := range delta.ToolCalls {
+			acc.add(toolCall)
 		}
 	}
 
-	// 执行最后一个工具调用
-	if currentToolCall != nil {
-		result, err := p.executeToolCall(*currentToolCall)
+	// 流结束后再执行工具调用，此时参数已完整
+	for _, toolCall := range acc.toolCalls() {
+		result, err := p.executeToolCall(toolCall)
 		if err != nil {
 			onDelta(fmt.Sprintf("\nTool execution error: %v\n", err))
 		} else {
@@ -121,27 +247,12 @@ func (p *Provider) StreamResponse(ctx context.Context, messages []openai.ChatCom
 }
 
 // StreamResponseWithHistory 支持历史对话的流式响应
-func (p *Provider) StreamResponseWithHistory(ctx context.Context, messages []openai.ChatCompletionMessage, onDelta func(string), onToolResult func(openai.ToolCall, string)) error {
-	// 准备工具定义
-	var toolDefinitions []openai.Tool
-	for _, tool := range p.tools {
-		toolDef := openai.Tool{
-			Type: openai.ToolTypeFunction,
-			Function: openai.FunctionDefinition{
-				Name:        tool.Name(),
-				Description: tool.Description(),
-				Parameters:  tool.Parameters(),
-			},
-		}
-		toolDefinitions = append(toolDefinitions, toolDef)
-	}
+func (p *OpenAIProvider) StreamResponseWithHistory(ctx context.Context, messages []openai.ChatCompletionMessage, onDelta func(string), onToolResult func(openai.ToolCall, string)) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
 
-	req := openai.ChatCompletionRequest{
-		Model:    "gpt-4o-mini",
-		Messages: messages,
-		Tools:    toolDefinitions,
-		Stream:   true,
-	}
+	req := p.newStreamRequest(messages)
+	p.logRequest(req)
 
 	stream, err := p.client.CreateChatCompletionStream(ctx, req)
 	if err != nil {
@@ -149,8 +260,7 @@ func (p *Provider) StreamResponseWithHistory(ctx context.Context, messages []ope
 	}
 	defer stream.Close()
 
-	var currentToolCall *openai.ToolCall
-	var toolCalls []openai.ToolCall
+	var acc toolCallAccumulator
 
 	for {
 		response, err := stream.Recv()
@@ -173,33 +283,13 @@ func (p *Provider) StreamResponseWithHistory(ctx context.Context, messages []ope
 		}
 
 		// 处理工具调用
-		if len(delta.ToolCalls) > 0 {
-			for _, toolCall := range delta.ToolCalls {
-				if toolCall.ID != "" {
-					// 新的工具调用
-					currentToolCall = &openai.ToolCall{
-						ID:   toolCall.ID,
-						Type: toolCall.Type,
-						Function: openai.FunctionCall{
-							Name:      toolCall.Function.Name,
-							Arguments: toolCall.Function.Arguments,
-						},
-					}
-					toolCalls = append(toolCalls, *currentToolCall)
-				} else if currentToolCall != nil {
-					// 继续构建当前工具调用
-					currentToolCall.Function.Arguments += toolCall.Function.Arguments
-					// 更新最后一个工具调用
-					if len(toolCalls) > 0 {
-						toolCalls[len(toolCalls)-1].Function.Arguments = currentToolCall.Function.Arguments
-					}
-				}
-			}
+		for _, toolCall := range delta.ToolCalls {
+			acc.add(toolCall)
 		}
 	}
 
 	// 执行所有工具调用
-	for _, toolCall := range toolCalls {
+	for _, toolCall := range acc.toolCalls() {
 		result, err := p.executeToolCall(toolCall)
 		if err != nil {
 			result = fmt.Sprintf("Error: %v", err)
@@ -210,36 +300,35 @@ func (p *Provider) StreamResponseWithHistory(ctx context.Context, messages []ope
 	return nil
 }
 
-// StreamResponseWithTools 支持多轮对话的流式响应（不立即执行工具）
-func (p *Provider) StreamResponseWithTools(ctx context.Context, messages []openai.ChatCompletionMessage, onDelta func(string), onToolCall func(openai.ToolCall)) error {
-	// 准备工具定义
-	var toolDefinitions []openai.Tool
-	for _, tool := range p.tools {
-		toolDef := openai.Tool{
-			Type: openai.ToolTypeFunction,
-			Function: openai.FunctionDefinition{
-				Name:        tool.Name(),
-				Description: tool.Description(),
-				Parameters:  tool.Parameters(),
-			},
-		}
-		toolDefinitions = append(toolDefinitions, toolDef)
-	}
+// StreamResponseWithTools 支持多轮对话的流式响应（不立即执行工具），返回本次请求的 token 用量
+func (p *OpenAIProvider) StreamResponseWithTools(ctx context.Context, messages []openai.ChatCompletionMessage, onDelta func(string), onToolCall func(openai.ToolCall)) (Usage, error) {
+	var usage Usage
+	err := p.retryStream(ctx, func(ctx context.Context) (bool, error) {
+		var streamed bool
+		var err error
+		usage, streamed, err = p.streamWithTools(ctx, messages, onDelta, onToolCall)
+		return streamed, err
+	})
+	return usage, err
+}
 
-	req := openai.ChatCompletionRequest{
-		Model:    "gpt-4o-mini",
-		Messages: messages,
-		Tools:    toolDefinitions,
-		Stream:   true,
-	}
+// streamWithTools 执行一次流式请求，返回 token 用量以及是否已经向调用方输出过内容
+func (p *OpenAIProvider) streamWithTools(ctx context.Context, messages []openai.ChatCompletionMessage, onDelta func(string), onToolCall func(openai.ToolCall)) (Usage, bool, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
 
+	req := p.newStreamRequest(messages)
+	p.logRequest(req)
+
+	var usage Usage
 	stream, err := p.client.CreateChatCompletionStream(ctx, req)
 	if err != nil {
-		return fmt.Errorf("failed to create stream: %v", err)
+		return usage, false, fmt.Errorf("failed to create stream: %w", err)
 	}
 	defer stream.Close()
 
-	var currentToolCall *openai.ToolCall
+	streamed := false
+	var acc toolCallAccumulator
 
 	for {
 		response, err := stream.Recv()
@@ -247,7 +336,15 @@ func (p *Provider) StreamResponseWithTools(ctx context.Context, messages []opena
 			if err.Error() == "EOF" {
 				break
 			}
-			return fmt.Errorf("stream error: %v", err)
+			return usage, streamed, fmt.Errorf("stream error: %w", err)
+		}
+
+		// 用量信息在最后一个不含 choices 的数据块中返回
+		if response.Usage != nil {
+			usage = Usage{
+				PromptTokens:     response.Usage.PromptTokens,
+				CompletionTokens: response.Usage.CompletionTokens,
+			}
 		}
 
 		if len(response.Choices) == 0 {
@@ -258,51 +355,126 @@ func (p *Provider) StreamResponseWithTools(ctx context.Context, messages []opena
 
 		// 处理文本内容
 		if delta.Content != "" {
+			streamed = true
 			onDelta(delta.Content)
 		}
 
 		// 处理工具调用
-		if len(delta.ToolCalls) > 0 {
-			for _, toolCall := range delta.ToolCalls {
-				if toolCall.ID != "" {
-					// 新的工具调用
-					if currentToolCall != nil {
-						// 通知之前的工具调用
-						onToolCall(*currentToolCall)
-					}
-					currentToolCall = &openai.ToolCall{
-						ID:   toolCall.ID,
-						Type: toolCall.Type,
-						Function: openai.FunctionCall{
-							Name:      toolCall.Function.Name,
-							Arguments: toolCall.Function.Arguments,
-						},
-					}
-				} else if currentToolCall != nil {
-					// 继续构建当前工具调用
-					currentToolCall.Function.Arguments += toolCall.Function.Arguments
-				}
+		// 工具调用在流结束后才通知调用方，组装期间仍可安全重试
+		for _, toolCall := range delta.ToolCalls {
+			acc.add(toolCall)
+		}
+	}
+
+	// 流结束后再通知工具调用，此时参数已完整
+	for _, toolCall := range acc.toolCalls() {
+		onToolCall(toolCall)
+	}
+
+	return usage, true, nil
+}
+
+// toolCallAccumulator 组装流式返回的工具调用片段。
+// 同时返回多个工具调用时，各调用的参数片段交错到达，需按 Index 区分。
+type toolCallAccumulator struct {
+	calls   []openai.ToolCall
+	byIndex map[int]int // Index -> calls 中的位置
+}
+
+// add 合并一个工具调用增量
+func (a *toolCallAccumulator) add(delta openai.ToolCall) {
+	pos := -1
+	if delta.Index != nil {
+		if i, ok := a.byIndex[*delta.Index]; ok {
+			pos = i
+		}
+	} else if delta.ID == "" && len(a.calls) > 0 {
+		// 没有 Index 的服务端：不带 ID 的片段属于最近一个调用
+		pos = len(a.calls) - 1
+	}
+
+	if pos < 0 {
+		a.calls = append(a.calls, openai.ToolCall{Type: openai.ToolTypeFunction})
+		pos = len(a.calls) - 1
+		if delta.Index != nil {
+			if a.byIndex == nil {
+				a.byIndex = make(map[int]int)
 			}
+			a.byIndex[*delta.Index] = pos
 		}
 	}
 
-	// 通知最后一个工具调用
-	if currentToolCall != nil {
-		onToolCall(*currentToolCall)
+	call := &a.calls[pos]
+	// 保留 tool_call_id，供调用方回传工具结果时关联
+	if delta.ID != "" {
+		call.ID = delta.ID
 	}
+	if delta.Type != "" {
+		call.Type = delta.Type
+	}
+	if delta.Function.Name != "" {
+		call.Function.Name = delta.Function.Name
+	}
+	call.Function.Arguments += delta.Function.Arguments
+}
 
-	return nil
+// toolCalls 按出现顺序返回组装完成的工具调用
+func (a *toolCallAccumulator) toolCalls() []openai.ToolCall {
+	return a.calls
+}
+
+// retryStream 对可重试错误（429、5xx）进行指数退避重试。
+// 一旦已经向用户输出过内容就不再重试，避免重复输出。
+func (p *OpenAIProvider) retryStream(ctx context.Context, attempt func(context.Context) (bool, error)) error {
+	delay := p.retryDelay
+	for retries := 0; ; retries++ {
+		streamed, err := attempt(ctx)
+		if err == nil || streamed || retries >= p.maxRetries || !isRetryableError(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// isRetryableError 判断请求错误是否为临时性错误
+func isRetryableError(err error) bool {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return isRetryableStatus(apiErr.HTTPStatusCode)
+	}
+
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return isRetryableStatus(reqErr.HTTPStatusCode)
+	}
+
+	return false
+}
+
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
 }
 
 // ExecuteToolCall 执行工具调用（公开方法）
-func (p *Provider) ExecuteToolCall(toolCall openai.ToolCall) (string, error) {
+func (p *OpenAIProvider) ExecuteToolCall(toolCall openai.ToolCall) (string, error) {
 	return p.executeToolCall(toolCall)
 }
 
-func (p *Provider) executeToolCall(toolCall openai.ToolCall) (string, error) {
+func (p *OpenAIProvider) executeToolCall(toolCall openai.ToolCall) (string, error) {
+	return executeToolCall(p.tools, toolCall, p.logger)
+}
+
+// executeToolCall 在 toolSet 中查找并执行工具调用，与具体的模型后端无关
+func executeToolCall(toolSet []tools.Tool, toolCall openai.ToolCall, logger core.Logger) (string, error) {
 	// 找到对应的工具
 	var targetTool tools.Tool
-	for _, tool := range p.tools {
+	for _, tool := range toolSet {
 		if tool.Name() == toolCall.Function.Name {
 			targetTool = tool
 			break
@@ -314,11 +486,19 @@ func (p *Provider) executeToolCall(toolCall openai.ToolCall) (string, error) {
 	}
 
 	// 解析参数
+	logger.Debug("tool call", "name", toolCall.Function.Name, "id", toolCall.ID, "arguments", toolCall.Function.Arguments)
 	var params map[string]any
 	if err := json.Unmarshal([]byte(toolCall.Function.Arguments), &params); err != nil {
+		logger.Debug("tool arguments invalid", "name", toolCall.Function.Name, "error", err)
 		return "", fmt.Errorf("failed to parse tool arguments: %v", err)
 	}
 
 	// 执行工具
-	return targetTool.Execute(params)
-}
\ No newline at end of file
+	result, err := targetTool.Execute(params)
+	if err != nil {
+		logger.Debug("tool result", "name", toolCall.Function.Name, "length", len(result), "error", err)
+	} else {
+		logger.Debug("tool result", "name", toolCall.Function.Name, "length", len(result))
+	}
+	return result, err
+}
@@ -1,8 +1,16 @@
 package agent
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/sashabaranov/go-openai"
 
@@ -28,10 +36,10 @@ func TestNewProvider(t *testing.T) {
 	
 	toolSet := []tools.Tool{mockTool}
 	
-	provider := NewProvider(cfg, toolSet)
+	provider := NewOpenAIProvider(cfg, toolSet)
 	
 	if provider == nil {
-		t.Fatal("NewProvider() 返回 nil")
+		t.Fatal("NewOpenAIProvider() 返回 nil")
 	}
 	
 	if provider.client == nil {
@@ -140,7 +148,7 @@ func TestProvider_ExecuteToolCall(t *testing.T) {
 				OpenAIBaseURL: "https://api.openai.com/v1",
 			}
 			
-			provider := NewProvider(cfg, []tools.Tool{tt.tool})
+			provider := NewOpenAIProvider(cfg, []tools.Tool{tt.tool})
 			
 			got, err := provider.executeToolCall(tt.toolCall)
 			
@@ -173,7 +181,7 @@ func TestProvider_ToolManagement(t *testing.T) {
 	
 	toolSet := []tools.Tool{tool1, tool2}
 	
-	provider := NewProvider(cfg, toolSet)
+	provider := NewOpenAIProvider(cfg, toolSet)
 	
 	// 验证所有工具都被注册
 	if len(provider.tools) != 2 {
@@ -201,7 +209,7 @@ func TestProvider_StreamMethods(t *testing.T) {
 		OpenAIBaseURL: "https://api.openai.com/v1",
 	}
 	
-	provider := NewProvider(cfg, []tools.Tool{})
+	provider := NewOpenAIProvider(cfg, []tools.Tool{})
 	
 	// 验证 provider 有必要的方法（通过类型断言确认）
 	if provider == nil {
@@ -234,7 +242,7 @@ func TestProvider_ClientConfiguration(t *testing.T) {
 				OpenAIBaseURL: tt.baseURL,
 			}
 			
-			provider := NewProvider(cfg, []tools.Tool{})
+			provider := NewOpenAIProvider(cfg, []tools.Tool{})
 			
 			if provider.client == nil {
 				t.Error("Client 未初始化")
@@ -247,4 +255,529 @@ func TestProvider_ClientConfiguration(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestNewClientConfig(t *testing.T) {
+	tests := []struct {
+		name            string
+		cfg             *config.Config
+		wantAPIType     openai.APIType
+		wantAPIVersion  string
+		wantDeployments map[string]string // 模型名 -> 部署名
+	}{
+		{
+			name:        "默认使用标准 OpenAI 配置",
+			cfg:         &config.Config{OpenAIAPIKey: "key", OpenAIBaseURL: "https://api.openai.com/v1"},
+			wantAPIType: openai.APITypeOpenAI,
+		},
+		{
+			name: "Azure 未指定部署名时按模型名推导",
+			cfg: &config.Config{
+				OpenAIAPIKey:  "key",
+				OpenAIBaseURL: "https://example.openai.azure.com",
+				Provider:      config.ProviderAzure,
+			},
+			wantAPIType:     openai.APITypeAzure,
+			wantAPIVersion:  "2023-05-15",
+			wantDeployments: map[string]string{"gpt-3.5-turbo": "gpt-35-turbo"},
+		},
+		{
+			name: "Azure 按模型映射部署名",
+			cfg: &config.Config{
+				OpenAIAPIKey:     "key",
+				OpenAIBaseURL:    "https://example.openai.azure.com",
+				Provider:         config.ProviderAzure,
+				AzureDeployment:  "shared",
+				AzureDeployments: map[string]string{"gpt-4o": "prod-gpt4o"},
+				APIVersion:       "2024-06-01",
+			},
+			wantAPIType:     openai.APITypeAzure,
+			wantAPIVersion:  "2024-06-01",
+			wantDeployments: map[string]string{"gpt-4o": "prod-gpt4o", "gpt-4o-mini": "shared"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientConfig := newClientConfig(tt.cfg)
+
+			if clientConfig.APIType != tt.wantAPIType {
+				t.Errorf("APIType = %v, want %v", clientConfig.APIType, tt.wantAPIType)
+			}
+			if clientConfig.BaseURL != tt.cfg.OpenAIBaseURL {
+				t.Errorf("BaseURL = %v, want %v", clientConfig.BaseURL, tt.cfg.OpenAIBaseURL)
+			}
+			if clientConfig.APIVersion != tt.wantAPIVersion {
+				t.Errorf("APIVersion = %v, want %v", clientConfig.APIVersion, tt.wantAPIVersion)
+			}
+			for model, want := range tt.wantDeployments {
+				if got := clientConfig.GetAzureDeploymentByModel(model); got != want {
+					t.Errorf("模型 %s 的部署名 = %v, want %v", model, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestProvider_AzureRequest(t *testing.T) {
+	var gotPath, gotVersion, gotKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotVersion = r.URL.Query().Get("api-version")
+		gotKey = r.Header.Get(openai.AzureAPIKeyHeader)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"hi"}}]}`)
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIProvider(&config.Config{
+		OpenAIAPIKey:    "azure-key",
+		OpenAIBaseURL:   server.URL,
+		Model:           "gpt-4o",
+		Provider:        config.ProviderAzure,
+		AzureDeployment: "my-deployment",
+		APIVersion:      "2024-06-01",
+	}, nil)
+
+	if _, err := provider.Complete(context.Background(), []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hi"}}); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if gotPath != "/openai/deployments/my-deployment/chat/completions" {
+		t.Errorf("请求路径 = %s, 应使用部署名", gotPath)
+	}
+	if gotVersion != "2024-06-01" {
+		t.Errorf("api-version = %s, want 2024-06-01", gotVersion)
+	}
+	if gotKey != "azure-key" {
+		t.Errorf("%s 头 = %s, want azure-key", openai.AzureAPIKeyHeader, gotKey)
+	}
+}
+
+// newStreamServer 创建返回固定 SSE 数据块的测试服务器，并记录收到的请求
+func newStreamServer(t *testing.T, chunks []string, onRequest func(openai.ChatCompletionRequest)) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("解析请求失败: %v", err)
+		}
+		if onRequest != nil {
+			onRequest(req)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range chunks {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// contentChunk 构造只包含文本增量的数据块
+func contentChunk(content string) string {
+	data, _ := json.Marshal(openai.ChatCompletionStreamResponse{
+		Choices: []openai.ChatCompletionStreamChoice{
+			{Delta: openai.ChatCompletionStreamChoiceDelta{Content: content}},
+		},
+	})
+	return string(data)
+}
+
+func TestProvider_UsesConfiguredModel(t *testing.T) {
+	var gotModels []string
+	server := newStreamServer(t, []string{contentChunk("hi")}, func(req openai.ChatCompletionRequest) {
+		gotModels = append(gotModels, req.Model)
+	})
+
+	cfg := &config.Config{
+		OpenAIAPIKey:  "test-key",
+		OpenAIBaseURL: server.URL,
+		Model:         "custom-model",
+	}
+	provider := NewOpenAIProvider(cfg, []tools.Tool{})
+
+	messages := []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hello"}}
+	if _, err := provider.StreamResponseWithTools(context.Background(), messages, func(string) {}, func(openai.ToolCall) {}); err != nil {
+		t.Fatalf("StreamResponseWithTools() error = %v", err)
+	}
+	if err := provider.StreamResponseWithHistory(context.Background(), messages, func(string) {}, func(openai.ToolCall, string) {}); err != nil {
+		t.Fatalf("StreamResponseWithHistory() error = %v", err)
+	}
+	if err := provider.StreamResponse(context.Background(), messages, func(string) {}, nil); err != nil {
+		t.Fatalf("StreamResponse() error = %v", err)
+	}
+
+	if len(gotModels) != 3 {
+		t.Fatalf("请求次数 = %d, want 3", len(gotModels))
+	}
+	for i, model := range gotModels {
+		if model != "custom-model" {
+			t.Errorf("请求 %d 的模型 = %s, want custom-model", i, model)
+		}
+	}
+}
+
+func TestProvider_DefaultModel(t *testing.T) {
+	cfg := &config.Config{
+		OpenAIAPIKey:  "test-key",
+		OpenAIBaseURL: "https://api.openai.com/v1",
+	}
+	provider := NewOpenAIProvider(cfg, []tools.Tool{})
+
+	if provider.model != config.DefaultModel {
+		t.Errorf("model = %s, want %s", provider.model, config.DefaultModel)
+	}
+}
+
+func TestProvider_RequestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 模拟一直不返回的服务端
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	cfg := &config.Config{
+		OpenAIAPIKey:   "test-key",
+		OpenAIBaseURL:  server.URL,
+		RequestTimeout: 30,
+	}
+	provider := NewOpenAIProvider(cfg, []tools.Tool{})
+	if provider.timeout != 30*time.Second {
+		t.Fatalf("timeout = %v, want 30s", provider.timeout)
+	}
+
+	// 缩短超时以加快测试
+	provider.timeout = 50 * time.Millisecond
+
+	start := time.Now()
+	messages := []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hello"}}
+	_, err := provider.StreamResponseWithTools(context.Background(), messages, func(string) {}, func(openai.ToolCall) {})
+	if err == nil {
+		t.Fatal("StreamResponseWithTools() 期望超时错误")
+	}
+	if !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Errorf("错误不是超时错误: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("超时未生效，耗时 %v", elapsed)
+	}
+}
+
+func TestProvider_NoTimeoutByDefault(t *testing.T) {
+	cfg := &config.Config{
+		OpenAIAPIKey:  "test-key",
+		OpenAIBaseURL: "https://api.openai.com/v1",
+	}
+	provider := NewOpenAIProvider(cfg, []tools.Tool{})
+
+	ctx, cancel := provider.withTimeout(context.Background())
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("timeout 为 0 时不应设置截止时间")
+	}
+}
+
+func TestProvider_Temperature(t *testing.T) {
+	var got openai.ChatCompletionRequest
+	server := newStreamServer(t, []string{contentChunk("ok")}, func(req openai.ChatCompletionRequest) {
+		got = req
+	})
+
+	cfg := &config.Config{
+		OpenAIAPIKey:  "test-key",
+		OpenAIBaseURL: server.URL,
+		Temperature:   0.3,
+		MaxTokens:     256,
+	}
+	provider := NewOpenAIProvider(cfg, []tools.Tool{})
+
+	messages := []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hello"}}
+	if _, err := provider.StreamResponseWithTools(context.Background(), messages, func(string) {}, func(openai.ToolCall) {}); err != nil {
+		t.Fatalf("StreamResponseWithTools() error = %v", err)
+	}
+
+	if got.Temperature != 0.3 {
+		t.Errorf("Temperature = %v, want 0.3", got.Temperature)
+	}
+	if got.MaxTokens != 256 {
+		t.Errorf("MaxTokens = %v, want 256", got.MaxTokens)
+	}
+}
+
+// fakeTransport 按顺序返回预设状态码的 RoundTripper，最后一次返回成功的流
+type fakeTransport struct {
+	statuses []int
+	body     string
+	calls    int
+}
+
+func (f *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	status := http.StatusOK
+	if f.calls < len(f.statuses) {
+		status = f.statuses[f.calls]
+	}
+	f.calls++
+
+	body := f.body
+	header := http.Header{"Content-Type": []string{"text/event-stream"}}
+	if status != http.StatusOK {
+		body = `{"error":{"message":"fake failure","type":"server_error"}}`
+		header.Set("Content-Type", "application/json")
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+// newFakeTransportProvider 创建使用 fakeTransport 的 Provider
+func newFakeTransportProvider(transport *fakeTransport, maxRetries int) *OpenAIProvider {
+	cfg := &config.Config{
+		OpenAIAPIKey:  "test-key",
+		OpenAIBaseURL: "https://fake.invalid/v1",
+		MaxRetries:    maxRetries,
+	}
+	provider := NewOpenAIProvider(cfg, []tools.Tool{})
+
+	clientConfig := openai.DefaultConfig(cfg.OpenAIAPIKey)
+	clientConfig.BaseURL = cfg.OpenAIBaseURL
+	clientConfig.HTTPClient = &http.Client{Transport: transport}
+	provider.client = openai.NewClientWithConfig(clientConfig)
+	provider.retryDelay = time.Millisecond
+
+	return provider
+}
+
+func TestProvider_RetryStream(t *testing.T) {
+	okBody := fmt.Sprintf("data: %s\n\ndata: [DONE]\n\n", contentChunk("hello"))
+	messages := []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hi"}}
+
+	tests := []struct {
+		name       string
+		statuses   []int
+		maxRetries int
+		wantErr    bool
+		wantCalls  int
+	}{
+		{
+			name:       "失败两次后成功",
+			statuses:   []int{http.StatusTooManyRequests, http.StatusBadGateway},
+			maxRetries: 3,
+			wantErr:    false,
+			wantCalls:  3,
+		},
+		{
+			name:       "401 不重试",
+			statuses:   []int{http.StatusUnauthorized},
+			maxRetries: 3,
+			wantErr:    true,
+			wantCalls:  1,
+		},
+		{
+			name:       "超过最大重试次数",
+			statuses:   []int{500, 500, 500, 500},
+			maxRetries: 2,
+			wantErr:    true,
+			wantCalls:  3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &fakeTransport{statuses: tt.statuses, body: okBody}
+			provider := newFakeTransportProvider(transport, tt.maxRetries)
+
+			var output string
+			_, err := provider.StreamResponseWithTools(context.Background(), messages, func(delta string) {
+				output += delta
+			}, func(openai.ToolCall) {})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("StreamResponseWithTools() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if transport.calls != tt.wantCalls {
+				t.Errorf("请求次数 = %d, want %d", transport.calls, tt.wantCalls)
+			}
+			if !tt.wantErr && output != "hello" {
+				t.Errorf("输出 = %q, want %q", output, "hello")
+			}
+		})
+	}
+}
+
+func TestProvider_RetryStream_NoRetryAfterOutput(t *testing.T) {
+	provider := newFakeTransportProvider(&fakeTransport{}, 3)
+
+	calls := 0
+	retryable := &openai.APIError{HTTPStatusCode: http.StatusServiceUnavailable}
+	err := provider.retryStream(context.Background(), func(context.Context) (bool, error) {
+		calls++
+		return true, retryable
+	})
+
+	if err == nil {
+		t.Fatal("retryStream() 期望返回错误")
+	}
+	if calls != 1 {
+		t.Errorf("已输出内容后不应重试，调用次数 = %d", calls)
+	}
+}
+
+func TestProvider_StreamUsage(t *testing.T) {
+	usageChunk, _ := json.Marshal(openai.ChatCompletionStreamResponse{
+		Choices: []openai.ChatCompletionStreamChoice{},
+		Usage:   &openai.Usage{PromptTokens: 1234, CompletionTokens: 567, TotalTokens: 1801},
+	})
+
+	var got openai.ChatCompletionRequest
+	server := newStreamServer(t, []string{contentChunk("hi"), string(usageChunk)}, func(req openai.ChatCompletionRequest) {
+		got = req
+	})
+
+	cfg := &config.Config{
+		OpenAIAPIKey:  "test-key",
+		OpenAIBaseURL: server.URL,
+	}
+	provider := NewOpenAIProvider(cfg, []tools.Tool{})
+
+	messages := []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hello"}}
+	usage, err := provider.StreamResponseWithTools(context.Background(), messages, func(string) {}, func(openai.ToolCall) {})
+	if err != nil {
+		t.Fatalf("StreamResponseWithTools() error = %v", err)
+	}
+
+	if got.StreamOptions == nil || !got.StreamOptions.IncludeUsage {
+		t.Error("请求未设置 stream_options.include_usage")
+	}
+	if usage.PromptTokens != 1234 || usage.CompletionTokens != 567 {
+		t.Errorf("usage = %+v, want 1234 in / 567 out", usage)
+	}
+}
+
+func TestProvider_ParallelToolCalls(t *testing.T) {
+	// 录制的多工具调用流：两个调用的参数片段交错到达，后续片段只带 Index
+	chunks := []string{
+		`{"choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_a","type":"function","function":{"name":"read","arguments":""}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_b","type":"function","function":{"name":"write","arguments":""}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"path\":"}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"function":{"arguments":"{\"path\":\"b.txt\","}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"a.txt\"}"}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"function":{"arguments":"\"content\":\"hi\"}"}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+	}
+	server := newStreamServer(t, chunks, nil)
+
+	var gotArgs = map[string]map[string]any{}
+	record := func(toolCall openai.ToolCall) {
+		var args map[string]any
+		if err := json.Unmarshal([]byte(toolCall.Function.Arguments), &args); err != nil {
+			t.Errorf("工具调用 %s 参数无法解析: %q, err = %v", toolCall.ID, toolCall.Function.Arguments, err)
+			return
+		}
+		gotArgs[toolCall.ID] = args
+	}
+
+	want := map[string]map[string]any{
+		"call_a": {"path": "a.txt"},
+		"call_b": {"path": "b.txt", "content": "hi"},
+	}
+	check := func(t *testing.T) {
+		t.Helper()
+		if len(gotArgs) != len(want) {
+			t.Fatalf("工具调用数 = %d, want %d", len(gotArgs), len(want))
+		}
+		for id, wantArgs := range want {
+			for key, value := range wantArgs {
+				if gotArgs[id][key] != value {
+					t.Errorf("%s 参数 %s = %v, want %v", id, key, gotArgs[id][key], value)
+				}
+			}
+		}
+	}
+
+	provider := NewOpenAIProvider(&config.Config{OpenAIAPIKey: "test-key", OpenAIBaseURL: server.URL}, nil)
+
+	t.Run("StreamResponseWithHistory", func(t *testing.T) {
+		gotArgs = map[string]map[string]any{}
+		err := provider.StreamResponseWithHistory(context.Background(), nil, func(string) {}, func(toolCall openai.ToolCall, result string) {
+			record(toolCall)
+		})
+		if err != nil {
+			t.Fatalf("StreamResponseWithHistory() error = %v", err)
+		}
+		check(t)
+	})
+
+	t.Run("StreamResponseWithTools", func(t *testing.T) {
+		gotArgs = map[string]map[string]any{}
+		_, err := provider.StreamResponseWithTools(context.Background(), nil, func(string) {}, record)
+		if err != nil {
+			t.Fatalf("StreamResponseWithTools() error = %v", err)
+		}
+		check(t)
+	})
+}
+
+func TestProvider_Complete(t *testing.T) {
+	var gotReq openai.ChatCompletionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&gotReq); err != nil {
+			t.Errorf("解析请求失败: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{
+				Message: openai.ChatCompletionMessage{
+					Role:    openai.ChatMessageRoleAssistant,
+					Content: "让我读取文件",
+					ToolCalls: []openai.ToolCall{{
+						ID:       "call_1",
+						Type:     openai.ToolTypeFunction,
+						Function: openai.FunctionCall{Name: "test_tool", Arguments: `{"path":"a.txt"}`},
+					}},
+				},
+			}},
+			Usage: openai.Usage{PromptTokens: 12, CompletionTokens: 5},
+		})
+	}))
+	defer server.Close()
+
+	mockTool := &MockTool{name: "test_tool", description: "Test tool"}
+	provider := NewOpenAIProvider(&config.Config{OpenAIAPIKey: "test-key", OpenAIBaseURL: server.URL}, []tools.Tool{mockTool})
+
+	completion, err := provider.Complete(context.Background(), []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleUser, Content: "read a.txt"},
+	})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	if gotReq.Stream {
+		t.Error("Complete() 不应发送流式请求")
+	}
+	if len(gotReq.Tools) != 1 || gotReq.Tools[0].Function.Name != "test_tool" {
+		t.Errorf("请求工具定义 = %+v, want test_tool", gotReq.Tools)
+	}
+	if completion.Content != "让我读取文件" {
+		t.Errorf("Content = %q, want %q", completion.Content, "让我读取文件")
+	}
+	if len(completion.ToolCalls) != 1 || completion.ToolCalls[0].ID != "call_1" {
+		t.Fatalf("ToolCalls = %+v, want call_1", completion.ToolCalls)
+	}
+	if completion.ToolCalls[0].Function.Arguments != `{"path":"a.txt"}` {
+		t.Errorf("Arguments = %q", completion.ToolCalls[0].Function.Arguments)
+	}
+	if completion.Usage != (Usage{PromptTokens: 12, CompletionTokens: 5}) {
+		t.Errorf("Usage = %+v, want 12/5", completion.Usage)
+	}
+}
@@ -0,0 +1,18 @@
+package agent
+
+// ToolCallRecord 记录单次工具调用及其结果
+type ToolCallRecord struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+	Result    string `json:"result"`
+}
+
+// RunResult 单次模式的执行结果，--json 模式下以 JSON 输出
+type RunResult struct {
+	FinalMessage string           `json:"final_message"`
+	ToolCalls    []ToolCallRecord `json:"tool_calls"`
+	Rounds       int              `json:"rounds"`
+	Usage        Usage            `json:"usage"`
+	// MaxRoundsReached 达到最大轮次时模型仍在调用工具，任务可能没有完成
+	MaxRoundsReached bool `json:"max_rounds_reached"`
+}
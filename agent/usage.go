@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"opencode_nano/config"
+)
+
+// Usage 记录 token 用量
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// Add 累加另一次请求的用量
+func (u *Usage) Add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+}
+
+// Total 返回总 token 数
+func (u Usage) Total() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// String 返回紧凑的用量描述
+func (u Usage) String() string {
+	return fmt.Sprintf("tokens: %d in / %d out", u.PromptTokens, u.CompletionTokens)
+}
+
+// EstimatedCost 按当前模型的价格估算用量的费用（美元），模型没有价格时返回 false
+func (a *Agent) EstimatedCost(usage Usage) (float64, bool) {
+	return estimateCost(usage, a.model, a.prices)
+}
+
+// estimateCost 先按模型名精确查找价格，找不到时使用最长的前缀匹配
+// （如 gpt-4o-mini-2024-07-18 使用 gpt-4o-mini 的价格）
+func estimateCost(usage Usage, model string, prices map[string]config.ModelPrice) (float64, bool) {
+	price, ok := prices[model]
+	if !ok {
+		best := ""
+		for name, p := range prices {
+			if name != "" && strings.HasPrefix(model, name) && len(name) > len(best) {
+				best, price, ok = name, p, true
+			}
+		}
+	}
+	if !ok {
+		return 0, false
+	}
+	cost := float64(usage.PromptTokens)/1000*price.Input + float64(usage.CompletionTokens)/1000*price.Output
+	return cost, true
+}
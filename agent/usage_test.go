@@ -0,0 +1,79 @@
+package agent
+
+import (
+	"math"
+	"testing"
+
+	"opencode_nano/config"
+)
+
+func TestUsage(t *testing.T) {
+	var total Usage
+	total.Add(Usage{PromptTokens: 100, CompletionTokens: 20})
+	total.Add(Usage{PromptTokens: 1134, CompletionTokens: 547})
+
+	if total.PromptTokens != 1234 || total.CompletionTokens != 567 {
+		t.Errorf("累加结果 = %+v", total)
+	}
+	if total.Total() != 1801 {
+		t.Errorf("Total() = %d, want 1801", total.Total())
+	}
+	if got := total.String(); got != "tokens: 1234 in / 567 out" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestAgent_RecordUsage(t *testing.T) {
+	a := &Agent{}
+	a.recordUsage(Usage{PromptTokens: 10, CompletionTokens: 5})
+	a.recordUsage(Usage{PromptTokens: 1, CompletionTokens: 2})
+
+	got := a.TotalUsage()
+	if got.PromptTokens != 11 || got.CompletionTokens != 7 {
+		t.Errorf("TotalUsage() = %+v, want 11 in / 7 out", got)
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	prices := map[string]config.ModelPrice{
+		"gpt-4o-mini": {Input: 0.00015, Output: 0.0006},
+		"gpt-4o":      {Input: 0.0025, Output: 0.01},
+	}
+	usage := Usage{PromptTokens: 10000, CompletionTokens: 2000}
+
+	tests := []struct {
+		name   string
+		model  string
+		want   float64
+		wantOK bool
+	}{
+		{"精确匹配", "gpt-4o-mini", 0.0015 + 0.0012, true},
+		{"带日期的模型使用最长前缀", "gpt-4o-mini-2024-07-18", 0.0015 + 0.0012, true},
+		{"另一个模型", "gpt-4o", 0.025 + 0.02, true},
+		{"未知模型", "claude-3", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := estimateCost(usage, tt.model, prices)
+			if ok != tt.wantOK {
+				t.Fatalf("estimateCost() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("estimateCost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAgent_EstimatedCost(t *testing.T) {
+	a := &Agent{model: "gpt-4o-mini", prices: config.DefaultPrices()}
+	cost, ok := a.EstimatedCost(Usage{PromptTokens: 1000, CompletionTokens: 1000})
+	if !ok || math.Abs(cost-0.00075) > 1e-9 {
+		t.Errorf("EstimatedCost() = %v, %v, want 0.00075, true", cost, ok)
+	}
+
+	a.model = "unknown-model"
+	if _, ok := a.EstimatedCost(Usage{PromptTokens: 1000}); ok {
+		t.Error("未知模型应返回费用不可用")
+	}
+}
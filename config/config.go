@@ -3,28 +3,510 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultModel 未配置时使用的模型
+	DefaultModel = "gpt-4o-mini"
+	// DefaultMaxRetries 请求失败时的默认重试次数
+	DefaultMaxRetries = 3
+	// DefaultMaxContextTokens 对话历史的默认 token 预算
+	DefaultMaxContextTokens = 100000
+	// DefaultMaxRounds 每次对话中模型与工具交互的默认最大轮次
+	DefaultMaxRounds = 10
+	// DefaultMultilineFence 交互模式下开始和结束多行输入的默认分隔符
+	DefaultMultilineFence = `"""`
+)
+
+const (
+	// ProviderOpenAI 标准 OpenAI 兼容接口（默认）
+	ProviderOpenAI = "openai"
+	// ProviderAzure Azure OpenAI 服务，按部署名调用模型
+	ProviderAzure = "azure"
 )
 
+// ModelPrice 模型的 token 单价（美元 / 1K tokens）
+type ModelPrice struct {
+	Input  float64 `yaml:"input"`
+	Output float64 `yaml:"output"`
+}
+
+// DefaultPrices 返回内置的模型价格表
+func DefaultPrices() map[string]ModelPrice {
+	return map[string]ModelPrice{
+		"gpt-4o-mini": {Input: 0.00015, Output: 0.0006},
+	}
+}
+
 type Config struct {
-	OpenAIAPIKey string
+	OpenAIAPIKey  string
 	OpenAIBaseURL string
+	Model         string
+	Temperature   float32
+	MaxTokens     int
+	// Provider 接口类型：openai（默认）或 azure
+	Provider string
+	// AzureDeployment Azure 下所有模型共用的部署名，为空时按 AzureDeployments 或模型名推导
+	AzureDeployment string
+	// AzureDeployments Azure 下按模型名指定的部署名，优先于 AzureDeployment
+	AzureDeployments map[string]string
+	// APIVersion Azure 接口版本，为空时使用客户端默认值
+	APIVersion string
+	// RequestTimeout 单次请求超时时间（秒），0 表示不限制
+	RequestTimeout int
+	// MaxRetries 遇到 429/5xx 等临时错误时的最大重试次数
+	MaxRetries int
+	// MaxContextTokens 对话历史的 token 预算，超出时丢弃最早的消息，0 表示不限制
+	MaxContextTokens int
+	// MaxRounds 每次对话中模型与工具交互的最大轮次，达到后停止并提示任务可能未完成
+	MaxRounds int
+	// Debug 是否向 stderr 输出调试日志
+	Debug bool
+	// DryRun 有副作用的工具调用只报告将要执行的操作，不修改文件也不执行命令
+	DryRun bool
+	// EnablePlugins 是否从 PluginDir 加载 Go 插件工具（仅 Linux、macOS 等支持 plugin 包的平台）
+	EnablePlugins bool
+	// PluginDir 插件目录，未配置时为 ~/.opencode_nano/plugins
+	PluginDir string
+	// DisabledTools 禁用的工具名称（或别名），这些工具不会注册，也不会提供给模型
+	DisabledTools []string
+	// BashDenyPatterns 在内置危险命令之外额外禁止的命令片段
+	BashDenyPatterns []string
+	// BashAllowPrefixes 以这些前缀开头的简单命令跳过危险模式检查
+	BashAllowPrefixes []string
+	// PermissionAllow 自动批准的权限规则
+	PermissionAllow []PermissionRule
+	// PermissionDeny 自动拒绝的权限规则，优先于 PermissionAllow
+	PermissionDeny []PermissionRule
+	// MultilineFence 交互模式下单独一行输入该分隔符时开始或结束多行输入
+	MultilineFence string
+	// SystemPromptFile 替换内置系统提示词的文件，其中的 %s 会替换为当前工作目录
+	SystemPromptFile string
+	// SystemPromptAppend 追加到系统提示词末尾的额外指令
+	SystemPromptAppend string
+	// Prices 按模型名索引的价格表，用于估算费用
+	Prices map[string]ModelPrice
+	// Profile 生效的配置档案名，未使用档案时为空
+	Profile string
+}
+
+// PermissionRule 权限规则，action 匹配工具名，pattern 匹配操作描述（子串或 * ? 通配符）
+type PermissionRule struct {
+	Action  string `yaml:"action"`
+	Pattern string `yaml:"pattern"`
+}
+
+// DefaultProfileName 未指定档案且未设置 default_profile 时，存在同名档案则使用它
+const DefaultProfileName = "default"
+
+// profileConfig 可以按档案切换的连接配置，顶层键与 profiles 下的每个档案共用
+type profileConfig struct {
+	APIKey      string   `yaml:"api_key"`
+	BaseURL     string   `yaml:"base_url"`
+	Model       string   `yaml:"model"`
+	Temperature *float32 `yaml:"temperature"`
+	MaxTokens   int      `yaml:"max_tokens"`
+
+	Provider         string            `yaml:"provider"`
+	AzureDeployment  string            `yaml:"azure_deployment"`
+	AzureDeployments map[string]string `yaml:"azure_deployments"`
+	APIVersion       string            `yaml:"api_version"`
 }
 
+// fileConfig 配置文件结构（YAML，兼容 JSON）
+type fileConfig struct {
+	profileConfig `yaml:",inline"`
+
+	// Profiles 命名的配置档案，选中的档案覆盖顶层键
+	Profiles map[string]profileConfig `yaml:"profiles"`
+	// DefaultProfile 未通过 --profile 或 OPENCODE_PROFILE 指定时使用的档案
+	DefaultProfile string `yaml:"default_profile"`
+
+	DisabledTools []string `yaml:"disabled_tools"`
+
+	EnablePlugins bool   `yaml:"enable_plugins"`
+	PluginDir     string `yaml:"plugin_dir"`
+
+	BashDenyPatterns  []string `yaml:"bash_deny_patterns"`
+	BashAllowPrefixes []string `yaml:"bash_allow_prefixes"`
+
+	MultilineFence string `yaml:"multiline_fence"`
+
+	DryRun bool `yaml:"dry_run"`
+
+	SystemPromptFile   string `yaml:"system_prompt_file"`
+	SystemPromptAppend string `yaml:"system_prompt_append"`
+
+	Prices map[string]ModelPrice `yaml:"prices"`
+
+	Permissions struct {
+		Allow []PermissionRule `yaml:"allow"`
+		Deny  []PermissionRule `yaml:"deny"`
+	} `yaml:"permissions"`
+}
+
+// Load 加载配置，档案由 OPENCODE_PROFILE 或配置文件中的 default_profile 决定
 func Load() (*Config, error) {
-	apiKey := strings.TrimSpace(os.Getenv("OPENAI_API_KEY"))
-	if apiKey == "" {
+	return LoadProfile("")
+}
+
+// LoadProfile 加载配置并应用指定的档案，name 为空时依次使用 OPENCODE_PROFILE、
+// default_profile 和名为 default 的档案，都没有时只使用顶层键
+func LoadProfile(name string) (*Config, error) {
+	cfg := &Config{
+		MaxRetries:       DefaultMaxRetries,
+		MaxContextTokens: DefaultMaxContextTokens,
+		MaxRounds:        DefaultMaxRounds,
+		MultilineFence:   DefaultMultilineFence,
+		Prices:           DefaultPrices(),
+	}
+
+	// 持久化的环境变量（如 OPENAI_API_KEY）不覆盖已有的环境变量
+	if err := applyEnvFile(EnvFilePath()); err != nil {
+		return nil, err
+	}
+
+	// 先加载配置文件（用户目录 < 当前目录），再应用选中的档案，最后由环境变量覆盖
+	profiles := make(map[string][]profileConfig)
+	defaultProfile := ""
+	for _, file := range configPaths() {
+		fc, err := loadFile(file.path)
+		if err != nil {
+			return nil, err
+		}
+		if fc == nil {
+			continue
+		}
+		mergeFile(fc, cfg, file.trusted)
+		for profileName, p := range fc.Profiles {
+			profileName = strings.TrimSpace(profileName)
+			profiles[profileName] = append(profiles[profileName], p)
+		}
+		if v := strings.TrimSpace(fc.DefaultProfile); v != "" {
+			defaultProfile = v
+		}
+	}
+
+	if err := applyProfile(cfg, profiles, strings.TrimSpace(name), defaultProfile); err != nil {
+		return nil, err
+	}
+
+	if apiKey := strings.TrimSpace(os.Getenv("OPENAI_API_KEY")); apiKey != "" {
+		cfg.OpenAIAPIKey = apiKey
+	}
+	if cfg.OpenAIAPIKey == "" {
 		return nil, fmt.Errorf("OPENAI_API_KEY environment variable is required")
 	}
 
-	baseURL := strings.TrimSpace(os.Getenv("OPENAI_BASE_URL"))
-	// 如果没有设置，使用默认的 OpenAI URL
-	if baseURL == "" {
-		baseURL = "https://api.openai.com/v1"
+	if provider := strings.TrimSpace(os.Getenv("OPENAI_PROVIDER")); provider != "" {
+		cfg.Provider = strings.ToLower(provider)
+	}
+	if cfg.Provider == "" {
+		cfg.Provider = ProviderOpenAI
+	}
+	if cfg.Provider != ProviderOpenAI && cfg.Provider != ProviderAzure {
+		return nil, fmt.Errorf("provider must be %q or %q: %q", ProviderOpenAI, ProviderAzure, cfg.Provider)
+	}
+	if deployment := strings.TrimSpace(os.Getenv("AZURE_OPENAI_DEPLOYMENT")); deployment != "" {
+		cfg.AzureDeployment = deployment
+	}
+	if version := strings.TrimSpace(os.Getenv("OPENAI_API_VERSION")); version != "" {
+		cfg.APIVersion = version
+	}
+
+	if baseURL := strings.TrimSpace(os.Getenv("OPENAI_BASE_URL")); baseURL != "" {
+		cfg.OpenAIBaseURL = baseURL
+	}
+	if cfg.OpenAIBaseURL == "" {
+		// Azure 的端点因资源而异，没有默认值
+		if cfg.Provider == ProviderAzure {
+			return nil, fmt.Errorf("OPENAI_BASE_URL (the Azure OpenAI endpoint) is required when provider is azure")
+		}
+		// 如果没有设置，使用默认的 OpenAI URL
+		cfg.OpenAIBaseURL = "https://api.openai.com/v1"
+	}
+
+	if model := strings.TrimSpace(os.Getenv("OPENAI_MODEL")); model != "" {
+		cfg.Model = model
+	}
+	if cfg.Model == "" {
+		cfg.Model = DefaultModel
+	}
+
+	if v := strings.TrimSpace(os.Getenv("OPENAI_TIMEOUT")); v != "" {
+		timeout, err := strconv.Atoi(v)
+		if err != nil || timeout < 0 {
+			return nil, fmt.Errorf("OPENAI_TIMEOUT must be a non-negative integer (seconds): %q", v)
+		}
+		cfg.RequestTimeout = timeout
+	}
+
+	if v := strings.TrimSpace(os.Getenv("OPENAI_MAX_RETRIES")); v != "" {
+		retries, err := strconv.Atoi(v)
+		if err != nil || retries < 0 {
+			return nil, fmt.Errorf("OPENAI_MAX_RETRIES must be a non-negative integer: %q", v)
+		}
+		cfg.MaxRetries = retries
+	}
+
+	if v := strings.TrimSpace(os.Getenv("OPENCODE_MAX_CONTEXT_TOKENS")); v != "" {
+		tokens, err := strconv.Atoi(v)
+		if err != nil || tokens < 0 {
+			return nil, fmt.Errorf("OPENCODE_MAX_CONTEXT_TOKENS must be a non-negative integer: %q", v)
+		}
+		cfg.MaxContextTokens = tokens
+	}
+
+	if v := strings.TrimSpace(os.Getenv("OPENCODE_MAX_ROUNDS")); v != "" {
+		rounds, err := strconv.Atoi(v)
+		if err != nil || rounds <= 0 {
+			return nil, fmt.Errorf("OPENCODE_MAX_ROUNDS must be a positive integer: %q", v)
+		}
+		cfg.MaxRounds = rounds
+	}
+
+	if v := strings.TrimSpace(os.Getenv("OPENCODE_DEBUG")); v != "" {
+		debug, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("OPENCODE_DEBUG must be a boolean: %q", v)
+		}
+		cfg.Debug = debug
+	}
+
+	// 逗号分隔，追加到配置文件中禁用的工具之后
+	if v := strings.TrimSpace(os.Getenv("OPENCODE_DISABLED_TOOLS")); v != "" {
+		cfg.DisabledTools = appendNonEmpty(cfg.DisabledTools, strings.Split(v, ","))
+	}
+
+	if v := strings.TrimSpace(os.Getenv("OPENCODE_PLUGINS")); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("OPENCODE_PLUGINS must be a boolean: %q", v)
+		}
+		cfg.EnablePlugins = enabled
+	}
+	if cfg.PluginDir == "" {
+		cfg.PluginDir = DefaultPluginDir()
+	}
+
+	if v := strings.TrimSpace(os.Getenv("OPENCODE_DRY_RUN")); v != "" {
+		dryRun, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("OPENCODE_DRY_RUN must be a boolean: %q", v)
+		}
+		cfg.DryRun = dryRun
+	}
+
+	if v := strings.TrimSpace(os.Getenv("OPENAI_TEMPERATURE")); v != "" {
+		temperature, err := strconv.ParseFloat(v, 32)
+		if err != nil {
+			return nil, fmt.Errorf("OPENAI_TEMPERATURE must be a number: %q", v)
+		}
+		cfg.Temperature = float32(temperature)
+	}
+
+	return cfg, nil
+}
+
+// LoadDisabledTools 只读取配置文件和 OPENCODE_DISABLED_TOOLS 中禁用的工具，不要求 API Key，
+// 供 --list-tools、schema 等不连接模型的命令使用
+func LoadDisabledTools() ([]string, error) {
+	if err := applyEnvFile(EnvFilePath()); err != nil {
+		return nil, err
+	}
+
+	var disabled []string
+	for _, file := range configPaths() {
+		fc, err := loadFile(file.path)
+		if err != nil {
+			return nil, err
+		}
+		if fc != nil {
+			disabled = appendNonEmpty(disabled, fc.DisabledTools)
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("OPENCODE_DISABLED_TOOLS")); v != "" {
+		disabled = appendNonEmpty(disabled, strings.Split(v, ","))
+	}
+	return disabled, nil
+}
+
+// DefaultPluginDir 返回默认的插件目录 ~/.opencode_nano/plugins
+func DefaultPluginDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".opencode_nano", "plugins")
+}
+
+// configFile 配置文件路径，trusted 为 false 的文件来自当前目录，可能随不受信任的仓库一起分发
+type configFile struct {
+	path    string
+	trusted bool
+}
+
+// configPaths 返回按优先级从低到高排列的配置文件
+func configPaths() []configFile {
+	var files []configFile
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		files = append(files, configFile{path: filepath.Join(homeDir, ".opencode_nano", "config.yaml"), trusted: true})
+	}
+	files = append(files, configFile{path: "opencode_nano.yaml"})
+	return files
+}
+
+// loadFile 读取并解析配置文件，文件不存在时返回 nil
+func loadFile(path string) (*fileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read config file %s: %v", path, err)
+	}
+
+	var fc fileConfig
+	if err := yaml.Unmarshal(data, &fc); err != nil {
+		// yaml 的错误信息中包含出错的行号
+		return nil, fmt.Errorf("failed to parse config file %s: %v", path, err)
+	}
+	return &fc, nil
+}
+
+// applyProfile 按名称选出档案，将它在各配置文件中的定义依次合并到 cfg
+func applyProfile(cfg *Config, profiles map[string][]profileConfig, name, defaultProfile string) error {
+	if name == "" {
+		name = strings.TrimSpace(os.Getenv("OPENCODE_PROFILE"))
+	}
+	if name == "" {
+		name = defaultProfile
+	}
+	if name == "" {
+		if _, ok := profiles[DefaultProfileName]; !ok {
+			return nil
+		}
+		name = DefaultProfileName
 	}
 
-	return &Config{
-		OpenAIAPIKey: apiKey,
-		OpenAIBaseURL: baseURL,
-	}, nil
-}
\ No newline at end of file
+	layers, ok := profiles[name]
+	if !ok {
+		available := make([]string, 0, len(profiles))
+		for profileName := range profiles {
+			available = append(available, profileName)
+		}
+		sort.Strings(available)
+		if len(available) == 0 {
+			return fmt.Errorf("profile %q not found: no profiles defined in config files", name)
+		}
+		return fmt.Errorf("profile %q not found (available: %s)", name, strings.Join(available, ", "))
+	}
+	for _, p := range layers {
+		mergeConnection(p, cfg)
+	}
+	cfg.Profile = name
+	return nil
+}
+
+// mergeConnection 将连接配置中设置了的字段合并到 cfg
+func mergeConnection(p profileConfig, cfg *Config) {
+	if v := strings.TrimSpace(p.APIKey); v != "" {
+		cfg.OpenAIAPIKey = v
+	}
+	if v := strings.TrimSpace(p.BaseURL); v != "" {
+		cfg.OpenAIBaseURL = v
+	}
+	if v := strings.TrimSpace(p.Model); v != "" {
+		cfg.Model = v
+	}
+	if p.Temperature != nil {
+		cfg.Temperature = *p.Temperature
+	}
+	if p.MaxTokens > 0 {
+		cfg.MaxTokens = p.MaxTokens
+	}
+	if v := strings.TrimSpace(p.Provider); v != "" {
+		cfg.Provider = strings.ToLower(v)
+	}
+	if v := strings.TrimSpace(p.AzureDeployment); v != "" {
+		cfg.AzureDeployment = v
+	}
+	// 部署映射按模型覆盖
+	for model, deployment := range p.AzureDeployments {
+		if cfg.AzureDeployments == nil {
+			cfg.AzureDeployments = make(map[string]string)
+		}
+		cfg.AzureDeployments[strings.TrimSpace(model)] = strings.TrimSpace(deployment)
+	}
+	if v := strings.TrimSpace(p.APIVersion); v != "" {
+		cfg.APIVersion = v
+	}
+}
+
+// mergeFile 将配置文件的顶层键合并到 cfg。
+// 会执行代码或放宽权限的设置（插件、允许规则、命令前缀）只从受信任的用户目录配置读取
+func mergeFile(fc *fileConfig, cfg *Config, trusted bool) {
+	mergeConnection(fc.profileConfig, cfg)
+	if v := strings.TrimSpace(fc.MultilineFence); v != "" {
+		cfg.MultilineFence = v
+	}
+	if fc.DryRun {
+		cfg.DryRun = true
+	}
+	if trusted && fc.EnablePlugins {
+		cfg.EnablePlugins = true
+	}
+	if v := strings.TrimSpace(fc.PluginDir); trusted && v != "" {
+		cfg.PluginDir = v
+	}
+	if v := strings.TrimSpace(fc.SystemPromptFile); v != "" {
+		cfg.SystemPromptFile = v
+	}
+	if v := strings.TrimSpace(fc.SystemPromptAppend); v != "" {
+		cfg.SystemPromptAppend = v
+	}
+	// 价格按模型覆盖内置价格
+	for model, price := range fc.Prices {
+		if cfg.Prices == nil {
+			cfg.Prices = make(map[string]ModelPrice)
+		}
+		cfg.Prices[strings.TrimSpace(model)] = price
+	}
+	// 禁用的工具和命令规则在多个配置文件之间累加
+	cfg.DisabledTools = appendNonEmpty(cfg.DisabledTools, fc.DisabledTools)
+	cfg.BashDenyPatterns = appendNonEmpty(cfg.BashDenyPatterns, fc.BashDenyPatterns)
+	// 当前目录的配置只能收紧权限：允许规则和跳过检查的命令前缀只从用户目录配置读取
+	if trusted {
+		cfg.BashAllowPrefixes = appendNonEmpty(cfg.BashAllowPrefixes, fc.BashAllowPrefixes)
+		cfg.PermissionAllow = appendRules(cfg.PermissionAllow, fc.Permissions.Allow)
+	}
+	cfg.PermissionDeny = appendRules(cfg.PermissionDeny, fc.Permissions.Deny)
+}
+
+// appendNonEmpty 追加去除首尾空白后非空的条目
+func appendNonEmpty(dst, items []string) []string {
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			dst = append(dst, item)
+		}
+	}
+	return dst
+}
+
+// appendRules 追加至少设置了 action 或 pattern 的规则
+func appendRules(dst, rules []PermissionRule) []PermissionRule {
+	for _, rule := range rules {
+		rule.Action = strings.TrimSpace(rule.Action)
+		rule.Pattern = strings.TrimSpace(rule.Pattern)
+		if rule.Action != "" || rule.Pattern != "" {
+			dst = append(dst, rule)
+		}
+	}
+	return dst
+}
@@ -2,10 +2,34 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
+// isolateConfigFiles 将 HOME 和工作目录指向空的临时目录，避免读取真实配置文件
+func isolateConfigFiles(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	cwd := t.TempDir()
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(cwd); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(oldWd) })
+
+	return home
+}
+
 func TestLoad(t *testing.T) {
+	isolateConfigFiles(t)
+
 	tests := []struct {
 		name    string
 		setup   func()
@@ -123,6 +147,284 @@ func TestLoad(t *testing.T) {
 	}
 }
 
+func TestLoad_ConfigFile(t *testing.T) {
+	home := isolateConfigFiles(t)
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("OPENAI_BASE_URL", "")
+	t.Setenv("OPENAI_MODEL", "")
+
+	// 用户目录配置
+	homeDir := filepath.Join(home, ".opencode_nano")
+	if err := os.MkdirAll(homeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	homeConfig := `api_key: home-key
+base_url: https://home.api.com/v1
+model: home-model
+temperature: 0.2
+max_tokens: 512
+multiline_fence: "<<<"
+system_prompt_file: prompt.md
+system_prompt_append: |
+  Always answer in English.
+prices:
+  home-model:
+    input: 0.001
+    output: 0.002
+`
+	if err := os.WriteFile(filepath.Join(homeDir, "config.yaml"), []byte(homeConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 当前目录配置优先
+	localConfig := `model: local-model
+temperature: 0.7
+`
+	if err := os.WriteFile("opencode_nano.yaml", []byte(localConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 环境变量优先于配置文件
+	os.Setenv("OPENAI_BASE_URL", "https://env.api.com/v1")
+	defer os.Unsetenv("OPENAI_BASE_URL")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.OpenAIAPIKey != "home-key" {
+		t.Errorf("OpenAIAPIKey = %v, want %v", cfg.OpenAIAPIKey, "home-key")
+	}
+	if cfg.OpenAIBaseURL != "https://env.api.com/v1" {
+		t.Errorf("OpenAIBaseURL = %v, want %v", cfg.OpenAIBaseURL, "https://env.api.com/v1")
+	}
+	if cfg.Model != "local-model" {
+		t.Errorf("Model = %v, want %v", cfg.Model, "local-model")
+	}
+	if cfg.Temperature != 0.7 {
+		t.Errorf("Temperature = %v, want %v", cfg.Temperature, 0.7)
+	}
+	if cfg.MaxTokens != 512 {
+		t.Errorf("MaxTokens = %v, want %v", cfg.MaxTokens, 512)
+	}
+	if cfg.MultilineFence != "<<<" {
+		t.Errorf("MultilineFence = %v, want %v", cfg.MultilineFence, "<<<")
+	}
+	if cfg.SystemPromptFile != "prompt.md" {
+		t.Errorf("SystemPromptFile = %v, want %v", cfg.SystemPromptFile, "prompt.md")
+	}
+	if cfg.SystemPromptAppend != "Always answer in English." {
+		t.Errorf("SystemPromptAppend = %q, want %q", cfg.SystemPromptAppend, "Always answer in English.")
+	}
+	wantPrices := map[string]ModelPrice{
+		"gpt-4o-mini": DefaultPrices()["gpt-4o-mini"],
+		"home-model":  {Input: 0.001, Output: 0.002},
+	}
+	if !reflect.DeepEqual(cfg.Prices, wantPrices) {
+		t.Errorf("Prices = %v, want %v", cfg.Prices, wantPrices)
+	}
+}
+
+func TestLoad_Model(t *testing.T) {
+	isolateConfigFiles(t)
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	t.Setenv("OPENAI_MODEL", "")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Model != DefaultModel {
+		t.Errorf("Model = %v, want %v", cfg.Model, DefaultModel)
+	}
+
+	t.Setenv("OPENAI_MODEL", "  gpt-4.1  ")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Model != "gpt-4.1" {
+		t.Errorf("Model = %v, want %v", cfg.Model, "gpt-4.1")
+	}
+}
+
+func TestLoad_TimeoutAndTemperature(t *testing.T) {
+	isolateConfigFiles(t)
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	t.Setenv("OPENAI_TIMEOUT", "45")
+	t.Setenv("OPENAI_TEMPERATURE", "0.5")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.RequestTimeout != 45 {
+		t.Errorf("RequestTimeout = %v, want 45", cfg.RequestTimeout)
+	}
+	if cfg.Temperature != 0.5 {
+		t.Errorf("Temperature = %v, want 0.5", cfg.Temperature)
+	}
+
+	t.Setenv("OPENAI_TIMEOUT", "abc")
+	if _, err := Load(); err == nil {
+		t.Error("无效的 OPENAI_TIMEOUT 应返回错误")
+	}
+
+	t.Setenv("OPENAI_TIMEOUT", "")
+	t.Setenv("OPENAI_TEMPERATURE", "hot")
+	if _, err := Load(); err == nil {
+		t.Error("无效的 OPENAI_TEMPERATURE 应返回错误")
+	}
+}
+
+func TestLoad_MaxRetries(t *testing.T) {
+	isolateConfigFiles(t)
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	t.Setenv("OPENAI_MAX_RETRIES", "")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.MaxRetries != DefaultMaxRetries {
+		t.Errorf("MaxRetries = %v, want %v", cfg.MaxRetries, DefaultMaxRetries)
+	}
+
+	t.Setenv("OPENAI_MAX_RETRIES", "0")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.MaxRetries != 0 {
+		t.Errorf("MaxRetries = %v, want 0", cfg.MaxRetries)
+	}
+
+	t.Setenv("OPENAI_MAX_RETRIES", "-1")
+	if _, err := Load(); err == nil {
+		t.Error("负数的 OPENAI_MAX_RETRIES 应返回错误")
+	}
+}
+
+func TestLoad_MaxContextTokens(t *testing.T) {
+	isolateConfigFiles(t)
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	t.Setenv("OPENCODE_MAX_CONTEXT_TOKENS", "")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.MaxContextTokens != DefaultMaxContextTokens {
+		t.Errorf("MaxContextTokens = %v, want %v", cfg.MaxContextTokens, DefaultMaxContextTokens)
+	}
+
+	t.Setenv("OPENCODE_MAX_CONTEXT_TOKENS", "8000")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.MaxContextTokens != 8000 {
+		t.Errorf("MaxContextTokens = %v, want 8000", cfg.MaxContextTokens)
+	}
+}
+
+func TestLoad_MaxRounds(t *testing.T) {
+	isolateConfigFiles(t)
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	t.Setenv("OPENCODE_MAX_ROUNDS", "")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.MaxRounds != DefaultMaxRounds {
+		t.Errorf("MaxRounds = %v, want %v", cfg.MaxRounds, DefaultMaxRounds)
+	}
+
+	t.Setenv("OPENCODE_MAX_ROUNDS", "25")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.MaxRounds != 25 {
+		t.Errorf("MaxRounds = %v, want 25", cfg.MaxRounds)
+	}
+
+	for _, v := range []string{"0", "-1", "abc"} {
+		t.Setenv("OPENCODE_MAX_ROUNDS", v)
+		if _, err := Load(); err == nil {
+			t.Errorf("OPENCODE_MAX_ROUNDS=%q 应返回错误", v)
+		}
+	}
+}
+
+func TestLoad_Debug(t *testing.T) {
+	isolateConfigFiles(t)
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	tests := []struct {
+		value   string
+		want    bool
+		wantErr bool
+	}{
+		{"", false, false},
+		{"1", true, false},
+		{"true", true, false},
+		{"false", false, false},
+		{"yes", false, true},
+	}
+	for _, tt := range tests {
+		t.Setenv("OPENCODE_DEBUG", tt.value)
+		cfg, err := Load()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("OPENCODE_DEBUG=%q error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if err == nil && cfg.Debug != tt.want {
+			t.Errorf("OPENCODE_DEBUG=%q Debug = %v, want %v", tt.value, cfg.Debug, tt.want)
+		}
+	}
+}
+
+func TestLoad_ConfigFileJSON(t *testing.T) {
+	isolateConfigFiles(t)
+	t.Setenv("OPENAI_API_KEY", "")
+
+	if err := os.WriteFile("opencode_nano.yaml", []byte(`{"api_key": "json-key", "max_tokens": 100}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.OpenAIAPIKey != "json-key" || cfg.MaxTokens != 100 {
+		t.Errorf("Load() = %+v, want api key json-key and max tokens 100", cfg)
+	}
+}
+
+func TestLoad_MalformedConfigFile(t *testing.T) {
+	isolateConfigFiles(t)
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	malformed := "model: ok\ntemperature: [unclosed\n"
+	if err := os.WriteFile("opencode_nano.yaml", []byte(malformed), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("Load() 期望返回错误")
+	}
+	if !strings.Contains(err.Error(), "opencode_nano.yaml") {
+		t.Errorf("错误信息未包含文件路径: %v", err)
+	}
+	if !strings.Contains(err.Error(), "line") {
+		t.Errorf("错误信息未包含行号: %v", err)
+	}
+}
+
 func TestConfig_Structure(t *testing.T) {
 	// 测试 Config 结构体的字段
 	cfg := &Config{
@@ -149,4 +451,518 @@ func BenchmarkLoad(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_, _ = Load()
 	}
-}
\ No newline at end of file
+}
+func TestLoad_BashPolicy(t *testing.T) {
+	home := isolateConfigFiles(t)
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	homeDir := filepath.Join(home, ".opencode_nano")
+	if err := os.MkdirAll(homeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	homeConfig := `bash_deny_patterns:
+  - git push --force
+bash_allow_prefixes:
+  - chown -R app
+`
+	if err := os.WriteFile(filepath.Join(homeDir, "config.yaml"), []byte(homeConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	localConfig := `bash_deny_patterns: ["DROP TABLE", "  "]
+bash_allow_prefixes: ["rm"]
+`
+	if err := os.WriteFile("opencode_nano.yaml", []byte(localConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// 多个配置文件中的规则累加，空白条目被忽略
+	wantDeny := []string{"git push --force", "DROP TABLE"}
+	if !reflect.DeepEqual(cfg.BashDenyPatterns, wantDeny) {
+		t.Errorf("BashDenyPatterns = %v, want %v", cfg.BashDenyPatterns, wantDeny)
+	}
+	// 当前目录配置中的允许前缀被忽略
+	if !reflect.DeepEqual(cfg.BashAllowPrefixes, []string{"chown -R app"}) {
+		t.Errorf("BashAllowPrefixes = %v, want [chown -R app]", cfg.BashAllowPrefixes)
+	}
+}
+
+func TestLoad_PermissionRules(t *testing.T) {
+	home := isolateConfigFiles(t)
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	homeDir := filepath.Join(home, ".opencode_nano")
+	if err := os.MkdirAll(homeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	homeConfig := `permissions:
+  allow:
+    - action: bash
+      pattern: "go test*"
+    - action: " "
+  deny:
+    - action: delete
+`
+	if err := os.WriteFile(filepath.Join(homeDir, "config.yaml"), []byte(homeConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	localConfig := `{"permissions": {"allow": [{"action": "read"}], "deny": [{"pattern": "rm -rf"}]}}`
+	if err := os.WriteFile("opencode_nano.yaml", []byte(localConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// 拒绝规则在多个配置文件之间累加，空规则被忽略；当前目录配置中的允许规则被忽略
+	wantAllow := []PermissionRule{{Action: "bash", Pattern: "go test*"}}
+	if !reflect.DeepEqual(cfg.PermissionAllow, wantAllow) {
+		t.Errorf("PermissionAllow = %+v, want %+v", cfg.PermissionAllow, wantAllow)
+	}
+	wantDeny := []PermissionRule{{Action: "delete"}, {Pattern: "rm -rf"}}
+	if !reflect.DeepEqual(cfg.PermissionDeny, wantDeny) {
+		t.Errorf("PermissionDeny = %+v, want %+v", cfg.PermissionDeny, wantDeny)
+	}
+}
+
+func TestLoad_Profiles(t *testing.T) {
+	home := isolateConfigFiles(t)
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("OPENAI_BASE_URL", "")
+	t.Setenv("OPENAI_MODEL", "")
+	t.Setenv("OPENCODE_PROFILE", "")
+
+	homeDir := filepath.Join(home, ".opencode_nano")
+	if err := os.MkdirAll(homeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	homeConfig := `api_key: top-key
+model: top-model
+max_tokens: 256
+profiles:
+  work:
+    api_key: work-key
+    base_url: https://work.api.com/v1
+    model: work-model
+  local:
+    base_url: http://localhost:11434/v1
+    model: llama3
+    temperature: 0
+`
+	if err := os.WriteFile(filepath.Join(homeDir, "config.yaml"), []byte(homeConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// 当前目录的配置文件可以补充同名档案的字段
+	localConfig := `profiles:
+  local:
+    max_tokens: 1024
+`
+	if err := os.WriteFile("opencode_nano.yaml", []byte(localConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		profile     string
+		env         map[string]string
+		wantProfile string
+		wantKey     string
+		wantBaseURL string
+		wantModel   string
+		wantTokens  int
+		wantErr     string
+	}{
+		{
+			name:        "未选择档案时使用顶层键",
+			wantKey:     "top-key",
+			wantBaseURL: "https://api.openai.com/v1",
+			wantModel:   "top-model",
+			wantTokens:  256,
+		},
+		{
+			name:        "选择 work 档案",
+			profile:     "work",
+			wantProfile: "work",
+			wantKey:     "work-key",
+			wantBaseURL: "https://work.api.com/v1",
+			wantModel:   "work-model",
+			wantTokens:  256,
+		},
+		{
+			name:        "选择 local 档案，未设置的字段沿用顶层键",
+			profile:     "local",
+			wantProfile: "local",
+			wantKey:     "top-key",
+			wantBaseURL: "http://localhost:11434/v1",
+			wantModel:   "llama3",
+			wantTokens:  1024,
+		},
+		{
+			name:        "通过 OPENCODE_PROFILE 选择",
+			env:         map[string]string{"OPENCODE_PROFILE": "work"},
+			wantProfile: "work",
+			wantKey:     "work-key",
+			wantBaseURL: "https://work.api.com/v1",
+			wantModel:   "work-model",
+			wantTokens:  256,
+		},
+		{
+			name:        "参数优先于 OPENCODE_PROFILE",
+			profile:     "local",
+			env:         map[string]string{"OPENCODE_PROFILE": "work"},
+			wantProfile: "local",
+			wantKey:     "top-key",
+			wantBaseURL: "http://localhost:11434/v1",
+			wantModel:   "llama3",
+			wantTokens:  1024,
+		},
+		{
+			name:        "环境变量覆盖选中的档案",
+			profile:     "work",
+			env:         map[string]string{"OPENAI_MODEL": "env-model", "OPENAI_API_KEY": "env-key"},
+			wantProfile: "work",
+			wantKey:     "env-key",
+			wantBaseURL: "https://work.api.com/v1",
+			wantModel:   "env-model",
+			wantTokens:  256,
+		},
+		{
+			name:    "未知档案",
+			profile: "missing",
+			wantErr: "available: local, work",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := LoadProfile(tt.profile)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadProfile() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadProfile() error = %v", err)
+			}
+
+			if cfg.Profile != tt.wantProfile {
+				t.Errorf("Profile = %v, want %v", cfg.Profile, tt.wantProfile)
+			}
+			if cfg.OpenAIAPIKey != tt.wantKey {
+				t.Errorf("OpenAIAPIKey = %v, want %v", cfg.OpenAIAPIKey, tt.wantKey)
+			}
+			if cfg.OpenAIBaseURL != tt.wantBaseURL {
+				t.Errorf("OpenAIBaseURL = %v, want %v", cfg.OpenAIBaseURL, tt.wantBaseURL)
+			}
+			if cfg.Model != tt.wantModel {
+				t.Errorf("Model = %v, want %v", cfg.Model, tt.wantModel)
+			}
+			if cfg.MaxTokens != tt.wantTokens {
+				t.Errorf("MaxTokens = %v, want %v", cfg.MaxTokens, tt.wantTokens)
+			}
+		})
+	}
+}
+
+func TestLoad_DefaultProfile(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    string
+		wantModel string
+	}{
+		{
+			name: "default_profile 指定默认档案",
+			config: `model: top-model
+default_profile: work
+profiles:
+  work:
+    model: work-model
+`,
+			wantModel: "work-model",
+		},
+		{
+			name: "存在名为 default 的档案时使用它",
+			config: `model: top-model
+profiles:
+  default:
+    model: default-model
+`,
+			wantModel: "default-model",
+		},
+		{
+			name: "没有默认档案时使用顶层键",
+			config: `model: top-model
+profiles:
+  work:
+    model: work-model
+`,
+			wantModel: "top-model",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateConfigFiles(t)
+			t.Setenv("OPENAI_API_KEY", "test-key")
+			t.Setenv("OPENAI_MODEL", "")
+			t.Setenv("OPENCODE_PROFILE", "")
+
+			if err := os.WriteFile("opencode_nano.yaml", []byte(tt.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.Model != tt.wantModel {
+				t.Errorf("Model = %v, want %v", cfg.Model, tt.wantModel)
+			}
+		})
+	}
+}
+
+func TestLoad_Azure(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		env     map[string]string
+		want    *Config
+		wantErr string
+	}{
+		{
+			name: "默认使用标准 OpenAI",
+			want: &Config{Provider: ProviderOpenAI, OpenAIBaseURL: "https://api.openai.com/v1"},
+		},
+		{
+			name: "配置文件启用 Azure",
+			config: `provider: Azure
+base_url: https://example.openai.azure.com
+azure_deployment: shared
+azure_deployments:
+  gpt-4o: prod-gpt4o
+api_version: 2024-06-01
+`,
+			want: &Config{
+				Provider:         ProviderAzure,
+				OpenAIBaseURL:    "https://example.openai.azure.com",
+				AzureDeployment:  "shared",
+				AzureDeployments: map[string]string{"gpt-4o": "prod-gpt4o"},
+				APIVersion:       "2024-06-01",
+			},
+		},
+		{
+			name: "环境变量启用 Azure",
+			env: map[string]string{
+				"OPENAI_PROVIDER":         "azure",
+				"OPENAI_BASE_URL":         "https://env.openai.azure.com",
+				"AZURE_OPENAI_DEPLOYMENT": "env-deployment",
+				"OPENAI_API_VERSION":      "2024-02-01",
+			},
+			want: &Config{
+				Provider:        ProviderAzure,
+				OpenAIBaseURL:   "https://env.openai.azure.com",
+				AzureDeployment: "env-deployment",
+				APIVersion:      "2024-02-01",
+			},
+		},
+		{
+			name:    "Azure 缺少端点",
+			config:  "provider: azure\n",
+			wantErr: "Azure OpenAI endpoint",
+		},
+		{
+			name:    "未知的接口类型",
+			env:     map[string]string{"OPENAI_PROVIDER": "bedrock"},
+			wantErr: "provider must be",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateConfigFiles(t)
+			t.Setenv("OPENAI_API_KEY", "test-key")
+			for _, key := range []string{"OPENAI_BASE_URL", "OPENAI_PROVIDER", "AZURE_OPENAI_DEPLOYMENT", "OPENAI_API_VERSION", "OPENCODE_PROFILE"} {
+				t.Setenv(key, "")
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			if tt.config != "" {
+				if err := os.WriteFile("opencode_nano.yaml", []byte(tt.config), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cfg, err := Load()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if cfg.Provider != tt.want.Provider {
+				t.Errorf("Provider = %v, want %v", cfg.Provider, tt.want.Provider)
+			}
+			if cfg.OpenAIBaseURL != tt.want.OpenAIBaseURL {
+				t.Errorf("OpenAIBaseURL = %v, want %v", cfg.OpenAIBaseURL, tt.want.OpenAIBaseURL)
+			}
+			if cfg.AzureDeployment != tt.want.AzureDeployment {
+				t.Errorf("AzureDeployment = %v, want %v", cfg.AzureDeployment, tt.want.AzureDeployment)
+			}
+			if !reflect.DeepEqual(cfg.AzureDeployments, tt.want.AzureDeployments) {
+				t.Errorf("AzureDeployments = %v, want %v", cfg.AzureDeployments, tt.want.AzureDeployments)
+			}
+			if cfg.APIVersion != tt.want.APIVersion {
+				t.Errorf("APIVersion = %v, want %v", cfg.APIVersion, tt.want.APIVersion)
+			}
+		})
+	}
+}
+
+func TestLoad_DryRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		env     string
+		want    bool
+		wantErr bool
+	}{
+		{name: "默认关闭"},
+		{name: "配置文件开启", config: "dry_run: true\n", want: true},
+		{name: "环境变量开启", env: "1", want: true},
+		{name: "环境变量覆盖配置文件", config: "dry_run: true\n", env: "false", want: false},
+		{name: "无效的环境变量", env: "maybe", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateConfigFiles(t)
+			t.Setenv("OPENAI_API_KEY", "test-key")
+			t.Setenv("OPENCODE_DRY_RUN", tt.env)
+			if tt.config != "" {
+				if err := os.WriteFile("opencode_nano.yaml", []byte(tt.config), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cfg, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.DryRun != tt.want {
+				t.Errorf("DryRun = %v, want %v", cfg.DryRun, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_DisabledTools(t *testing.T) {
+	home := isolateConfigFiles(t)
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	t.Setenv("OPENCODE_DISABLED_TOOLS", " process, ,fetch")
+
+	homeDir := filepath.Join(home, ".opencode_nano")
+	if err := os.MkdirAll(homeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(homeDir, "config.yaml"), []byte("disabled_tools:\n  - bash\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("opencode_nano.yaml", []byte("disabled_tools: [pipeline, \" \"]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	// 多个配置文件和环境变量中的条目累加，空白条目被忽略
+	want := []string{"bash", "pipeline", "process", "fetch"}
+	if !reflect.DeepEqual(cfg.DisabledTools, want) {
+		t.Errorf("DisabledTools = %v, want %v", cfg.DisabledTools, want)
+	}
+
+	// LoadDisabledTools 不需要 API Key，结果与 Load 一致
+	t.Setenv("OPENAI_API_KEY", "")
+	disabled, err := LoadDisabledTools()
+	if err != nil {
+		t.Fatalf("LoadDisabledTools() error = %v", err)
+	}
+	if !reflect.DeepEqual(disabled, want) {
+		t.Errorf("LoadDisabledTools() = %v, want %v", disabled, want)
+	}
+}
+
+func TestLoad_Plugins(t *testing.T) {
+	tests := []struct {
+		name        string
+		home        string // 用户目录配置
+		local       string // 当前目录配置
+		env         string
+		wantEnabled bool
+		wantDir     string // 为空时期望默认目录
+		wantErr     bool
+	}{
+		{name: "默认关闭"},
+		{name: "用户目录配置开启并指定目录", home: "enable_plugins: true\nplugin_dir: /opt/plugins\n", wantEnabled: true, wantDir: "/opt/plugins"},
+		{name: "忽略当前目录配置", local: "enable_plugins: true\nplugin_dir: ./plugins\n"},
+		{name: "当前目录配置不能改变插件目录", home: "enable_plugins: true\n", local: "plugin_dir: ./plugins\n", wantEnabled: true},
+		{name: "环境变量开启", env: "true", wantEnabled: true},
+		{name: "无效的环境变量", env: "sometimes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := isolateConfigFiles(t)
+			t.Setenv("OPENAI_API_KEY", "test-key")
+			t.Setenv("OPENCODE_PLUGINS", tt.env)
+			if tt.home != "" {
+				if err := os.MkdirAll(filepath.Join(home, ".opencode_nano"), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(home, ".opencode_nano", "config.yaml"), []byte(tt.home), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.local != "" {
+				if err := os.WriteFile("opencode_nano.yaml", []byte(tt.local), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cfg, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if cfg.EnablePlugins != tt.wantEnabled {
+				t.Errorf("EnablePlugins = %v, want %v", cfg.EnablePlugins, tt.wantEnabled)
+			}
+			wantDir := tt.wantDir
+			if wantDir == "" {
+				wantDir = filepath.Join(home, ".opencode_nano", "plugins")
+			}
+			if cfg.PluginDir != wantDir {
+				t.Errorf("PluginDir = %v, want %v", cfg.PluginDir, wantDir)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// envNameRe 合法的环境变量名
+var envNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// EnvFilePath 返回持久化环境变量文件的路径（~/.opencode_nano/env）
+func EnvFilePath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".opencode_nano", "env")
+}
+
+// ValidEnvName 检查环境变量名是否合法
+func ValidEnvName(name string) bool {
+	return envNameRe.MatchString(name)
+}
+
+// ReadEnvFile 读取 .env 格式的文件，文件不存在时返回空 map
+func ReadEnvFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("failed to read env file %s: %v", path, err)
+	}
+
+	vars, err := ParseEnv(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse env file %s: %v", path, err)
+	}
+	return vars, nil
+}
+
+// WriteEnvFile 将变量以 .env 格式写入文件，必要时创建目录
+func WriteEnvFile(path string, vars map[string]string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %v", path, err)
+	}
+	// 变量中可能包含密钥，只允许当前用户读写
+	if err := os.WriteFile(path, []byte(FormatEnv(vars)), 0600); err != nil {
+		return fmt.Errorf("failed to write env file %s: %v", path, err)
+	}
+	return nil
+}
+
+// ParseEnv 解析 .env 格式内容：忽略空行和 # 注释，支持 export 前缀以及单双引号
+func ParseEnv(content string) (map[string]string, error) {
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !ValidEnvName(name) {
+			return nil, fmt.Errorf("line %d: invalid entry %q", lineNum, line)
+		}
+
+		value = strings.TrimSpace(value)
+		switch {
+		case strings.HasPrefix(value, `"`):
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid quoted value for %s", lineNum, name)
+			}
+			value = unquoted
+		case len(value) >= 2 && strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'"):
+			value = value[1 : len(value)-1]
+		}
+		vars[name] = value
+	}
+	return vars, scanner.Err()
+}
+
+// FormatEnv 按名称排序输出 .env 格式内容，值统一使用双引号转义
+func FormatEnv(vars map[string]string) string {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&sb, "%s=%s\n", name, strconv.Quote(vars[name]))
+	}
+	return sb.String()
+}
+
+// applyEnvFile 将持久化的环境变量设置到当前进程，已存在的环境变量优先
+func applyEnvFile(path string) error {
+	if path == "" {
+		return nil
+	}
+	vars, err := ReadEnvFile(path)
+	if err != nil {
+		return err
+	}
+	for name, value := range vars {
+		if _, exists := os.LookupEnv(name); exists {
+			continue
+		}
+		if err := os.Setenv(name, value); err != nil {
+			return fmt.Errorf("failed to set %s from env file: %v", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"空内容", "", map[string]string{}, false},
+		{"注释和空行", "# comment\n\nA=1\n", map[string]string{"A": "1"}, false},
+		{"export 前缀", "export A=1", map[string]string{"A": "1"}, false},
+		{"双引号转义", `A="x\ny \"z\""`, map[string]string{"A": "x\ny \"z\""}, false},
+		{"单引号原样保留", `A='$HOME\n'`, map[string]string{"A": `$HOME\n`}, false},
+		{"值中包含等号", "A=b=c", map[string]string{"A": "b=c"}, false},
+		{"空值", "A=", map[string]string{"A": ""}, false},
+		{"缺少等号", "A", nil, true},
+		{"非法变量名", "1A=x", nil, true},
+		{"未闭合的双引号", `A="x`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEnv(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvFile_RoundTrip(t *testing.T) {
+	vars := map[string]string{
+		"PLAIN":   "value",
+		"EMPTY":   "",
+		"SPACES":  "  padded  ",
+		"QUOTES":  `it's "quoted"`,
+		"NEWLINE": "line1\nline2",
+		"UNICODE": "你好",
+		"HASH":    "a # not a comment",
+	}
+
+	path := filepath.Join(t.TempDir(), "nested", "env")
+	if err := WriteEnvFile(path, vars); err != nil {
+		t.Fatalf("WriteEnvFile() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("文件权限 = %o, want 600", perm)
+	}
+
+	got, err := ReadEnvFile(path)
+	if err != nil {
+		t.Fatalf("ReadEnvFile() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, vars) {
+		t.Errorf("往返结果 = %v, want %v", got, vars)
+	}
+}
+
+func TestReadEnvFile_Missing(t *testing.T) {
+	vars, err := ReadEnvFile(filepath.Join(t.TempDir(), "missing"))
+	if err != nil {
+		t.Fatalf("文件不存在时不应报错: %v", err)
+	}
+	if len(vars) != 0 {
+		t.Errorf("vars = %v, want empty", vars)
+	}
+}
+
+func TestLoad_EnvFile(t *testing.T) {
+	home := isolateConfigFiles(t)
+	t.Setenv("OPENCODE_NANO_TEST_KEEP", "from-env")
+	// 确保测试结束后清理由 Load 设置的变量
+	t.Setenv("OPENCODE_NANO_TEST_PERSISTED", "")
+	os.Unsetenv("OPENCODE_NANO_TEST_PERSISTED")
+
+	err := WriteEnvFile(filepath.Join(home, ".opencode_nano", "env"), map[string]string{
+		"OPENCODE_NANO_TEST_PERSISTED": "from-file",
+		"OPENCODE_NANO_TEST_KEEP":      "from-file",
+		"OPENAI_API_KEY":               "sk-from-file",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OPENAI_API_KEY", "")
+	os.Unsetenv("OPENAI_API_KEY")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if got := os.Getenv("OPENCODE_NANO_TEST_PERSISTED"); got != "from-file" {
+		t.Errorf("持久化变量 = %q, want from-file", got)
+	}
+	if got := os.Getenv("OPENCODE_NANO_TEST_KEEP"); got != "from-env" {
+		t.Errorf("已存在的环境变量被覆盖: %q", got)
+	}
+	if cfg.OpenAIAPIKey != "sk-from-file" {
+		t.Errorf("OpenAIAPIKey = %q, want sk-from-file", cfg.OpenAIAPIKey)
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HistoryLimit 输入历史最多保留的条数
+const HistoryLimit = 1000
+
+// HistoryFilePath 返回交互模式输入历史文件的路径（~/.opencode_nano/history）
+func HistoryFilePath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".opencode_nano", "history")
+}
+
+// ReadHistory 读取输入历史，每行一条，文件不存在时返回空列表
+func ReadHistory(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read history file %s: %v", path, err)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return trimHistory(lines), nil
+}
+
+// WriteHistory 写入输入历史，只保留最近 HistoryLimit 条。
+// 多行输入合并为一行保存，必要时创建目录
+func WriteHistory(path string, lines []string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %v", path, err)
+	}
+
+	var content strings.Builder
+	for _, line := range trimHistory(lines) {
+		line = strings.Join(strings.Fields(strings.ReplaceAll(line, "\n", " ")), " ")
+		if line != "" {
+			content.WriteString(line + "\n")
+		}
+	}
+	// 输入中可能包含敏感内容，只允许当前用户读写
+	if err := os.WriteFile(path, []byte(content.String()), 0600); err != nil {
+		return fmt.Errorf("failed to write history file %s: %v", path, err)
+	}
+	return nil
+}
+
+// trimHistory 只保留最近 HistoryLimit 条
+func trimHistory(lines []string) []string {
+	if len(lines) > HistoryLimit {
+		return lines[len(lines)-HistoryLimit:]
+	}
+	return lines
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHistoryRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "history")
+
+	// 文件不存在时返回空列表
+	lines, err := ReadHistory(path)
+	if err != nil {
+		t.Fatalf("ReadHistory() error = %v", err)
+	}
+	if len(lines) != 0 {
+		t.Errorf("ReadHistory() = %v, want empty", lines)
+	}
+
+	history := []string{"help", "读取 README.md", "多行\n输入", ""}
+	if err := WriteHistory(path, history); err != nil {
+		t.Fatalf("WriteHistory() error = %v", err)
+	}
+	lines, err = ReadHistory(path)
+	if err != nil {
+		t.Fatalf("ReadHistory() error = %v", err)
+	}
+	want := []string{"help", "读取 README.md", "多行 输入"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("ReadHistory() = %q, want %q", lines, want)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("history 文件权限 = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestWriteHistory_Limit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	var history []string
+	for i := 0; i < HistoryLimit+5; i++ {
+		history = append(history, fmt.Sprintf("cmd %d", i))
+	}
+	if err := WriteHistory(path, history); err != nil {
+		t.Fatalf("WriteHistory() error = %v", err)
+	}
+
+	lines, err := ReadHistory(path)
+	if err != nil {
+		t.Fatalf("ReadHistory() error = %v", err)
+	}
+	if len(lines) != HistoryLimit {
+		t.Fatalf("ReadHistory() 返回 %d 条, want %d", len(lines), HistoryLimit)
+	}
+	if lines[0] != "cmd 5" || lines[len(lines)-1] != fmt.Sprintf("cmd %d", HistoryLimit+4) {
+		t.Errorf("应保留最近的历史, got %q ... %q", lines[0], lines[len(lines)-1])
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"opencode_nano/config"
+)
+
+// lineReader 读取交互模式的输入。stdin 是终端时提供行编辑（方向键移动、
+// Ctrl+A/E、上下键浏览历史），并把历史保存到 ~/.opencode_nano/history；
+// 否则按普通文本逐行读取
+type lineReader struct {
+	fd          int
+	terminal    *term.Terminal
+	rw          *terminalIO
+	scanner     *bufio.Scanner
+	history     []string
+	historyPath string
+}
+
+// terminalIO 可切换读写端的 io.ReadWriter，用于向 term.Terminal 预先载入历史
+type terminalIO struct {
+	io.Reader
+	io.Writer
+}
+
+// newLineReader 创建从 stdin 读取的 lineReader，historyPath 为空时不保存历史
+func newLineReader(historyPath string) *lineReader {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return &lineReader{fd: -1, scanner: bufio.NewScanner(os.Stdin)}
+	}
+
+	history, err := config.ReadHistory(historyPath)
+	if err != nil {
+		history = nil
+	}
+	r := newTerminalReader(os.Stdin, os.Stdout, history)
+	r.fd = fd
+	r.historyPath = historyPath
+	return r
+}
+
+// newTerminalReader 创建带行编辑的 lineReader，history 为已有的历史记录
+func newTerminalReader(in io.Reader, out io.Writer, history []string) *lineReader {
+	rw := &terminalIO{Reader: in, Writer: out}
+	r := &lineReader{fd: -1, terminal: term.NewTerminal(rw, ""), rw: rw}
+	r.loadHistory(history)
+	return r
+}
+
+// loadHistory 将历史逐条输入 term.Terminal，使上下键可以浏览。
+// term.Terminal 没有直接设置历史的接口，输入期间丢弃回显
+func (r *lineReader) loadHistory(history []string) {
+	if len(history) == 0 {
+		return
+	}
+	in, out := r.rw.Reader, r.rw.Writer
+	r.rw.Reader = strings.NewReader(strings.Join(history, "\r") + "\r")
+	r.rw.Writer = io.Discard
+	for range history {
+		if _, err := r.terminal.ReadLine(); err != nil {
+			break
+		}
+	}
+	r.rw.Reader, r.rw.Writer = in, out
+	r.history = append(r.history, history...)
+}
+
+// ReadLine 显示 prompt 并读取一行输入，输入结束（Ctrl+D/Ctrl+C）时返回 io.EOF
+func (r *lineReader) ReadLine(prompt string) (string, error) {
+	if r.terminal == nil {
+		os.Stdout.WriteString(prompt)
+		if !r.scanner.Scan() {
+			if err := r.scanner.Err(); err != nil {
+				return "", err
+			}
+			return "", io.EOF
+		}
+		return r.scanner.Text(), nil
+	}
+
+	// 只在读取时进入 raw 模式，以便权限确认等其他输入仍可正常读取 stdin
+	if r.fd >= 0 {
+		state, err := term.MakeRaw(r.fd)
+		if err != nil {
+			return "", err
+		}
+		defer term.Restore(r.fd, state)
+		if width, height, err := term.GetSize(r.fd); err == nil {
+			r.terminal.SetSize(width, height)
+		}
+	}
+
+	r.terminal.SetPrompt(prompt)
+	line, err := r.terminal.ReadLine()
+	if err == term.ErrPasteIndicator {
+		err = nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if strings.TrimSpace(line) != "" {
+		r.history = append(r.history, line)
+		if r.historyPath != "" {
+			config.WriteHistory(r.historyPath, r.history)
+		}
+	}
+	return line, nil
+}
+
+// continuationPrompt 多行输入时后续行的提示符
+const continuationPrompt = "... "
+
+// readInput 读取一条完整的输入：单独一行 fence 开始多行块，直到再次输入 fence；
+// 以反斜杠结尾的行与下一行相连。多行输入中途结束时返回已读取的内容
+func readInput(readLine func(prompt string) (string, error), prompt, fence string) (string, error) {
+	line, err := readLine(prompt)
+	if err != nil {
+		return "", err
+	}
+
+	var lines []string
+	switch {
+	case fence != "" && strings.TrimSpace(line) == fence:
+		for {
+			line, err := readLine(continuationPrompt)
+			if err != nil || strings.TrimSpace(line) == fence {
+				break
+			}
+			lines = append(lines, line)
+		}
+	case strings.HasSuffix(line, "\\"):
+		for strings.HasSuffix(line, "\\") {
+			lines = append(lines, strings.TrimSuffix(line, "\\"))
+			if line, err = readLine(continuationPrompt); err != nil {
+				line = ""
+				break
+			}
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	default:
+		return line, nil
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"opencode_nano/config"
+)
+
+func TestLineReader_History(t *testing.T) {
+	// ↑ 回到上一条历史，两次 ↑ 回到更早的历史
+	input := "\x1b[A\r" + "\x1b[A\x1b[A\r" + "new command\r"
+	reader := newTerminalReader(strings.NewReader(input), &bytes.Buffer{}, []string{"help", "读取 README.md"})
+	reader.historyPath = filepath.Join(t.TempDir(), "history")
+
+	var lines []string
+	for {
+		line, err := reader.ReadLine("> ")
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("ReadLine() error = %v", err)
+		}
+		lines = append(lines, line)
+	}
+
+	want := []string{"读取 README.md", "读取 README.md", "new command"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("ReadLine() = %q, want %q", lines, want)
+	}
+
+	// 新的输入追加到历史文件
+	saved, err := config.ReadHistory(reader.historyPath)
+	if err != nil {
+		t.Fatalf("ReadHistory() error = %v", err)
+	}
+	wantSaved := []string{"help", "读取 README.md", "读取 README.md", "读取 README.md", "new command"}
+	if !reflect.DeepEqual(saved, wantSaved) {
+		t.Errorf("保存的历史 = %q, want %q", saved, wantSaved)
+	}
+}
+
+func TestReadInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		fence string
+		want  string
+	}{
+		{"单行", []string{"hello"}, `"""`, "hello"},
+		{"分隔符多行块", []string{`"""`, "func main() {", "", "}", `"""`}, `"""`, "func main() {\n\n}"},
+		{"自定义分隔符", []string{"<<<", "a", `"""`, "<<<"}, "<<<", "a\n\"\"\""},
+		{"反斜杠续行", []string{`first \`, `second\`, "third"}, `"""`, "first \nsecond\nthird"},
+		{"多行块未结束", []string{`"""`, "a", "b"}, `"""`, "a\nb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remaining := tt.lines
+			var prompts []string
+			readLine := func(prompt string) (string, error) {
+				prompts = append(prompts, prompt)
+				if len(remaining) == 0 {
+					return "", io.EOF
+				}
+				line := remaining[0]
+				remaining = remaining[1:]
+				return line, nil
+			}
+
+			got, err := readInput(readLine, "> ", tt.fence)
+			if err != nil {
+				t.Fatalf("readInput() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("readInput() = %q, want %q", got, tt.want)
+			}
+			if len(remaining) != 0 {
+				t.Errorf("未读取的行: %q", remaining)
+			}
+			for _, prompt := range prompts[1:] {
+				if prompt != continuationPrompt {
+					t.Errorf("后续行提示符 = %q, want %q", prompt, continuationPrompt)
+				}
+			}
+		})
+	}
+
+	// 开始时已结束输入
+	_, err := readInput(func(string) (string, error) { return "", io.EOF }, "> ", `"""`)
+	if err != io.EOF {
+		t.Errorf("readInput() error = %v, want io.EOF", err)
+	}
+}
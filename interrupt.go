@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// exitWindow 两次 Ctrl+C 之间不超过该时间时退出程序
+const exitWindow = 2 * time.Second
+
+// interruptHandler 处理 Ctrl+C：第一次取消正在进行的一轮对话，
+// window 内再次按下时退出程序
+type interruptHandler struct {
+	mu     sync.Mutex
+	window time.Duration
+	cancel context.CancelFunc // 当前一轮对话的取消函数，没有进行中的对话时为 nil
+	last   time.Time          // 上一次 Ctrl+C 的时间
+}
+
+// newInterruptHandler 创建 interruptHandler
+func newInterruptHandler(window time.Duration) *interruptHandler {
+	return &interruptHandler{window: window}
+}
+
+// startTurn 为一轮对话创建可被 Ctrl+C 取消的 context，对话结束后需调用 done
+func (h *interruptHandler) startTurn(parent context.Context) (ctx context.Context, done func()) {
+	ctx, cancel := context.WithCancel(parent)
+
+	h.mu.Lock()
+	h.cancel = cancel
+	h.mu.Unlock()
+
+	return ctx, func() {
+		h.mu.Lock()
+		h.cancel = nil
+		h.mu.Unlock()
+		cancel()
+	}
+}
+
+// interrupt 处理一次 Ctrl+C，返回是否应退出程序
+func (h *interruptHandler) interrupt() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	if !h.last.IsZero() && now.Sub(h.last) < h.window {
+		return true
+	}
+	h.last = now
+
+	if h.cancel != nil {
+		h.cancel()
+		h.cancel = nil
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestInterruptHandler(t *testing.T) {
+	h := newInterruptHandler(50 * time.Millisecond)
+
+	// 第一次 Ctrl+C 取消当前一轮对话，不退出
+	ctx, done := h.startTurn(context.Background())
+	if h.interrupt() {
+		t.Fatal("第一次 Ctrl+C 不应退出")
+	}
+	select {
+	case <-ctx.Done():
+	default:
+		t.Fatal("第一次 Ctrl+C 应取消当前一轮对话")
+	}
+	done()
+
+	// 短时间内再次按下时退出
+	if !h.interrupt() {
+		t.Error("短时间内第二次 Ctrl+C 应退出")
+	}
+
+	// 超过时间窗口后重新计算，新一轮对话不受之前取消的影响
+	time.Sleep(60 * time.Millisecond)
+	ctx, done = h.startTurn(context.Background())
+	defer done()
+	if ctx.Err() != nil {
+		t.Fatal("新一轮对话的 context 不应已取消")
+	}
+	if h.interrupt() {
+		t.Error("超过时间窗口后的 Ctrl+C 不应退出")
+	}
+	if ctx.Err() == nil {
+		t.Error("Ctrl+C 应取消新一轮对话")
+	}
+}
@@ -1,58 +1,237 @@
 package main
 
 import (
-	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
 	"strings"
 	"syscall"
 
+	"golang.org/x/term"
+
 	"opencode_nano/agent"
 	"opencode_nano/config"
+	"opencode_nano/output"
 	"opencode_nano/permission"
 	"opencode_nano/tools"
+	"opencode_nano/tools/core"
+)
+
+// 版本信息，构建时通过 -ldflags 设置，例如：
+// go build -ldflags "-X main.version=v0.1.0 -X main.commit=$(git rev-parse --short HEAD)"
+var (
+	version = "dev"
+	commit  = ""
 )
 
+// cliOptions 启动参数
+type cliOptions struct {
+	autoMode    bool
+	noStream    bool
+	legacyTools bool
+	listTools   bool
+	showVersion bool
+	jsonOutput  bool     // 单次模式以 JSON 输出结果
+	noColor     bool     // 不使用 emoji 和颜色
+	quiet       bool     // 单次模式只输出助手回复
+	debug       bool     // 向 stderr 输出调试日志
+	dryRun      bool     // 有副作用的工具调用只报告将要执行的操作
+	promptFile  string   // -f/--prompt-file 指定的提示词文件
+	cwd         string   // -C/--cwd 指定的工作目录
+	profile     string   // --profile 指定的配置档案
+	args        []string // 其余参数，作为单次模式的提示词
+}
+
+// parseArgs 解析启动参数
+func parseArgs(argv []string) (cliOptions, error) {
+	var opts cliOptions
+	for i := 0; i < len(argv); i++ {
+		arg := argv[i]
+		switch {
+		case arg == "--auto" || arg == "-a":
+			opts.autoMode = true
+		case arg == "--no-stream":
+			opts.noStream = true
+		case arg == "--quiet" || arg == "-q":
+			opts.quiet = true
+		case arg == "--debug":
+			opts.debug = true
+		case arg == "--dry-run":
+			opts.dryRun = true
+		case arg == "--no-color":
+			opts.noColor = true
+		case arg == "--json":
+			opts.jsonOutput = true
+		case arg == "--legacy-tools":
+			opts.legacyTools = true
+		case arg == "--list-tools":
+			opts.listTools = true
+		case arg == "--version" || arg == "-v":
+			opts.showVersion = true
+		case arg == "--prompt-file" || arg == "-f":
+			if i+1 >= len(argv) {
+				return opts, fmt.Errorf("%s requires a file path", arg)
+			}
+			i++
+			opts.promptFile = argv[i]
+		case strings.HasPrefix(arg, "--prompt-file="):
+			opts.promptFile = strings.TrimPrefix(arg, "--prompt-file=")
+		case arg == "--cwd" || arg == "-C":
+			if i+1 >= len(argv) {
+				return opts, fmt.Errorf("%s requires a directory", arg)
+			}
+			i++
+			opts.cwd = argv[i]
+		case strings.HasPrefix(arg, "--cwd="):
+			opts.cwd = strings.TrimPrefix(arg, "--cwd=")
+		case arg == "--profile":
+			if i+1 >= len(argv) {
+				return opts, fmt.Errorf("%s requires a profile name", arg)
+			}
+			i++
+			opts.profile = argv[i]
+		case strings.HasPrefix(arg, "--profile="):
+			opts.profile = strings.TrimPrefix(arg, "--profile=")
+		default:
+			opts.args = append(opts.args, arg)
+		}
+	}
+	if opts.promptFile != "" && len(opts.args) > 0 && !opts.listTools {
+		return opts, fmt.Errorf("--prompt-file cannot be combined with a prompt argument")
+	}
+	return opts, nil
+}
+
+// changeDir 切换工作目录，目录必须存在且可读
+func changeDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("--cwd directory not found: %s", dir)
+		}
+		return fmt.Errorf("--cwd: %v", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("--cwd is not a directory: %s", dir)
+	}
+
+	f, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("--cwd directory is not readable: %v", err)
+	}
+	_, err = f.Readdirnames(1)
+	f.Close()
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("--cwd directory is not readable: %v", err)
+	}
+
+	if err := os.Chdir(dir); err != nil {
+		return fmt.Errorf("--cwd: %v", err)
+	}
+	return nil
+}
+
 func main() {
-	// 检查是否有 --auto 参数
-	autoMode := false
-	args := os.Args[1:]
-	for i, arg := range args {
-		if arg == "--auto" || arg == "-a" {
-			autoMode = true
-			// 从参数列表中移除 --auto
-			args = append(args[:i], args[i+1:]...)
-			break
+	// 解析启动参数，其余参数作为单次模式的提示词
+	opts, err := parseArgs(os.Args[1:])
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	autoMode, noStream, args := opts.autoMode, opts.noStream, opts.args
+	output.Configure(opts.noColor, term.IsTerminal(int(os.Stdout.Fd())))
+
+	// --cwd 在加载配置和创建代理之前切换目录，系统提示词和相对路径都以新目录为准；
+	// 提示词文件仍相对于启动时的目录
+	if opts.cwd != "" {
+		if opts.promptFile != "" {
+			if abs, err := filepath.Abs(opts.promptFile); err == nil {
+				opts.promptFile = abs
+			}
+		}
+		if err := changeDir(opts.cwd); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
 		}
 	}
 
-	fmt.Println("🤖 OpenCode Nano - Interactive AI Programming Assistant")
-	if autoMode {
-		fmt.Println("⚡ 自动模式已启用 - 所有操作将自动批准")
-		fmt.Println("⚠️  警告: 请确保您信任正在执行的任务")
+	// --version 只输出版本信息，不需要加载配置
+	if opts.showVersion {
+		printVersion(os.Stdout)
+		return
+	}
+
+	// --list-tools 和 schema 子命令不需要完整配置，但仍排除配置中禁用的工具
+	if opts.listTools || (len(args) > 0 && args[0] == "schema") {
+		if err := initToolRegistry(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// --list-tools 只列出工具，不需要加载配置
+	if opts.listTools {
+		if err := printTools(os.Stdout, strings.Join(args, " ")); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// schema 子命令只输出工具定义，不需要加载配置
+	if isSchemaCommand(args) {
+		if err := printSchemas(os.Stdout, args[1:]); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// JSON 和 quiet 模式下 stdout 只输出结果，不显示欢迎信息
+	if !opts.jsonOutput && !opts.quiet {
+		fmt.Println(output.Sprintf(output.Assistant, "OpenCode Nano - Interactive AI Programming Assistant"))
+		if autoMode {
+			fmt.Println(output.Sprintf(output.Auto, "自动模式已启用 - 所有操作将自动批准"))
+			fmt.Println(output.Sprintf(output.Warning, "警告: 请确保您信任正在执行的任务"))
+		}
+		fmt.Println("Type 'exit' or 'quit' to exit, Ctrl+C to interrupt")
+		fmt.Println(strings.Repeat("=", 50))
 	}
-	fmt.Println("Type 'exit' or 'quit' to exit, Ctrl+C to interrupt")
-	fmt.Println(strings.Repeat("=", 50))
 
 	// 加载配置
-	cfg, err := config.Load()
+	cfg, err := config.LoadProfile(opts.profile)
 	if err != nil {
 		fmt.Printf("Error loading config: %v\n", err)
 		os.Exit(1)
 	}
+	if opts.dryRun {
+		cfg.DryRun = true
+	}
+	if opts.debug {
+		cfg.Debug = true
+	}
+	if cfg.DryRun && !opts.jsonOutput && !opts.quiet {
+		fmt.Println(output.Sprintf(output.Warning, "Dry run 模式 - 写文件、执行命令等操作只报告不执行"))
+	}
 
 	// 创建权限管理器
-	var perm permission.Manager
-	if autoMode {
-		perm = permission.NewAuto()
+	perm := newPermissionManager(cfg, autoMode)
+
+	// 创建工具集 - 默认使用注册表中的全部工具，--legacy-tools 时只保留最小工具集
+	var toolSet []tools.Tool
+	if opts.legacyTools {
+		toolSet, err = tools.CreateToolSet(perm, cfg)
 	} else {
-		perm = permission.New()
+		toolSet, err = tools.CreateFullToolSet(perm, cfg)
 	}
-
-	// 创建工具集 - 使用新的工具系统
-	toolSet, err := tools.CreateToolSet(perm)
 	if err != nil {
 		fmt.Printf("Error creating tool set: %v\n", err)
 		os.Exit(1)
@@ -64,22 +243,48 @@ func main() {
 		fmt.Printf("Error creating agent: %v\n", err)
 		os.Exit(1)
 	}
+	// 调试日志写入 stderr，不影响 stdout 和 JSON 输出
+	if cfg.Debug {
+		ag.SetLogger(core.NewWriterLogger(os.Stderr))
+	}
 
-	// 设置信号处理
-	ctx, cancel := context.WithCancel(context.Background())
+	// 设置信号处理：Ctrl+C 中断当前一轮对话，短时间内再次按下或收到 SIGTERM 时退出
+	ctx := context.Background()
+	interrupts := newInterruptHandler(exitWindow)
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	go func() {
-		<-c
-		fmt.Println("\n\n👋 Goodbye!")
-		cancel()
-		os.Exit(0)
+		for sig := range c {
+			if sig == os.Interrupt && !interrupts.interrupt() {
+				fmt.Printf("\n%s\n", output.Sprintf(output.Interrupt, "已中断，再次按 Ctrl+C 退出"))
+				continue
+			}
+			fmt.Printf("\n\n%s\n", output.Sprintf(output.Goodbye, "Goodbye!"))
+			printSessionUsage(ag)
+			os.Exit(0)
+		}
 	}()
 
-	// 如果有命令行参数，执行单次对话模式
-	if len(args) > 0 {
-		prompt := strings.Join(args, " ")
-		err := ag.RunOnce(ctx, prompt)
+	// 如果指定了提示词文件、有命令行参数或通过管道输入了提示词，执行单次对话模式
+	var prompt string
+	if opts.promptFile != "" {
+		prompt, err = readPromptFile(opts.promptFile)
+	} else {
+		prompt, err = singleShotPrompt(args, os.Stdin)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if prompt != "" {
+		ag.SetStreaming(!noStream)
+		ag.SetQuiet(opts.quiet)
+		if opts.jsonOutput {
+			ag.SetJSONOutput(os.Stdout)
+		}
+		turnCtx, done := interrupts.startTurn(ctx)
+		err := ag.RunOnce(turnCtx, prompt)
+		done()
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			os.Exit(1)
@@ -88,26 +293,40 @@ func main() {
 	}
 
 	// 交互式模式
-	scanner := bufio.NewScanner(os.Stdin)
+	reader := newLineReader(config.HistoryFilePath())
+	var readErr error
 	for {
-		fmt.Print("\n💬 You: ")
-		if !scanner.Scan() {
+		fmt.Println()
+		line, err := readInput(reader.ReadLine, output.Prefix(output.User)+"You: ", cfg.MultilineFence)
+		if err != nil {
+			if err != io.EOF {
+				readErr = err
+			}
 			break
 		}
 
-		input := strings.TrimSpace(scanner.Text())
+		input := strings.TrimSpace(line)
 		if input == "" {
 			continue
 		}
 
 		if input == "exit" || input == "quit" {
-			fmt.Println("👋 Goodbye!")
+			fmt.Println(output.Sprintf(output.Goodbye, "Goodbye!"))
 			break
 		}
 
 		if input == "clear" {
 			ag.ClearConversation()
-			fmt.Println("🧹 Conversation cleared!")
+			fmt.Println(output.Sprintf(output.Cleared, "Conversation cleared!"))
+			continue
+		}
+
+		if input == "undo" {
+			if ag.UndoLastExchange() {
+				fmt.Println(output.Sprintf(output.Undo, "已撤销上一轮对话"))
+			} else {
+				fmt.Println("没有可撤销的对话")
+			}
 			continue
 		}
 
@@ -116,15 +335,231 @@ func main() {
 			continue
 		}
 
+		if input == "tools" || strings.HasPrefix(input, "tools ") {
+			if err := printTools(os.Stdout, strings.TrimSpace(strings.TrimPrefix(input, "tools"))); err != nil {
+				fmt.Println(output.Sprintf(output.Error, "Error: %v", err))
+			}
+			continue
+		}
+
 		// 处理用户输入
-		err := ag.RunInteractive(ctx, input)
-		if err != nil {
-			fmt.Printf("❌ Error: %v\n", err)
+		turnCtx, done := interrupts.startTurn(ctx)
+		err = ag.RunInteractive(turnCtx, input)
+		done()
+		// 被 Ctrl+C 中断时回到输入提示，信号处理中已输出提示
+		if err != nil && !errors.Is(err, agent.ErrInterrupted) {
+			fmt.Println(output.Sprintf(output.Error, "Error: %v", err))
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		fmt.Printf("Error reading input: %v\n", err)
+	printSessionUsage(ag)
+
+	if readErr != nil {
+		fmt.Printf("Error reading input: %v\n", readErr)
+	}
+}
+
+// newPermissionManager 根据配置的规则和 --auto 创建权限管理器：
+// 配置了规则时先按规则判断，未命中再自动批准（--auto）或询问用户
+func newPermissionManager(cfg *config.Config, auto bool) permission.Manager {
+	rules := permissionRules(cfg)
+	switch {
+	case auto && !rules.Empty():
+		return permission.NewPolicyWithFallback(rules, permission.NewAuto())
+	case auto:
+		return permission.NewAuto()
+	case !rules.Empty():
+		return permission.NewPolicy(rules)
+	default:
+		return permission.New()
+	}
+}
+
+// permissionRules 将配置文件中的权限规则转换为权限管理器使用的规则
+func permissionRules(cfg *config.Config) permission.Rules {
+	var rules permission.Rules
+	for _, rule := range cfg.PermissionAllow {
+		rules.Allow = append(rules.Allow, permission.Rule{Action: rule.Action, Pattern: rule.Pattern})
+	}
+	for _, rule := range cfg.PermissionDeny {
+		rules.Deny = append(rules.Deny, permission.Rule{Action: rule.Action, Pattern: rule.Pattern})
+	}
+	return rules
+}
+
+// initToolRegistry 按配置文件和 OPENCODE_DISABLED_TOOLS 中禁用的工具初始化默认注册表
+func initToolRegistry() error {
+	disabled, err := config.LoadDisabledTools()
+	if err != nil {
+		return err
+	}
+	_, err = tools.InitializeRegistry(disabled...)
+	return err
+}
+
+// printTools 按分类列出已注册的工具，query 非空时只列出匹配的工具
+func printTools(w io.Writer, query string) error {
+	registry := tools.DefaultRegistry
+	if registry == nil {
+		var err error
+		if registry, err = tools.InitializeRegistry(); err != nil {
+			return err
+		}
+	}
+
+	var matched map[string]bool
+	if query != "" {
+		matched = make(map[string]bool)
+		for _, tool := range registry.Find(query) {
+			matched[tool.Info().Name] = true
+		}
+		if len(matched) == 0 {
+			fmt.Fprintf(w, "没有匹配 %q 的工具\n", query)
+			return nil
+		}
+	}
+
+	categories := registry.Categories()
+	sort.Strings(categories)
+	for _, category := range categories {
+		var lines []string
+		for _, tool := range registry.GetByCategory(category) {
+			info := tool.Info()
+			if matched != nil && !matched[info.Name] {
+				continue
+			}
+
+			line := "  • " + info.Name
+			var aliases []string
+			for _, alias := range registry.GetAliases(info.Name) {
+				if alias != info.Name {
+					aliases = append(aliases, alias)
+				}
+			}
+			if len(aliases) > 0 {
+				sort.Strings(aliases)
+				line += " (" + strings.Join(aliases, ", ") + ")"
+			}
+			if info.RequiresPerm {
+				line += " 🔒"
+			}
+			description, _, _ := strings.Cut(info.Description, "\n")
+			lines = append(lines, line+" - "+description)
+		}
+		if len(lines) == 0 {
+			continue
+		}
+
+		sort.Strings(lines)
+		fmt.Fprintf(w, "📂 %s\n%s\n", category, strings.Join(lines, "\n"))
+	}
+	fmt.Fprintln(w, "\n🔒 表示需要权限")
+	return nil
+}
+
+// isSchemaCommand 判断参数是否为 schema 子命令：schema 单独出现，或后面全部是工具名（含别名）。
+// 其他以 schema 开头的参数（如 "schema 里的 users 表"）仍作为提示词
+func isSchemaCommand(args []string) bool {
+	if len(args) == 0 || args[0] != "schema" {
+		return false
+	}
+	for _, name := range args[1:] {
+		if _, err := tools.GetTool(name); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// printSchemas 以 JSON 输出工具的参数 schema，names 为空时输出全部工具，支持别名
+func printSchemas(w io.Writer, names []string) error {
+	schemas, err := tools.ExportSchemas()
+	if err != nil {
+		return err
+	}
+
+	if len(names) > 0 {
+		selected := make(map[string]json.RawMessage, len(names))
+		for _, name := range names {
+			tool, err := tools.GetTool(name)
+			if err != nil {
+				return err
+			}
+			selected[tool.Info().Name] = schemas[tool.Info().Name]
+		}
+		schemas = selected
+	}
+
+	data, err := json.MarshalIndent(schemas, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode schemas: %v", err)
+	}
+	fmt.Fprintln(w, string(data))
+	return nil
+}
+
+// singleShotPrompt 返回单次模式的提示词：有参数时使用参数，
+// 否则在 stdin 不是终端时读取管道内容。返回空字符串表示进入交互模式
+func singleShotPrompt(args []string, stdin *os.File) (string, error) {
+	if len(args) > 0 {
+		return strings.Join(args, " "), nil
+	}
+
+	info, err := stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice != 0 {
+		return "", nil
+	}
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read stdin: %v", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// readPromptFile 读取提示词文件，文件不存在或内容为空时返回错误
+func readPromptFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("prompt file not found: %s", path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read prompt file: %v", err)
+	}
+	prompt := strings.TrimSpace(string(data))
+	if prompt == "" {
+		return "", fmt.Errorf("prompt file is empty: %s", path)
+	}
+	return prompt, nil
+}
+
+// printVersion 输出版本、git 提交和 Go 运行时版本。
+// 未通过 -ldflags 设置提交时，使用 go build 嵌入的 vcs 信息
+func printVersion(w io.Writer) {
+	rev := commit
+	if rev == "" {
+		rev = "unknown"
+		if info, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range info.Settings {
+				if setting.Key == "vcs.revision" && setting.Value != "" {
+					rev = setting.Value
+					if len(rev) > 12 {
+						rev = rev[:12]
+					}
+				}
+			}
+		}
+	}
+	fmt.Fprintf(w, "opencode_nano %s (commit %s, %s %s/%s)\n", version, rev, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
+
+// printSessionUsage 打印整个会话累计的 token 用量
+func printSessionUsage(ag *agent.Agent) {
+	if usage := ag.TotalUsage(); usage.Total() > 0 {
+		cost := "cost unavailable for this model"
+		if c, ok := ag.EstimatedCost(usage); ok {
+			cost = fmt.Sprintf("~$%.4f this session", c)
+		}
+		fmt.Println(output.Sprintf(output.Stats, "Session total %s (%s)", usage, cost))
 	}
 }
 
@@ -133,18 +568,45 @@ func printHelp() {
 📖 可用命令:
   • 直接输入您的请求与 AI 对话
   • 'clear' - 清除对话历史
+  • 'undo' - 撤销上一轮对话（用户消息及之后的回复）
   • 'help' - 显示此帮助信息  
-  • 'exit' 或 'quit' - 退出程序
-  • Ctrl+C - 中断当前操作
+  • 'tools [关键词]' - 列出可用工具及别名，可按关键词过滤
+  • 'exit' 或 'quit' - 退出程序（或按 Ctrl+D）
+  • ↑/↓ 浏览历史输入，←/→、Ctrl+A/Ctrl+E 编辑当前行
+  • 行尾输入 \ 换行继续输入，单独一行 """ 开始/结束多行输入（可通过 multiline_fence 配置）
+  • Ctrl+C - 中断当前一轮对话，2 秒内再次按下退出程序
 
 🔧 可用工具:
-  • read_file - 读取文件内容
-  • write_file - 写入文件（需要权限）
-  • bash - 执行 shell 命令（需要权限）
+  • read / write / edit / patch - 读取和修改文件（写入类操作需要权限）
+  • search / glob / list / tree - 查找和浏览文件
+  • touch - 创建空文件或更新文件的修改时间（需要权限）
+  • ff - 按模糊匹配查找只记得部分名称的文件，按匹配程度排序
+  • json - 格式化、压缩 JSON 或按路径（如 a.b[0].c）提取字段
+  • gzip / zip / tar - 压缩、解压 .gz 文件，创建或解压 zip 和 .tar.gz 归档（需要权限）
+  • bash / pipeline / process - 执行命令和管理进程（需要权限）
+  • env / sysinfo / which - 查看环境信息
+  • go_parse - 检查 Go 源码的语法错误（不编译），可返回 gofmt 格式化结果
+  • go_symbols - 列出 Go 文件或包中的函数、方法、类型等声明，或查找某个符号的定义位置
+  • fetch - 通过 HTTP GET 获取 URL 内容（需要权限，默认拒绝本机和内网地址）
   • todo - 管理会话 todo 列表（无需权限）
 
 ⚡ 启动参数:
   • --auto 或 -a - 自动模式，批准所有操作（谨慎使用）
+  • --no-stream - 单次模式下使用非流式请求，便于脚本调用
+  • --quiet, -q - 单次模式只输出助手回复，不显示思考提示、工具调用和完成信息
+  • --dry-run - 写文件、编辑、删除、执行命令等有副作用的工具调用只报告将要执行的操作，不修改磁盘（也可设置 OPENCODE_DRY_RUN=1）
+  • --debug - 向 stderr 输出调试日志：请求摘要、工具调用的原始参数和结果长度（也可设置 OPENCODE_DEBUG=1）
+  • --no-color - 不使用 emoji 和颜色，输出纯文本（也可设置 NO_COLOR 环境变量）
+  • --json - 单次模式下不显示过程，结束后输出 JSON 结果（final_message、tool_calls、rounds、usage）
+  • --legacy-tools - 只启用 read、write、bash、todo 四个基础工具
+  • --list-tools [关键词] - 列出可用工具后退出
+  • --version 或 -v - 显示版本信息后退出
+  • --prompt-file 或 -f <文件> - 读取文件内容作为提示词，执行单次模式
+  • --cwd 或 -C <目录> - 切换到指定目录后启动，系统提示词和相对路径都以该目录为准
+  • --profile <名称> - 使用配置文件 profiles 中的指定档案（也可设置 OPENCODE_PROFILE），环境变量仍优先
+
+🧩 子命令:
+  • schema [工具名...] - 以 JSON Schema 格式输出工具的参数定义（参数不全是工具名时作为普通提示词）
 
 💡 示例提示:
   • "创建一个 Go 的 hello world 程序"
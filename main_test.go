@@ -2,10 +2,21 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
 	"strings"
 	"testing"
+
+	"opencode_nano/agent"
+	"opencode_nano/config"
+	"opencode_nano/permission"
+	"opencode_nano/tools"
 )
 
 func TestPrintHelp(t *testing.T) {
@@ -38,9 +49,13 @@ func TestPrintHelp(t *testing.T) {
 		"exit",
 		"quit",
 		"🔧 可用工具:",
-		"read_file",
-		"write_file",
+		"read",
+		"write",
+		"edit",
 		"bash",
+		"--legacy-tools",
+		"--list-tools",
+		"tools",
 		"⚡ 启动参数:",
 		"--auto",
 		"-a",
@@ -144,4 +159,411 @@ func TestArgumentProcessing(t *testing.T) {
 }
 
 // 由于 main 函数包含交互式循环，很难直接测试
-// 这里我们测试可以独立测试的部分
\ No newline at end of file
+// 这里我们测试可以独立测试的部分
+func TestPermissionRules(t *testing.T) {
+	if rules := permissionRules(&config.Config{}); !rules.Empty() {
+		t.Errorf("没有配置规则时应为空: %+v", rules)
+	}
+
+	cfg := &config.Config{
+		PermissionAllow: []config.PermissionRule{{Action: "bash", Pattern: "go test*"}},
+		PermissionDeny:  []config.PermissionRule{{Pattern: "rm -rf"}},
+	}
+	want := permission.Rules{
+		Allow: []permission.Rule{{Action: "bash", Pattern: "go test*"}},
+		Deny:  []permission.Rule{{Pattern: "rm -rf"}},
+	}
+	if got := permissionRules(cfg); !reflect.DeepEqual(got, want) {
+		t.Errorf("permissionRules() = %+v, want %+v", got, want)
+	}
+}
+
+func TestNewPermissionManager(t *testing.T) {
+	withRules := &config.Config{PermissionDeny: []config.PermissionRule{{Action: "bash", Pattern: "rm -rf"}}}
+
+	if _, ok := newPermissionManager(&config.Config{}, true).(*permission.AutoManager); !ok {
+		t.Error("--auto 且没有规则时应使用 AutoManager")
+	}
+	if _, ok := newPermissionManager(&config.Config{}, false).(*permission.InteractiveManager); !ok {
+		t.Error("默认应使用 InteractiveManager")
+	}
+	if _, ok := newPermissionManager(withRules, false).(*permission.PolicyManager); !ok {
+		t.Error("配置了规则时应使用 PolicyManager")
+	}
+
+	// --auto 仍然遵守拒绝规则，未命中时自动批准
+	perm := newPermissionManager(withRules, true)
+	if perm.Request("bash", "Execute command: rm -rf /tmp/x") {
+		t.Error("--auto 时拒绝规则应生效")
+	}
+	if !perm.Request("bash", "Execute command: go test ./...") {
+		t.Error("--auto 时未命中规则应自动批准")
+	}
+}
+
+func TestIsSchemaCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{"单独的 schema", []string{"schema"}, true},
+		{"指定工具", []string{"schema", "bash", "read"}, true},
+		{"工具别名", []string{"schema", "sh"}, true},
+		{"以 schema 开头的提示词", []string{"schema", "of", "the", "users", "table"}, false},
+		{"含有未知工具名", []string{"schema", "bash", "please"}, false},
+		{"schema 不在开头", []string{"explain", "schema"}, false},
+		{"整句提示词", []string{"schema of the users table"}, false},
+		{"没有参数", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSchemaCommand(tt.args); got != tt.want {
+				t.Errorf("isSchemaCommand(%q) = %v, want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintSchemas(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printSchemas(&buf, []string{"sh", "read"}); err != nil {
+		t.Fatalf("printSchemas() error = %v", err)
+	}
+
+	var schemas map[string]map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &schemas); err != nil {
+		t.Fatalf("输出不是有效的 JSON: %v", err)
+	}
+	if len(schemas) != 2 || schemas["bash"] == nil || schemas["read"] == nil {
+		t.Errorf("输出的工具 = %v, want bash 和 read", reflect.ValueOf(schemas).MapKeys())
+	}
+
+	if err := printSchemas(&buf, []string{"no_such_tool"}); err == nil {
+		t.Error("未知工具应返回错误")
+	}
+}
+
+func TestPrintTools(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printTools(&buf, ""); err != nil {
+		t.Fatalf("printTools() error = %v", err)
+	}
+	output := buf.String()
+
+	expectedContents := []string{
+		"📂 file",
+		"📂 system",
+		"📂 development",
+		"read (cat, r)",
+		"write (w) 🔒",
+		"bash (cmd, sh, shell) 🔒",
+		"todo",
+	}
+	for _, expected := range expectedContents {
+		if !strings.Contains(output, expected) {
+			t.Errorf("printTools() 输出未包含: %s", expected)
+		}
+	}
+
+	// 按关键词过滤
+	buf.Reset()
+	if err := printTools(&buf, "grep"); err != nil {
+		t.Fatalf("printTools() error = %v", err)
+	}
+	output = buf.String()
+	if !strings.Contains(output, "search") || strings.Contains(output, "bash") {
+		t.Errorf("过滤后的输出 = %s", output)
+	}
+}
+
+func TestInitToolRegistry_DisabledTools(t *testing.T) {
+	oldRegistry := tools.DefaultRegistry
+	defer func() {
+		tools.DefaultRegistry = oldRegistry
+	}()
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("OPENCODE_DISABLED_TOOLS", "rm")
+	if err := initToolRegistry(); err != nil {
+		t.Fatalf("initToolRegistry() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := printTools(&buf, ""); err != nil {
+		t.Fatalf("printTools() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "• delete") || !strings.Contains(buf.String(), "• read") {
+		t.Errorf("printTools() 应只省略禁用的工具:\n%s", buf.String())
+	}
+
+	if isSchemaCommand([]string{"schema", "delete"}) {
+		t.Error("禁用的工具不应作为 schema 参数")
+	}
+	buf.Reset()
+	if err := printSchemas(&buf, nil); err != nil {
+		t.Fatalf("printSchemas() error = %v", err)
+	}
+	var schemas map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &schemas); err != nil {
+		t.Fatalf("输出不是有效的 JSON: %v", err)
+	}
+	if _, ok := schemas["delete"]; ok {
+		t.Error("printSchemas() 不应导出禁用的工具")
+	}
+	if _, ok := schemas["read"]; !ok {
+		t.Error("printSchemas() 应导出未禁用的工具")
+	}
+}
+
+func TestPrintVersion(t *testing.T) {
+	oldVersion, oldCommit := version, commit
+	defer func() {
+		version, commit = oldVersion, oldCommit
+	}()
+
+	version, commit = "v1.2.3", "abc1234"
+	var buf bytes.Buffer
+	printVersion(&buf)
+	want := fmt.Sprintf("opencode_nano v1.2.3 (commit abc1234, %s %s/%s)\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	if buf.String() != want {
+		t.Errorf("printVersion() = %q, want %q", buf.String(), want)
+	}
+
+	// 未设置提交时仍输出完整格式
+	version, commit = "dev", ""
+	buf.Reset()
+	printVersion(&buf)
+	pattern := regexp.MustCompile(`^opencode_nano dev \(commit \S+, go\S* \S+/\S+\)\n$`)
+	if !pattern.MatchString(buf.String()) {
+		t.Errorf("printVersion() = %q, 格式不正确", buf.String())
+	}
+}
+
+func TestSingleShotPrompt(t *testing.T) {
+	pipe := func(content string) *os.File {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatalf("os.Pipe() error = %v", err)
+		}
+		t.Cleanup(func() { r.Close() })
+		go func() {
+			io.WriteString(w, content)
+			w.Close()
+		}()
+		return r
+	}
+
+	tests := []struct {
+		name  string
+		args  []string
+		stdin string
+		want  string
+	}{
+		{"管道输入", nil, "refactor x\nand y\n", "refactor x\nand y"},
+		{"参数优先", []string{"from", "args"}, "from stdin", "from args"},
+		{"空管道进入交互模式", nil, "  \n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := singleShotPrompt(tt.args, pipe(tt.stdin))
+			if err != nil {
+				t.Fatalf("singleShotPrompt() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("singleShotPrompt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		argv    []string
+		want    cliOptions
+		wantErr bool
+	}{
+		{
+			name: "提示词参数",
+			argv: []string{"--auto", "fix", "tests"},
+			want: cliOptions{autoMode: true, args: []string{"fix", "tests"}},
+		},
+		{
+			name: "-f 指定提示词文件",
+			argv: []string{"-a", "-f", "task.md", "--no-stream"},
+			want: cliOptions{autoMode: true, noStream: true, promptFile: "task.md"},
+		},
+		{
+			name: "--prompt-file=",
+			argv: []string{"--prompt-file=task.md"},
+			want: cliOptions{promptFile: "task.md"},
+		},
+		{
+			name:    "缺少文件路径",
+			argv:    []string{"--prompt-file"},
+			wantErr: true,
+		},
+		{
+			name:    "文件与提示词参数同时使用",
+			argv:    []string{"-f", "task.md", "extra"},
+			wantErr: true,
+		},
+		{
+			name: "JSON 输出",
+			argv: []string{"--json", "count", "lines"},
+			want: cliOptions{jsonOutput: true, args: []string{"count", "lines"}},
+		},
+		{
+			name: "纯文本输出",
+			argv: []string{"--no-color", "fix"},
+			want: cliOptions{noColor: true, args: []string{"fix"}},
+		},
+		{
+			name: "quiet",
+			argv: []string{"-q", "--no-stream", "summarize"},
+			want: cliOptions{quiet: true, noStream: true, args: []string{"summarize"}},
+		},
+		{
+			name: "调试日志",
+			argv: []string{"--debug", "--json", "fix"},
+			want: cliOptions{debug: true, jsonOutput: true, args: []string{"fix"}},
+		},
+		{
+			name: "dry run",
+			argv: []string{"--dry-run", "refactor"},
+			want: cliOptions{dryRun: true, args: []string{"refactor"}},
+		},
+		{
+			name: "版本",
+			argv: []string{"-v"},
+			want: cliOptions{showVersion: true},
+		},
+		{
+			name: "-C 指定工作目录",
+			argv: []string{"-C", "../other", "fix"},
+			want: cliOptions{cwd: "../other", args: []string{"fix"}},
+		},
+		{
+			name: "--cwd=",
+			argv: []string{"--cwd=/tmp/project", "-a"},
+			want: cliOptions{cwd: "/tmp/project", autoMode: true},
+		},
+		{
+			name:    "缺少工作目录",
+			argv:    []string{"--cwd"},
+			wantErr: true,
+		},
+		{
+			name: "指定配置档案",
+			argv: []string{"--profile", "work", "fix"},
+			want: cliOptions{profile: "work", args: []string{"fix"}},
+		},
+		{
+			name: "--profile=",
+			argv: []string{"--profile=local"},
+			want: cliOptions{profile: "local"},
+		},
+		{
+			name:    "缺少档案名",
+			argv:    []string{"--profile"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseArgs(tt.argv)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseArgs() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadPromptFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "task.md")
+	if err := os.WriteFile(path, []byte("\n重构 main.go\n并补充测试\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	prompt, err := readPromptFile(path)
+	if err != nil {
+		t.Fatalf("readPromptFile() error = %v", err)
+	}
+	if prompt != "重构 main.go\n并补充测试" {
+		t.Errorf("readPromptFile() = %q", prompt)
+	}
+
+	_, err = readPromptFile(filepath.Join(dir, "missing.md"))
+	if err == nil || !strings.Contains(err.Error(), "prompt file not found") {
+		t.Errorf("不存在的文件应返回明确错误, got %v", err)
+	}
+
+	empty := filepath.Join(dir, "empty.md")
+	os.WriteFile(empty, []byte("  \n"), 0644)
+	if _, err := readPromptFile(empty); err == nil {
+		t.Error("空文件应返回错误")
+	}
+}
+
+func TestChangeDir(t *testing.T) {
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(oldWd) })
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.txt")
+	if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		dir     string
+		wantErr string
+	}{
+		{name: "目录不存在", dir: filepath.Join(dir, "missing"), wantErr: "not found"},
+		{name: "不是目录", dir: file, wantErr: "not a directory"},
+	} {
+		if err := changeDir(tt.dir); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: changeDir() error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if err := changeDir(dir); err != nil {
+		t.Fatalf("changeDir() error = %v", err)
+	}
+	// 临时目录可能位于符号链接之下（如 macOS 的 /var），以当前工作目录为准
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved, _ := filepath.EvalSymlinks(cwd); resolved != mustEvalSymlinks(t, dir) {
+		t.Fatalf("工作目录 = %s, want %s", cwd, dir)
+	}
+
+	ag, err := agent.New(&config.Config{OpenAIAPIKey: "test", Model: "gpt-4o-mini"}, nil)
+	if err != nil {
+		t.Fatalf("agent.New() error = %v", err)
+	}
+	if !strings.Contains(ag.SystemPrompt(), "当前工作目录："+cwd) {
+		t.Errorf("系统提示词应包含新的工作目录 %s:\n%s", cwd, ag.SystemPrompt())
+	}
+}
+
+func mustEvalSymlinks(t *testing.T, path string) string {
+	t.Helper()
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resolved
+}
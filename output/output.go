@@ -0,0 +1,86 @@
+// Package output 统一终端输出的样式：默认使用 emoji 前缀，在终端中为部分消息着色；
+// 设置 NO_COLOR 环境变量或使用 --no-color 参数时改为纯文本前缀，便于记录日志和在 CI 中使用
+package output
+
+import (
+	"fmt"
+	"os"
+)
+
+// Kind 输出消息的类别，决定前缀和颜色
+type Kind int
+
+const (
+	Assistant Kind = iota // 助手回复
+	User                  // 用户输入提示
+	Tool                  // 工具调用
+	Result                // 工具结果
+	Success               // 任务完成
+	Warning               // 警告
+	Error                 // 错误
+	Stats                 // token 用量与费用
+	Interrupt             // 中断提示
+	Goodbye               // 退出
+	Cleared               // 清除对话
+	Undo                  // 撤销对话
+	Auto                  // 自动模式
+)
+
+// style 一种消息类别的样式，plain 为空表示纯文本模式下不加前缀
+type style struct {
+	emoji string
+	plain string
+	color string
+}
+
+const reset = "\x1b[0m"
+
+var styles = map[Kind]style{
+	Assistant: {emoji: "🤖 "},
+	User:      {emoji: "💬 "},
+	Tool:      {emoji: "🔧 ", plain: "[tool] ", color: "\x1b[36m"},
+	Result:    {emoji: "📝 ", plain: "[result] "},
+	Success:   {emoji: "✅ ", plain: "[done] ", color: "\x1b[32m"},
+	Warning:   {emoji: "⚠️  ", plain: "[warn] ", color: "\x1b[33m"},
+	Error:     {emoji: "❌ ", plain: "[error] ", color: "\x1b[31m"},
+	Stats:     {emoji: "📊 ", plain: "[usage] "},
+	Interrupt: {emoji: "⏹️  ", plain: "[interrupted] ", color: "\x1b[33m"},
+	Goodbye:   {emoji: "👋 "},
+	Cleared:   {emoji: "🧹 "},
+	Undo:      {emoji: "↩️  "},
+	Auto:      {emoji: "⚡ ", plain: "[auto] ", color: "\x1b[33m"},
+}
+
+var (
+	plain = os.Getenv("NO_COLOR") != ""
+	color = false
+)
+
+// Configure 设置输出样式：noColor 为 true 或设置了 NO_COLOR 时使用纯文本，
+// 否则使用 emoji 前缀，并在 stdout 为终端时启用颜色
+func Configure(noColor bool, isTerminal bool) {
+	plain = noColor || os.Getenv("NO_COLOR") != ""
+	color = !plain && isTerminal
+}
+
+// Plain 返回是否处于纯文本模式
+func Plain() bool {
+	return plain
+}
+
+// Prefix 返回消息类别的前缀
+func Prefix(kind Kind) string {
+	if plain {
+		return styles[kind].plain
+	}
+	return styles[kind].emoji
+}
+
+// Sprintf 格式化一条带前缀的消息，启用颜色时为消息文本着色
+func Sprintf(kind Kind, format string, args ...any) string {
+	msg := fmt.Sprintf(format, args...)
+	if c := styles[kind].color; color && c != "" {
+		msg = c + msg + reset
+	}
+	return Prefix(kind) + msg
+}
@@ -0,0 +1,56 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSprintf(t *testing.T) {
+	defer Configure(false, false)
+
+	tests := []struct {
+		name       string
+		noColor    bool
+		envNoColor string
+		isTerminal bool
+		kind       Kind
+		want       string
+	}{
+		{"终端中带 emoji 和颜色", false, "", true, Error, "❌ \x1b[31mboom\x1b[0m"},
+		{"非终端只有 emoji", false, "", false, Error, "❌ boom"},
+		{"--no-color 使用纯文本前缀", true, "", true, Error, "[error] boom"},
+		{"NO_COLOR 使用纯文本前缀", false, "1", true, Tool, "[tool] boom"},
+		{"纯文本模式下装饰性前缀为空", false, "1", true, Assistant, "boom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.envNoColor)
+			Configure(tt.noColor, tt.isTerminal)
+			if got := Sprintf(tt.kind, "%s", "boom"); got != tt.want {
+				t.Errorf("Sprintf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoColorSuppressesStyling(t *testing.T) {
+	defer Configure(false, false)
+	t.Setenv("NO_COLOR", "1")
+	Configure(false, true)
+
+	if !Plain() {
+		t.Fatal("设置 NO_COLOR 后应为纯文本模式")
+	}
+	for kind := range styles {
+		got := Sprintf(kind, "message")
+		if strings.Contains(got, "\x1b[") {
+			t.Errorf("Sprintf(%d) 包含 ANSI 转义: %q", kind, got)
+		}
+		for _, r := range got {
+			if r > 0x2000 {
+				t.Errorf("Sprintf(%d) 包含 emoji: %q", kind, got)
+				break
+			}
+		}
+	}
+}
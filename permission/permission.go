@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 )
 
 // Manager 权限管理器接口
@@ -13,18 +14,35 @@ type Manager interface {
 }
 
 // InteractiveManager 交互式权限管理器
-type InteractiveManager struct{}
+type InteractiveManager struct {
+	mu        sync.Mutex
+	decisions map[string]bool // 本次会话记住的选择
+}
 
 func New() Manager {
 	return &InteractiveManager{}
 }
 
-// Request 请求执行权限，返回是否允许
+// Request 请求执行权限，返回是否允许。
+// 输入 a/always 在本次会话中始终允许同类操作，输入 never 始终拒绝
 func (m *InteractiveManager) Request(action, description string) bool {
+	key := decisionKey(action, description)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if allowed, ok := m.decisions[key]; ok {
+		if allowed {
+			fmt.Printf("✅ 本次会话已允许: %s - %s\n", action, description)
+		} else {
+			fmt.Printf("⛔ 本次会话已拒绝: %s - %s\n", action, description)
+		}
+		return allowed
+	}
+
 	fmt.Printf("\n🔐 需要权限:\n")
 	fmt.Printf("操作: %s\n", action)
 	fmt.Printf("描述: %s\n", description)
-	fmt.Printf("是否允许? [y/N]: ")
+	fmt.Printf("是否允许? [y/N, a=本次会话始终允许, never=始终拒绝]: ")
 
 	reader := bufio.NewReader(os.Stdin)
 	response, err := reader.ReadString('\n')
@@ -32,8 +50,39 @@ func (m *InteractiveManager) Request(action, description string) bool {
 		return false
 	}
 
-	response = strings.TrimSpace(strings.ToLower(response))
-	return response == "y" || response == "yes"
+	switch strings.TrimSpace(strings.ToLower(response)) {
+	case "y", "yes":
+		return true
+	case "a", "always":
+		m.remember(key, true)
+		return true
+	case "never":
+		m.remember(key, false)
+		return false
+	default:
+		return false
+	}
+}
+
+// Reset 清除本次会话记住的选择
+func (m *InteractiveManager) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.decisions = nil
+}
+
+// remember 记住选择，调用方需持有锁
+func (m *InteractiveManager) remember(key string, allowed bool) {
+	if m.decisions == nil {
+		m.decisions = make(map[string]bool)
+	}
+	m.decisions[key] = allowed
+}
+
+// decisionKey 由操作名和规范化后的完整描述组成缓存键，忽略大小写和多余空白。
+// 不按前缀匹配：在已允许的命令后追加内容（如 "; rm -rf ~"）会得到不同的键
+func decisionKey(action, description string) string {
+	return action + "\x00" + strings.ToLower(strings.Join(strings.Fields(description), " "))
 }
 
 // AutoManager 自动批准权限管理器
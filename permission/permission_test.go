@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -195,4 +196,113 @@ func TestNewAuto(t *testing.T) {
 	if _, ok := manager.(*AutoManager); !ok {
 		t.Errorf("NewAuto() 返回的不是 *AutoManager 类型")
 	}
-}
\ No newline at end of file
+}
+// requestWithInput 以 input 作为标准输入调用 Request，返回结果和输出
+func requestWithInput(t *testing.T, m *InteractiveManager, input, action, description string) (bool, string) {
+	t.Helper()
+	oldStdin := os.Stdin
+	oldStdout := os.Stdout
+	defer func() {
+		os.Stdin = oldStdin
+		os.Stdout = oldStdout
+	}()
+
+	r, w, _ := os.Pipe()
+	os.Stdin = r
+	go func() {
+		defer w.Close()
+		w.Write([]byte(input))
+	}()
+
+	outR, outW, _ := os.Pipe()
+	os.Stdout = outW
+	got := m.Request(action, description)
+	outW.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, outR)
+	r.Close()
+	return got, buf.String()
+}
+
+func TestInteractiveManager_RememberDecision(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{"a 本次会话始终允许", "a\n", true},
+		{"always 本次会话始终允许", "ALWAYS\n", true},
+		{"never 本次会话始终拒绝", "never\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &InteractiveManager{}
+			prompts := 0
+			// 首次之后不再读取输入，即使输入相反的选择也沿用记住的结果
+			inputs := []string{tt.input, "n\n", "y\n"}
+			descriptions := []string{"Execute command: go test", "execute   command:  GO TEST", "Execute command: go test"}
+			for i, input := range inputs {
+				got, output := requestWithInput(t, m, input, "bash", descriptions[i])
+				if got != tt.want {
+					t.Errorf("第 %d 次 Request() = %v, want %v", i+1, got, tt.want)
+				}
+				prompts += strings.Count(output, "🔐 需要权限:")
+			}
+			if prompts != 1 {
+				t.Errorf("提示次数 = %d, want 1", prompts)
+			}
+
+			// 其他操作或描述不受影响
+			if got, output := requestWithInput(t, m, "n\n", "write", "Execute command: go test"); got || !strings.Contains(output, "🔐 需要权限:") {
+				t.Errorf("其他操作应重新询问, got %v", got)
+			}
+			if _, output := requestWithInput(t, m, "n\n", "bash", "Execute command: go vet"); !strings.Contains(output, "🔐 需要权限:") {
+				t.Error("其他描述应重新询问")
+			}
+
+			// Reset 后重新询问
+			m.Reset()
+			if _, output := requestWithInput(t, m, "n\n", "bash", "Execute command: go test"); !strings.Contains(output, "🔐 需要权限:") {
+				t.Error("Reset 后应重新询问")
+			}
+		})
+	}
+}
+
+func TestInteractiveManager_YesNotRemembered(t *testing.T) {
+	m := &InteractiveManager{}
+	if got, _ := requestWithInput(t, m, "y\n", "bash", "Execute command: ls"); !got {
+		t.Fatal("输入 y 应允许")
+	}
+	got, output := requestWithInput(t, m, "n\n", "bash", "Execute command: ls")
+	if got || !strings.Contains(output, "🔐 需要权限:") {
+		t.Errorf("y 只允许一次，第二次应重新询问, got %v", got)
+	}
+}
+
+func TestDecisionKey(t *testing.T) {
+	long := strings.Repeat("x", 200)
+	if decisionKey("bash", long+"a") == decisionKey("bash", long+"b") {
+		t.Error("描述的任意部分不同缓存键都应不同")
+	}
+	if decisionKey("bash", "a") == decisionKey("write", "a") {
+		t.Error("不同操作的缓存键应不同")
+	}
+}
+
+func TestInteractiveManager_ExtendedCommandPrompts(t *testing.T) {
+	m := &InteractiveManager{}
+	cached := "Execute command: " + strings.Repeat("echo ok && ", 10) + "go test ./..."
+	if got, _ := requestWithInput(t, m, "a\n", "bash", cached); !got {
+		t.Fatal("输入 a 应允许")
+	}
+
+	// 在已允许的命令后追加内容必须重新询问
+	got, output := requestWithInput(t, m, "n\n", "bash", cached+"; rm -rf ~")
+	if got || !strings.Contains(output, "🔐 需要权限:") {
+		t.Errorf("扩展后的命令应重新询问, got %v", got)
+	}
+}
@@ -0,0 +1,98 @@
+package permission
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Rule 权限规则。Action 匹配操作名，Pattern 匹配描述；
+// 两者为空或 "*" 时匹配任意值，含 * 或 ? 时按通配符完整匹配，否则按子串匹配
+type Rule struct {
+	Action  string
+	Pattern string
+}
+
+// Rules 允许和拒绝规则，拒绝规则优先
+type Rules struct {
+	Allow []Rule
+	Deny  []Rule
+}
+
+// Empty 是否没有任何规则
+func (r Rules) Empty() bool {
+	return len(r.Allow) == 0 && len(r.Deny) == 0
+}
+
+// PolicyManager 按规则自动允许或拒绝，未命中规则时交给回退管理器
+type PolicyManager struct {
+	rules    Rules
+	fallback Manager
+}
+
+// NewPolicy 创建规则权限管理器，未命中规则时交互式询问
+func NewPolicy(rules Rules) Manager {
+	return NewPolicyWithFallback(rules, New())
+}
+
+// NewPolicyWithFallback 创建规则权限管理器并指定回退管理器
+func NewPolicyWithFallback(rules Rules, fallback Manager) *PolicyManager {
+	return &PolicyManager{rules: rules, fallback: fallback}
+}
+
+// Request 先检查拒绝规则，再检查允许规则，都未命中时询问回退管理器
+func (m *PolicyManager) Request(action, description string) bool {
+	for _, rule := range m.rules.Deny {
+		if rule.Matches(action, description) {
+			fmt.Printf("⛔ 规则拒绝: %s - %s\n", action, description)
+			return false
+		}
+	}
+	for _, rule := range m.rules.Allow {
+		if rule.Matches(action, description) {
+			fmt.Printf("✅ 规则允许: %s - %s\n", action, description)
+			return true
+		}
+	}
+	if m.fallback == nil {
+		return false
+	}
+	return m.fallback.Request(action, description)
+}
+
+// Matches 判断规则是否匹配该请求
+func (r Rule) Matches(action, description string) bool {
+	if r.Action != "" && r.Action != "*" && !globMatch(r.Action, action, false) {
+		return false
+	}
+	if r.Pattern != "" && r.Pattern != "*" && !globMatch(r.Pattern, description, true) {
+		return false
+	}
+	return true
+}
+
+// globMatch 含通配符时完整匹配，否则按 substring 决定子串匹配还是精确匹配
+func globMatch(pattern, s string, substring bool) bool {
+	if !strings.ContainsAny(pattern, "*?") {
+		if substring {
+			return strings.Contains(s, pattern)
+		}
+		return pattern == s
+	}
+
+	var sb strings.Builder
+	sb.WriteString("(?s)^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	matched, _ := regexp.MatchString(sb.String(), s)
+	return matched
+}
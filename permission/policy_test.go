@@ -0,0 +1,97 @@
+package permission
+
+import (
+	"testing"
+)
+
+// recordingManager 记录请求并返回固定结果的回退管理器
+type recordingManager struct {
+	allow    bool
+	requests []string
+}
+
+func (m *recordingManager) Request(action, description string) bool {
+	m.requests = append(m.requests, action+": "+description)
+	return m.allow
+}
+
+func TestPolicyManager_Request(t *testing.T) {
+	rules := Rules{
+		Allow: []Rule{
+			{Action: "read"},
+			{Action: "bash", Pattern: "Execute command: go test*"},
+			{Action: "write", Pattern: "/tmp/"},
+			{Action: "git_*"},
+		},
+		Deny: []Rule{
+			{Action: "bash", Pattern: "rm -rf"},
+			{Pattern: "*.env"},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		action       string
+		description  string
+		fallback     bool
+		want         bool
+		wantFallback bool
+	}{
+		{"按操作名允许", "read", "Write to file: main.go", false, true, false},
+		{"通配符匹配描述", "bash", "Execute command: go test ./...", false, true, false},
+		{"子串匹配描述", "write", "Write to file: /tmp/out.txt", false, true, false},
+		{"通配符匹配操作名", "git_status", "status", false, true, false},
+		{"拒绝规则优先", "bash", "Execute command: go test ./... && rm -rf /", true, false, false},
+		{"任意操作的拒绝规则", "write", "Write to file: /tmp/.env", true, false, false},
+		{"操作名不同不匹配", "bash", "Write to file: /tmp/out.txt", true, true, true},
+		{"通配符需要完整匹配", "bash", "Execute command: echo go test", false, false, true},
+		{"未命中规则时回退并允许", "delete", "Write to file: a.txt", true, true, true},
+		{"未命中规则时回退并拒绝", "delete", "Write to file: a.txt", false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fallback := &recordingManager{allow: tt.fallback}
+			m := NewPolicyWithFallback(rules, fallback)
+
+			if got := m.Request(tt.action, tt.description); got != tt.want {
+				t.Errorf("Request() = %v, want %v", got, tt.want)
+			}
+			if gotFallback := len(fallback.requests) > 0; gotFallback != tt.wantFallback {
+				t.Errorf("回退询问 = %v, want %v", gotFallback, tt.wantFallback)
+			}
+		})
+	}
+}
+
+func TestPolicyManager_NilFallback(t *testing.T) {
+	m := NewPolicyWithFallback(Rules{}, nil)
+	if m.Request("bash", "Execute command: ls") {
+		t.Error("没有回退管理器时应拒绝")
+	}
+}
+
+func TestRule_Matches(t *testing.T) {
+	tests := []struct {
+		name        string
+		rule        Rule
+		action      string
+		description string
+		want        bool
+	}{
+		{"空规则匹配任意请求", Rule{}, "bash", "anything", true},
+		{"星号匹配任意请求", Rule{Action: "*", Pattern: "*"}, "bash", "anything", true},
+		{"操作名精确匹配", Rule{Action: "bash"}, "bash_extra", "", false},
+		{"问号匹配单个字符", Rule{Pattern: "rm ?"}, "bash", "rm x", true},
+		{"通配符跨越多行", Rule{Pattern: "echo*done"}, "bash", "echo a\ndone", true},
+		{"正则字符按字面处理", Rule{Pattern: "a.b*"}, "bash", "axb", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Matches(tt.action, tt.description); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
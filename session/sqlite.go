@@ -0,0 +1,130 @@
+package session
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	_ "modernc.org/sqlite" // 纯 Go 实现的 SQLite 驱动，不需要 cgo
+)
+
+// SQLiteStorage 实现基于 SQLite 的存储，支持单条写入，
+// 多个进程同时写入时由 SQLite 的锁保证数据完整
+type SQLiteStorage struct {
+	db *sql.DB
+}
+
+// NewSQLiteStorage 打开（必要时创建）path 处的 SQLite 数据库
+func NewSQLiteStorage(path string) (*SQLiteStorage, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %v", err)
+	}
+
+	// 等待其他连接释放锁，而不是立即返回 SQLITE_BUSY
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	// SQLite 同一时间只允许一个写入者，单连接避免进程内的锁竞争
+	db.SetMaxOpenConns(1)
+
+	// todo 项整体以 JSON 保存，新增字段时不需要迁移表结构
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS todos (
+		id   TEXT PRIMARY KEY,
+		data TEXT NOT NULL
+	)`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create table: %v", err)
+	}
+
+	return &SQLiteStorage{db: db}, nil
+}
+
+// Close 关闭数据库
+func (ss *SQLiteStorage) Close() error {
+	return ss.db.Close()
+}
+
+// Load 从数据库加载 todo 数据
+func (ss *SQLiteStorage) Load() (map[string]*TodoItem, error) {
+	rows, err := ss.db.Query(`SELECT id, data FROM todos`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query todos: %v", err)
+	}
+	defer rows.Close()
+
+	items := make(map[string]*TodoItem)
+	for rows.Next() {
+		var id, data string
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, fmt.Errorf("failed to scan todo: %v", err)
+		}
+		var item TodoItem
+		if err := json.Unmarshal([]byte(data), &item); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal todo %s: %v", id, err)
+		}
+		items[id] = &item
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read todos: %v", err)
+	}
+
+	return items, nil
+}
+
+// Save 在一个事务中用 items 替换数据库中的全部 todo
+func (ss *SQLiteStorage) Save(items map[string]*TodoItem) error {
+	tx, err := ss.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM todos`); err != nil {
+		return fmt.Errorf("failed to clear todos: %v", err)
+	}
+	for _, item := range items {
+		if err := upsertTodo(tx, item); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// Upsert 插入或更新单个 todo 项
+func (ss *SQLiteStorage) Upsert(item *TodoItem) error {
+	return upsertTodo(ss.db, item)
+}
+
+// Remove 删除单个 todo 项，不存在时忽略
+func (ss *SQLiteStorage) Remove(id string) error {
+	if _, err := ss.db.Exec(`DELETE FROM todos WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete todo %s: %v", id, err)
+	}
+	return nil
+}
+
+// execer sql.DB 与 sql.Tx 共有的执行方法
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// upsertTodo 写入单个 todo 项
+func upsertTodo(db execer, item *TodoItem) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal todo %s: %v", item.ID, err)
+	}
+	if _, err := db.Exec(`INSERT INTO todos (id, data) VALUES (?, ?)
+		ON CONFLICT(id) DO UPDATE SET data = excluded.data`, item.ID, string(data)); err != nil {
+		return fmt.Errorf("failed to write todo %s: %v", item.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package session
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestSQLiteStorage(t *testing.T, path string) *SQLiteStorage {
+	t.Helper()
+	storage, err := NewSQLiteStorage(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage() failed: %v", err)
+	}
+	t.Cleanup(func() { storage.Close() })
+	return storage
+}
+
+func TestSQLiteStorage(t *testing.T) {
+	storage := newTestSQLiteStorage(t, filepath.Join(t.TempDir(), "todos.db"))
+
+	// 测试加载空数据库
+	items, err := storage.Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if len(items) != 0 {
+		t.Error("Load() should return empty map for empty database")
+	}
+
+	// 测试保存和加载实际数据
+	due := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	testItems := map[string]*TodoItem{
+		"1": {
+			ID:        "1",
+			Content:   "Test todo",
+			Status:    StatusPending,
+			Priority:  PriorityHigh,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			DueAt:     &due,
+			Tags:      []string{"backend"},
+		},
+		"2": {
+			ID:       "2",
+			Content:  "Child todo",
+			Status:   StatusCompleted,
+			Priority: PriorityLow,
+			ParentID: "1",
+		},
+	}
+	if err := storage.Save(testItems); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	loadedItems, err := storage.Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if len(loadedItems) != 2 {
+		t.Fatalf("Load() returned %d items, want 2", len(loadedItems))
+	}
+	loaded := loadedItems["1"]
+	if loaded.Content != "Test todo" || loaded.DueAt == nil || !loaded.DueAt.Equal(due) || len(loaded.Tags) != 1 {
+		t.Errorf("Loaded item = %+v, want %+v", loaded, testItems["1"])
+	}
+	if loadedItems["2"].ParentID != "1" {
+		t.Errorf("Loaded ParentID = %q, want 1", loadedItems["2"].ParentID)
+	}
+
+	// Save 替换全部数据
+	delete(testItems, "2")
+	if err := storage.Save(testItems); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	if loadedItems, _ = storage.Load(); len(loadedItems) != 1 {
+		t.Errorf("After Save(), Load() returned %d items, want 1", len(loadedItems))
+	}
+}
+
+func TestSQLiteStorage_UpsertRemove(t *testing.T) {
+	storage := newTestSQLiteStorage(t, filepath.Join(t.TempDir(), "todos.db"))
+
+	item := &TodoItem{ID: "1", Content: "Original", Status: StatusPending, Priority: PriorityMedium}
+	if err := storage.Upsert(item); err != nil {
+		t.Fatalf("Upsert() failed: %v", err)
+	}
+	item.Content = "Updated"
+	if err := storage.Upsert(item); err != nil {
+		t.Fatalf("Upsert() failed: %v", err)
+	}
+
+	items, _ := storage.Load()
+	if len(items) != 1 || items["1"].Content != "Updated" {
+		t.Errorf("After Upsert(), Load() = %v", items)
+	}
+
+	if err := storage.Remove("1"); err != nil {
+		t.Fatalf("Remove() failed: %v", err)
+	}
+	if err := storage.Remove("non-existent"); err != nil {
+		t.Errorf("Remove() of missing item should not fail: %v", err)
+	}
+	if items, _ = storage.Load(); len(items) != 0 {
+		t.Errorf("After Remove(), Load() returned %d items, want 0", len(items))
+	}
+}
+
+func TestSQLiteStorage_WithTodoManager(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.db")
+	manager := NewTodoManager(newTestSQLiteStorage(t, path))
+
+	item, _ := manager.Add("Persisted todo", PriorityHigh)
+	manager.Update(item.ID, StatusInProgress, "", "")
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	reloaded := NewTodoManager(newTestSQLiteStorage(t, path))
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	loaded, err := reloaded.Get(item.ID)
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if loaded.Content != "Persisted todo" || loaded.Status != StatusInProgress {
+		t.Errorf("Loaded item = %+v", loaded)
+	}
+}
+
+func TestSQLiteStorage_ConcurrentWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.db")
+	// 两个独立的连接模拟两个进程同时写入
+	storages := []*SQLiteStorage{
+		newTestSQLiteStorage(t, path),
+		newTestSQLiteStorage(t, path),
+	}
+
+	const writers, perWriter = 8, 20
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*perWriter)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			storage := storages[w%len(storages)]
+			for i := 0; i < perWriter; i++ {
+				id := fmt.Sprintf("%d-%d", w, i)
+				if err := storage.Upsert(&TodoItem{ID: id, Content: id, Status: StatusPending}); err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("concurrent Upsert() failed: %v", err)
+	}
+	items, err := storages[0].Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if len(items) != writers*perWriter {
+		t.Errorf("Load() returned %d items, want %d", len(items), writers*perWriter)
+	}
+}
@@ -12,12 +12,17 @@ import (
 type Storage interface {
 	Load() (map[string]*TodoItem, error)
 	Save(items map[string]*TodoItem) error
+	// Upsert 写入单个 todo 项，已存在时覆盖
+	Upsert(item *TodoItem) error
+	// Remove 移除单个 todo 项，不存在时忽略
+	Remove(id string) error
 }
 
 // FileStorage 实现基于文件的存储
 type FileStorage struct {
 	filePath string
 	mu       sync.RWMutex
+	writeMu  sync.Mutex // 保证 Upsert/Remove 的读取和保存之间不被其他修改打断
 }
 
 // NewFileStorage 创建新的文件存储
@@ -102,6 +107,33 @@ func (fs *FileStorage) Save(items map[string]*TodoItem) error {
 	return nil
 }
 
+// Upsert 写入单个 todo 项。JSON 文件无法局部修改，读取后整体重写
+func (fs *FileStorage) Upsert(item *TodoItem) error {
+	return fs.update(func(items map[string]*TodoItem) {
+		items[item.ID] = item
+	})
+}
+
+// Remove 移除单个 todo 项
+func (fs *FileStorage) Remove(id string) error {
+	return fs.update(func(items map[string]*TodoItem) {
+		delete(items, id)
+	})
+}
+
+// update 读取文件、修改后整体保存
+func (fs *FileStorage) update(modify func(items map[string]*TodoItem)) error {
+	fs.writeMu.Lock()
+	defer fs.writeMu.Unlock()
+
+	items, err := fs.Load()
+	if err != nil {
+		return err
+	}
+	modify(items)
+	return fs.Save(items)
+}
+
 // MemoryStorage 实现基于内存的存储（主要用于测试）
 type MemoryStorage struct {
 	items map[string]*TodoItem
@@ -143,4 +175,23 @@ func (ms *MemoryStorage) Save(items map[string]*TodoItem) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// Upsert 写入单个 todo 项
+func (ms *MemoryStorage) Upsert(item *TodoItem) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	itemCopy := *item
+	ms.items[item.ID] = &itemCopy
+	return nil
+}
+
+// Remove 移除单个 todo 项
+func (ms *MemoryStorage) Remove(id string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	delete(ms.items, id)
+	return nil
+}
@@ -2,7 +2,9 @@ package session
 
 import (
 	"fmt"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -33,6 +35,17 @@ type TodoItem struct {
 	Priority    TodoPriority `json:"priority"`
 	CreatedAt   time.Time    `json:"created_at"`
 	UpdatedAt   time.Time    `json:"updated_at"`
+	DueAt       *time.Time   `json:"due_at,omitempty"`    // 截止时间，nil 表示没有截止时间
+	Tags        []string     `json:"tags,omitempty"`      // 标签，已规范化为小写且不含 #
+	ParentID    string       `json:"parent_id,omitempty"` // 父任务 ID，空表示顶层任务
+	Progress    int          `json:"progress,omitempty"`  // 完成百分比，0-100
+	Notes       string       `json:"notes,omitempty"`     // 备注
+}
+
+// TreeNode 树形列表中的一项，Depth 为嵌套层级（顶层为 0）
+type TreeNode struct {
+	Item  *TodoItem
+	Depth int
 }
 
 // TodoManager 管理 todo 列表
@@ -55,6 +68,12 @@ func (tm *TodoManager) Load() error {
 	if err != nil {
 		return fmt.Errorf("failed to load todos: %v", err)
 	}
+	// 旧版本文件没有 due_at 字段，零值统一视为没有截止时间
+	for _, item := range items {
+		if item.DueAt != nil && item.DueAt.IsZero() {
+			item.DueAt = nil
+		}
+	}
 	tm.items = items
 	return nil
 }
@@ -67,7 +86,15 @@ func (tm *TodoManager) Save() error {
 	return nil
 }
 
-// Add 添加新的 todo 项
+// persist 将单个 todo 项写入存储
+func (tm *TodoManager) persist(item *TodoItem) error {
+	if err := tm.storage.Upsert(item); err != nil {
+		return fmt.Errorf("failed to save todo: %v", err)
+	}
+	return nil
+}
+
+// Add 添加新的 todo 项并写入存储
 func (tm *TodoManager) Add(content string, priority TodoPriority) (*TodoItem, error) {
 	if strings.TrimSpace(content) == "" {
 		return nil, fmt.Errorf("todo content cannot be empty")
@@ -86,21 +113,66 @@ func (tm *TodoManager) Add(content string, priority TodoPriority) (*TodoItem, er
 	}
 
 	tm.items[id] = item
+	if err := tm.persist(item); err != nil {
+		return nil, err
+	}
 	return item, nil
 }
 
-// Update 更新 todo 项
+// Update 更新 todo 项并写入存储
 func (tm *TodoManager) Update(id string, status TodoStatus, content string, priority TodoPriority) (*TodoItem, error) {
 	item, exists := tm.items[id]
 	if !exists {
 		return nil, fmt.Errorf("todo item with id %s not found", id)
 	}
 
+	applyUpdate(item, status, content, priority)
+
+	if err := tm.persist(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// UpdateMany 批量更新多个 todo 项的状态和优先级，只整体保存一次。
+// 返回已更新的项以及不存在的 ID
+func (tm *TodoManager) UpdateMany(ids []string, status TodoStatus, priority TodoPriority) ([]*TodoItem, []string, error) {
+	var updated []*TodoItem
+	var missing []string
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		item, exists := tm.items[id]
+		if !exists {
+			missing = append(missing, id)
+			continue
+		}
+		applyUpdate(item, status, "", priority)
+		updated = append(updated, item)
+	}
+
+	if len(updated) > 0 {
+		if err := tm.Save(); err != nil {
+			return nil, missing, err
+		}
+	}
+	return updated, missing, nil
+}
+
+// applyUpdate 修改 todo 项的非空字段，完成时进度设为 100
+func applyUpdate(item *TodoItem, status TodoStatus, content string, priority TodoPriority) {
 	now := time.Now()
 	
 	if status != "" {
 		item.Status = status
 		item.UpdatedAt = now
+		if status == StatusCompleted {
+			item.Progress = 100
+		}
 	}
 	
 	if strings.TrimSpace(content) != "" {
@@ -112,16 +184,144 @@ func (tm *TodoManager) Update(id string, status TodoStatus, content string, prio
 		item.Priority = priority
 		item.UpdatedAt = now
 	}
+}
+
+// SetDue 设置 todo 项的截止时间并写入存储，due 为 nil 时清除
+func (tm *TodoManager) SetDue(id string, due *time.Time) (*TodoItem, error) {
+	item, exists := tm.items[id]
+	if !exists {
+		return nil, fmt.Errorf("todo item with id %s not found", id)
+	}
+
+	item.DueAt = due
+	item.UpdatedAt = time.Now()
+	if err := tm.persist(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// SetTags 设置 todo 项的标签并写入存储，tags 为空时清除
+func (tm *TodoManager) SetTags(id string, tags []string) (*TodoItem, error) {
+	item, exists := tm.items[id]
+	if !exists {
+		return nil, fmt.Errorf("todo item with id %s not found", id)
+	}
 
+	item.Tags = NormalizeTags(tags)
+	item.UpdatedAt = time.Now()
+	if err := tm.persist(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// SetProgress 设置 todo 项的完成百分比并写入存储，progress 必须在 0-100 之间
+func (tm *TodoManager) SetProgress(id string, progress int) (*TodoItem, error) {
+	item, exists := tm.items[id]
+	if !exists {
+		return nil, fmt.Errorf("todo item with id %s not found", id)
+	}
+	if progress < 0 || progress > 100 {
+		return nil, fmt.Errorf("progress must be between 0 and 100, got %d", progress)
+	}
+
+	item.Progress = progress
+	item.UpdatedAt = time.Now()
+	if err := tm.persist(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// SetNotes 设置 todo 项的备注并写入存储，notes 为空时清除
+func (tm *TodoManager) SetNotes(id string, notes string) (*TodoItem, error) {
+	item, exists := tm.items[id]
+	if !exists {
+		return nil, fmt.Errorf("todo item with id %s not found", id)
+	}
+
+	item.Notes = strings.TrimSpace(notes)
+	item.UpdatedAt = time.Now()
+	if err := tm.persist(item); err != nil {
+		return nil, err
+	}
 	return item, nil
 }
 
-// Delete 删除 todo 项
+// SetParent 设置 todo 项的父任务并写入存储，parentID 为空时变为顶层任务。
+// 父任务必须存在，且不能是该项自身或其子孙
+func (tm *TodoManager) SetParent(id, parentID string) (*TodoItem, error) {
+	item, exists := tm.items[id]
+	if !exists {
+		return nil, fmt.Errorf("todo item with id %s not found", id)
+	}
+
+	if parentID != "" {
+		if _, exists := tm.items[parentID]; !exists {
+			return nil, fmt.Errorf("parent todo item with id %s not found", parentID)
+		}
+		// 沿父链向上查找，遇到自身说明会形成环
+		for ancestor := parentID; ancestor != ""; {
+			if ancestor == id {
+				return nil, fmt.Errorf("todo item %s cannot be its own ancestor", id)
+			}
+			parent, exists := tm.items[ancestor]
+			if !exists {
+				break
+			}
+			ancestor = parent.ParentID
+		}
+	}
+
+	item.ParentID = parentID
+	item.UpdatedAt = time.Now()
+	if err := tm.persist(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// Children 返回 todo 项的直接子任务，顺序与 List 相同
+func (tm *TodoManager) Children(id string) []*TodoItem {
+	children := make([]*TodoItem, 0)
+	for _, item := range tm.List() {
+		if item.ParentID == id {
+			children = append(children, item)
+		}
+	}
+	return children
+}
+
+// IncompleteDescendants 返回 todo 项所有未完成的子孙任务
+func (tm *TodoManager) IncompleteDescendants(id string) []*TodoItem {
+	var result []*TodoItem
+	for _, child := range tm.Children(id) {
+		if child.Status != StatusCompleted {
+			result = append(result, child)
+		}
+		result = append(result, tm.IncompleteDescendants(child.ID)...)
+	}
+	return result
+}
+
+// Delete 删除 todo 项并从存储中移除，其子任务变为顶层任务
 func (tm *TodoManager) Delete(id string) error {
 	if _, exists := tm.items[id]; !exists {
 		return fmt.Errorf("todo item with id %s not found", id)
 	}
 	delete(tm.items, id)
+	if err := tm.storage.Remove(id); err != nil {
+		return fmt.Errorf("failed to remove todo: %v", err)
+	}
+	for _, item := range tm.items {
+		if item.ParentID == id {
+			item.ParentID = ""
+			if err := tm.persist(item); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
@@ -172,6 +372,65 @@ func (tm *TodoManager) List() []*TodoItem {
 	return items
 }
 
+// ListByDue 列出所有 todo 项，有截止时间的按截止时间升序排在前面，其余保持 List 的顺序
+func (tm *TodoManager) ListByDue() []*TodoItem {
+	items := tm.List()
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].DueAt, items[j].DueAt
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return a.Before(*b)
+	})
+	return items
+}
+
+// ListTree 按层级列出所有 todo 项，子任务紧跟在父任务之后
+func (tm *TodoManager) ListTree() []TreeNode {
+	return Tree(tm.List())
+}
+
+// Tree 将 items 按父子关系排列，同级保持 items 中的顺序。
+// 父任务不在 items 中的项作为顶层任务
+func Tree(items []*TodoItem) []TreeNode {
+	present := make(map[string]bool, len(items))
+	for _, item := range items {
+		present[item.ID] = true
+	}
+
+	children := make(map[string][]*TodoItem)
+	var roots []*TodoItem
+	for _, item := range items {
+		if item.ParentID != "" && item.ParentID != item.ID && present[item.ParentID] {
+			children[item.ParentID] = append(children[item.ParentID], item)
+		} else {
+			roots = append(roots, item)
+		}
+	}
+
+	nodes := make([]TreeNode, 0, len(items))
+	visited := make(map[string]bool, len(items))
+	var walk func(item *TodoItem, depth int)
+	walk = func(item *TodoItem, depth int) {
+		if visited[item.ID] {
+			return
+		}
+		visited[item.ID] = true
+		nodes = append(nodes, TreeNode{Item: item, Depth: depth})
+		for _, child := range children[item.ID] {
+			walk(child, depth+1)
+		}
+	}
+	for _, root := range roots {
+		walk(root, 0)
+	}
+	// 文件被手动修改形成环时，环上的项没有根，按顶层任务输出
+	for _, item := range items {
+		walk(item, 0)
+	}
+	return nodes
+}
+
 // ListByStatus 按状态筛选 todo 项
 func (tm *TodoManager) ListByStatus(status TodoStatus) []*TodoItem {
 	items := tm.List()
@@ -186,9 +445,43 @@ func (tm *TodoManager) ListByStatus(status TodoStatus) []*TodoItem {
 	return filtered
 }
 
-// Clear 清空所有 todo 项
-func (tm *TodoManager) Clear() {
+// Search 返回内容或标签包含 query 的 todo 项（不区分大小写），顺序与 List 相同
+func (tm *TodoManager) Search(query string) []*TodoItem {
+	query = strings.ToLower(strings.TrimSpace(query))
+	results := make([]*TodoItem, 0)
+	if query == "" {
+		return results
+	}
+	
+	for _, item := range tm.List() {
+		if strings.Contains(strings.ToLower(item.Content), query) ||
+			strings.Contains(strings.ToLower(strings.Join(item.Tags, " ")), strings.TrimPrefix(query, "#")) {
+			results = append(results, item)
+		}
+	}
+	
+	return results
+}
+
+// ListByTag 筛选同时包含所有指定标签的 todo 项
+func (tm *TodoManager) ListByTag(tags ...string) []*TodoItem {
+	wanted := NormalizeTags(tags)
+	items := tm.List()
+	filtered := make([]*TodoItem, 0)
+	
+	for _, item := range items {
+		if item.HasTags(wanted...) {
+			filtered = append(filtered, item)
+		}
+	}
+	
+	return filtered
+}
+
+// Clear 清空所有 todo 项并保存
+func (tm *TodoManager) Clear() error {
 	tm.items = make(map[string]*TodoItem)
+	return tm.Save()
 }
 
 // Count 统计不同状态的 todo 数量
@@ -220,12 +513,120 @@ func (item *TodoItem) String() string {
 		PriorityLow:    "🟢",
 	}
 	
-	return fmt.Sprintf("%s %s [%s] %s",
+	str := fmt.Sprintf("%s %s [%s] %s",
 		statusSymbol[item.Status],
 		prioritySymbol[item.Priority],
 		item.ID,
 		item.Content,
 	)
+	if progress := item.ProgressLabel(); progress != "" {
+		str += " " + progress
+	}
+	if due := item.DueLabel(); due != "" {
+		str += " " + due
+	}
+	if tags := item.TagsLabel(); tags != "" {
+		str += " " + tags
+	}
+	return str
+}
+
+// progressBarWidth 进度条的格数
+const progressBarWidth = 10
+
+// ProgressLabel 返回进度条文本，如 "[██████░░░░] 60%"，没有进度时返回空字符串
+func (item *TodoItem) ProgressLabel() string {
+	if item.Progress <= 0 {
+		return ""
+	}
+	filled := item.Progress * progressBarWidth / 100
+	if filled > progressBarWidth {
+		filled = progressBarWidth
+	}
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled)
+	return fmt.Sprintf("[%s] %d%%", bar, item.Progress)
+}
+
+// TagsLabel 返回标签的显示文本，如 "#backend #urgent"，没有标签时返回空字符串
+func (item *TodoItem) TagsLabel() string {
+	labels := make([]string, len(item.Tags))
+	for i, tag := range item.Tags {
+		labels[i] = "#" + tag
+	}
+	return strings.Join(labels, " ")
+}
+
+// HasTags 判断 todo 项是否包含所有指定的标签（标签需已规范化）
+func (item *TodoItem) HasTags(tags ...string) bool {
+	for _, tag := range tags {
+		found := false
+		for _, own := range item.Tags {
+			if own == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeTags 去除标签的首尾空白和前缀 #，转为小写并去重，忽略空标签
+func NormalizeTags(tags []string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+// DueLabel 返回截止时间的显示文本，没有截止时间时返回空字符串
+func (item *TodoItem) DueLabel() string {
+	if item.DueAt == nil {
+		return ""
+	}
+	return "⏰ due " + item.DueAt.Local().Format("2006-01-02 15:04")
+}
+
+// relativeDuePattern 相对截止时间，如 +30m、+2h、+3d、+1w
+var relativeDuePattern = regexp.MustCompile(`^\+(\d+)([mhdw])$`)
+
+// ParseDue 解析截止时间，支持 RFC3339、2006-01-02 日期（当天结束）以及相对于 now 的 +Nm/+Nh/+Nd/+Nw
+func ParseDue(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if m := relativeDuePattern.FindStringSubmatch(value); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid due date %q: %v", value, err)
+		}
+		switch m[2] {
+		case "m":
+			return now.Add(time.Duration(n) * time.Minute), nil
+		case "h":
+			return now.Add(time.Duration(n) * time.Hour), nil
+		case "d":
+			return now.AddDate(0, 0, n), nil
+		default:
+			return now.AddDate(0, 0, 7*n), nil
+		}
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid due date %q: expected RFC3339, YYYY-MM-DD or relative like +2d", value)
 }
 
 // generateID 生成唯一 ID
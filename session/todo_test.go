@@ -1,7 +1,9 @@
 package session
 
 import (
+	"encoding/json"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -108,6 +110,115 @@ func TestTodoManager_Update(t *testing.T) {
 	}
 }
 
+func TestTodoManager_UpdateMany(t *testing.T) {
+	storage := NewMemoryStorage()
+	manager := NewTodoManager(storage)
+	item1, _ := manager.Add("Todo 1", PriorityLow)
+	item2, _ := manager.Add("Todo 2", PriorityLow)
+	item3, _ := manager.Add("Todo 3", PriorityLow)
+
+	updated, missing, err := manager.UpdateMany([]string{item1.ID, "nonexistent", item2.ID, item1.ID}, StatusCompleted, PriorityHigh)
+	if err != nil {
+		t.Fatalf("UpdateMany() failed: %v", err)
+	}
+	if len(updated) != 2 {
+		t.Errorf("UpdateMany() updated %d items, want 2", len(updated))
+	}
+	if len(missing) != 1 || missing[0] != "nonexistent" {
+		t.Errorf("UpdateMany() missing = %v, want [nonexistent]", missing)
+	}
+	for _, item := range []*TodoItem{item1, item2} {
+		if item.Status != StatusCompleted || item.Priority != PriorityHigh || item.Progress != 100 {
+			t.Errorf("item %s = %s/%s/%d, want completed/high/100", item.ID, item.Status, item.Priority, item.Progress)
+		}
+	}
+	if item3.Status != StatusPending {
+		t.Error("UpdateMany() should not change unlisted items")
+	}
+
+	// 修改已保存
+	reloaded := NewTodoManager(storage)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if got, _ := reloaded.Get(item2.ID); got.Status != StatusCompleted {
+		t.Errorf("reloaded status = %s, want completed", got.Status)
+	}
+}
+
+func TestTodoManager_SetProgress(t *testing.T) {
+	manager := NewTodoManager(NewMemoryStorage())
+	item, _ := manager.Add("Test todo", PriorityMedium)
+
+	tests := []struct {
+		progress int
+		wantErr  bool
+	}{
+		{0, false},
+		{60, false},
+		{100, false},
+		{-1, true},
+		{101, true},
+	}
+	for _, tt := range tests {
+		_, err := manager.SetProgress(item.ID, tt.progress)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SetProgress(%d) error = %v, wantErr %v", tt.progress, err, tt.wantErr)
+		}
+		if err == nil && item.Progress != tt.progress {
+			t.Errorf("SetProgress(%d) progress = %d", tt.progress, item.Progress)
+		}
+	}
+	// 超出范围时保持原值
+	if item.Progress != 100 {
+		t.Errorf("invalid SetProgress() changed progress to %d", item.Progress)
+	}
+
+	if _, err := manager.SetProgress("nonexistent", 10); err == nil {
+		t.Error("SetProgress() should fail with nonexistent ID")
+	}
+}
+
+func TestTodoManager_CompleteSetsProgress(t *testing.T) {
+	manager := NewTodoManager(NewMemoryStorage())
+	item, _ := manager.Add("Test todo", PriorityMedium)
+	manager.SetProgress(item.ID, 40)
+
+	// 其他状态不改变进度
+	manager.Update(item.ID, StatusInProgress, "", "")
+	if item.Progress != 40 {
+		t.Errorf("in_progress progress = %d, want 40", item.Progress)
+	}
+
+	manager.Update(item.ID, StatusCompleted, "", "")
+	if item.Progress != 100 {
+		t.Errorf("completed progress = %d, want 100", item.Progress)
+	}
+}
+
+func TestTodoItem_ProgressLabel(t *testing.T) {
+	tests := []struct {
+		progress int
+		want     string
+	}{
+		{0, ""},
+		{60, "[██████░░░░] 60%"},
+		{5, "[░░░░░░░░░░] 5%"},
+		{100, "[██████████] 100%"},
+	}
+	for _, tt := range tests {
+		item := &TodoItem{Progress: tt.progress}
+		if got := item.ProgressLabel(); got != tt.want {
+			t.Errorf("ProgressLabel(%d) = %q, want %q", tt.progress, got, tt.want)
+		}
+	}
+
+	item := &TodoItem{ID: "1", Content: "Test", Status: StatusInProgress, Priority: PriorityLow, Progress: 60}
+	if !contains(item.String(), "[██████░░░░] 60%") {
+		t.Errorf("String() should contain progress bar, got %q", item.String())
+	}
+}
+
 func TestTodoManager_Delete(t *testing.T) {
 	storage := NewMemoryStorage()
 	manager := NewTodoManager(storage)
@@ -283,7 +394,9 @@ func TestTodoManager_Clear(t *testing.T) {
 	manager.Add("Todo 2", PriorityMedium)
 
 	// 清空
-	manager.Clear()
+	if err := manager.Clear(); err != nil {
+		t.Fatalf("Clear() failed: %v", err)
+	}
 
 	// 验证已清空
 	items := manager.List()
@@ -344,6 +457,93 @@ func TestTodoManager_SaveLoad(t *testing.T) {
 	}
 }
 
+func TestTodoManager_IncrementalPersistence(t *testing.T) {
+	storages := map[string]func(t *testing.T) Storage{
+		"memory": func(t *testing.T) Storage {
+			return NewMemoryStorage()
+		},
+		"file": func(t *testing.T) Storage {
+			return NewFileStorage(filepath.Join(t.TempDir(), "todos.json"))
+		},
+		"sqlite": func(t *testing.T) Storage {
+			return newTestSQLiteStorage(t, filepath.Join(t.TempDir(), "todos.db"))
+		},
+	}
+
+	for name, newStorage := range storages {
+		t.Run(name, func(t *testing.T) {
+			storage := newStorage(t)
+			manager := NewTodoManager(storage)
+
+			// 每次修改都应立即写入存储，不调用 Save
+			parent, _ := manager.Add("Parent", PriorityHigh)
+			child, _ := manager.Add("Child", PriorityMedium)
+			other, _ := manager.Add("Other", PriorityLow)
+			if _, err := manager.SetParent(child.ID, parent.ID); err != nil {
+				t.Fatalf("SetParent() failed: %v", err)
+			}
+			due := time.Date(2024, 3, 10, 18, 0, 0, 0, time.UTC)
+			if _, err := manager.SetDue(other.ID, &due); err != nil {
+				t.Fatalf("SetDue() failed: %v", err)
+			}
+			if _, err := manager.SetTags(other.ID, []string{"Work", "#urgent"}); err != nil {
+				t.Fatalf("SetTags() failed: %v", err)
+			}
+			if _, err := manager.Update(child.ID, StatusInProgress, "Child task", ""); err != nil {
+				t.Fatalf("Update() failed: %v", err)
+			}
+			if err := manager.Delete(parent.ID); err != nil {
+				t.Fatalf("Delete() failed: %v", err)
+			}
+
+			// 重新加载后应与内存中的状态一致
+			reloaded := NewTodoManager(storage)
+			if err := reloaded.Load(); err != nil {
+				t.Fatalf("Load() failed: %v", err)
+			}
+			assertSameTodos(t, manager.List(), reloaded.List())
+
+			// Clear 整体保存
+			if err := manager.Clear(); err != nil {
+				t.Fatalf("Clear() failed: %v", err)
+			}
+			reloaded = NewTodoManager(storage)
+			if err := reloaded.Load(); err != nil {
+				t.Fatalf("Load() failed: %v", err)
+			}
+			if len(reloaded.List()) != 0 {
+				t.Errorf("After Clear(), reload returned %d items, want 0", len(reloaded.List()))
+			}
+		})
+	}
+}
+
+// assertSameTodos 比较两组 todo 序列化后的内容
+func assertSameTodos(t *testing.T, want, got []*TodoItem) {
+	t.Helper()
+	encode := func(items []*TodoItem) map[string]string {
+		result := make(map[string]string, len(items))
+		for _, item := range items {
+			data, err := json.Marshal(item)
+			if err != nil {
+				t.Fatalf("Marshal() failed: %v", err)
+			}
+			result[item.ID] = string(data)
+		}
+		return result
+	}
+
+	wantData, gotData := encode(want), encode(got)
+	if len(gotData) != len(wantData) {
+		t.Fatalf("reload returned %d items, want %d", len(gotData), len(wantData))
+	}
+	for id, data := range wantData {
+		if gotData[id] != data {
+			t.Errorf("item %s after reload = %s, want %s", id, gotData[id], data)
+		}
+	}
+}
+
 func TestFileStorage(t *testing.T) {
 	// 创建临时文件
 	tmpFile, err := os.CreateTemp("", "todo_test_*.json")
@@ -414,6 +614,253 @@ func TestFileStorage_NonexistentFile(t *testing.T) {
 	}
 }
 
+func TestParseDue(t *testing.T) {
+	now := time.Date(2024, 3, 10, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"+30m", now.Add(30 * time.Minute), false},
+		{"+2h", now.Add(2 * time.Hour), false},
+		{"+2d", time.Date(2024, 3, 12, 9, 30, 0, 0, time.UTC), false},
+		{" +1w ", time.Date(2024, 3, 17, 9, 30, 0, 0, time.UTC), false},
+		{"2024-04-01T12:00:00Z", time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC), false},
+		{"2024-04-01", time.Date(2024, 4, 1, 23, 59, 59, 0, time.UTC), false},
+		{"+2y", time.Time{}, true},
+		{"-1d", time.Time{}, true},
+		{"tomorrow", time.Time{}, true},
+		{"", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDue(tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDue(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("ParseDue(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTodoManager_ListByDue(t *testing.T) {
+	manager := NewTodoManager(NewMemoryStorage())
+	now := time.Now()
+	later := now.Add(48 * time.Hour)
+	sooner := now.Add(time.Hour)
+
+	noDue, _ := manager.Add("No due", PriorityHigh)
+	laterItem, _ := manager.Add("Later", PriorityHigh)
+	soonerItem, _ := manager.Add("Sooner", PriorityLow)
+	manager.SetDue(laterItem.ID, &later)
+	manager.SetDue(soonerItem.ID, &sooner)
+
+	items := manager.ListByDue()
+	want := []string{soonerItem.ID, laterItem.ID, noDue.ID}
+	for i, id := range want {
+		if items[i].ID != id {
+			t.Errorf("ListByDue()[%d] = %s, want %s", i, items[i].Content, manager.items[id].Content)
+		}
+	}
+
+	if !contains(soonerItem.String(), "⏰ due") {
+		t.Error("String() should contain due date")
+	}
+	if contains(noDue.String(), "⏰") {
+		t.Error("String() should not contain due date when unset")
+	}
+
+	if _, err := manager.SetDue("non-existent", &later); err == nil {
+		t.Error("SetDue() should fail for non-existent id")
+	}
+}
+
+func TestFileStorage_LegacyWithoutDue(t *testing.T) {
+	path := t.TempDir() + "/todos.json"
+	legacy := `{"1": {"id": "1", "content": "Old todo", "status": "pending", "priority": "high",
+		"created_at": "2024-01-01T00:00:00Z", "updated_at": "2024-01-01T00:00:00Z"},
+		"2": {"id": "2", "content": "Zero due", "status": "pending", "priority": "low",
+		"created_at": "2024-01-01T00:00:00Z", "updated_at": "2024-01-01T00:00:00Z", "due_at": "0001-01-01T00:00:00Z"}}`
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := NewTodoManager(NewFileStorage(path))
+	if err := manager.Load(); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	for _, item := range manager.List() {
+		if item.DueAt != nil {
+			t.Errorf("%s DueAt = %v, want nil", item.Content, item.DueAt)
+		}
+	}
+}
+
+func TestTodoManager_ListByTag(t *testing.T) {
+	manager := NewTodoManager(NewMemoryStorage())
+
+	api, _ := manager.Add("API", PriorityHigh)
+	db, _ := manager.Add("Database", PriorityMedium)
+	docs, _ := manager.Add("Docs", PriorityLow)
+	manager.SetTags(api.ID, []string{"backend", " #Urgent", "backend"})
+	manager.SetTags(db.ID, []string{"backend"})
+	manager.SetTags(docs.ID, []string{"", "urgent"})
+
+	if got := api.Tags; len(got) != 2 || got[0] != "backend" || got[1] != "urgent" {
+		t.Errorf("SetTags() tags = %v, want [backend urgent]", got)
+	}
+	if !contains(api.String(), "#backend #urgent") {
+		t.Errorf("String() = %q, should contain tags", api.String())
+	}
+
+	tests := []struct {
+		tags []string
+		want int
+	}{
+		{[]string{"backend"}, 2},
+		{[]string{"urgent"}, 2},
+		{[]string{"backend", "urgent"}, 1},
+		{[]string{"#BACKEND"}, 2},
+		{[]string{"frontend"}, 0},
+		{nil, 3},
+	}
+	for _, tt := range tests {
+		if got := manager.ListByTag(tt.tags...); len(got) != tt.want {
+			t.Errorf("ListByTag(%v) returned %d items, want %d", tt.tags, len(got), tt.want)
+		}
+	}
+
+	// 清除标签
+	manager.SetTags(api.ID, nil)
+	if len(api.Tags) != 0 || contains(api.String(), "#") {
+		t.Errorf("SetTags(nil) should clear tags, got %v", api.Tags)
+	}
+}
+
+func TestTodoManager_SetParent(t *testing.T) {
+	manager := NewTodoManager(NewMemoryStorage())
+	root, _ := manager.Add("Root", PriorityHigh)
+	child, _ := manager.Add("Child", PriorityHigh)
+	grandchild, _ := manager.Add("Grandchild", PriorityHigh)
+
+	if _, err := manager.SetParent(child.ID, root.ID); err != nil {
+		t.Fatalf("SetParent() failed: %v", err)
+	}
+	if _, err := manager.SetParent(grandchild.ID, child.ID); err != nil {
+		t.Fatalf("SetParent() failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		id       string
+		parentID string
+	}{
+		{"自身作为父任务", root.ID, root.ID},
+		{"子任务作为父任务", root.ID, child.ID},
+		{"孙任务作为父任务", root.ID, grandchild.ID},
+		{"父任务不存在", root.ID, "non-existent"},
+		{"任务不存在", "non-existent", root.ID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := manager.SetParent(tt.id, tt.parentID); err == nil {
+				t.Error("SetParent() should fail")
+			}
+		})
+	}
+	if root.ParentID != "" {
+		t.Errorf("失败的 SetParent() 不应修改 ParentID, got %q", root.ParentID)
+	}
+
+	if children := manager.Children(root.ID); len(children) != 1 || children[0].ID != child.ID {
+		t.Errorf("Children() = %v, want [Child]", children)
+	}
+	if incomplete := manager.IncompleteDescendants(root.ID); len(incomplete) != 2 {
+		t.Errorf("IncompleteDescendants() returned %d items, want 2", len(incomplete))
+	}
+
+	// 删除父任务后子任务变为顶层任务
+	manager.Delete(child.ID)
+	if grandchild.ParentID != "" {
+		t.Errorf("删除父任务后 ParentID = %q, want empty", grandchild.ParentID)
+	}
+}
+
+func TestTodoManager_ListTree(t *testing.T) {
+	manager := NewTodoManager(NewMemoryStorage())
+	a, _ := manager.Add("A", PriorityHigh)
+	b, _ := manager.Add("B", PriorityMedium)
+	a1, _ := manager.Add("A1", PriorityLow)
+	a1x, _ := manager.Add("A1x", PriorityLow)
+	b1, _ := manager.Add("B1", PriorityHigh)
+	manager.SetParent(a1.ID, a.ID)
+	manager.SetParent(a1x.ID, a1.ID)
+	manager.SetParent(b1.ID, b.ID)
+
+	nodes := manager.ListTree()
+	want := []struct {
+		content string
+		depth   int
+	}{
+		{"A", 0}, {"A1", 1}, {"A1x", 2}, {"B", 0}, {"B1", 1},
+	}
+	if len(nodes) != len(want) {
+		t.Fatalf("ListTree() returned %d nodes, want %d", len(nodes), len(want))
+	}
+	for i, w := range want {
+		if nodes[i].Item.Content != w.content || nodes[i].Depth != w.depth {
+			t.Errorf("ListTree()[%d] = %s@%d, want %s@%d", i, nodes[i].Item.Content, nodes[i].Depth, w.content, w.depth)
+		}
+	}
+
+	// 父任务不在列表中时作为顶层任务
+	nodes = Tree([]*TodoItem{a1x, b})
+	if len(nodes) != 2 || nodes[0].Depth != 0 || nodes[1].Depth != 0 {
+		t.Errorf("Tree() 缺少父任务时应输出顶层任务, got %v", nodes)
+	}
+
+	// 手动修改形成的环不会导致死循环或遗漏
+	a.ParentID = a1x.ID
+	if nodes = manager.ListTree(); len(nodes) != 5 {
+		t.Errorf("有环时 ListTree() returned %d nodes, want 5", len(nodes))
+	}
+}
+
+func TestTodoManager_Search(t *testing.T) {
+	manager := NewTodoManager(NewMemoryStorage())
+	bug, _ := manager.Add("Fix Login bug", PriorityLow)
+	page, _ := manager.Add("Login page", PriorityHigh)
+	docs, _ := manager.Add("Write docs", PriorityMedium)
+	manager.SetTags(docs.ID, []string{"documentation"})
+
+	tests := []struct {
+		query string
+		want  []*TodoItem
+	}{
+		{"login", []*TodoItem{page, bug}},
+		{"DOCUMENT", []*TodoItem{docs}},
+		{"#documentation", []*TodoItem{docs}},
+		{"deploy", nil},
+		{"  ", nil},
+	}
+	for _, tt := range tests {
+		got := manager.Search(tt.query)
+		if len(got) != len(tt.want) {
+			t.Errorf("Search(%q) returned %d items, want %d", tt.query, len(got), len(tt.want))
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Search(%q)[%d] = %s, want %s", tt.query, i, got[i].Content, tt.want[i].Content)
+			}
+		}
+	}
+}
+
 // 辅助函数
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && s[len(s)-len(substr):] == substr || 
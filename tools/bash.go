@@ -1,69 +1,83 @@
 package tools
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"strings"
 
 	"opencode_nano/permission"
+	"opencode_nano/tools/core"
 )
 
+// BashTool 旧版 bash 工具，权限检查由 CoreToolAdapter 完成
+//
+// Deprecated: 使用 CreateToolSet 或 system.NewBashTool，保留以兼容旧接口
 type BashTool struct {
-	perm permission.Manager
+	*CoreToolAdapter
+	perm permission.Manager // 保留以兼容直接构造 BashTool 的旧代码
 }
 
 func NewBashTool(perm permission.Manager) *BashTool {
-	return &BashTool{perm: perm}
+	return &BashTool{CoreToolAdapter: NewCoreToolAdapter(newBashCommandTool(), perm), perm: perm}
 }
 
-func (t *BashTool) Name() string {
-	return "bash"
+func (t *BashTool) isDangerous(command string) bool {
+	return isDangerousCommand(command)
 }
 
-func (t *BashTool) Description() string {
-	return "Execute bash commands. Use with caution."
+// bashCommandTool 旧版 bash 的 core.Tool 实现
+type bashCommandTool struct {
+	*core.BaseTool
 }
 
-func (t *BashTool) Parameters() map[string]any {
-	return map[string]any{
-		"type": "object",
-		"properties": map[string]any{
-			"command": map[string]any{
-				"type":        "string",
-				"description": "The bash command to execute",
+func newBashCommandTool() *bashCommandTool {
+	tool := &bashCommandTool{
+		BaseTool: core.NewBaseTool("bash", "system", "Execute bash commands. Use with caution."),
+	}
+	tool.SetRequiresPerm(true)
+	tool.SetSchema(core.ParameterSchema{
+		Type: "object",
+		Properties: map[string]core.PropertySchema{
+			"command": {
+				Type:        "string",
+				Description: "The bash command to execute",
 			},
 		},
-		"required": []string{"command"},
-	}
+		Required: []string{"command"},
+	})
+	return tool
+}
+
+// NeedsPermission 参数无效或命令危险时直接由 Execute 拒绝，不请求权限
+func (t *bashCommandTool) NeedsPermission(params core.Parameters) bool {
+	command, ok := stringParam(params, "command")
+	return ok && !isDangerousCommand(command)
 }
 
-func (t *BashTool) Execute(params map[string]any) (string, error) {
-	command, ok := params["command"].(string)
+func (t *bashCommandTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	command, ok := stringParam(params, "command")
 	if !ok {
-		return "", fmt.Errorf("command parameter is required and must be a string")
+		return nil, core.ErrInvalidParams(t.Info().Name, "command parameter is required and must be a string")
 	}
 
 	// 简单的安全检查
-	if t.isDangerous(command) {
-		return "", fmt.Errorf("command contains dangerous operations: %s", command)
-	}
-
-	// 请求权限
-	if !t.perm.Request("bash", fmt.Sprintf("Execute command: %s", command)) {
-		return "", fmt.Errorf("permission denied for command: %s", command)
+	if isDangerousCommand(command) {
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("command contains dangerous operations: %s", command))
 	}
 
 	// 执行命令
-	cmd := exec.Command("bash", "-c", command)
+	cmd := exec.CommandContext(ctx, "bash", "-c", command)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return "", fmt.Errorf("command failed: %v\nOutput: %s", err, string(output))
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("command failed: %v\nOutput: %s", err, string(output)))
 	}
 
-	return fmt.Sprintf("Command executed successfully:\n%s", string(output)), nil
+	return core.NewSimpleResult(fmt.Sprintf("Command executed successfully:\n%s", string(output))), nil
 }
 
-func (t *BashTool) isDangerous(command string) bool {
+// isDangerousCommand 检查命令是否包含危险操作
+func isDangerousCommand(command string) bool {
 	dangerous := []string{
 		"rm -rf",
 		"sudo",
@@ -89,4 +103,4 @@ func (t *BashTool) isDangerous(command string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
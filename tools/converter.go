@@ -2,53 +2,120 @@ package tools
 
 import (
 	"context"
+	"os"
+	"sort"
+	"strings"
+
+	"opencode_nano/config"
 	"opencode_nano/permission"
 	"opencode_nano/tools/core"
-	"opencode_nano/tools/file"
 	"opencode_nano/tools/system"
-	"opencode_nano/tools/task"
 )
 
-// CreateToolSet creates tools compatible with the old interface directly
-func CreateToolSet(perm permission.Manager) ([]Tool, error) {
-	// Create tools list
-	var tools []Tool
-	
-	// Add file read tool (no permission needed)
-	readTool := file.NewReadTool()
-	tools = append(tools, &CoreToolAdapter{tool: readTool})
-	
-	// Add file write tool (needs permission) 
-	writeTool := file.NewWriteTool()
-	tools = append(tools, &CoreToolAdapter{
-		tool: writeTool,
-		needsPerm: true,
-		perm: perm,
-	})
-	
-	// Add bash tool (needs permission)
-	bashTool := system.NewBashTool()
-	tools = append(tools, &CoreToolAdapter{
-		tool: bashTool,
-		needsPerm: true,
-		perm: perm,
-	})
-	
-	// Add task/todo tool (no permission needed)
-	taskTool, err := task.NewTaskTool()
+// legacyToolNames lists the tools exposed by CreateToolSet, in order.
+var legacyToolNames = []string{"read", "write", "bash", "todo"}
+
+// CreateToolSet builds the minimal tool set (read, write, bash, todo).
+// cfg may be nil, in which case the built-in defaults are used.
+func CreateToolSet(perm permission.Manager, cfg *config.Config) ([]Tool, error) {
+	registry, err := InitializeRegistry(disabledTools(cfg)...)
 	if err != nil {
 		return nil, err
 	}
-	tools = append(tools, &CoreToolAdapter{tool: taskTool})
 	
+	tools := make([]Tool, 0, len(legacyToolNames))
+	for _, name := range legacyToolNames {
+		tool, err := registry.Get(name)
+		if core.GetErrorCode(err) == core.ErrCodeToolDisabled {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, AdaptTool(tool, perm, cfg))
+	}
 	return tools, nil
 }
 
-// CoreToolAdapter adapts core.Tool to the old Tool interface
+// CreateFullToolSet builds the agent's tool set from every tool in the registry,
+// plus plugin tools when cfg enables them.
+func CreateFullToolSet(perm permission.Manager, cfg *config.Config) ([]Tool, error) {
+	registry, err := InitializeRegistry(disabledTools(cfg)...)
+	if err != nil {
+		return nil, err
+	}
+	if cfg != nil && cfg.EnablePlugins && cfg.PluginDir != "" {
+		// Plugin failures are warnings on stderr; they never abort startup.
+		// Loaded plugins are only reported in debug mode.
+		var logger core.Logger = core.NewWriterLogger(os.Stderr)
+		if !cfg.Debug {
+			logger = warnOnlyLogger{logger}
+		}
+		LoadPlugins(registry, cfg.PluginDir, logger)
+	}
+	return AdaptAllTools(registry, perm, cfg), nil
+}
+
+// disabledTools returns the tool names disabled by cfg, or nil when cfg is nil.
+func disabledTools(cfg *config.Config) []string {
+	if cfg == nil {
+		return nil
+	}
+	return cfg.DisabledTools
+}
+
+// AdaptAllTools wraps all registry tools for the old interface, sorted by name.
+func AdaptAllTools(registry *core.ToolRegistry, perm permission.Manager, cfg *config.Config) []Tool {
+	all := registry.All()
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Info().Name < all[j].Info().Name
+	})
+	
+	tools := make([]Tool, 0, len(all))
+	for _, tool := range all {
+		tools = append(tools, AdaptTool(tool, perm, cfg))
+	}
+	return tools
+}
+
+// AdaptTool applies cfg to tool and wraps it for the old interface.
+// This is the single path through which core tools reach the agent.
+func AdaptTool(tool core.Tool, perm permission.Manager, cfg *config.Config) Tool {
+	applyCommandPolicy(tool, cfg)
+	// In dry-run mode, calls with side effects only report what they would do.
+	if cfg != nil && cfg.DryRun {
+		tool = core.WithDryRun(tool)
+	}
+	return NewCoreToolAdapter(tool, perm)
+}
+
+// applyCommandPolicy applies the configured bash policy to shell tools.
+func applyCommandPolicy(tool core.Tool, cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	policy := system.CommandPolicy{
+		DenyPatterns:  cfg.BashDenyPatterns,
+		AllowPrefixes: cfg.BashAllowPrefixes,
+	}
+	switch t := core.UnwrapTool(tool).(type) {
+	case *system.BashTool:
+		t.SetCommandPolicy(policy)
+	case *system.PipelineTool:
+		t.SetCommandPolicy(policy)
+	}
+}
+
+// CoreToolAdapter adapts core.Tool to the old Tool interface.
+// Tools whose Info().RequiresPerm is true ask perm before executing.
 type CoreToolAdapter struct {
-	tool      core.Tool
-	needsPerm bool
-	perm      permission.Manager
+	tool core.Tool
+	perm permission.Manager
+}
+
+// NewCoreToolAdapter wraps tool, guarding it with perm when it requires permission.
+func NewCoreToolAdapter(tool core.Tool, perm permission.Manager) *CoreToolAdapter {
+	return &CoreToolAdapter{tool: tool, perm: perm}
 }
 
 func (a *CoreToolAdapter) Name() string {
@@ -60,44 +127,13 @@ func (a *CoreToolAdapter) Description() string {
 }
 
 func (a *CoreToolAdapter) Parameters() map[string]interface{} {
-	schema := a.tool.Schema()
-	params := make(map[string]interface{})
-	
-	params["type"] = "object"
-	params["required"] = schema.Required
-	
-	properties := make(map[string]interface{})
-	for key, prop := range schema.Properties {
-		propMap := map[string]interface{}{
-			"type":        prop.Type,
-			"description": prop.Description,
-		}
-		if len(prop.Enum) > 0 {
-			propMap["enum"] = prop.Enum
-		}
-		if prop.Default != nil {
-			propMap["default"] = prop.Default
-		}
-		properties[key] = propMap
-	}
-	params["properties"] = properties
-	
-	return params
+	return a.tool.Schema().JSONSchema()
 }
 
 func (a *CoreToolAdapter) Execute(params map[string]interface{}) (string, error) {
 	// Check permission if needed
-	if a.needsPerm {
-		description := a.tool.Info().Description
-		if cmd, ok := params["command"].(string); ok {
-			description = "Execute command: " + cmd
-		} else if path, ok := params["path"].(string); ok {
-			description = "Write to file: " + path
-		} else if filePath, ok := params["file_path"].(string); ok {
-			description = "Write to file: " + filePath
-		}
-		
-		if !a.perm.Request(a.tool.Info().Name, description) {
+	if a.needsPermission(params) {
+		if a.perm == nil || !a.perm.Request(a.tool.Info().Name, permissionDescription(a.tool, params)) {
 			return "", core.ErrPermissionDenied(a.tool.Info().Name, "permission denied by user")
 		}
 	}
@@ -110,4 +146,85 @@ func (a *CoreToolAdapter) Execute(params map[string]interface{}) (string, error)
 	}
 	
 	return result.String(), nil
-}
\ No newline at end of file
+}
+
+// needsPermission reports whether this call must be approved by the user.
+func (a *CoreToolAdapter) needsPermission(params map[string]interface{}) bool {
+	return a.tool.Info().RequiresPerm && core.NeedsPermission(a.tool, core.NewMapParameters(params))
+}
+
+// permissionDescription describes the call shown in the permission prompt.
+// Commands and URLs are shown as is; other calls are labelled with the tool
+// name and action followed by their target, e.g. "Delete: a.txt" or
+// "Move: a.txt → b.txt".
+func permissionDescription(tool core.Tool, params map[string]interface{}) string {
+	if cmd, ok := params["command"].(string); ok {
+		return "Execute command: " + cmd
+	}
+	if cmds := stringList(params["commands"]); len(cmds) > 0 {
+		return "Execute pipeline: " + strings.Join(cmds, " | ")
+	}
+	if url, ok := params["url"].(string); ok {
+		return "Fetch URL: " + url
+	}
+
+	label := permissionLabel(tool, params)
+	if src, ok := params["src"].(string); ok {
+		if dst, ok := params["dst"].(string); ok {
+			return label + ": " + src + " → " + dst
+		}
+		return label + ": " + src
+	}
+	for _, key := range []string{"archive", "path", "file_path", "name"} {
+		if target, ok := params[key].(string); ok {
+			return label + ": " + target
+		}
+	}
+	if edits, ok := params["edits"].([]interface{}); ok {
+		var paths []string
+		for _, edit := range edits {
+			if m, ok := edit.(map[string]interface{}); ok {
+				if path, ok := m["path"].(string); ok {
+					paths = append(paths, path)
+				}
+			}
+		}
+		if len(paths) > 0 {
+			return label + ": " + strings.Join(paths, ", ")
+		}
+	}
+	if _, ok := params["action"].(string); ok {
+		return label
+	}
+	return tool.Info().Description
+}
+
+// permissionLabel turns the tool name and action into a prompt label,
+// e.g. "Delete", "Multi edit" or "Tar extract".
+func permissionLabel(tool core.Tool, params map[string]interface{}) string {
+	label := strings.ReplaceAll(tool.Info().Name, "_", " ")
+	if action, ok := params["action"].(string); ok && action != "" {
+		label += " " + action
+	}
+	if label == "" {
+		return label
+	}
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+// stringList converts a []string or []interface{} of strings parameter.
+func stringList(v interface{}) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,425 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"opencode_nano/config"
+	"opencode_nano/permission"
+	"opencode_nano/tools/core"
+	"opencode_nano/tools/file"
+	"opencode_nano/tools/system"
+)
+
+func TestCoreToolAdapter_DryRunSkipsPermission(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(path, []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		dryRun       any
+		wantRequests int
+		wantErr      bool
+	}{
+		{"dry run 不请求权限", true, 0, false},
+		{"实际编辑需要权限", false, 1, true},
+		{"默认需要权限", nil, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			perm := &MockPermissionManager{shouldAllow: false}
+			adapter := NewCoreToolAdapter(file.NewEditTool(), perm)
+
+			params := map[string]interface{}{
+				"path":       path,
+				"operations": []interface{}{map[string]interface{}{"type": "replace", "find": "hello", "replace": "bye"}},
+			}
+			if tt.dryRun != nil {
+				params["dry_run"] = tt.dryRun
+			}
+
+			_, err := adapter.Execute(params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(perm.requests) != tt.wantRequests {
+				t.Errorf("权限请求次数 = %d, want %d", len(perm.requests), tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestCoreToolAdapter_ReplacePreviewSkipsPermission(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(path, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		dryRun       any
+		wantRequests int
+		wantErr      bool
+	}{
+		{"默认只预览不请求权限", nil, 0, false},
+		{"dry run 不请求权限", true, 0, false},
+		{"实际替换需要权限", false, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			perm := &MockPermissionManager{shouldAllow: false}
+			params := map[string]interface{}{"pattern": "old", "replacement": "new", "path": dir}
+			if tt.dryRun != nil {
+				params["dry_run"] = tt.dryRun
+			}
+
+			_, err := NewCoreToolAdapter(file.NewReplaceTool(), perm).Execute(params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(perm.requests) != tt.wantRequests {
+				t.Errorf("权限请求次数 = %d, want %d", len(perm.requests), tt.wantRequests)
+			}
+			if data, _ := os.ReadFile(path); string(data) != "old\n" {
+				t.Errorf("文件被修改: %q", data)
+			}
+		})
+	}
+}
+
+func TestCreateFullToolSet_DryRun(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.txt")
+	if err := os.WriteFile(existing, []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	perm := &MockPermissionManager{shouldAllow: true}
+	toolSet, err := CreateFullToolSet(perm, &config.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("CreateFullToolSet() error = %v", err)
+	}
+	tools := make(map[string]Tool)
+	for _, tool := range toolSet {
+		tools[tool.Name()] = tool
+	}
+
+	tests := []struct {
+		name       string
+		tool       string
+		params     map[string]interface{}
+		wantDryRun bool
+		wantOutput string
+	}{
+		{"写文件只报告", "write", map[string]interface{}{"path": filepath.Join(dir, "new.txt"), "content": "hello"}, true, ""},
+		{"删除只报告", "delete", map[string]interface{}{"path": existing}, true, ""},
+		{"命令只报告", "bash", map[string]interface{}{"command": "touch " + filepath.Join(dir, "touched.txt")}, true, ""},
+		{"读文件照常执行", "read", map[string]interface{}{"path": existing}, false, "hello"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool, ok := tools[tt.tool]
+			if !ok {
+				t.Fatalf("工具集中没有 %s", tt.tool)
+			}
+			out, err := tool.Execute(tt.params)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if strings.Contains(out, "[dry-run]") != tt.wantDryRun {
+				t.Errorf("输出 = %q, wantDryRun %v", out, tt.wantDryRun)
+			}
+			if !strings.Contains(out, tt.wantOutput) {
+				t.Errorf("输出 = %q, want containing %q", out, tt.wantOutput)
+			}
+		})
+	}
+
+	// 磁盘上没有任何变化，也没有请求权限
+	for _, name := range []string{"new.txt", "touched.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("dry run 不应创建 %s", name)
+		}
+	}
+	if data, err := os.ReadFile(existing); err != nil || string(data) != "hello\n" {
+		t.Errorf("dry run 不应修改或删除已有文件: %q, %v", data, err)
+	}
+	if len(perm.requests) != 0 {
+		t.Errorf("权限请求次数 = %d, want 0", len(perm.requests))
+	}
+}
+
+func TestCreateFullToolSet(t *testing.T) {
+	perm := &MockPermissionManager{shouldAllow: false}
+	toolSet, err := CreateFullToolSet(perm, nil)
+	if err != nil {
+		t.Fatalf("CreateFullToolSet() error = %v", err)
+	}
+
+	adapters := make(map[string]*CoreToolAdapter)
+	for _, tool := range toolSet {
+		adapter, ok := tool.(*CoreToolAdapter)
+		if !ok {
+			t.Fatalf("%s 不是 CoreToolAdapter", tool.Name())
+		}
+		adapters[tool.Name()] = adapter
+	}
+
+	legacy, err := CreateToolSet(perm, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(toolSet) <= len(legacy) {
+		t.Errorf("完整工具集只有 %d 个工具，最小工具集有 %d 个", len(toolSet), len(legacy))
+	}
+
+	// 需要权限的工具应保留权限包装
+	wantPerm := map[string]bool{
+		"read": false, "search": false, "glob": false, "list": false, "todo": false,
+		"write": true, "edit": true, "patch": true, "bash": true, "pipeline": true, "process": true,
+	}
+	for name, want := range wantPerm {
+		adapter, ok := adapters[name]
+		if !ok {
+			t.Errorf("缺少工具 %s", name)
+			continue
+		}
+		if got := adapter.needsPermission(map[string]interface{}{}); got != want {
+			t.Errorf("%s needsPermission = %v, want %v", name, got, want)
+		}
+		if adapter.perm != perm {
+			t.Errorf("%s 没有使用传入的权限管理器", name)
+		}
+	}
+
+	// 权限被拒绝时不执行写入
+	path := filepath.Join(t.TempDir(), "denied.txt")
+	if _, err := adapters["write"].Execute(map[string]interface{}{"path": path, "content": "x"}); err == nil {
+		t.Error("权限被拒绝时应返回错误")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("权限被拒绝时不应写入文件")
+	}
+	if len(perm.requests) != 1 {
+		t.Errorf("权限请求次数 = %d, want 1", len(perm.requests))
+	}
+}
+
+// privilegedTool 需要权限的测试工具，记录是否被执行
+type privilegedTool struct {
+	*core.BaseTool
+	executed bool
+}
+
+func newPrivilegedTool(requiresPerm bool) *privilegedTool {
+	tool := &privilegedTool{BaseTool: core.NewBaseTool("privileged", "test", "Privileged test tool")}
+	tool.SetRequiresPerm(requiresPerm)
+	return tool
+}
+
+func (t *privilegedTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	t.executed = true
+	return core.NewSimpleResult("ok"), nil
+}
+
+func TestCoreToolAdapter_RequiresPerm(t *testing.T) {
+	tests := []struct {
+		name            string
+		requiresPerm    bool
+		perm            permission.Manager
+		params          map[string]interface{}
+		wantErr         bool
+		wantDescription string
+	}{
+		{"无需权限直接执行", false, nil, map[string]interface{}{}, false, ""},
+		{"拒绝时不执行", true, &MockPermissionManager{shouldAllow: false}, map[string]interface{}{"command": "rm x"}, true, "Execute command: rm x"},
+		{"允许时执行", true, &MockPermissionManager{shouldAllow: true}, map[string]interface{}{"path": "a.txt"}, false, "Privileged: a.txt"},
+		{"使用 file_path 描述", true, &MockPermissionManager{shouldAllow: true}, map[string]interface{}{"file_path": "b.txt"}, false, "Privileged: b.txt"},
+		{"无参数时使用工具描述", true, &MockPermissionManager{shouldAllow: true}, map[string]interface{}{}, false, "Privileged test tool"},
+		{"没有权限管理器时拒绝", true, nil, map[string]interface{}{}, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := newPrivilegedTool(tt.requiresPerm)
+			_, err := NewCoreToolAdapter(tool, tt.perm).Execute(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tool.executed == tt.wantErr {
+				t.Errorf("executed = %v, wantErr %v", tool.executed, tt.wantErr)
+			}
+
+			mock, ok := tt.perm.(*MockPermissionManager)
+			if !ok {
+				return
+			}
+			if len(mock.requests) != 1 {
+				t.Fatalf("权限请求次数 = %d, want 1", len(mock.requests))
+			}
+			if mock.requests[0].action != "privileged" || mock.requests[0].description != tt.wantDescription {
+				t.Errorf("权限请求 = %+v, want privileged/%q", mock.requests[0], tt.wantDescription)
+			}
+		})
+	}
+}
+
+func TestPermissionDescription(t *testing.T) {
+	tests := []struct {
+		name   string
+		tool   core.Tool
+		params map[string]interface{}
+		want   string
+	}{
+		{"命令", system.NewBashTool(), map[string]interface{}{"command": "go test ./..."}, "Execute command: go test ./..."},
+		{"管道", system.NewPipelineTool(), map[string]interface{}{"commands": []interface{}{"cat a.txt", "sort"}}, "Execute pipeline: cat a.txt | sort"},
+		{"URL", system.NewFetchTool(), map[string]interface{}{"url": "https://example.com"}, "Fetch URL: https://example.com"},
+		{"写入", file.NewWriteTool(), map[string]interface{}{"path": "a.txt", "content": "x"}, "Write: a.txt"},
+		{"删除", file.NewDeleteTool(), map[string]interface{}{"path": "a.txt"}, "Delete: a.txt"},
+		{"移动", file.NewMoveTool(), map[string]interface{}{"src": "a.txt", "dst": "b.txt"}, "Move: a.txt → b.txt"},
+		{"复制", file.NewCopyTool(), map[string]interface{}{"src": "a", "dst": "b"}, "Copy: a → b"},
+		{"带动作的归档", file.NewTarTool(), map[string]interface{}{"action": "extract", "archive": "x.tar.gz"}, "Tar extract: x.tar.gz"},
+		{"多文件编辑", file.NewMultiEditTool(), map[string]interface{}{"edits": []interface{}{
+			map[string]interface{}{"path": "a.go"}, map[string]interface{}{"path": "b.go"},
+		}}, "Multi edit: a.go, b.go"},
+		{"只有动作", system.NewEnvTool(), map[string]interface{}{"action": "persist"}, "Env persist"},
+		{"无参数时使用工具描述", newPrivilegedTool(true), map[string]interface{}{}, "Privileged test tool"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := permissionDescription(tt.tool, tt.params); got != tt.want {
+				t.Errorf("permissionDescription() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoreToolAdapter_WithTimeoutKeepsPermission(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(path, []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	operations := []interface{}{map[string]interface{}{"type": "replace", "find": "hello", "replace": "bye"}}
+
+	perm := &MockPermissionManager{shouldAllow: false}
+	adapter := NewCoreToolAdapter(core.WithTimeout(file.NewEditTool(), time.Second), perm)
+
+	// dry run 仍然不需要权限
+	if _, err := adapter.Execute(map[string]interface{}{"path": path, "operations": operations, "dry_run": true}); err != nil {
+		t.Fatalf("dry run error = %v", err)
+	}
+	if len(perm.requests) != 0 {
+		t.Errorf("dry run 权限请求次数 = %d, want 0", len(perm.requests))
+	}
+
+	// 实际编辑仍需权限，拒绝后不修改文件
+	if _, err := adapter.Execute(map[string]interface{}{"path": path, "operations": operations}); core.GetErrorCode(err) != core.ErrCodePermissionDenied {
+		t.Errorf("error = %v, want permission denied", err)
+	}
+	if len(perm.requests) != 1 {
+		t.Errorf("权限请求次数 = %d, want 1", len(perm.requests))
+	}
+	if data, _ := os.ReadFile(path); string(data) != "hello\n" {
+		t.Errorf("权限被拒绝后文件被修改: %q", data)
+	}
+}
+
+func TestCoreToolAdapter_EnvPersistRequiresPermission(t *testing.T) {
+	t.Setenv("OPENCODE_NANO_TEST_PERSIST", "")
+
+	tests := []struct {
+		name         string
+		params       map[string]interface{}
+		wantRequests int
+		wantErr      bool
+	}{
+		{"仅设置当前进程不请求权限", map[string]interface{}{"action": "set", "name": "OPENCODE_NANO_TEST_PERSIST", "value": "x"}, 0, false},
+		{"持久化设置需要权限", map[string]interface{}{"action": "set", "name": "OPENCODE_NANO_TEST_PERSIST", "value": "x", "persist": true}, 1, true},
+		{"持久化删除需要权限", map[string]interface{}{"action": "delete", "name": "OPENCODE_NANO_TEST_PERSIST", "persist": true}, 1, true},
+		{"persist 动作需要权限", map[string]interface{}{"action": "persist"}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envFile := filepath.Join(t.TempDir(), "env")
+			perm := &MockPermissionManager{shouldAllow: false}
+			adapter := NewCoreToolAdapter(system.NewEnvTool().SetEnvFile(envFile), perm)
+
+			_, err := adapter.Execute(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && core.GetErrorCode(err) != core.ErrCodePermissionDenied {
+				t.Errorf("error = %v, want permission denied", err)
+			}
+			if len(perm.requests) != tt.wantRequests {
+				t.Errorf("权限请求次数 = %d, want %d", len(perm.requests), tt.wantRequests)
+			}
+			if _, err := os.Stat(envFile); !os.IsNotExist(err) {
+				t.Errorf("未获得权限时不应写入持久化文件: %v", err)
+			}
+		})
+	}
+}
+
+func TestCreateToolSet(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.txt")
+
+	tests := []struct {
+		tool     string
+		params   map[string]interface{}
+		allow    bool
+		wantPerm bool
+		wantErr  bool
+	}{
+		{"write", map[string]interface{}{"path": path, "content": "hello"}, false, true, true},
+		{"write", map[string]interface{}{"path": path, "content": "hello"}, true, true, false},
+		{"read", map[string]interface{}{"path": path}, false, false, false},
+		{"bash", map[string]interface{}{"command": "echo hi"}, false, true, true},
+		{"bash", map[string]interface{}{"command": "echo hi"}, true, true, false},
+		{"todo", map[string]interface{}{"action": "list"}, false, false, false},
+	}
+
+	for _, tt := range tests {
+		perm := &MockPermissionManager{shouldAllow: tt.allow}
+		toolSet, err := CreateToolSet(perm, nil)
+		if err != nil {
+			t.Fatalf("CreateToolSet() error = %v", err)
+		}
+
+		var names []string
+		tools := make(map[string]Tool)
+		for _, tool := range toolSet {
+			names = append(names, tool.Name())
+			tools[tool.Name()] = tool
+		}
+		if strings.Join(names, ",") != "read,write,bash,todo" {
+			t.Fatalf("工具集 = %v, want [read write bash todo]", names)
+		}
+
+		_, err = tools[tt.tool].Execute(tt.params)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s(allow=%v) error = %v, wantErr %v", tt.tool, tt.allow, err, tt.wantErr)
+		}
+		if got := len(perm.requests) > 0; got != tt.wantPerm {
+			t.Errorf("%s 请求权限 = %v, want %v", tt.tool, got, tt.wantPerm)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "hello" {
+		t.Errorf("write 写入内容 = %q, %v", data, err)
+	}
+}
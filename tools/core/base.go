@@ -3,6 +3,8 @@ package core
 import (
 	"context"
 	"fmt"
+	"math"
+	"reflect"
 	"strconv"
 )
 
@@ -169,8 +171,20 @@ func (p *MapParameters) Set(key string, value any) {
 	p.data[key] = value
 }
 
-// Validate 验证参数
+// ApplyDefaults 用 schema 中的默认值填充缺失的参数，已有的值不会被覆盖
+func (p *MapParameters) ApplyDefaults(schema ParameterSchema) {
+	for key, propSchema := range schema.Properties {
+		if propSchema.Default == nil || p.Has(key) {
+			continue
+		}
+		p.data[key] = propSchema.Default
+	}
+}
+
+// Validate 验证参数，验证前先填充默认值
 func (p *MapParameters) Validate(schema ParameterSchema) error {
+	p.ApplyDefaults(schema)
+	
 	// 检查必需参数
 	for _, required := range schema.Required {
 		if !p.Has(required) {
@@ -185,30 +199,106 @@ func (p *MapParameters) Validate(schema ParameterSchema) error {
 		}
 		
 		value, _ := p.Get(key)
-		
-		// 验证枚举值
-		if len(propSchema.Enum) > 0 {
-			strValue := fmt.Sprintf("%v", value)
-			found := false
-			for _, enum := range propSchema.Enum {
-				if strValue == enum {
-					found = true
-					break
+		if err := validateValue(key, value, propSchema, false); err != nil {
+			return err
+		}
+	}
+	
+	return nil
+}
+
+// validateValue 按 schema 验证单个值。strict 为 true 时严格检查类型，
+// 用于数组元素和对象字段；顶层参数为兼容字符串形式的数字等输入不做类型检查
+func validateValue(name string, value any, schema PropertySchema, strict bool) error {
+	if strict {
+		if err := checkType(name, value, schema.Type); err != nil {
+			return err
+		}
+	}
+	
+	// 验证枚举值
+	if len(schema.Enum) > 0 {
+		strValue := fmt.Sprintf("%v", value)
+		found := false
+		for _, enum := range schema.Enum {
+			if strValue == enum {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("parameter %s must be one of %v", name, schema.Enum)
+		}
+	}
+	
+	switch schema.Type {
+	case "string":
+		// 验证字符串长度
+		strValue, ok := value.(string)
+		if !ok {
+			strValue = fmt.Sprintf("%v", value)
+		}
+		if schema.MinLength > 0 && len(strValue) < schema.MinLength {
+			return fmt.Errorf("parameter %s must be at least %d characters", name, schema.MinLength)
+		}
+		if schema.MaxLength > 0 && len(strValue) > schema.MaxLength {
+			return fmt.Errorf("parameter %s must be at most %d characters", name, schema.MaxLength)
+		}
+	case "integer", "number":
+		// 验证数值范围
+		if schema.Minimum == nil && schema.Maximum == nil {
+			return nil
+		}
+		number, ok := toFloat(value)
+		if !ok {
+			return fmt.Errorf("parameter %s must be a number", name)
+		}
+		if schema.Minimum != nil && number < *schema.Minimum {
+			return fmt.Errorf("parameter %s must be at least %v, got %v", name, *schema.Minimum, number)
+		}
+		if schema.Maximum != nil && number > *schema.Maximum {
+			return fmt.Errorf("parameter %s must be at most %v, got %v", name, *schema.Maximum, number)
+		}
+	case "array":
+		// 验证数组长度和元素
+		if schema.Items == nil && schema.MinItems == 0 {
+			return nil
+		}
+		items, ok := toSlice(value)
+		if !ok {
+			return fmt.Errorf("parameter %s must be an array", name)
+		}
+		if len(items) < schema.MinItems {
+			return fmt.Errorf("parameter %s must contain at least %d items, got %d", name, schema.MinItems, len(items))
+		}
+		if schema.Items != nil {
+			for i, item := range items {
+				if err := validateValue(fmt.Sprintf("%s[%d]", name, i), item, *schema.Items, true); err != nil {
+					return err
 				}
 			}
-			if !found {
-				return fmt.Errorf("parameter %s must be one of %v", key, propSchema.Enum)
+		}
+	case "object":
+		// 验证对象字段
+		if len(schema.Properties) == 0 && len(schema.Required) == 0 {
+			return nil
+		}
+		fields, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("parameter %s must be an object", name)
+		}
+		for _, required := range schema.Required {
+			if _, exists := fields[required]; !exists {
+				return fmt.Errorf("parameter %s.%s is missing", name, required)
 			}
 		}
-		
-		// 验证字符串长度
-		if propSchema.Type == "string" {
-			strValue, _ := p.GetString(key)
-			if propSchema.MinLength > 0 && len(strValue) < propSchema.MinLength {
-				return fmt.Errorf("parameter %s must be at least %d characters", key, propSchema.MinLength)
+		for key, fieldSchema := range schema.Properties {
+			fieldValue, exists := fields[key]
+			if !exists {
+				continue
 			}
-			if propSchema.MaxLength > 0 && len(strValue) > propSchema.MaxLength {
-				return fmt.Errorf("parameter %s must be at most %d characters", key, propSchema.MaxLength)
+			if err := validateValue(name+"."+key, fieldValue, fieldSchema, true); err != nil {
+				return err
 			}
 		}
 	}
@@ -216,6 +306,68 @@ func (p *MapParameters) Validate(schema ParameterSchema) error {
 	return nil
 }
 
+// checkType 检查值是否符合 JSON Schema 类型，类型为空时不检查
+func checkType(name string, value any, typ string) error {
+	ok := true
+	switch typ {
+	case "string":
+		_, ok = value.(string)
+	case "integer":
+		number, isNumber := toFloat(value)
+		_, isString := value.(string)
+		ok = isNumber && !isString && number == math.Trunc(number)
+	case "number":
+		_, isString := value.(string)
+		_, isNumber := toFloat(value)
+		ok = isNumber && !isString
+	case "boolean":
+		_, ok = value.(bool)
+	case "array":
+		_, ok = toSlice(value)
+	case "object":
+		_, ok = value.(map[string]any)
+	}
+	if !ok {
+		return fmt.Errorf("parameter %s must be of type %s, got %T", name, typ, value)
+	}
+	return nil
+}
+
+// toSlice 将任意切片转换为 []any
+func toSlice(value any) ([]any, bool) {
+	if items, ok := value.([]any); ok {
+		return items, true
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice {
+		return nil, false
+	}
+	items := make([]any, v.Len())
+	for i := range items {
+		items[i] = v.Index(i).Interface()
+	}
+	return items, true
+}
+
+// toFloat 将数值参数转换为 float64
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
 // Raw 获取原始 map
 func (p *MapParameters) Raw() map[string]any {
 	return p.data
@@ -0,0 +1,174 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMapParameters_ApplyDefaults(t *testing.T) {
+	schema := ParameterSchema{
+		Type: "object",
+		Properties: map[string]PropertySchema{
+			"path":    {Type: "string", Default: "."},
+			"limit":   {Type: "integer", Default: 10},
+			"verbose": {Type: "boolean", Default: true},
+			"mode":    {Type: "string", Enum: []string{"a", "b"}, Default: "a"},
+			"name":    {Type: "string"},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		input map[string]any
+		want  map[string]any
+	}{
+		{
+			name:  "缺失的参数使用默认值",
+			input: map[string]any{},
+			want:  map[string]any{"path": ".", "limit": 10, "verbose": true, "mode": "a"},
+		},
+		{
+			name:  "显式传入的值不被覆盖",
+			input: map[string]any{"path": "src", "limit": 0, "verbose": false, "mode": "b", "name": "x"},
+			want:  map[string]any{"path": "src", "limit": 0, "verbose": false, "mode": "b", "name": "x"},
+		},
+		{
+			name:  "部分参数缺失",
+			input: map[string]any{"limit": float64(5)},
+			want:  map[string]any{"path": ".", "limit": float64(5), "verbose": true, "mode": "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := NewMapParameters(tt.input)
+			params.ApplyDefaults(schema)
+			if !reflect.DeepEqual(params.Raw(), tt.want) {
+				t.Errorf("ApplyDefaults() = %v, want %v", params.Raw(), tt.want)
+			}
+		})
+	}
+}
+
+func TestMapParameters_ValidateAppliesDefaults(t *testing.T) {
+	schema := ParameterSchema{
+		Type: "object",
+		Properties: map[string]PropertySchema{
+			"action": {Type: "string", Enum: []string{"list", "add"}},
+			"limit":  {Type: "integer", Default: 10},
+		},
+		Required: []string{"action"},
+	}
+
+	params := NewMapParameters(map[string]any{"action": "list"})
+	if err := params.Validate(schema); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if limit, err := params.GetInt("limit"); err != nil || limit != 10 {
+		t.Errorf("limit = %v, %v, want 10", limit, err)
+	}
+
+	// 默认值不能代替必需参数
+	if err := NewMapParameters(nil).Validate(schema); err == nil {
+		t.Error("缺少必需参数时应返回错误")
+	}
+}
+
+func TestMapParameters_ValidateRange(t *testing.T) {
+	schema := ParameterSchema{
+		Type: "object",
+		Properties: map[string]PropertySchema{
+			"timeout": {Type: "integer", Minimum: Bound(0), Maximum: Bound(3600)},
+			"ratio":   {Type: "number", Minimum: Bound(0.5)},
+			"count":   {Type: "integer", Maximum: Bound(10)},
+			"free":    {Type: "integer"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		params  map[string]any
+		wantErr bool
+	}{
+		{"范围内", map[string]any{"timeout": 300}, false},
+		{"等于最小值", map[string]any{"timeout": 0}, false},
+		{"等于最大值", map[string]any{"timeout": float64(3600)}, false},
+		{"小于最小值", map[string]any{"timeout": -1}, true},
+		{"大于最大值", map[string]any{"timeout": int64(3601)}, true},
+		{"字符串数值", map[string]any{"timeout": "60"}, false},
+		{"字符串超出范围", map[string]any{"timeout": "7200"}, true},
+		{"非数值", map[string]any{"timeout": "soon"}, true},
+		{"浮点数小于最小值", map[string]any{"ratio": 0.25}, true},
+		{"浮点数范围内", map[string]any{"ratio": 0.75}, false},
+		{"只有最大值", map[string]any{"count": -100}, false},
+		{"只有最大值时超出", map[string]any{"count": 11}, true},
+		{"未设置范围", map[string]any{"free": -100}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewMapParameters(tt.params).Validate(schema)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMapParameters_ValidateItems(t *testing.T) {
+	schema := ParameterSchema{
+		Type: "object",
+		Properties: map[string]PropertySchema{
+			"names": {Type: "array", Items: &PropertySchema{Type: "string"}, MinItems: 1},
+			"lines": {Type: "array", Items: &PropertySchema{Type: "integer", Minimum: Bound(1)}},
+			"ops": {
+				Type: "array",
+				Items: &PropertySchema{
+					Type: "object",
+					Properties: map[string]PropertySchema{
+						"type": {Type: "string", Enum: []string{"insert", "delete"}},
+						"line": {Type: "integer"},
+					},
+					Required: []string{"type"},
+				},
+			},
+			"loose": {Type: "array"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		params  map[string]any
+		wantErr string
+	}{
+		{"字符串数组", map[string]any{"names": []any{"a", "b"}}, ""},
+		{"[]string 类型", map[string]any{"names": []string{"a"}}, ""},
+		{"数组过短", map[string]any{"names": []any{}}, "parameter names must contain at least 1 items, got 0"},
+		{"元素类型错误", map[string]any{"names": []any{"a", 1}}, "parameter names[1] must be of type string, got int"},
+		{"不是数组", map[string]any{"names": "a"}, "parameter names must be an array"},
+		{"JSON 数字作为整数", map[string]any{"lines": []any{float64(1), float64(2)}}, ""},
+		{"非整数", map[string]any{"lines": []any{1.5}}, "parameter lines[0] must be of type integer, got float64"},
+		{"元素超出范围", map[string]any{"lines": []any{0}}, "parameter lines[0] must be at least 1, got 0"},
+		{"对象元素", map[string]any{"ops": []any{map[string]any{"type": "insert", "line": 2}}}, ""},
+		{"对象缺少必需字段", map[string]any{"ops": []any{map[string]any{"line": 2}}}, "parameter ops[0].type is missing"},
+		{"对象字段类型错误", map[string]any{"ops": []any{map[string]any{"type": "delete", "line": "2"}}}, "parameter ops[0].line must be of type integer, got string"},
+		{"对象字段不在枚举中", map[string]any{"ops": []any{map[string]any{"type": "move"}}}, "parameter ops[0].type must be one of [insert delete]"},
+		{"元素不是对象", map[string]any{"ops": []any{"insert"}}, "parameter ops[0] must be of type object, got string"},
+		{"未声明元素 schema", map[string]any{"loose": "anything"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewMapParameters(tt.params).Validate(schema)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
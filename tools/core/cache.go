@@ -0,0 +1,152 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultResultCache WithCache 使用的默认缓存
+var DefaultResultCache = NewResultCache()
+
+// ResultCache 工具结果缓存，多个工具共享同一个缓存以便写入后统一失效
+type ResultCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// cacheEntry 缓存条目
+type cacheEntry struct {
+	result  Result
+	expires time.Time
+}
+
+// NewResultCache 创建结果缓存
+func NewResultCache() *ResultCache {
+	return &ResultCache{entries: make(map[string]cacheEntry)}
+}
+
+// get 获取未过期的缓存结果
+func (c *ResultCache) get(key string) (Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.result, true
+}
+
+// set 保存缓存结果
+func (c *ResultCache) set(key string, result Result, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cacheEntry{result: result, expires: time.Now().Add(ttl)}
+}
+
+// Invalidate 清空所有缓存，文件被修改后调用
+func (c *ResultCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]cacheEntry)
+}
+
+// Len 当前缓存条目数（包括已过期但尚未清理的条目）
+func (c *ResultCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// cachedTool 缓存成功结果的装饰器
+type cachedTool struct {
+	Tool
+	cache *ResultCache
+	ttl   time.Duration
+}
+
+// WithCache 使用 DefaultResultCache 缓存只读工具的成功结果，ttl 内相同参数的调用直接返回缓存。
+// 需要权限的工具会修改状态，原样返回不做缓存
+func WithCache(tool Tool, ttl time.Duration) Tool {
+	return WithCacheStore(tool, ttl, DefaultResultCache)
+}
+
+// WithCacheStore 与 WithCache 相同，但使用指定的缓存
+func WithCacheStore(tool Tool, ttl time.Duration, cache *ResultCache) Tool {
+	if tool.Info().RequiresPerm || ttl <= 0 {
+		return tool
+	}
+	return &cachedTool{Tool: tool, cache: cache, ttl: ttl}
+}
+
+// Execute 命中缓存时直接返回，否则执行并缓存成功的结果
+func (t *cachedTool) Execute(ctx context.Context, params Parameters) (Result, error) {
+	key, err := cacheKey(t.Info().Name, params)
+	if err != nil {
+		// 参数无法序列化时不缓存
+		return t.Tool.Execute(ctx, params)
+	}
+
+	if result, ok := t.cache.get(key); ok {
+		return result, nil
+	}
+
+	result, err := t.Tool.Execute(ctx, params)
+	if err == nil && result != nil && result.Success() {
+		t.cache.set(key, result, t.ttl)
+	}
+	return result, err
+}
+
+// Unwrap 返回被包装的工具
+func (t *cachedTool) Unwrap() Tool {
+	return t.Tool
+}
+
+// cacheKey 由工具名和参数的哈希组成缓存键
+func cacheKey(name string, params Parameters) (string, error) {
+	// json.Marshal 按键排序输出 map，相同参数得到相同的键
+	data, err := json.Marshal(params.Raw())
+	if err != nil {
+		return "", fmt.Errorf("failed to encode params: %v", err)
+	}
+	sum := sha256.Sum256(data)
+	return name + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// invalidatingTool 执行后清空缓存的装饰器
+type invalidatingTool struct {
+	Tool
+	cache *ResultCache
+}
+
+// InvalidateOnWrite 包装会修改文件的工具，执行后清空 cache，避免读取到旧结果。
+// 失败的执行也可能已修改部分文件，因此无论成败都会清空
+func InvalidateOnWrite(tool Tool, cache *ResultCache) Tool {
+	return &invalidatingTool{Tool: tool, cache: cache}
+}
+
+// Execute 执行被包装的工具，然后清空缓存
+func (t *invalidatingTool) Execute(ctx context.Context, params Parameters) (Result, error) {
+	defer t.cache.Invalidate()
+	return t.Tool.Execute(ctx, params)
+}
+
+// NeedsPermission 保留被包装工具的权限判断
+func (t *invalidatingTool) NeedsPermission(params Parameters) bool {
+	return NeedsPermission(t.Tool, params)
+}
+
+// Unwrap 返回被包装的工具
+func (t *invalidatingTool) Unwrap() Tool {
+	return t.Tool
+}
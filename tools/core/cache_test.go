@@ -0,0 +1,122 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// countingTool 记录执行次数的测试工具
+type countingTool struct {
+	*BaseTool
+	calls int
+	fail  bool
+}
+
+func newCountingTool(name string, requiresPerm bool) *countingTool {
+	tool := &countingTool{BaseTool: NewBaseTool(name, "test", "Counting test tool")}
+	tool.SetRequiresPerm(requiresPerm)
+	return tool
+}
+
+func (t *countingTool) Execute(ctx context.Context, params Parameters) (Result, error) {
+	t.calls++
+	if t.fail {
+		return nil, ErrExecutionFailed(t.Info().Name, "failed")
+	}
+	return NewSimpleResult(t.calls), nil
+}
+
+func TestWithCache(t *testing.T) {
+	cache := NewResultCache()
+	inner := newCountingTool("read", false)
+	tool := WithCacheStore(inner, time.Minute, cache)
+	ctx := context.Background()
+
+	first, _ := tool.Execute(ctx, NewMapParameters(map[string]any{"path": "a.txt", "limit": 10}))
+	second, _ := tool.Execute(ctx, NewMapParameters(map[string]any{"limit": 10, "path": "a.txt"}))
+	if inner.calls != 1 {
+		t.Errorf("相同参数执行次数 = %d, want 1", inner.calls)
+	}
+	if second.Data() != first.Data() {
+		t.Errorf("缓存结果 = %v, want %v", second.Data(), first.Data())
+	}
+
+	// 参数不同时重新执行
+	tool.Execute(ctx, NewMapParameters(map[string]any{"path": "b.txt", "limit": 10}))
+	if inner.calls != 2 {
+		t.Errorf("不同参数执行次数 = %d, want 2", inner.calls)
+	}
+
+	// 失效后重新执行
+	cache.Invalidate()
+	tool.Execute(ctx, NewMapParameters(map[string]any{"path": "a.txt", "limit": 10}))
+	if inner.calls != 3 {
+		t.Errorf("失效后执行次数 = %d, want 3", inner.calls)
+	}
+}
+
+func TestWithCache_TTL(t *testing.T) {
+	inner := newCountingTool("glob", false)
+	tool := WithCacheStore(inner, 20*time.Millisecond, NewResultCache())
+	params := map[string]any{"pattern": "*.go"}
+
+	tool.Execute(context.Background(), NewMapParameters(params))
+	tool.Execute(context.Background(), NewMapParameters(params))
+	if inner.calls != 1 {
+		t.Fatalf("TTL 内执行次数 = %d, want 1", inner.calls)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	tool.Execute(context.Background(), NewMapParameters(params))
+	if inner.calls != 2 {
+		t.Errorf("TTL 过期后执行次数 = %d, want 2", inner.calls)
+	}
+}
+
+func TestWithCache_SkipsFailuresAndPrivilegedTools(t *testing.T) {
+	cache := NewResultCache()
+
+	failing := newCountingTool("search", false)
+	failing.fail = true
+	tool := WithCacheStore(failing, time.Minute, cache)
+	for i := 0; i < 2; i++ {
+		tool.Execute(context.Background(), NewMapParameters(nil))
+	}
+	if failing.calls != 2 {
+		t.Errorf("失败结果不应缓存, 执行次数 = %d", failing.calls)
+	}
+
+	privileged := newCountingTool("write", true)
+	if wrapped := WithCacheStore(privileged, time.Minute, cache); wrapped != Tool(privileged) {
+		t.Errorf("需要权限的工具不应被缓存包装, got %T", wrapped)
+	}
+}
+
+func TestInvalidateOnWrite(t *testing.T) {
+	cache := NewResultCache()
+	reader := newCountingTool("read", false)
+	read := WithCacheStore(reader, time.Minute, cache)
+
+	writer := newCountingTool("write", true)
+	write := InvalidateOnWrite(writer, cache)
+	if !write.Info().RequiresPerm {
+		t.Error("包装后应保留 RequiresPerm")
+	}
+
+	params := map[string]any{"path": "a.txt"}
+	read.Execute(context.Background(), NewMapParameters(params))
+	read.Execute(context.Background(), NewMapParameters(params))
+	if reader.calls != 1 {
+		t.Fatalf("写入前执行次数 = %d, want 1", reader.calls)
+	}
+
+	write.Execute(context.Background(), NewMapParameters(params))
+	if cache.Len() != 0 {
+		t.Errorf("写入后缓存条目数 = %d, want 0", cache.Len())
+	}
+	read.Execute(context.Background(), NewMapParameters(params))
+	if reader.calls != 2 {
+		t.Errorf("写入后执行次数 = %d, want 2", reader.calls)
+	}
+}
@@ -9,6 +9,7 @@ const (
 	ErrCodeInvalidParams    = "INVALID_PARAMS"
 	ErrCodePermissionDenied = "PERMISSION_DENIED"
 	ErrCodeToolNotFound     = "TOOL_NOT_FOUND"
+	ErrCodeToolDisabled     = "TOOL_DISABLED"
 	ErrCodeExecutionFailed  = "EXECUTION_FAILED"
 	ErrCodeTimeout          = "TIMEOUT"
 	ErrCodeCancelled        = "CANCELLED"
@@ -83,6 +84,11 @@ func ErrToolNotFound(name string) *ToolError {
 	return NewToolError(ErrCodeToolNotFound, name, fmt.Sprintf("tool not found: %s", name))
 }
 
+// ErrToolDisabled 创建工具已被配置禁用的错误
+func ErrToolDisabled(name string) *ToolError {
+	return NewToolError(ErrCodeToolDisabled, name, fmt.Sprintf("tool disabled: %s", name))
+}
+
 // ErrExecutionFailed 创建执行失败错误
 func ErrExecutionFailed(tool, message string) *ToolError {
 	return NewToolError(ErrCodeExecutionFailed, tool, message)
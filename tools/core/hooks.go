@@ -0,0 +1,55 @@
+package core
+
+import (
+	"context"
+	"fmt"
+)
+
+// Hooks 工具执行前后的回调，未设置的回调会被忽略
+type Hooks struct {
+	// OnBefore 在工具执行前调用
+	OnBefore func(tool Tool, params Parameters)
+	// OnAfter 在工具执行后调用，执行 panic 时 err 为内部错误
+	OnAfter func(tool Tool, result Result, err error)
+}
+
+// hooksTool 在执行前后调用回调的装饰器
+type hooksTool struct {
+	Tool
+	hooks Hooks
+}
+
+// WithHooks 包装工具，执行前后分别调用 hooks.OnBefore 和 hooks.OnAfter，
+// 可用于日志、进度显示等
+func WithHooks(tool Tool, hooks Hooks) Tool {
+	return &hooksTool{Tool: tool, hooks: hooks}
+}
+
+// Execute 执行被包装的工具，工具 panic 时恢复并以错误返回，保证 OnAfter 总会被调用
+func (t *hooksTool) Execute(ctx context.Context, params Parameters) (result Result, err error) {
+	if t.hooks.OnBefore != nil {
+		t.hooks.OnBefore(t.Tool, params)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			result = nil
+			err = ErrInternalError(t.Info().Name, fmt.Sprintf("panic during execution: %v", r))
+		}
+		if t.hooks.OnAfter != nil {
+			t.hooks.OnAfter(t.Tool, result, err)
+		}
+	}()
+
+	return t.Tool.Execute(ctx, params)
+}
+
+// NeedsPermission 保留被包装工具的权限判断
+func (t *hooksTool) NeedsPermission(params Parameters) bool {
+	return NeedsPermission(t.Tool, params)
+}
+
+// Unwrap 返回被包装的工具
+func (t *hooksTool) Unwrap() Tool {
+	return t.Tool
+}
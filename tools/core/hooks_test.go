@@ -0,0 +1,71 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// panicTool 执行时 panic 的测试工具
+type panicTool struct {
+	*BaseTool
+}
+
+func (t *panicTool) Execute(ctx context.Context, params Parameters) (Result, error) {
+	panic("boom")
+}
+
+func TestWithHooks(t *testing.T) {
+	tests := []struct {
+		name    string
+		tool    Tool
+		wantErr bool
+	}{
+		{"执行成功", newStubTool("ok", 0, nil), false},
+		{"执行失败", newStubTool("fail", 0, errors.New("failed")), true},
+		{"执行 panic", &panicTool{BaseTool: NewBaseTool("panic", "test", "Panicking tool")}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var before, after int
+			var afterErr error
+			hooks := Hooks{
+				OnBefore: func(tool Tool, params Parameters) {
+					before++
+					if tool.Info().Name != tt.tool.Info().Name {
+						t.Errorf("OnBefore 工具名 = %s", tool.Info().Name)
+					}
+				},
+				OnAfter: func(tool Tool, result Result, err error) {
+					after++
+					afterErr = err
+					if err == nil && result == nil {
+						t.Error("成功时 OnAfter 应收到结果")
+					}
+				},
+			}
+
+			_, err := WithHooks(tt.tool, hooks).Execute(context.Background(), NewMapParameters(nil))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if before != 1 || after != 1 {
+				t.Errorf("回调次数 before = %d, after = %d, want 1, 1", before, after)
+			}
+			if afterErr != err {
+				t.Errorf("OnAfter 收到的错误 = %v, want %v", afterErr, err)
+			}
+		})
+	}
+}
+
+func TestWithHooks_PanicError(t *testing.T) {
+	tool := WithHooks(&panicTool{BaseTool: NewBaseTool("panic", "test", "Panicking tool")}, Hooks{})
+
+	_, err := tool.Execute(context.Background(), NewMapParameters(nil))
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Code != ErrCodeInternalError {
+		t.Errorf("panic 应转换为内部错误, got %v", err)
+	}
+}
@@ -45,7 +45,7 @@ type Parameters interface {
 	// Has 检查参数是否存在
 	Has(key string) bool
 	
-	// Validate 验证参数
+	// Validate 验证参数，并用 schema 中的默认值填充缺失的参数
 	Validate(schema ParameterSchema) error
 	
 	// Raw 获取原始 map
@@ -85,6 +85,18 @@ type PropertySchema struct {
 	Enum        []string `json:"enum,omitempty"`
 	MinLength   int      `json:"minLength,omitempty"`
 	MaxLength   int      `json:"maxLength,omitempty"`
+	Minimum     *float64 `json:"minimum,omitempty"` // integer/number 的最小值，nil 表示不限制
+	Maximum     *float64 `json:"maximum,omitempty"` // integer/number 的最大值，nil 表示不限制
+
+	Items      *PropertySchema           `json:"items,omitempty"`      // array 的元素 schema
+	MinItems   int                       `json:"minItems,omitempty"`   // array 的最少元素数
+	Properties map[string]PropertySchema `json:"properties,omitempty"` // object 的字段 schema
+	Required   []string                  `json:"required,omitempty"`   // object 的必需字段
+}
+
+// Bound 返回数值范围边界的指针，用于设置 Minimum/Maximum
+func Bound(v float64) *float64 {
+	return &v
 }
 
 // AsyncTool 异步工具接口
@@ -93,6 +105,13 @@ type AsyncTool interface {
 	ExecuteAsync(ctx context.Context, params Parameters) <-chan Result
 }
 
+// ConditionalPermissionTool 根据参数决定是否需要权限的工具接口，
+// 例如 dry run 模式不修改文件时无需请求权限
+type ConditionalPermissionTool interface {
+	Tool
+	NeedsPermission(params Parameters) bool
+}
+
 // PermissionChecker 权限检查器接口
 type PermissionChecker interface {
 	// Check 检查单个工具的权限
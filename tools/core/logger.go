@@ -0,0 +1,74 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NopLogger 丢弃所有日志
+type NopLogger struct{}
+
+func (NopLogger) Debug(msg string, fields ...any) {}
+func (NopLogger) Info(msg string, fields ...any)  {}
+func (NopLogger) Warn(msg string, fields ...any)  {}
+func (NopLogger) Error(msg string, fields ...any) {}
+
+// WriterLogger 将带时间戳的结构化日志写入 io.Writer，
+// fields 按 key, value 成对输出为 key=value
+type WriterLogger struct {
+	mu  sync.Mutex
+	w   io.Writer
+	now func() time.Time
+}
+
+// NewWriterLogger 创建写入 w 的日志器
+func NewWriterLogger(w io.Writer) *WriterLogger {
+	return &WriterLogger{w: w, now: time.Now}
+}
+
+func (l *WriterLogger) Debug(msg string, fields ...any) {
+	l.log("DEBUG", msg, fields)
+}
+
+func (l *WriterLogger) Info(msg string, fields ...any) {
+	l.log("INFO", msg, fields)
+}
+
+func (l *WriterLogger) Warn(msg string, fields ...any) {
+	l.log("WARN", msg, fields)
+}
+
+func (l *WriterLogger) Error(msg string, fields ...any) {
+	l.log("ERROR", msg, fields)
+}
+
+func (l *WriterLogger) log(level, msg string, fields []any) {
+	var b strings.Builder
+	b.WriteString(l.now().Format("2006-01-02T15:04:05.000Z07:00"))
+	fmt.Fprintf(&b, " [%s] %s", level, msg)
+	for i := 0; i < len(fields); i += 2 {
+		if i+1 == len(fields) {
+			fmt.Fprintf(&b, " %s", formatLogValue(fields[i]))
+			break
+		}
+		fmt.Fprintf(&b, " %v=%s", fields[i], formatLogValue(fields[i+1]))
+	}
+	b.WriteByte('\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	io.WriteString(l.w, b.String())
+}
+
+// formatLogValue 格式化字段值，含空白或引号的字符串加引号，保证每条日志只占一行
+func formatLogValue(v any) string {
+	s := fmt.Sprint(v)
+	if s == "" || strings.ContainsAny(s, " \t\r\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,46 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestWriterLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewWriterLogger(&buf)
+	logger.now = func() time.Time {
+		return time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		log  func()
+		want string
+	}{
+		{
+			name: "带字段",
+			log:  func() { logger.Debug("tool result", "name", "read", "length", 42) },
+			want: "2024-05-01T12:30:00.000Z [DEBUG] tool result name=read length=42\n",
+		},
+		{
+			name: "含空白的值加引号",
+			log:  func() { logger.Warn("tool call", "arguments", `{"path": "a b"}`) },
+			want: "2024-05-01T12:30:00.000Z [WARN] tool call arguments=\"{\\\"path\\\": \\\"a b\\\"}\"\n",
+		},
+		{
+			name: "落单的字段原样输出",
+			log:  func() { logger.Error("failed", "oops") },
+			want: "2024-05-01T12:30:00.000Z [ERROR] failed oops\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			tt.log()
+			if buf.String() != tt.want {
+				t.Errorf("日志 = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
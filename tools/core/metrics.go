@@ -0,0 +1,72 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+// ToolMetrics 单个工具的执行统计
+type ToolMetrics struct {
+	Count         int           `json:"count"`          // 执行次数
+	Errors        int           `json:"errors"`         // 失败次数
+	TotalDuration time.Duration `json:"total_duration"` // 累计耗时
+}
+
+// AverageDuration 平均耗时
+func (m ToolMetrics) AverageDuration() time.Duration {
+	if m.Count == 0 {
+		return 0
+	}
+	return m.TotalDuration / time.Duration(m.Count)
+}
+
+// MemoryMetrics 保存在内存中的指标收集器
+type MemoryMetrics struct {
+	mu      sync.Mutex
+	metrics map[string]ToolMetrics
+}
+
+// NewMemoryMetrics 创建内存指标收集器
+func NewMemoryMetrics() *MemoryMetrics {
+	return &MemoryMetrics{metrics: make(map[string]ToolMetrics)}
+}
+
+// RecordExecution 记录一次执行
+func (m *MemoryMetrics) RecordExecution(name string, dur time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	metrics := m.metrics[name]
+	metrics.Count++
+	metrics.TotalDuration += dur
+	if err != nil {
+		metrics.Errors++
+	}
+	m.metrics[name] = metrics
+}
+
+// Get 获取单个工具的统计，未执行过时返回零值
+func (m *MemoryMetrics) Get(name string) ToolMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.metrics[name]
+}
+
+// All 获取所有工具的统计副本
+func (m *MemoryMetrics) All() map[string]ToolMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make(map[string]ToolMetrics, len(m.metrics))
+	for name, metrics := range m.metrics {
+		result[name] = metrics
+	}
+	return result
+}
+
+// Reset 清空统计
+func (m *MemoryMetrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.metrics = make(map[string]ToolMetrics)
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// stubTool 返回固定结果的测试工具
+type stubTool struct {
+	*BaseTool
+	delay time.Duration
+	err   error
+}
+
+func newStubTool(name string, delay time.Duration, err error) *stubTool {
+	return &stubTool{BaseTool: NewBaseTool(name, "test", "Stub test tool"), delay: delay, err: err}
+}
+
+func (t *stubTool) Execute(ctx context.Context, params Parameters) (Result, error) {
+	time.Sleep(t.delay)
+	if t.err != nil {
+		return nil, t.err
+	}
+	return NewSimpleResult("ok"), nil
+}
+
+func TestMemoryMetrics_RecordExecution(t *testing.T) {
+	m := NewMemoryMetrics()
+	m.RecordExecution("read", 10*time.Millisecond, nil)
+	m.RecordExecution("read", 30*time.Millisecond, errors.New("boom"))
+	m.RecordExecution("bash", 5*time.Millisecond, nil)
+
+	want := map[string]ToolMetrics{
+		"read": {Count: 2, Errors: 1, TotalDuration: 40 * time.Millisecond},
+		"bash": {Count: 1, Errors: 0, TotalDuration: 5 * time.Millisecond},
+	}
+	for name, w := range want {
+		if got := m.Get(name); got != w {
+			t.Errorf("Get(%s) = %+v, want %+v", name, got, w)
+		}
+	}
+	if got := m.Get("read").AverageDuration(); got != 20*time.Millisecond {
+		t.Errorf("AverageDuration() = %v, want 20ms", got)
+	}
+	if got := m.Get("missing"); got != (ToolMetrics{}) || got.AverageDuration() != 0 {
+		t.Errorf("未执行的工具 = %+v, want 零值", got)
+	}
+	if len(m.All()) != 2 {
+		t.Errorf("All() = %v, want 2 个工具", m.All())
+	}
+
+	m.Reset()
+	if len(m.All()) != 0 {
+		t.Errorf("Reset 后 All() = %v", m.All())
+	}
+}
+
+func TestWithMetrics(t *testing.T) {
+	m := NewMemoryMetrics()
+	ok := WithMetrics(newStubTool("ok", 5*time.Millisecond, nil), m)
+	failing := WithMetrics(newStubTool("failing", 0, ErrExecutionFailed("failing", "boom")), m)
+
+	for i := 0; i < 3; i++ {
+		if _, err := ok.Execute(context.Background(), NewMapParameters(nil)); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	}
+	if _, err := failing.Execute(context.Background(), NewMapParameters(nil)); GetErrorCode(err) != ErrCodeExecutionFailed {
+		t.Errorf("错误应原样返回, got %v", err)
+	}
+
+	okMetrics := m.Get("ok")
+	if okMetrics.Count != 3 || okMetrics.Errors != 0 {
+		t.Errorf("ok 统计 = %+v, want 3 次 0 错误", okMetrics)
+	}
+	if okMetrics.TotalDuration < 15*time.Millisecond {
+		t.Errorf("ok 累计耗时 = %v, want >= 15ms", okMetrics.TotalDuration)
+	}
+	if failingMetrics := m.Get("failing"); failingMetrics.Count != 1 || failingMetrics.Errors != 1 {
+		t.Errorf("failing 统计 = %+v, want 1 次 1 错误", failingMetrics)
+	}
+}
+
+func TestToolRegistry_SetMetrics(t *testing.T) {
+	m := NewMemoryMetrics()
+	registry := NewRegistry()
+	registry.SetMetrics(m)
+	if err := registry.Register(newStubTool("stub", 0, nil), "s"); err != nil {
+		t.Fatal(err)
+	}
+
+	tool, err := registry.Get("s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := UnwrapTool(tool).(*stubTool); !ok {
+		t.Errorf("UnwrapTool() = %T, want *stubTool", UnwrapTool(tool))
+	}
+	for i := 0; i < 2; i++ {
+		tool.Execute(context.Background(), NewMapParameters(nil))
+	}
+	if got := m.Get("stub").Count; got != 2 {
+		t.Errorf("Count = %d, want 2", got)
+	}
+}
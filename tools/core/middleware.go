@@ -0,0 +1,251 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// timeoutTool 为工具执行设置超时的装饰器
+type timeoutTool struct {
+	Tool
+	timeout time.Duration
+}
+
+// WithTimeout 包装工具，执行超过 d 时返回 ErrTimeout。
+// 即使被包装的工具不响应 context 取消，也会在超时后立即返回。d <= 0 时不限制
+func WithTimeout(tool Tool, d time.Duration) Tool {
+	return &timeoutTool{Tool: tool, timeout: d}
+}
+
+// Execute 在超时 context 中执行被包装的工具
+func (t *timeoutTool) Execute(ctx context.Context, params Parameters) (Result, error) {
+	if t.timeout <= 0 {
+		return t.Tool.Execute(ctx, params)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	type outcome struct {
+		result Result
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := t.Tool.Execute(ctx, params)
+		done <- outcome{result, err}
+	}()
+
+	select {
+	case o := <-done:
+		// 工具自行响应了超时，统一转换为超时错误
+		if o.err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, ErrTimeout(t.Info().Name).WithCause(o.err)
+		}
+		return o.result, o.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, ErrTimeout(t.Info().Name).WithCause(ctx.Err())
+		}
+		return nil, ErrCancelled(t.Info().Name).WithCause(ctx.Err())
+	}
+}
+
+// NeedsPermission 保留被包装工具的权限判断，使装饰器可以和权限检查组合使用
+func (t *timeoutTool) NeedsPermission(params Parameters) bool {
+	return NeedsPermission(t.Tool, params)
+}
+
+// Unwrap 返回被包装的工具
+func (t *timeoutTool) Unwrap() Tool {
+	return t.Tool
+}
+
+// retryTool 遇到可重试错误时重新执行的装饰器
+type retryTool struct {
+	Tool
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// WithRetry 包装工具，返回的错误可重试（IsRetryable）时重新执行，最多执行 maxAttempts 次。
+// 第一次重试前等待 backoff，之后每次加倍；权限拒绝和不可重试的错误立即返回
+func WithRetry(tool Tool, maxAttempts int, backoff time.Duration) Tool {
+	return &retryTool{Tool: tool, maxAttempts: maxAttempts, backoff: backoff}
+}
+
+// Execute 执行被包装的工具，必要时重试
+func (t *retryTool) Execute(ctx context.Context, params Parameters) (Result, error) {
+	delay := t.backoff
+	for attempt := 1; ; attempt++ {
+		result, err := t.Tool.Execute(ctx, params)
+		if err == nil || attempt >= t.maxAttempts || !shouldRetry(err) {
+			return result, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// shouldRetry 判断错误是否值得重试，权限拒绝即使标记为可重试也不重试
+func shouldRetry(err error) bool {
+	return IsRetryable(err) && GetErrorCode(err) != ErrCodePermissionDenied
+}
+
+// NeedsPermission 保留被包装工具的权限判断
+func (t *retryTool) NeedsPermission(params Parameters) bool {
+	return NeedsPermission(t.Tool, params)
+}
+
+// Unwrap 返回被包装的工具
+func (t *retryTool) Unwrap() Tool {
+	return t.Tool
+}
+
+// MutatingTool 可以按参数判断本次执行是否会修改文件、执行命令等产生副作用的工具
+type MutatingTool interface {
+	Tool
+	Mutates(params Parameters) bool
+}
+
+// Mutates 判断工具本次执行是否会产生副作用：逐层查找实现了 MutatingTool 的工具，
+// 都没有实现时视需要权限的调用为有副作用
+func Mutates(tool Tool, params Parameters) bool {
+	for t := tool; ; {
+		if mutating, ok := t.(MutatingTool); ok {
+			return mutating.Mutates(params)
+		}
+		wrapper, ok := t.(interface{ Unwrap() Tool })
+		if !ok {
+			break
+		}
+		t = wrapper.Unwrap()
+	}
+	return tool.Info().RequiresPerm && NeedsPermission(tool, params)
+}
+
+// dryRunTool 只报告将要执行的操作、不产生副作用的装饰器
+type dryRunTool struct {
+	Tool
+}
+
+// WithDryRun 包装工具，有副作用的调用（见 Mutates）只校验参数并返回模拟的成功结果，
+// 不修改文件也不执行命令；只读的调用照常执行
+func WithDryRun(tool Tool) Tool {
+	return &dryRunTool{Tool: tool}
+}
+
+// Execute 只读调用照常执行，有副作用的调用返回将要执行的操作
+func (t *dryRunTool) Execute(ctx context.Context, params Parameters) (Result, error) {
+	if !Mutates(t.Tool, params) {
+		return t.Tool.Execute(ctx, params)
+	}
+
+	// 在填充默认值之前记录模型给出的参数
+	args := dryRunArgs(params)
+	name := t.Info().Name
+	if err := params.Validate(t.Schema()); err != nil {
+		return nil, ErrInvalidParams(name, err.Error())
+	}
+
+	result := NewSimpleResult(fmt.Sprintf("[dry-run] Would execute %s with %s; nothing was changed", name, args))
+	result.WithMetadata("dry_run", true)
+	return result, nil
+}
+
+// dryRunMaxArgs 模拟结果中参数的最大长度，避免回显大段写入内容
+const dryRunMaxArgs = 300
+
+// dryRunArgs 以 JSON 描述调用参数，过长时截断
+func dryRunArgs(params Parameters) string {
+	data, err := json.Marshal(params.Raw())
+	if err != nil {
+		return "{}"
+	}
+	if len(data) > dryRunMaxArgs {
+		// 不在多字节字符中间截断
+		n := dryRunMaxArgs
+		for n > 0 && !utf8.RuneStart(data[n]) {
+			n--
+		}
+		return string(data[:n]) + "..."
+	}
+	return string(data)
+}
+
+// NeedsPermission 有副作用的调用不会真正执行，无需请求权限
+func (t *dryRunTool) NeedsPermission(params Parameters) bool {
+	if Mutates(t.Tool, params) {
+		return false
+	}
+	return NeedsPermission(t.Tool, params)
+}
+
+// Unwrap 返回被包装的工具
+func (t *dryRunTool) Unwrap() Tool {
+	return t.Tool
+}
+
+// MetricsCollector 工具执行指标收集接口
+type MetricsCollector interface {
+	// RecordExecution 记录一次执行的工具名、耗时和错误（成功时为 nil）
+	RecordExecution(name string, dur time.Duration, err error)
+}
+
+// metricsTool 记录执行指标的装饰器
+type metricsTool struct {
+	Tool
+	collector MetricsCollector
+}
+
+// WithMetrics 包装工具，每次执行后将耗时和结果报告给 collector
+func WithMetrics(tool Tool, collector MetricsCollector) Tool {
+	return &metricsTool{Tool: tool, collector: collector}
+}
+
+// Execute 执行被包装的工具并记录指标
+func (t *metricsTool) Execute(ctx context.Context, params Parameters) (Result, error) {
+	start := time.Now()
+	result, err := t.Tool.Execute(ctx, params)
+	t.collector.RecordExecution(t.Info().Name, time.Since(start), err)
+	return result, err
+}
+
+// NeedsPermission 保留被包装工具的权限判断
+func (t *metricsTool) NeedsPermission(params Parameters) bool {
+	return NeedsPermission(t.Tool, params)
+}
+
+// Unwrap 返回被包装的工具
+func (t *metricsTool) Unwrap() Tool {
+	return t.Tool
+}
+
+// UnwrapTool 逐层解开装饰器，返回最内层的工具
+func UnwrapTool(tool Tool) Tool {
+	for {
+		wrapper, ok := tool.(interface{ Unwrap() Tool })
+		if !ok {
+			return tool
+		}
+		tool = wrapper.Unwrap()
+	}
+}
+
+// NeedsPermission 判断工具本次执行是否需要权限：实现了 ConditionalPermissionTool 的工具
+// 按参数判断，否则取决于 Info().RequiresPerm
+func NeedsPermission(tool Tool, params Parameters) bool {
+	if conditional, ok := tool.(ConditionalPermissionTool); ok {
+		return conditional.NeedsPermission(params)
+	}
+	return tool.Info().RequiresPerm
+}
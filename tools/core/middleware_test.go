@@ -0,0 +1,340 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// slowTool 等待 delay 后返回的测试工具，respectCtx 为 false 时忽略 context 取消
+type slowTool struct {
+	*BaseTool
+	delay      time.Duration
+	respectCtx bool
+}
+
+func newSlowTool(delay time.Duration, respectCtx bool) *slowTool {
+	return &slowTool{BaseTool: NewBaseTool("slow", "test", "Slow test tool"), delay: delay, respectCtx: respectCtx}
+}
+
+func (t *slowTool) Execute(ctx context.Context, params Parameters) (Result, error) {
+	if !t.respectCtx {
+		time.Sleep(t.delay)
+		return NewSimpleResult("done"), nil
+	}
+	select {
+	case <-time.After(t.delay):
+		return NewSimpleResult("done"), nil
+	case <-ctx.Done():
+		return nil, ErrCancelled(t.Info().Name)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		tool     Tool
+		timeout  time.Duration
+		wantCode string
+	}{
+		{"按时完成", newSlowTool(time.Millisecond, true), time.Second, ""},
+		{"不限制超时", newSlowTool(time.Millisecond, true), 0, ""},
+		{"响应 context 的工具超时", newSlowTool(time.Second, true), 20 * time.Millisecond, ErrCodeTimeout},
+		{"忽略 context 的工具超时", newSlowTool(200*time.Millisecond, false), 20 * time.Millisecond, ErrCodeTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := WithTimeout(tt.tool, tt.timeout)
+			if wrapped.Info().Name != "slow" {
+				t.Errorf("Info().Name = %q, want slow", wrapped.Info().Name)
+			}
+
+			start := time.Now()
+			result, err := wrapped.Execute(context.Background(), NewMapParameters(nil))
+			elapsed := time.Since(start)
+
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("Execute() error = %v", err)
+				}
+				if result.String() != "done" {
+					t.Errorf("result = %q, want done", result.String())
+				}
+				return
+			}
+
+			if GetErrorCode(err) != tt.wantCode {
+				t.Fatalf("错误代码 = %q, want %q (err = %v)", GetErrorCode(err), tt.wantCode, err)
+			}
+			if !IsRetryable(err) {
+				t.Error("超时错误应可重试")
+			}
+			if elapsed > 150*time.Millisecond {
+				t.Errorf("超时后未及时返回, 耗时 %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestWithTimeout_ParentCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := WithTimeout(newSlowTool(time.Second, false), time.Second).Execute(ctx, NewMapParameters(nil))
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Code != ErrCodeCancelled {
+		t.Errorf("error = %v, want cancelled", err)
+	}
+}
+
+// flakyTool 前 failures 次执行返回 err，之后成功，并记录执行次数
+type flakyTool struct {
+	*BaseTool
+	failures int
+	err      error
+	calls    int
+}
+
+func newFlakyTool(failures int, err error) *flakyTool {
+	return &flakyTool{BaseTool: NewBaseTool("flaky", "test", "Flaky test tool"), failures: failures, err: err}
+}
+
+func (t *flakyTool) Execute(ctx context.Context, params Parameters) (Result, error) {
+	t.calls++
+	if t.calls <= t.failures {
+		return nil, t.err
+	}
+	return NewSimpleResult("ok"), nil
+}
+
+func TestWithRetry(t *testing.T) {
+	tests := []struct {
+		name        string
+		failures    int
+		err         error
+		maxAttempts int
+		wantCalls   int
+		wantErr     bool
+	}{
+		{
+			name:        "可重试错误两次后成功",
+			failures:    2,
+			err:         ErrTimeout("flaky"),
+			maxAttempts: 3,
+			wantCalls:   3,
+		},
+		{
+			name:        "达到最大次数后放弃",
+			failures:    5,
+			err:         ErrExecutionFailed("flaky", "fetch failed").WithRetryable(true),
+			maxAttempts: 3,
+			wantCalls:   3,
+			wantErr:     true,
+		},
+		{
+			name:        "不可重试的错误立即返回",
+			failures:    2,
+			err:         ErrInvalidParams("flaky", "bad path"),
+			maxAttempts: 3,
+			wantCalls:   1,
+			wantErr:     true,
+		},
+		{
+			name:        "普通错误不重试",
+			failures:    2,
+			err:         errors.New("boom"),
+			maxAttempts: 3,
+			wantCalls:   1,
+			wantErr:     true,
+		},
+		{
+			name:        "权限拒绝即使标记为可重试也不重试",
+			failures:    2,
+			err:         ErrPermissionDenied("flaky", "write").WithRetryable(true),
+			maxAttempts: 3,
+			wantCalls:   1,
+			wantErr:     true,
+		},
+		{
+			name:        "maxAttempts 为 1 时只执行一次",
+			failures:    1,
+			err:         ErrTimeout("flaky"),
+			maxAttempts: 1,
+			wantCalls:   1,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := newFlakyTool(tt.failures, tt.err)
+			wrapped := WithRetry(tool, tt.maxAttempts, time.Millisecond)
+
+			result, err := wrapped.Execute(context.Background(), NewMapParameters(nil))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tool.calls != tt.wantCalls {
+				t.Errorf("执行次数 = %d, want %d", tool.calls, tt.wantCalls)
+			}
+			if err != nil && err != tt.err {
+				t.Errorf("应返回最后一次的错误, got %v", err)
+			}
+			if !tt.wantErr && result.String() != "ok" {
+				t.Errorf("result = %q, want ok", result.String())
+			}
+		})
+	}
+}
+
+func TestWithRetry_StopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	tool := newFlakyTool(5, ErrTimeout("flaky"))
+	wrapped := WithRetry(&cancelOnExecute{Tool: tool, cancel: cancel}, 5, time.Hour)
+
+	start := time.Now()
+	if _, err := wrapped.Execute(ctx, NewMapParameters(nil)); err == nil {
+		t.Fatal("Execute() 期望返回错误")
+	}
+	if tool.calls != 1 {
+		t.Errorf("取消后不应继续重试, 执行次数 = %d", tool.calls)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("取消后未及时返回, 耗时 %v", elapsed)
+	}
+}
+
+// cancelOnExecute 执行被包装的工具后取消 context
+type cancelOnExecute struct {
+	Tool
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnExecute) Execute(ctx context.Context, params Parameters) (Result, error) {
+	defer c.cancel()
+	return c.Tool.Execute(ctx, params)
+}
+
+// sideEffectTool 执行时记录是否被调用的测试工具
+type sideEffectTool struct {
+	*BaseTool
+	executed bool
+}
+
+func newSideEffectTool(requiresPerm bool) *sideEffectTool {
+	tool := &sideEffectTool{BaseTool: NewBaseTool("side_effect", "test", "Side effect test tool")}
+	tool.SetRequiresPerm(requiresPerm)
+	tool.SetSchema(ParameterSchema{
+		Type: "object",
+		Properties: map[string]PropertySchema{
+			"path": {Type: "string"},
+		},
+		Required: []string{"path"},
+	})
+	return tool
+}
+
+func (t *sideEffectTool) Execute(ctx context.Context, params Parameters) (Result, error) {
+	t.executed = true
+	return NewSimpleResult("executed"), nil
+}
+
+// readOnlyTool 需要权限但声明没有副作用的测试工具
+type readOnlyTool struct {
+	*sideEffectTool
+}
+
+func (t *readOnlyTool) Mutates(params Parameters) bool {
+	return false
+}
+
+func TestWithDryRun(t *testing.T) {
+	tests := []struct {
+		name         string
+		tool         *sideEffectTool
+		wrap         func(*sideEffectTool) Tool
+		params       map[string]any
+		wantExecuted bool
+		wantDryRun   bool
+		wantErr      bool
+	}{
+		{
+			name:       "需要权限的调用只报告不执行",
+			tool:       newSideEffectTool(true),
+			params:     map[string]any{"path": "a.txt"},
+			wantDryRun: true,
+		},
+		{
+			name:         "不需要权限的调用照常执行",
+			tool:         newSideEffectTool(false),
+			params:       map[string]any{"path": "a.txt"},
+			wantExecuted: true,
+		},
+		{
+			name:         "声明没有副作用的工具照常执行",
+			tool:         newSideEffectTool(true),
+			wrap:         func(t *sideEffectTool) Tool { return &readOnlyTool{t} },
+			params:       map[string]any{"path": "a.txt"},
+			wantExecuted: true,
+		},
+		{
+			name:       "透过其他装饰器判断副作用",
+			tool:       newSideEffectTool(true),
+			wrap:       func(t *sideEffectTool) Tool { return WithTimeout(t, time.Second) },
+			params:     map[string]any{"path": "a.txt"},
+			wantDryRun: true,
+		},
+		{
+			name:    "参数无效时仍然报错",
+			tool:    newSideEffectTool(true),
+			params:  map[string]any{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inner Tool = tt.tool
+			if tt.wrap != nil {
+				inner = tt.wrap(tt.tool)
+			}
+			wrapped := WithDryRun(inner)
+
+			result, err := wrapped.Execute(context.Background(), NewMapParameters(tt.params))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.tool.executed != tt.wantExecuted {
+				t.Errorf("executed = %v, want %v", tt.tool.executed, tt.wantExecuted)
+			}
+			if tt.wantErr {
+				return
+			}
+			if (result.Metadata()["dry_run"] == true) != tt.wantDryRun {
+				t.Errorf("dry_run = %v, want %v", result.Metadata()["dry_run"], tt.wantDryRun)
+			}
+			if tt.wantDryRun {
+				if !strings.Contains(result.String(), `Would execute side_effect with {"path":"a.txt"}`) {
+					t.Errorf("结果未说明将要执行的操作: %s", result.String())
+				}
+				if NeedsPermission(wrapped, NewMapParameters(tt.params)) {
+					t.Error("dry run 的调用不应请求权限")
+				}
+			}
+		})
+	}
+}
+
+func TestDryRunArgs_Truncates(t *testing.T) {
+	args := dryRunArgs(NewMapParameters(map[string]any{"content": strings.Repeat("中", 200)}))
+	if !strings.HasSuffix(args, "...") {
+		t.Errorf("过长的参数应被截断: %s", args)
+	}
+	if !utf8.ValidString(args) {
+		t.Errorf("截断后不应出现无效的 UTF-8: %q", args)
+	}
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -13,6 +14,8 @@ type ToolRegistry struct {
 	aliases    map[string]string
 	categories map[string][]Tool
 	tagIndex   map[string][]Tool
+	metrics    MetricsCollector // 非 nil 时注册的工具会被 WithMetrics 包装
+	disabled   map[string]bool  // 禁用的工具名和别名，这些工具不会被注册
 }
 
 // NewRegistry 创建新的注册表
@@ -25,11 +28,69 @@ func NewRegistry() *ToolRegistry {
 	}
 }
 
+// SetMetrics 设置指标收集器，之后注册的工具都会记录执行指标
+func (r *ToolRegistry) SetMetrics(collector MetricsCollector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = collector
+}
+
+// SetDisabled 设置禁用的工具（名称或别名），之后注册的这些工具会被跳过，
+// 通过 Get 获取时返回 ErrToolDisabled
+func (r *ToolRegistry) SetDisabled(names ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.disabled = make(map[string]bool, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			r.disabled[name] = true
+		}
+	}
+}
+
+// isDisabled 判断工具是否被禁用，名称或任一别名命中即视为禁用
+func (r *ToolRegistry) isDisabled(name string, aliases []string) bool {
+	if r.disabled[name] {
+		return true
+	}
+	for _, alias := range aliases {
+		if r.disabled[alias] {
+			return true
+		}
+	}
+	return false
+}
+
+// Disabled 返回被禁用的工具名称和别名，包括注册时因禁用而跳过的工具的全部别名
+func (r *ToolRegistry) Disabled() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.disabled))
+	for name := range r.disabled {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Register 注册工具
 func (r *ToolRegistry) Register(tool Tool, aliases ...string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	// 禁用的工具不注册，记下它的名称和别名，使 Get 能给出明确的错误
+	if info := tool.Info(); r.isDisabled(info.Name, aliases) {
+		r.disabled[info.Name] = true
+		for _, alias := range aliases {
+			r.disabled[alias] = true
+		}
+		return nil
+	}
+
+	if r.metrics != nil {
+		tool = WithMetrics(tool, r.metrics)
+	}
 	info := tool.Info()
 	
 	// 检查名称是否已存在
@@ -78,6 +139,9 @@ func (r *ToolRegistry) Get(name string) (Tool, error) {
 		}
 	}
 	
+	if r.disabled[name] {
+		return nil, ErrToolDisabled(name)
+	}
 	return nil, ErrToolNotFound(name)
 }
 
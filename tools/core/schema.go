@@ -0,0 +1,86 @@
+package core
+
+import "encoding/json"
+
+// JSONSchemaDraft 导出的 JSON Schema 版本
+const JSONSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// JSONSchema 转换为 JSON Schema，包括枚举、默认值、数值范围以及嵌套的元素和字段
+func (s ParameterSchema) JSONSchema() map[string]any {
+	properties := make(map[string]any, len(s.Properties))
+	for name, prop := range s.Properties {
+		properties[name] = prop.JSONSchema()
+	}
+
+	schemaType := s.Type
+	if schemaType == "" {
+		schemaType = "object"
+	}
+	return map[string]any{
+		"type":       schemaType,
+		"properties": properties,
+		"required":   s.Required,
+	}
+}
+
+// JSONSchema 转换为 JSON Schema，未设置的约束不输出
+func (p PropertySchema) JSONSchema() map[string]any {
+	schema := map[string]any{
+		"type": p.Type,
+	}
+	if p.Description != "" {
+		schema["description"] = p.Description
+	}
+	if len(p.Enum) > 0 {
+		schema["enum"] = p.Enum
+	}
+	if p.Default != nil {
+		schema["default"] = p.Default
+	}
+	if p.MinLength > 0 {
+		schema["minLength"] = p.MinLength
+	}
+	if p.MaxLength > 0 {
+		schema["maxLength"] = p.MaxLength
+	}
+	if p.Minimum != nil {
+		schema["minimum"] = *p.Minimum
+	}
+	if p.Maximum != nil {
+		schema["maximum"] = *p.Maximum
+	}
+	if p.Items != nil {
+		schema["items"] = p.Items.JSONSchema()
+	}
+	if p.MinItems > 0 {
+		schema["minItems"] = p.MinItems
+	}
+	if len(p.Properties) > 0 {
+		fields := make(map[string]any, len(p.Properties))
+		for name, field := range p.Properties {
+			fields[name] = field.JSONSchema()
+		}
+		schema["properties"] = fields
+	}
+	if len(p.Required) > 0 {
+		schema["required"] = p.Required
+	}
+	return schema
+}
+
+// MarshalSchema 将工具的参数 schema 序列化为完整的 JSON Schema 文档，
+// 以工具名作为 title、工具描述作为 description
+func MarshalSchema(tool Tool) ([]byte, error) {
+	info := tool.Info()
+	doc := tool.Schema().JSONSchema()
+	doc["$schema"] = JSONSchemaDraft
+	doc["title"] = info.Name
+	if info.Description != "" {
+		doc["description"] = info.Description
+	}
+	if len(tool.Schema().Required) == 0 {
+		// 输出 [] 而不是 null
+		doc["required"] = []string{}
+	}
+	return json.Marshal(doc)
+}
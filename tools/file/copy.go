@@ -0,0 +1,238 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"opencode_nano/tools/core"
+)
+
+// CopyTool 文件复制工具
+type CopyTool struct {
+	*core.BaseTool
+}
+
+// NewCopyTool 创建复制工具
+func NewCopyTool() *CopyTool {
+	tool := &CopyTool{
+		BaseTool: core.NewBaseTool("copy", "file", "Copy a file or recursively copy a directory"),
+	}
+
+	tool.SetRequiresPerm(true)
+	tool.SetTags("file", "copy", "duplicate")
+	tool.SetSchema(core.ParameterSchema{
+		Type: "object",
+		Properties: map[string]core.PropertySchema{
+			"src": {
+				Type:        "string",
+				Description: "Source file or directory",
+			},
+			"dst": {
+				Type:        "string",
+				Description: "Destination path",
+			},
+			"overwrite": {
+				Type:        "boolean",
+				Description: "Overwrite existing files",
+				Default:     false,
+			},
+			"preserve_mode": {
+				Type:        "boolean",
+				Description: "Preserve file permissions",
+				Default:     false,
+			},
+		},
+		Required: []string{"src", "dst"},
+	})
+
+	return tool
+}
+
+// copyEntry 待复制的单个条目
+type copyEntry struct {
+	src, dst string
+	mode     fs.FileMode
+	isDir    bool
+}
+
+// Execute 执行复制
+func (t *CopyTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	// 参数验证
+	if err := params.Validate(t.Schema()); err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+
+	src, err := params.GetString("src")
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, "invalid src parameter")
+	}
+
+	dst, err := params.GetString("dst")
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, "invalid dst parameter")
+	}
+
+	overwrite := false
+	if params.Has("overwrite") {
+		overwrite, _ = params.GetBool("overwrite")
+	}
+
+	preserveMode := false
+	if params.Has("preserve_mode") {
+		preserveMode, _ = params.GetBool("preserve_mode")
+	}
+
+	src = filepath.Clean(src)
+	dst = filepath.Clean(dst)
+
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("source not found: %s", src))
+		}
+		return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+	}
+
+	// 目标与源是同一文件，或目录复制到自身内部时拒绝，避免截断源文件或无限递归
+	if dstInfo, err := os.Stat(dst); err == nil && os.SameFile(srcInfo, dstInfo) {
+		return nil, core.ErrInvalidParams(t.Info().Name, fmt.Sprintf("destination is the same as source: %s", dst))
+	}
+	if srcInfo.IsDir() {
+		srcPath, err := resolvePath(src)
+		if err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+		}
+		dstPath, err := resolvePath(dst)
+		if err != nil {
+			dstPath, err = resolveEntry(dst)
+		}
+		if err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+		}
+		if isWithin(dstPath, srcPath) {
+			return nil, core.ErrInvalidParams(t.Info().Name, fmt.Sprintf("cannot copy %s into itself", src))
+		}
+	}
+
+	// 先收集所有条目并检查冲突，避免复制到一半才失败
+	entries, err := collectCopyEntries(src, dst, srcInfo)
+	if err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+	}
+
+	if !overwrite {
+		for _, entry := range entries {
+			if entry.isDir {
+				continue
+			}
+			if _, err := os.Stat(entry.dst); err == nil {
+				return nil, core.ErrExecutionFailed(t.Info().Name,
+					fmt.Sprintf("destination already exists: %s (set overwrite to replace)", entry.dst))
+			}
+		}
+	}
+
+	var bytesCopied int64
+	filesCopied := 0
+	for _, entry := range entries {
+		select {
+		case <-ctx.Done():
+			return nil, core.ErrCancelled(t.Info().Name)
+		default:
+		}
+
+		if entry.isDir {
+			if err := os.MkdirAll(entry.dst, dirMode(entry.mode, preserveMode)); err != nil {
+				return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to create directory: %v", err))
+			}
+			continue
+		}
+
+		n, err := copyFileContents(entry.src, entry.dst, fileMode(entry.mode, preserveMode))
+		if err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to copy %s: %v", entry.src, err))
+		}
+		bytesCopied += n
+		filesCopied++
+	}
+
+	result := core.NewSimpleResult(fmt.Sprintf("Successfully copied %s to %s (%d files, %d bytes)", src, dst, filesCopied, bytesCopied))
+	result.WithMetadata("src", src)
+	result.WithMetadata("dst", dst)
+	result.WithMetadata("files_copied", filesCopied)
+	result.WithMetadata("bytes_copied", bytesCopied)
+
+	return result, nil
+}
+
+// collectCopyEntries 列出需要复制的文件和目录，目录排在其内容之前
+func collectCopyEntries(src, dst string, srcInfo fs.FileInfo) ([]copyEntry, error) {
+	if !srcInfo.IsDir() {
+		return []copyEntry{{src: src, dst: dst, mode: srcInfo.Mode()}}, nil
+	}
+
+	var entries []copyEntry
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		// 跳过符号链接、设备文件等非常规文件
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, copyEntry{
+			src:   path,
+			dst:   filepath.Join(dst, rel),
+			mode:  info.Mode(),
+			isDir: info.IsDir(),
+		})
+		return nil
+	})
+	return entries, err
+}
+
+// copyFileContents 复制单个文件，先写入临时文件再重命名，自动创建父目录
+func copyFileContents(src, dst string, mode fs.FileMode) (int64, error) {
+	source, err := os.Open(src)
+	if err != nil {
+		return 0, err
+	}
+	defer source.Close()
+
+	return writeStreamAtomic(dst, mode, func(w io.Writer) error {
+		_, err := io.Copy(w, source)
+		return err
+	})
+}
+
+// fileMode 返回目标文件的权限
+func fileMode(mode fs.FileMode, preserve bool) fs.FileMode {
+	if preserve {
+		return mode.Perm()
+	}
+	return 0644
+}
+
+// dirMode 返回目标目录的权限
+func dirMode(mode fs.FileMode, preserve bool) fs.FileMode {
+	if preserve {
+		return mode.Perm()
+	}
+	return 0755
+}
@@ -0,0 +1,226 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+func TestCopyTool_File(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "src.txt")
+	if err := os.WriteFile(src, []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		params    map[string]any
+		existing  string // 目标文件已有内容，为空表示不存在
+		wantErr   bool
+		wantData  string
+		wantPerm  os.FileMode
+		wantBytes int64
+	}{
+		{
+			name:      "复制到新路径并创建父目录",
+			params:    map[string]any{"dst": filepath.Join(tmpDir, "a", "b", "dst.txt")},
+			wantData:  "hello",
+			wantPerm:  0644,
+			wantBytes: 5,
+		},
+		{
+			name:     "目标已存在且未允许覆盖",
+			params:   map[string]any{"dst": filepath.Join(tmpDir, "exists.txt")},
+			existing: "old",
+			wantErr:  true,
+			wantData: "old",
+		},
+		{
+			name:      "允许覆盖",
+			params:    map[string]any{"dst": filepath.Join(tmpDir, "overwrite.txt"), "overwrite": true},
+			existing:  "old content",
+			wantData:  "hello",
+			wantPerm:  0644,
+			wantBytes: 5,
+		},
+		{
+			name:      "保留权限",
+			params:    map[string]any{"dst": filepath.Join(tmpDir, "mode.txt"), "preserve_mode": true},
+			wantData:  "hello",
+			wantPerm:  0600,
+			wantBytes: 5,
+		},
+	}
+
+	tool := NewCopyTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := tt.params["dst"].(string)
+			if tt.existing != "" {
+				if err := os.WriteFile(dst, []byte(tt.existing), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			tt.params["src"] = src
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(tt.params))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			data, _ := os.ReadFile(dst)
+			if string(data) != tt.wantData {
+				t.Errorf("目标文件内容 = %q, want %q", data, tt.wantData)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			info, err := os.Stat(dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != tt.wantPerm {
+				t.Errorf("目标文件权限 = %v, want %v", info.Mode().Perm(), tt.wantPerm)
+			}
+			if result.Metadata()["bytes_copied"] != tt.wantBytes {
+				t.Errorf("bytes_copied = %v, want %d", result.Metadata()["bytes_copied"], tt.wantBytes)
+			}
+			if result.Metadata()["files_copied"] != 1 {
+				t.Errorf("files_copied = %v, want 1", result.Metadata()["files_copied"])
+			}
+		})
+	}
+}
+
+func TestCopyTool_Directory(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "src")
+	files := map[string]string{
+		"a.txt":          "aaa",
+		"sub/b.txt":      "bb",
+		"sub/deep/c.txt": "c",
+		"empty/.gitkeep": "",
+	}
+	for rel, content := range files {
+		path := filepath.Join(src, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tool := NewCopyTool()
+	dst := filepath.Join(tmpDir, "dst")
+	result, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"src": src,
+		"dst": dst,
+	}))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	for rel, content := range files {
+		data, err := os.ReadFile(filepath.Join(dst, rel))
+		if err != nil {
+			t.Errorf("缺少复制的文件 %s: %v", rel, err)
+			continue
+		}
+		if string(data) != content {
+			t.Errorf("%s 内容 = %q, want %q", rel, data, content)
+		}
+	}
+	if result.Metadata()["files_copied"] != len(files) {
+		t.Errorf("files_copied = %v, want %d", result.Metadata()["files_copied"], len(files))
+	}
+	if result.Metadata()["bytes_copied"] != int64(6) {
+		t.Errorf("bytes_copied = %v, want 6", result.Metadata()["bytes_copied"])
+	}
+
+	// 再次复制时目标已存在，未允许覆盖应失败且不修改文件
+	if err := os.WriteFile(filepath.Join(src, "a.txt"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"src": src,
+		"dst": dst,
+	}))
+	if err == nil {
+		t.Fatal("目标已存在时应返回错误")
+	}
+	data, _ := os.ReadFile(filepath.Join(dst, "a.txt"))
+	if string(data) != "aaa" {
+		t.Errorf("覆盖保护失败，a.txt = %q", data)
+	}
+
+	// 允许覆盖后应成功
+	_, err = tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"src":       src,
+		"dst":       dst,
+		"overwrite": true,
+	}))
+	if err != nil {
+		t.Fatalf("Execute(overwrite) error = %v", err)
+	}
+	data, _ = os.ReadFile(filepath.Join(dst, "a.txt"))
+	if string(data) != "changed" {
+		t.Errorf("覆盖后 a.txt = %q, want %q", data, "changed")
+	}
+}
+
+func TestCopyTool_RequiresPermission(t *testing.T) {
+	if !NewCopyTool().Info().RequiresPerm {
+		t.Error("copy 工具应需要权限")
+	}
+}
+
+func TestCopyTool_RejectsSameOrNestedDestination(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("创建符号链接需要额外权限")
+	}
+
+	tests := []struct {
+		name string
+		src  string // 相对临时目录
+		dst  string
+	}{
+		{"复制到自身", "proj/a.txt", "proj/a.txt"},
+		{"经过符号链接复制到自身", "proj/a.txt", "link.txt"},
+		{"目录复制到自身", "proj", "proj"},
+		{"目录复制到自身的子目录", "proj", "proj/backup"},
+	}
+
+	tool := NewCopyTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			file := filepath.Join(tmpDir, "proj", "a.txt")
+			writeTestFile(t, file, "important")
+			if err := os.Symlink(file, filepath.Join(tmpDir, "link.txt")); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+				"src":       filepath.Join(tmpDir, tt.src),
+				"dst":       filepath.Join(tmpDir, tt.dst),
+				"overwrite": true,
+			}))
+			if core.GetErrorCode(err) != core.ErrCodeInvalidParams {
+				t.Errorf("error = %v, want invalid params", err)
+			}
+			if data, _ := os.ReadFile(file); string(data) != "important" {
+				t.Errorf("源文件被修改: %q", data)
+			}
+			if _, err := os.Stat(filepath.Join(tmpDir, "proj", "backup")); !os.IsNotExist(err) {
+				t.Error("不应在源目录内创建副本")
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"opencode_nano/tools/core"
+)
+
+// DeleteTool 文件删除工具
+type DeleteTool struct {
+	*core.BaseTool
+}
+
+// NewDeleteTool 创建删除工具
+func NewDeleteTool() *DeleteTool {
+	tool := &DeleteTool{
+		BaseTool: core.NewBaseTool("delete", "file", "Delete a file or directory"),
+	}
+
+	tool.SetRequiresPerm(true)
+	tool.SetTags("file", "delete", "remove")
+	tool.SetSchema(core.ParameterSchema{
+		Type: "object",
+		Properties: map[string]core.PropertySchema{
+			"path": {
+				Type:        "string",
+				Description: "File or directory to delete",
+			},
+			"recursive": {
+				Type:        "boolean",
+				Description: "Delete directories and their contents recursively",
+				Default:     false,
+			},
+		},
+		Required: []string{"path"},
+	})
+
+	return tool
+}
+
+// Execute 执行删除
+func (t *DeleteTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	// 参数验证
+	if err := params.Validate(t.Schema()); err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+
+	path, err := params.GetString("path")
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, "invalid path parameter")
+	}
+
+	recursive := false
+	if params.Has("recursive") {
+		recursive, _ = params.GetBool("recursive")
+	}
+
+	path = filepath.Clean(path)
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("path not found: %s", path))
+		}
+		return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+	}
+
+	if err := checkProtectedPath(path); err != nil {
+		return nil, core.ErrPermissionDenied(t.Info().Name, err.Error())
+	}
+
+	var removed []string
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to read directory: %v", err))
+		}
+		if len(entries) > 0 && !recursive {
+			return nil, core.ErrExecutionFailed(t.Info().Name,
+				fmt.Sprintf("directory is not empty: %s (set recursive to delete)", path))
+		}
+
+		// 先记录将被删除的路径，子路径在前
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			removed = append(removed, p)
+			return nil
+		})
+		if err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to scan directory: %v", err))
+		}
+		for i, j := 0, len(removed)-1; i < j; i, j = i+1, j-1 {
+			removed[i], removed[j] = removed[j], removed[i]
+		}
+
+		if err := os.RemoveAll(path); err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to delete: %v", err))
+		}
+	} else {
+		if err := os.Remove(path); err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to delete: %v", err))
+		}
+		removed = []string{path}
+	}
+
+	result := core.NewSimpleResult(fmt.Sprintf("Successfully deleted %s (%d paths removed)", path, len(removed)))
+	result.WithMetadata("path", path)
+	result.WithMetadata("removed", removed)
+	result.WithMetadata("count", len(removed))
+
+	return result, nil
+}
+
+// checkProtectedPath 拒绝删除文件系统根目录和用户主目录
+func checkProtectedPath(path string) error {
+	resolved, err := resolvePath(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path: %v", err)
+	}
+
+	if filepath.Dir(resolved) == resolved {
+		return fmt.Errorf("refusing to delete filesystem root: %s", path)
+	}
+
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		if home, err := resolvePath(homeDir); err == nil && home == resolved {
+			return fmt.Errorf("refusing to delete home directory: %s", path)
+		}
+	}
+
+	return nil
+}
+
+// resolvePath 返回解析符号链接后的绝对路径
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", err
+	}
+	return resolved, nil
+}
@@ -0,0 +1,147 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+func TestDeleteTool_Execute(t *testing.T) {
+	tests := []struct {
+		name      string
+		setup     func(t *testing.T, dir string) string // 返回要删除的路径
+		recursive bool
+		wantErr   bool
+		wantCount int
+	}{
+		{
+			name: "删除文件",
+			setup: func(t *testing.T, dir string) string {
+				path := filepath.Join(dir, "a.txt")
+				writeTestFile(t, path, "a")
+				return path
+			},
+			wantCount: 1,
+		},
+		{
+			name: "删除空目录",
+			setup: func(t *testing.T, dir string) string {
+				path := filepath.Join(dir, "empty")
+				if err := os.Mkdir(path, 0755); err != nil {
+					t.Fatal(err)
+				}
+				return path
+			},
+			wantCount: 1,
+		},
+		{
+			name: "非递归删除非空目录",
+			setup: func(t *testing.T, dir string) string {
+				path := filepath.Join(dir, "full")
+				writeTestFile(t, filepath.Join(path, "a.txt"), "a")
+				return path
+			},
+			wantErr: true,
+		},
+		{
+			name: "递归删除目录",
+			setup: func(t *testing.T, dir string) string {
+				path := filepath.Join(dir, "tree")
+				writeTestFile(t, filepath.Join(path, "a.txt"), "a")
+				writeTestFile(t, filepath.Join(path, "sub", "b.txt"), "b")
+				return path
+			},
+			recursive: true,
+			wantCount: 4, // tree、tree/a.txt、tree/sub、tree/sub/b.txt
+		},
+		{
+			name: "路径不存在",
+			setup: func(t *testing.T, dir string) string {
+				return filepath.Join(dir, "missing")
+			},
+			wantErr: true,
+		},
+	}
+
+	tool := NewDeleteTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := tt.setup(t, t.TempDir())
+
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+				"path":      path,
+				"recursive": tt.recursive,
+			}))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("删除后路径仍存在: %s", path)
+			}
+			if result.Metadata()["count"] != tt.wantCount {
+				t.Errorf("count = %v, want %d", result.Metadata()["count"], tt.wantCount)
+			}
+			removed, _ := result.Metadata()["removed"].([]string)
+			if len(removed) != tt.wantCount {
+				t.Errorf("removed = %v, want %d 项", removed, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestDeleteTool_ProtectedPaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeTestFile(t, filepath.Join(home, "keep.txt"), "keep")
+
+	// 指向主目录的符号链接也应被拒绝
+	link := filepath.Join(t.TempDir(), "home-link")
+	if err := os.Symlink(home, link); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "文件系统根目录", path: "/"},
+		{name: "用户主目录", path: home},
+		{name: "带尾部斜杠的主目录", path: home + "/"},
+		{name: "指向主目录的符号链接", path: link},
+	}
+
+	tool := NewDeleteTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+				"path":      tt.path,
+				"recursive": true,
+			}))
+			if err == nil {
+				t.Fatalf("删除 %s 应被拒绝", tt.path)
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(home, "keep.txt")); err != nil {
+		t.Errorf("主目录内容被删除: %v", err)
+	}
+}
+
+// writeTestFile 写入测试文件，自动创建父目录
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
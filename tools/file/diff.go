@@ -0,0 +1,364 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"opencode_nano/tools/core"
+)
+
+// DiffTool 比较两个文件并生成 unified diff 的工具
+type DiffTool struct {
+	*core.BaseTool
+}
+
+// NewDiffTool 创建 diff 工具
+func NewDiffTool() *DiffTool {
+	tool := &DiffTool{
+		BaseTool: core.NewBaseTool("diff", "file", "Compare two files and produce a unified diff"),
+	}
+
+	tool.SetTags("file", "diff", "compare")
+	tool.SetSchema(core.ParameterSchema{
+		Type: "object",
+		Properties: map[string]core.PropertySchema{
+			"path_a": {
+				Type:        "string",
+				Description: "Original file path",
+			},
+			"path_b": {
+				Type:        "string",
+				Description: "Modified file path",
+			},
+			"path": {
+				Type:        "string",
+				Description: "File to compare against content (alternative to path_a/path_b)",
+			},
+			"content": {
+				Type:        "string",
+				Description: "New content to compare with the file at path",
+			},
+			"context_lines": {
+				Type:        "integer",
+				Description: "Number of context lines around changes (default: 3)",
+				Default:     3,
+				Minimum:     core.Bound(0),
+			},
+		},
+	})
+
+	return tool
+}
+
+// Execute 生成 unified diff
+func (t *DiffTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	// 参数验证
+	if err := params.Validate(t.Schema()); err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+
+	contextLines := 3
+	if params.Has("context_lines") {
+		contextLines, _ = params.GetInt("context_lines")
+	}
+
+	var nameA, nameB, contentA, contentB string
+	switch {
+	case params.Has("path_a") && params.Has("path_b"):
+		pathA, _ := params.GetString("path_a")
+		pathB, _ := params.GetString("path_b")
+		nameA, nameB = filepath.Clean(pathA), filepath.Clean(pathB)
+
+		data, err := os.ReadFile(nameA)
+		if err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to read file: %v", err))
+		}
+		contentA = string(data)
+
+		data, err = os.ReadFile(nameB)
+		if err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to read file: %v", err))
+		}
+		contentB = string(data)
+	case params.Has("path") && params.Has("content"):
+		path, _ := params.GetString("path")
+		nameA = filepath.Clean(path)
+		nameB = nameA
+
+		data, err := os.ReadFile(nameA)
+		if err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to read file: %v", err))
+		}
+		contentA = string(data)
+		contentB, _ = params.GetString("content")
+	default:
+		return nil, core.ErrInvalidParams(t.Info().Name, "either path_a and path_b, or path and content are required")
+	}
+
+	diff, added, removed := unifiedDiff(nameA, nameB, contentA, contentB, contextLines)
+
+	result := core.NewSimpleResult(diff)
+	result.WithMetadata("path_a", nameA)
+	result.WithMetadata("path_b", nameB)
+	result.WithMetadata("added", added)
+	result.WithMetadata("removed", removed)
+	result.WithMetadata("identical", diff == "")
+
+	return result, nil
+}
+
+// diffOp 行级编辑操作
+type diffOp struct {
+	kind byte // ' '、'-'、'+'
+	line string
+}
+
+// unifiedDiff 生成 unified diff，返回 diff 文本以及新增、删除的行数。
+// 内容相同时返回空字符串。
+func unifiedDiff(nameA, nameB, a, b string, contextLines int) (string, int, int) {
+	linesA, linesB := splitLinesKeepEOL(a), splitLinesKeepEOL(b)
+	ops := diffLines(linesA, linesB)
+
+	added, removed := 0, 0
+	for _, op := range ops {
+		switch op.kind {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+	if added == 0 && removed == 0 {
+		return "", 0, 0
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", filepath.ToSlash(nameA), filepath.ToSlash(nameB))
+
+	// oldLine/newLine 为 ops[i] 之前已消耗的行数
+	oldLine, newLine := 0, 0
+	oldAt := make([]int, len(ops)+1)
+	newAt := make([]int, len(ops)+1)
+	for i, op := range ops {
+		oldAt[i], newAt[i] = oldLine, newLine
+		if op.kind != '+' {
+			oldLine++
+		}
+		if op.kind != '-' {
+			newLine++
+		}
+	}
+	oldAt[len(ops)], newAt[len(ops)] = oldLine, newLine
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+
+		// 向前扩展上下文，向后合并间隔不超过 2*contextLines 的变更
+		start := i - contextLines
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*contextLines {
+				end += min(contextLines, run-end)
+				break
+			}
+			end = run
+		}
+
+		oldCount := oldAt[end] - oldAt[start]
+		newCount := newAt[end] - newAt[start]
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(oldAt[start], oldCount), hunkRange(newAt[start], newCount))
+		for _, op := range ops[start:end] {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+
+	return sb.String(), added, removed
+}
+
+// splitLinesKeepEOL 按行拆分内容并保留每行的换行符，
+// 使缺少结尾换行的最后一行与带换行的同一行不相等
+func splitLinesKeepEOL(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// hunkRange 按 GNU diff 的格式输出 hunk 范围：行数为 1 时省略，为 0 时起始行指向插入位置之前
+func hunkRange(before, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", before)
+	case 1:
+		return fmt.Sprintf("%d", before+1)
+	default:
+		return fmt.Sprintf("%d,%d", before+1, count)
+	}
+}
+
+// diffLines 计算两组行之间的最短编辑序列，先去掉公共前后缀以减少计算量
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{kind: ' ', line: line})
+	}
+	ops = append(ops, myersDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{kind: ' ', line: line})
+	}
+	return ops
+}
+
+// myersDiff 使用线性空间的 Myers 算法计算编辑序列：每次找出最短编辑路径中间的
+// 一段对角线（middle snake），再递归处理两侧，内存只与输入长度成正比
+func myersDiff(a, b []string) []diffOp {
+	if len(a)+len(b) == 0 {
+		return nil
+	}
+	size := (len(a)+len(b)+1)/2 + 2
+	d := &myers{a: a, b: b, vf: make([]int, 2*size+1), vb: make([]int, 2*size+1), offset: size}
+	d.compare(0, len(a), 0, len(b))
+
+	// 连续的变更中删除行排在新增行之前，与 GNU diff 的输出一致
+	ops := d.ops
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		j := i
+		for j < len(ops) && ops[j].kind != ' ' {
+			j++
+		}
+		sort.SliceStable(ops[i:j], func(p, q int) bool {
+			return ops[i+p].kind == '-' && ops[i+q].kind == '+'
+		})
+		i = j
+	}
+	return ops
+}
+
+// myers 保存递归过程中复用的前向、后向 V 数组
+type myers struct {
+	a, b   []string
+	vf, vb []int
+	offset int
+	ops    []diffOp
+}
+
+// compare 生成 a[aLo:aHi] 与 b[bLo:bHi] 之间的编辑序列
+func (d *myers) compare(aLo, aHi, bLo, bHi int) {
+	x, y, u, v, dist := d.middleSnake(aLo, aHi, bLo, bHi)
+	if dist <= 1 {
+		// 编辑距离不超过 1 时较短的一方是另一方的子序列，顺序比较即可
+		i, j := aLo, bLo
+		for i < aHi || j < bHi {
+			switch {
+			case i < aHi && j < bHi && d.a[i] == d.b[j]:
+				d.ops = append(d.ops, diffOp{kind: ' ', line: d.a[i]})
+				i++
+				j++
+			case aHi-i > bHi-j:
+				d.ops = append(d.ops, diffOp{kind: '-', line: d.a[i]})
+				i++
+			default:
+				d.ops = append(d.ops, diffOp{kind: '+', line: d.b[j]})
+				j++
+			}
+		}
+		return
+	}
+
+	d.compare(aLo, aLo+x, bLo, bLo+y)
+	for _, line := range d.a[aLo+x : aLo+u] {
+		d.ops = append(d.ops, diffOp{kind: ' ', line: line})
+	}
+	d.compare(aLo+u, aHi, bLo+v, bHi)
+}
+
+// middleSnake 同时从两端搜索，返回最短编辑路径中间对角线的起点 (x, y)、
+// 终点 (u, v)（相对 aLo、bLo）以及整段的编辑距离
+func (d *myers) middleSnake(aLo, aHi, bLo, bHi int) (x, y, u, v, dist int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta&1 != 0
+	vf, vb, off := d.vf, d.vb, d.offset
+	vf[off+1], vb[off+1] = 0, 0
+
+	for k := 0; k <= (n+m+1)/2; k++ {
+		// 前向：vf[off+diag] 为对角线 diag 上能到达的最远 x
+		for diag := -k; diag <= k; diag += 2 {
+			if diag == -k || (diag != k && vf[off+diag-1] < vf[off+diag+1]) {
+				x = vf[off+diag+1]
+			} else {
+				x = vf[off+diag-1] + 1
+			}
+			y = x - diag
+			u, v = x, y
+			for u < n && v < m && d.a[aLo+u] == d.b[bLo+v] {
+				u++
+				v++
+			}
+			vf[off+diag] = u
+			// 后向对角线 delta-diag 上已有第 k-1 轮的结果
+			if odd && delta-diag >= -(k-1) && delta-diag <= k-1 && u+vb[off+delta-diag] >= n {
+				return x, y, u, v, 2*k - 1
+			}
+		}
+
+		// 后向：vb[off+diag] 为从末尾倒序比较时对角线 diag 上的最远距离
+		for diag := -k; diag <= k; diag += 2 {
+			var rx int
+			if diag == -k || (diag != k && vb[off+diag-1] < vb[off+diag+1]) {
+				rx = vb[off+diag+1]
+			} else {
+				rx = vb[off+diag-1] + 1
+			}
+			ry := rx - diag
+			sx, sy := rx, ry
+			for rx < n && ry < m && d.a[aHi-1-rx] == d.b[bHi-1-ry] {
+				rx++
+				ry++
+			}
+			vb[off+diag] = rx
+			if !odd && delta-diag >= -k && delta-diag <= k && rx+vf[off+delta-diag] >= n {
+				return n - rx, m - ry, n - sx, m - sy, 2 * k
+			}
+		}
+	}
+	// 不会到达：编辑距离不超过 n+m
+	return 0, 0, n, m, n + m
+}
@@ -0,0 +1,292 @@
+package file
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+func TestDiffTool(t *testing.T) {
+	tests := []struct {
+		name        string
+		old         string
+		new         string
+		context     any
+		wantDiff    string
+		wantAdded   int
+		wantRemoved int
+	}{
+		{
+			name:     "相同文件",
+			old:      "a\nb\nc\n",
+			new:      "a\nb\nc\n",
+			wantDiff: "",
+		},
+		{
+			name:      "插入",
+			old:       "a\nb\nc\n",
+			new:       "a\nb\nnew\nc\n",
+			wantDiff:  "@@ -1,3 +1,4 @@\n a\n b\n+new\n c\n",
+			wantAdded: 1,
+		},
+		{
+			name:        "删除",
+			old:         "a\nb\nc\nd\n",
+			new:         "a\nd\n",
+			wantDiff:    "@@ -1,4 +1,2 @@\n a\n-b\n-c\n d\n",
+			wantRemoved: 2,
+		},
+		{
+			name:        "修改",
+			old:         "a\nb\nc\n",
+			new:         "a\nB\nc\n",
+			wantDiff:    "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+			wantAdded:   1,
+			wantRemoved: 1,
+		},
+		{
+			name:        "距离较远的变更拆成多个 hunk",
+			old:         "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			new:         "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			context:     1,
+			wantDiff:    "@@ -1,2 +1,2 @@\n-1\n+one\n 2\n@@ -9,2 +9,2 @@\n 9\n-10\n+ten\n",
+			wantAdded:   2,
+			wantRemoved: 2,
+		},
+		{
+			name:      "无上下文的插入",
+			old:       "a\nb\n",
+			new:       "a\nx\nb\n",
+			context:   0,
+			wantDiff:  "@@ -1,0 +2 @@\n+x\n",
+			wantAdded: 1,
+		},
+		{
+			name:      "空文件",
+			old:       "",
+			new:       "a\nb\n",
+			wantDiff:  "@@ -0,0 +1,2 @@\n+a\n+b\n",
+			wantAdded: 2,
+		},
+	}
+
+	tool := NewDiffTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			pathA := filepath.Join(root, "a.txt")
+			pathB := filepath.Join(root, "b.txt")
+			writeTestFile(t, pathA, tt.old)
+			writeTestFile(t, pathB, tt.new)
+
+			params := map[string]any{"path_a": pathA, "path_b": pathB}
+			if tt.context != nil {
+				params["context_lines"] = tt.context
+			}
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(params))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			got := result.String()
+			if tt.wantDiff == "" {
+				if got != "" {
+					t.Errorf("相同文件应输出空 diff, got %q", got)
+				}
+			} else {
+				header := "--- a/" + filepath.ToSlash(pathA) + "\n+++ b/" + filepath.ToSlash(pathB) + "\n"
+				if got != header+tt.wantDiff {
+					t.Errorf("diff =\n%s\nwant:\n%s%s", got, header, tt.wantDiff)
+				}
+			}
+			if result.Metadata()["added"] != tt.wantAdded || result.Metadata()["removed"] != tt.wantRemoved {
+				t.Errorf("added/removed = %v/%v, want %d/%d",
+					result.Metadata()["added"], result.Metadata()["removed"], tt.wantAdded, tt.wantRemoved)
+			}
+
+			// 生成的 diff 应能直接交给 PatchTool 应用
+			if tt.wantDiff != "" {
+				_, err := NewPatchTool().Execute(context.Background(), core.NewMapParameters(map[string]any{
+					"path":  pathA,
+					"patch": got,
+				}))
+				if err != nil {
+					t.Fatalf("PatchTool 应用失败: %v", err)
+				}
+				data, _ := os.ReadFile(pathA)
+				if string(data) != tt.new {
+					t.Errorf("应用补丁后 = %q, want %q", data, tt.new)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffTool_PathAndContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	writeTestFile(t, path, "hello\nworld\n")
+
+	result, err := NewDiffTool().Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"path":    path,
+		"content": "hello\nthere\n",
+	}))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.Contains(result.String(), "-world\n+there\n") {
+		t.Errorf("diff = %q", result.String())
+	}
+
+	if _, err := NewDiffTool().Execute(context.Background(), core.NewMapParameters(map[string]any{"path": path})); err == nil {
+		t.Error("缺少 content 时应返回错误")
+	}
+}
+
+func TestUnifiedDiff_NoNewlineAtEOF(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		wantDiff string
+	}{
+		{
+			name:     "删除结尾换行",
+			old:      "a\nb\n",
+			new:      "a\nb",
+			wantDiff: "@@ -1,2 +1,2 @@\n a\n-b\n+b\n\\ No newline at end of file\n",
+		},
+		{
+			name:     "补上结尾换行",
+			old:      "a\nb",
+			new:      "a\nb\n",
+			wantDiff: "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			name:     "两侧都没有结尾换行",
+			old:      "a\nb",
+			new:      "a\nc",
+			wantDiff: "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, added, removed := unifiedDiff("f", "f", tt.old, tt.new, 3)
+			if want := "--- a/f\n+++ b/f\n" + tt.wantDiff; diff != want {
+				t.Errorf("diff =\n%s\nwant:\n%s", diff, want)
+			}
+			if added != 1 || removed != 1 {
+				t.Errorf("added/removed = %d/%d, want 1/1", added, removed)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff_AppliesWithPatch(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+	}{
+		{"修改中间行", "a\nb\nc\n", "a\nB\nc\n"},
+		{"删除结尾换行", "a\nb\n", "a\nb"},
+		{"补上结尾换行", "a\nb", "a\nb\n"},
+		{"修改没有结尾换行的最后一行", "a\nb", "a\nc"},
+		{"修改最后一行并删除结尾换行", "a\nb\n", "a\nc"},
+		{"没有结尾换行时修改开头", "a\nb\nc\nd\ne\nf\ng\nh", "A\nb\nc\nd\ne\nf\ng\nh"},
+		{"从空文件创建", "", "a\nb"},
+		{"清空文件", "a\nb", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, _, _ := unifiedDiff("f", "f", tt.old, tt.new, 3)
+			got, _, err := applyUnifiedDiff(tt.old, diff, false)
+			if err != nil {
+				t.Fatalf("应用 diff 失败: %v\n%s", err, diff)
+			}
+			if got != tt.new {
+				t.Errorf("正向应用 = %q, want %q\n%s", got, tt.new, diff)
+			}
+
+			back, _, err := applyUnifiedDiff(tt.new, diff, true)
+			if err != nil {
+				t.Fatalf("反向应用 diff 失败: %v\n%s", err, diff)
+			}
+			if back != tt.old {
+				t.Errorf("反向应用 = %q, want %q\n%s", back, tt.old, diff)
+			}
+		})
+	}
+}
+
+// lcsLength 用动态规划计算最长公共子序列长度，用于校验编辑序列是否最短
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func TestUnifiedDiff_RoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomLines := func() string {
+		lines := make([]string, rng.Intn(30))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(4)))
+		}
+		if len(lines) == 0 {
+			return ""
+		}
+		// 部分内容没有结尾换行，覆盖 "\ No newline at end of file" 的情况
+		if rng.Intn(3) == 0 {
+			return strings.Join(lines, "\n")
+		}
+		return strings.Join(lines, "\n") + "\n"
+	}
+
+	for i := 0; i < 200; i++ {
+		a, b := randomLines(), randomLines()
+		diff, added, removed := unifiedDiff("a", "b", a, b, rng.Intn(4))
+		linesA, linesB := splitLinesKeepEOL(a), splitLinesKeepEOL(b)
+		if want := len(linesA) + len(linesB) - 2*lcsLength(linesA, linesB); added+removed != want {
+			t.Fatalf("编辑行数 = %d, want 最短 %d: %q vs %q", added+removed, want, a, b)
+		}
+		if diff == "" {
+			if a != b {
+				t.Fatalf("内容不同却得到空 diff: %q vs %q", a, b)
+			}
+			continue
+		}
+
+		got, _, err := applyUnifiedDiff(a, diff, false)
+		if err != nil {
+			t.Fatalf("应用 diff 失败: %v\n%s", err, diff)
+		}
+		if got != b {
+			t.Fatalf("正向应用 = %q, want %q\n%s", got, b, diff)
+		}
+
+		back, _, err := applyUnifiedDiff(b, diff, true)
+		if err != nil {
+			t.Fatalf("反向应用 diff 失败: %v\n%s", err, diff)
+		}
+		if back != a {
+			t.Fatalf("反向应用 = %q, want %q\n%s", back, a, diff)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"opencode_nano/tools/core"
@@ -34,6 +35,18 @@ func NewEditTool() *EditTool {
 			"operations": {
 				Type:        "array",
 				Description: "List of edit operations to perform",
+				Items:       editOperationSchema(),
+				MinItems:    1,
+			},
+			"dry_run": {
+				Type:        "boolean",
+				Description: "Preview the changes as a unified diff without writing the file",
+				Default:     false,
+			},
+			"apply_by_original_line": {
+				Type:        "boolean",
+				Description: "When operations are only inserts and deletes, resolve their line numbers against the original file so earlier edits don't shift later ones; lists with other operation types always run in order",
+				Default:     true,
 			},
 		},
 		Required: []string{"path", "operations"},
@@ -44,14 +57,46 @@ func NewEditTool() *EditTool {
 
 // EditOperation 编辑操作
 type EditOperation struct {
-	Type        string `json:"type"`        // replace, regex_replace, insert, delete
+	Type        string `json:"type"`        // replace, regex_replace, insert, delete, range_replace
 	Find        string `json:"find"`        // 查找内容
 	Replace     string `json:"replace"`     // 替换内容
 	Line        int    `json:"line"`        // 行号（用于 insert/delete）
+	StartLine   int    `json:"start_line"`  // 起始行号（用于 range_replace，包含）
+	EndLine     int    `json:"end_line"`    // 结束行号（用于 range_replace，包含）
 	All         bool   `json:"all"`         // 是否替换所有匹配
 	CaseSensitive bool `json:"case_sensitive"` // 是否区分大小写
 }
 
+// editOperationSchema 单个编辑操作的 schema
+func editOperationSchema() *core.PropertySchema {
+	return &core.PropertySchema{
+		Type: "object",
+		Properties: map[string]core.PropertySchema{
+			"type": {
+				Type:        "string",
+				Description: "Operation type (default: replace)",
+				Enum:        []string{"replace", "regex_replace", "insert", "delete", "range_replace"},
+			},
+			"find":           {Type: "string", Description: "Text or regex to find (replace, regex_replace)"},
+			"replace":        {Type: "string", Description: "Replacement text, the line to insert, or the lines replacing a range (empty removes the range)"},
+			"line":           {Type: "integer", Description: "1-based line number (insert, delete)", Minimum: core.Bound(1)},
+			"start_line":     {Type: "integer", Description: "First line of the range to replace, 1-based and inclusive (range_replace)", Minimum: core.Bound(1)},
+			"end_line":       {Type: "integer", Description: "Last line of the range to replace, inclusive (range_replace)", Minimum: core.Bound(1)},
+			"all":            {Type: "boolean", Description: "Replace all matches (default: true)"},
+			"case_sensitive": {Type: "boolean", Description: "Case sensitive matching (default: true)"},
+		},
+	}
+}
+
+// NeedsPermission dry run 只预览不写入，无需请求权限
+func (t *EditTool) NeedsPermission(params core.Parameters) bool {
+	if params.Has("dry_run") {
+		dryRun, _ := params.GetBool("dry_run")
+		return !dryRun
+	}
+	return true
+}
+
 // Execute 执行编辑操作
 func (t *EditTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
 	// 参数验证
@@ -65,9 +110,74 @@ func (t *EditTool) Execute(ctx context.Context, params core.Parameters) (core.Re
 		return nil, core.ErrInvalidParams(t.Info().Name, "invalid path parameter")
 	}
 	
-	// 规范化路径
-	filePath = filepath.Clean(filePath)
+	// 获取操作列表
+	operationsRaw, err := params.Get("operations")
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, "invalid operations parameter")
+	}
+	
+	byOriginalLine := true
+	if params.Has("apply_by_original_line") {
+		byOriginalLine, _ = params.GetBool("apply_by_original_line")
+	}
+	
+	// 先计算全部操作的结果，任一操作失败都不会写入文件
+	plan, err := t.planEdit(filepath.Clean(filePath), operationsRaw, byOriginalLine)
+	if err != nil {
+		return nil, err
+	}
+	
+	// 预览模式只返回 diff，不写入文件
+	dryRun := false
+	if params.Has("dry_run") {
+		dryRun, _ = params.GetBool("dry_run")
+	}
+	if dryRun {
+		diff, added, removed := unifiedDiff(plan.path, plan.path, string(plan.original), plan.newContent, 3)
+		output := diff
+		if diff == "" {
+			output = fmt.Sprintf("No changes to %s", plan.path)
+		}
+		
+		result := plan.result(output, operationsRaw)
+		result.WithMetadata("dry_run", true)
+		result.WithMetadata("diff", diff)
+		result.WithMetadata("added", added)
+		result.WithMetadata("removed", removed)
+		return result, nil
+	}
 	
+	// 写回文件
+	if err := writeFileAtomic(plan.path, []byte(plan.newContent)); err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to write file: %v", err))
+	}
+	
+	return plan.result(fmt.Sprintf("Successfully edited %s", plan.path), operationsRaw), nil
+}
+
+// editPlan 一个文件的编辑计划，保存原内容以便回滚
+type editPlan struct {
+	path          string
+	original      []byte
+	newContent    string
+	edits         int
+	originalLines int
+	newLines      int
+}
+
+// result 根据编辑计划生成结果
+func (p *editPlan) result(output string, operationsRaw any) *core.SimpleResult {
+	result := core.NewSimpleResult(output)
+	result.WithMetadata("path", p.path)
+	result.WithMetadata("edits", p.edits)
+	result.WithMetadata("original_lines", p.originalLines)
+	result.WithMetadata("new_lines", p.newLines)
+	result.WithMetadata("operations", operationsRaw)
+	return result
+}
+
+// planEdit 读取文件并计算所有操作后的内容，不写入文件
+func (t *EditTool) planEdit(filePath string, operationsRaw any, byOriginalLine bool) (*editPlan, error) {
 	// 检查文件是否存在
 	if _, err := os.Stat(filePath); err != nil {
 		if os.IsNotExist(err) {
@@ -82,25 +192,38 @@ func (t *EditTool) Execute(ctx context.Context, params core.Parameters) (core.Re
 		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to read file: %v", err))
 	}
 	
-	// 将内容转换为行
-	lines := strings.Split(string(content), "\n")
-	originalLineCount := len(lines)
-	
-	// 获取操作列表
-	operationsRaw, err := params.Get("operations")
-	if err != nil {
-		return nil, core.ErrInvalidParams(t.Info().Name, "invalid operations parameter")
-	}
-
 	// 解析操作列表
 	operations, err := t.parseOperations(operationsRaw)
 	if err != nil {
 		return nil, core.ErrInvalidParams(t.Info().Name, fmt.Sprintf("invalid operations: %v", err))
 	}
-
+	
 	// 执行编辑操作
+	lines := strings.Split(string(content), "\n")
+	var editCount int
+	if byOriginalLine {
+		lines, editCount, err = applyEditOperationsByOriginalLine(lines, operations)
+	} else {
+		lines, editCount, err = applyEditOperations(lines, operations)
+	}
+	if err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+	}
+	
+	return &editPlan{
+		path:          filePath,
+		original:      content,
+		newContent:    strings.Join(lines, "\n"),
+		edits:         editCount,
+		originalLines: strings.Count(string(content), "\n") + 1,
+		newLines:      len(lines),
+	}, nil
+}
+
+// applyEditOperations 依次执行编辑操作，任一操作无效时返回错误
+func applyEditOperations(lines []string, operations []EditOperation) ([]string, int, error) {
 	editCount := 0
-	for _, op := range operations {
+	for i, op := range operations {
 		switch op.Type {
 		case "replace", "regex_replace":
 			var count int
@@ -114,37 +237,85 @@ func (t *EditTool) Execute(ctx context.Context, params core.Parameters) (core.Re
 			editCount += count
 		
 		case "insert":
-			if op.Line > 0 && op.Line <= len(lines)+1 {
-				lines = insertLine(lines, op.Line, op.Replace)
-				editCount++
+			if op.Line > len(lines)+1 {
+				return nil, 0, fmt.Errorf("operation %d: insert line %d is out of range (file has %d lines)", i+1, op.Line, len(lines))
 			}
+			lines = insertLine(lines, op.Line, op.Replace)
+			editCount++
 		
 		case "delete":
-			if op.Line > 0 && op.Line <= len(lines) {
-				lines = deleteLine(lines, op.Line)
-				editCount++
+			if op.Line > len(lines) {
+				return nil, 0, fmt.Errorf("operation %d: delete line %d is out of range (file has %d lines)", i+1, op.Line, len(lines))
 			}
+			lines = deleteLine(lines, op.Line)
+			editCount++
+		
+		case "range_replace":
+			if op.EndLine > len(lines) {
+				return nil, 0, fmt.Errorf("operation %d: range_replace lines %d-%d are out of range (file has %d lines)", i+1, op.StartLine, op.EndLine, len(lines))
+			}
+			lines = replaceLineRange(lines, op.StartLine, op.EndLine, op.Replace)
+			editCount++
 		
 		default:
-			return nil, core.ErrInvalidParams(t.Info().Name, fmt.Sprintf("unknown operation type: %s", op.Type))
+			return nil, 0, fmt.Errorf("operation %d: unknown operation type: %s", i+1, op.Type)
 		}
 	}
-	
-	// 写回文件
-	newContent := strings.Join(lines, "\n")
-	if err := os.WriteFile(filePath, []byte(newContent), 0644); err != nil {
-		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to write file: %v", err))
+	return lines, editCount, nil
+}
+
+// applyEditOperationsByOriginalLine 按原文件的行号执行只包含 insert、delete 的操作列表：
+// 先检查所有行号和冲突，再从下往上一次性应用，之前的编辑不会让之后的行号错位。
+// 列表中还有其他类型的操作时，为保持它们之间的先后关系，仍按顺序依次执行
+func applyEditOperationsByOriginalLine(lines []string, operations []EditOperation) ([]string, int, error) {
+	for _, op := range operations {
+		if op.Type != "insert" && op.Type != "delete" {
+			return applyEditOperations(lines, operations)
+		}
+	}
+
+	order := make([]int, 0, len(operations))
+	deleted := make(map[int]int)
+	for i, op := range operations {
+		switch op.Type {
+		case "insert":
+			if op.Line > len(lines)+1 {
+				return nil, 0, fmt.Errorf("operation %d: insert line %d is out of range (file has %d lines)", i+1, op.Line, len(lines))
+			}
+		case "delete":
+			if op.Line > len(lines) {
+				return nil, 0, fmt.Errorf("operation %d: delete line %d is out of range (file has %d lines)", i+1, op.Line, len(lines))
+			}
+			if j, ok := deleted[op.Line]; ok {
+				return nil, 0, fmt.Errorf("operation %d: line %d is already deleted by operation %d", i+1, op.Line, j+1)
+			}
+			deleted[op.Line] = i
+		}
+		order = append(order, i)
 	}
 	
-	// 创建结果
-	result := core.NewSimpleResult(fmt.Sprintf("Successfully edited %s", filePath))
-	result.WithMetadata("path", filePath)
-	result.WithMetadata("edits", editCount)
-	result.WithMetadata("original_lines", originalLineCount)
-	result.WithMetadata("new_lines", len(lines))
-	result.WithMetadata("operations", operationsRaw)
+	// 从下往上排序；同一行先删除再插入，同一行的多个插入倒序执行以保持原顺序
+	sort.SliceStable(order, func(a, b int) bool {
+		opA, opB := operations[order[a]], operations[order[b]]
+		if opA.Line != opB.Line {
+			return opA.Line > opB.Line
+		}
+		insertA, insertB := opA.Type == "insert", opB.Type == "insert"
+		if insertA != insertB {
+			return !insertA
+		}
+		return insertA && order[a] > order[b]
+	})
 	
-	return result, nil
+	for _, i := range order {
+		op := operations[i]
+		if op.Type == "insert" {
+			lines = insertLine(lines, op.Line, op.Replace)
+		} else {
+			lines = deleteLine(lines, op.Line)
+		}
+	}
+	return lines, len(order), nil
 }
 
 // MultiEditTool 多文件编辑工具
@@ -168,6 +339,20 @@ func NewMultiEditTool() *MultiEditTool {
 			"edits": {
 				Type:        "array",
 				Description: "List of file edits to perform",
+				Items: &core.PropertySchema{
+					Type: "object",
+					Properties: map[string]core.PropertySchema{
+						"path":       {Type: "string", Description: "File path", MinLength: 1},
+						"operations": {Type: "array", Description: "Edit operations for this file", Items: editOperationSchema(), MinItems: 1},
+					},
+					Required: []string{"path", "operations"},
+				},
+				MinItems: 1,
+			},
+			"rollback": {
+				Type:        "boolean",
+				Description: "Apply all edits or none: restore every file if any edit fails",
+				Default:     false,
 			},
 		},
 		Required: []string{"edits"},
@@ -195,6 +380,14 @@ func (t *MultiEditTool) Execute(ctx context.Context, params core.Parameters) (co
 		return nil, core.ErrInvalidParams(t.Info().Name, fmt.Sprintf("invalid edits: %v", err))
 	}
 	
+	rollback := false
+	if params.Has("rollback") {
+		rollback, _ = params.GetBool("rollback")
+	}
+	if rollback {
+		return t.executeAtomic(edits)
+	}
+	
 	// 执行所有编辑
 	results := make([]map[string]interface{}, 0, len(edits))
 	successCount := 0
@@ -234,6 +427,57 @@ func (t *MultiEditTool) Execute(ctx context.Context, params core.Parameters) (co
 	return result, nil
 }
 
+// executeAtomic 先计算所有文件的编辑结果再统一写入，写入失败时恢复已修改的文件
+func (t *MultiEditTool) executeAtomic(edits []FileEdit) (core.Result, error) {
+	plans := make([]*editPlan, 0, len(edits))
+	for _, edit := range edits {
+		plan, err := t.editTool.planEdit(filepath.Clean(edit.Path), edit.Operations, true)
+		if err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("edit %s failed, no files were changed: %v", edit.Path, err))
+		}
+		plans = append(plans, plan)
+	}
+	
+	for i, plan := range plans {
+		if err := writeFileAtomic(plan.path, []byte(plan.newContent)); err != nil {
+			restoreErrs := restoreEditPlans(plans[:i])
+			msg := fmt.Sprintf("failed to write %s: %v; rolled back %d files", plan.path, err, i)
+			if len(restoreErrs) > 0 {
+				msg += fmt.Sprintf(" (restore errors: %s)", strings.Join(restoreErrs, "; "))
+			}
+			return nil, core.ErrExecutionFailed(t.Info().Name, msg)
+		}
+	}
+	
+	results := make([]map[string]interface{}, 0, len(plans))
+	for i, plan := range plans {
+		results = append(results, map[string]interface{}{
+			"path":     edits[i].Path,
+			"success":  true,
+			"metadata": plan.result("", edits[i].Operations).Metadata(),
+		})
+	}
+	
+	result := core.NewSimpleResult(fmt.Sprintf("Edited %d files successfully, 0 failed", len(plans)))
+	result.WithMetadata("success_count", len(plans))
+	result.WithMetadata("fail_count", 0)
+	result.WithMetadata("results", results)
+	result.WithMetadata("rollback", true)
+	
+	return result, nil
+}
+
+// restoreEditPlans 将文件恢复为编辑前的内容，返回恢复失败的信息
+func restoreEditPlans(plans []*editPlan) []string {
+	var errs []string
+	for _, plan := range plans {
+		if err := writeFileAtomic(plan.path, plan.original); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", plan.path, err))
+		}
+	}
+	return errs
+}
+
 // FileEdit 文件编辑信息
 type FileEdit struct {
 	Path       string        `json:"path"`
@@ -342,15 +586,14 @@ func (t *PatchTool) Execute(ctx context.Context, params core.Parameters) (core.R
 		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to read file: %v", err))
 	}
 	
-	// 应用补丁（简化实现）
-	// 在实际实现中，应该使用专门的 diff/patch 库
-	newContent, applied, err := t.applySimplePatch(string(originalContent), patchContent, reverse)
+	// 应用补丁，上下文不匹配时直接返回错误，不写入文件
+	newContent, applied, err := applyUnifiedDiff(string(originalContent), patchContent, reverse)
 	if err != nil {
 		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to apply patch: %v", err))
 	}
 	
 	// 写回文件
-	if err := os.WriteFile(filePath, []byte(newContent), 0644); err != nil {
+	if err := writeFileAtomic(filePath, []byte(newContent)); err != nil {
 		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to write file: %v", err))
 	}
 	
@@ -363,86 +606,17 @@ func (t *PatchTool) Execute(ctx context.Context, params core.Parameters) (core.R
 	return result, nil
 }
 
-// applySimplePatch 简单的补丁应用（仅用于演示）
-func (t *PatchTool) applySimplePatch(content, patch string, reverse bool) (string, int, error) {
-	// 这是一个极简的实现，仅支持简单的行替换
-	// 实际应该使用 github.com/sourcegraph/go-diff 或类似库
-	
-	lines := strings.Split(content, "\n")
-	patchLines := strings.Split(patch, "\n")
-	applied := 0
-	
-	for i := 0; i < len(patchLines); i++ {
-		line := patchLines[i]
-		
-		// 简单查找以 - 开头的行并替换为 + 开头的行
-		if strings.HasPrefix(line, "-") && i+1 < len(patchLines) && strings.HasPrefix(patchLines[i+1], "+") {
-			oldLine := strings.TrimPrefix(line, "-")
-			newLine := strings.TrimPrefix(patchLines[i+1], "+")
-			
-			if reverse {
-				oldLine, newLine = newLine, oldLine
-			}
-			
-			// 在内容中查找并替换
-			for j, contentLine := range lines {
-				if strings.TrimSpace(contentLine) == strings.TrimSpace(oldLine) {
-					lines[j] = newLine
-					applied++
-					break
-				}
-			}
-			
-			i++ // 跳过下一行
-		}
-	}
-	
-	return strings.Join(lines, "\n"), applied, nil
-}
-
 // findAndReplace 执行查找替换
 func findAndReplace(content, find, replace string, all, caseSensitive bool) (string, int) {
-	count := 0
-	
+	pattern := regexp.QuoteMeta(find)
 	if !caseSensitive {
-		// 不区分大小写的替换
-		re := regexp.MustCompile("(?i)" + regexp.QuoteMeta(find))
-		if all {
-			content = re.ReplaceAllStringFunc(content, func(match string) string {
-				count++
-				return replace
-			})
-		} else {
-			content = re.ReplaceAllStringFunc(content, func(match string) string {
-				if count == 0 {
-					count++
-					return replace
-				}
-				return match
-			})
-		}
-	} else {
-		// 区分大小写的替换
-		if all {
-			newContent := strings.ReplaceAll(content, find, replace)
-			count = strings.Count(content, find)
-			content = newContent
-		} else {
-			index := strings.Index(content, find)
-			if index != -1 {
-				content = content[:index] + replace + content[index+len(find):]
-				count = 1
-			}
-		}
+		pattern = "(?i)" + pattern
 	}
-	
-	return content, count
+	return replaceMatches(content, regexp.MustCompile(pattern), replace, all)
 }
 
 // regexReplace 执行正则表达式替换
 func regexReplace(content, pattern, replace string, all, caseSensitive bool) (string, int) {
-	count := 0
-	
 	// 构建正则表达式
 	flags := ""
 	if !caseSensitive {
@@ -455,22 +629,32 @@ func regexReplace(content, pattern, replace string, all, caseSensitive bool) (st
 		return content, 0
 	}
 	
+	return replaceMatches(content, re, replace, all)
+}
+
+// replaceMatches 将匹配替换为字面文本；all 为 false 时只替换第一个匹配。
+// 返回的数量即实际替换的次数
+func replaceMatches(content string, re *regexp.Regexp, replace string, all bool) (string, int) {
+	n := 1
 	if all {
-		content = re.ReplaceAllStringFunc(content, func(match string) string {
-			count++
-			return replace
-		})
-	} else {
-		content = re.ReplaceAllStringFunc(content, func(match string) string {
-			if count == 0 {
-				count++
-				return replace
-			}
-			return match
-		})
+		n = -1
 	}
 	
-	return content, count
+	matches := re.FindAllStringIndex(content, n)
+	if len(matches) == 0 {
+		return content, 0
+	}
+	
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		sb.WriteString(content[last:m[0]])
+		sb.WriteString(replace)
+		last = m[1]
+	}
+	sb.WriteString(content[last:])
+	
+	return sb.String(), len(matches)
 }
 
 // parseOperations 解析操作列表
@@ -491,6 +675,8 @@ func (t *EditTool) parseOperations(raw interface{}) ([]EditOperation, error) {
 				Find:          getStringValue(opMap, "find", ""),
 				Replace:       getStringValue(opMap, "replace", ""),
 				Line:          getIntValue(opMap, "line", 0),
+				StartLine:     getIntValue(opMap, "start_line", 0),
+				EndLine:       getIntValue(opMap, "end_line", 0),
 				All:           getBoolValue(opMap, "all", true),
 				CaseSensitive: getBoolValue(opMap, "case_sensitive", true),
 			}
@@ -512,10 +698,17 @@ func (t *EditTool) parseOperations(raw interface{}) ([]EditOperation, error) {
 // validateOperation 验证操作
 func (t *EditTool) validateOperation(op EditOperation) error {
 	switch op.Type {
-	case "replace", "regex_replace":
+	case "replace":
 		if op.Find == "" {
 			return fmt.Errorf("%s operation requires 'find' field", op.Type)
 		}
+	case "regex_replace":
+		if op.Find == "" {
+			return fmt.Errorf("%s operation requires 'find' field", op.Type)
+		}
+		if _, err := regexp.Compile(op.Find); err != nil {
+			return fmt.Errorf("invalid regex %q: %v", op.Find, err)
+		}
 	case "insert":
 		if op.Line <= 0 {
 			return fmt.Errorf("insert operation requires positive 'line' field")
@@ -524,6 +717,13 @@ func (t *EditTool) validateOperation(op EditOperation) error {
 		if op.Line <= 0 {
 			return fmt.Errorf("delete operation requires positive 'line' field")
 		}
+	case "range_replace":
+		if op.StartLine <= 0 || op.EndLine <= 0 {
+			return fmt.Errorf("range_replace operation requires positive 'start_line' and 'end_line' fields")
+		}
+		if op.EndLine < op.StartLine {
+			return fmt.Errorf("range_replace end_line %d is before start_line %d", op.EndLine, op.StartLine)
+		}
 	default:
 		return fmt.Errorf("unknown operation type: %s", op.Type)
 	}
@@ -594,4 +794,23 @@ func deleteLine(lines []string, lineNum int) []string {
 	result = append(result, lines[index+1:]...)
 	
 	return result
-}
\ No newline at end of file
+}
+
+// replaceLineRange 将第 start 到 end 行（包含）替换为 content 的各行，content 为空时删除这些行
+func replaceLineRange(lines []string, start, end int, content string) []string {
+	if start <= 0 || end < start || end > len(lines) {
+		return lines
+	}
+	
+	var replacement []string
+	if content != "" {
+		replacement = strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	}
+	
+	result := make([]string, 0, len(lines)-(end-start+1)+len(replacement))
+	result = append(result, lines[:start-1]...)
+	result = append(result, replacement...)
+	result = append(result, lines[end:]...)
+	
+	return result
+}
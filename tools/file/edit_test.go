@@ -0,0 +1,388 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+func TestEditTool_Operations(t *testing.T) {
+	const original = "one\ntwo\nthree"
+
+	tests := []struct {
+		name       string
+		operations []any
+		sequential bool
+		want       string
+		wantEdits  int
+		wantErr    bool
+	}{
+		{
+			name:       "顺序执行多个操作",
+			sequential: true,
+			operations: []any{
+				map[string]any{"type": "replace", "find": "two", "replace": "2"},
+				map[string]any{"type": "insert", "line": 1, "replace": "zero"},
+				map[string]any{"type": "delete", "line": 4},
+			},
+			want:      "zero\none\n2",
+			wantEdits: 3,
+		},
+		{
+			name: "按原行号混合插入和删除",
+			operations: []any{
+				map[string]any{"type": "insert", "line": 1, "replace": "zero"},
+				map[string]any{"type": "delete", "line": 2},
+				map[string]any{"type": "insert", "line": 3, "replace": "two and a half"},
+				map[string]any{"type": "insert", "line": 4, "replace": "four"},
+			},
+			want:      "zero\none\ntwo and a half\nthree\nfour",
+			wantEdits: 4,
+		},
+		{
+			name: "按原行号删除多行",
+			operations: []any{
+				map[string]any{"type": "delete", "line": 1},
+				map[string]any{"type": "delete", "line": 3},
+			},
+			want:      "two",
+			wantEdits: 2,
+		},
+		{
+			name: "同一行的多个插入保持顺序",
+			operations: []any{
+				map[string]any{"type": "insert", "line": 2, "replace": "a"},
+				map[string]any{"type": "delete", "line": 2},
+				map[string]any{"type": "insert", "line": 2, "replace": "b"},
+			},
+			want:      "one\na\nb\nthree",
+			wantEdits: 3,
+		},
+		{
+			name: "混合文本替换时按顺序执行",
+			operations: []any{
+				map[string]any{"type": "replace", "find": "one", "replace": "1\n1.5"},
+				map[string]any{"type": "delete", "line": 2},
+			},
+			want:      "1\ntwo\nthree",
+			wantEdits: 2,
+		},
+		{
+			name: "之后插入的内容不被之前的替换修改",
+			operations: []any{
+				map[string]any{"type": "replace", "find": "two", "replace": "2"},
+				map[string]any{"type": "insert", "line": 3, "replace": "two"},
+			},
+			want:      "one\n2\ntwo\nthree",
+			wantEdits: 2,
+		},
+		{
+			name: "重复删除同一行",
+			operations: []any{
+				map[string]any{"type": "delete", "line": 2},
+				map[string]any{"type": "delete", "line": 2},
+			},
+			wantErr: true,
+		},
+		{
+			name: "包含区间替换时按顺序执行",
+			operations: []any{
+				map[string]any{"type": "range_replace", "start_line": 1, "end_line": 3, "replace": "x"},
+				map[string]any{"type": "insert", "line": 2, "replace": "y"},
+			},
+			want:      "x\ny",
+			wantEdits: 2,
+		},
+		{
+			name:       "按原行号删除越界",
+			operations: []any{map[string]any{"type": "delete", "line": 4}},
+			wantErr:    true,
+		},
+		{
+			name:       "在末尾之后插入",
+			operations: []any{map[string]any{"type": "insert", "line": 4, "replace": "four"}},
+			want:       "one\ntwo\nthree\nfour",
+			wantEdits:  1,
+		},
+		{
+			name: "中途失败不修改文件",
+			operations: []any{
+				map[string]any{"type": "replace", "find": "one", "replace": "1"},
+				map[string]any{"type": "delete", "line": 10},
+			},
+			wantErr: true,
+		},
+		{
+			name:       "插入行号越界",
+			operations: []any{map[string]any{"type": "insert", "line": 5, "replace": "x"}},
+			wantErr:    true,
+		},
+		{
+			name:       "替换多行区间",
+			operations: []any{map[string]any{"type": "range_replace", "start_line": 1, "end_line": 2, "replace": "a\nb\nc"}},
+			want:       "a\nb\nc\nthree",
+			wantEdits:  1,
+		},
+		{
+			name:       "空内容删除区间",
+			operations: []any{map[string]any{"type": "range_replace", "start_line": 2, "end_line": 3, "replace": ""}},
+			want:       "one",
+			wantEdits:  1,
+		},
+		{
+			name:       "区间超出文件长度",
+			operations: []any{map[string]any{"type": "range_replace", "start_line": 2, "end_line": 4, "replace": "x"}},
+			wantErr:    true,
+		},
+		{
+			name:       "区间结束行在起始行之前",
+			operations: []any{map[string]any{"type": "range_replace", "start_line": 3, "end_line": 2, "replace": "x"}},
+			wantErr:    true,
+		},
+		{
+			name:       "区间缺少结束行",
+			operations: []any{map[string]any{"type": "range_replace", "start_line": 1, "replace": "x"}},
+			wantErr:    true,
+		},
+		{
+			name:       "无效正则",
+			operations: []any{map[string]any{"type": "regex_replace", "find": "(", "replace": "x"}},
+			wantErr:    true,
+		},
+		{
+			name:       "空操作列表",
+			operations: []any{},
+			wantErr:    true,
+		},
+		{
+			name:       "操作不是对象",
+			operations: []any{"replace two"},
+			wantErr:    true,
+		},
+		{
+			name:       "行号类型错误",
+			operations: []any{map[string]any{"type": "delete", "line": "two"}},
+			wantErr:    true,
+		},
+	}
+
+	tool := NewEditTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "file.txt")
+			writeTestFile(t, path, original)
+
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+				"path":                   path,
+				"operations":             tt.operations,
+				"apply_by_original_line": !tt.sequential,
+			}))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			data, _ := os.ReadFile(path)
+			if tt.wantErr {
+				if string(data) != original {
+					t.Errorf("失败后文件被修改: %q", data)
+				}
+				return
+			}
+			if string(data) != tt.want {
+				t.Errorf("内容 = %q, want %q", data, tt.want)
+			}
+			if result.Metadata()["edits"] != tt.wantEdits {
+				t.Errorf("edits = %v, want %d", result.Metadata()["edits"], tt.wantEdits)
+			}
+		})
+	}
+}
+
+func TestMultiEditTool_Rollback(t *testing.T) {
+	tests := []struct {
+		name     string
+		rollback any
+		wantErr  bool
+		wantA    string
+	}{
+		{
+			name:  "默认逐个编辑，失败不影响已完成的文件",
+			wantA: "A\n",
+		},
+		{
+			name:     "开启回滚时全部不修改",
+			rollback: true,
+			wantErr:  true,
+			wantA:    "a\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			pathA := filepath.Join(root, "a.txt")
+			pathB := filepath.Join(root, "b.txt")
+			writeTestFile(t, pathA, "a\n")
+			writeTestFile(t, pathB, "b\n")
+
+			params := map[string]any{
+				"edits": []any{
+					map[string]any{
+						"path":       pathA,
+						"operations": []any{map[string]any{"type": "replace", "find": "a", "replace": "A"}},
+					},
+					map[string]any{
+						"path": pathB,
+						"operations": []any{
+							map[string]any{"type": "replace", "find": "b", "replace": "B"},
+							map[string]any{"type": "delete", "line": 99},
+						},
+					},
+				},
+			}
+			if tt.rollback != nil {
+				params["rollback"] = tt.rollback
+			}
+
+			result, err := NewMultiEditTool().Execute(context.Background(), core.NewMapParameters(params))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && result.Metadata()["fail_count"] != 1 {
+				t.Errorf("fail_count = %v, want 1", result.Metadata()["fail_count"])
+			}
+
+			dataA, _ := os.ReadFile(pathA)
+			if string(dataA) != tt.wantA {
+				t.Errorf("a.txt = %q, want %q", dataA, tt.wantA)
+			}
+			dataB, _ := os.ReadFile(pathB)
+			if string(dataB) != "b\n" {
+				t.Errorf("b.txt = %q, 失败的编辑不应写入", dataB)
+			}
+		})
+	}
+}
+
+func TestMultiEditTool_RollbackSuccess(t *testing.T) {
+	root := t.TempDir()
+	pathA := filepath.Join(root, "a.txt")
+	pathB := filepath.Join(root, "b.txt")
+	writeTestFile(t, pathA, "a\n")
+	writeTestFile(t, pathB, "b\n")
+
+	result, err := NewMultiEditTool().Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"rollback": true,
+		"edits": []any{
+			map[string]any{"path": pathA, "operations": []any{map[string]any{"type": "replace", "find": "a", "replace": "A"}}},
+			map[string]any{"path": pathB, "operations": []any{map[string]any{"type": "replace", "find": "b", "replace": "B"}}},
+		},
+	}))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Metadata()["success_count"] != 2 {
+		t.Errorf("success_count = %v, want 2", result.Metadata()["success_count"])
+	}
+
+	for path, want := range map[string]string{pathA: "A\n", pathB: "B\n"} {
+		data, _ := os.ReadFile(path)
+		if string(data) != want {
+			t.Errorf("%s = %q, want %q", filepath.Base(path), data, want)
+		}
+	}
+}
+
+func TestEditTool_DryRun(t *testing.T) {
+	const original = "one\ntwo\nthree\n"
+	path := filepath.Join(t.TempDir(), "file.txt")
+	writeTestFile(t, path, original)
+
+	result, err := NewEditTool().Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"path": path,
+		"operations": []any{
+			map[string]any{"type": "replace", "find": "two", "replace": "2"},
+			map[string]any{"type": "insert", "line": 1, "replace": "zero"},
+		},
+		"dry_run": true,
+	}))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	wantDiff := "--- a/" + filepath.ToSlash(path) + "\n+++ b/" + filepath.ToSlash(path) + "\n" +
+		"@@ -1,3 +1,4 @@\n+zero\n one\n-two\n+2\n three\n"
+	if result.String() != wantDiff {
+		t.Errorf("diff =\n%s\nwant:\n%s", result.String(), wantDiff)
+	}
+	metadata := result.Metadata()
+	if metadata["added"] != 2 || metadata["removed"] != 1 || metadata["edits"] != 2 {
+		t.Errorf("added/removed/edits = %v/%v/%v, want 2/1/2", metadata["added"], metadata["removed"], metadata["edits"])
+	}
+	if metadata["dry_run"] != true {
+		t.Error("dry_run 元数据应为 true")
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != original {
+		t.Errorf("dry run 修改了文件: %q", data)
+	}
+
+	// 将预览的 diff 应用到原文件应得到实际编辑的结果
+	patched, _, err := applyUnifiedDiff(original, result.String(), false)
+	if err != nil {
+		t.Fatalf("应用预览 diff 失败: %v", err)
+	}
+	if patched != "zero\none\n2\nthree\n" {
+		t.Errorf("应用预览后 = %q", patched)
+	}
+}
+
+func TestFindAndRegexReplace(t *testing.T) {
+	const content = "Foo foo FOO food"
+
+	tests := []struct {
+		name          string
+		regex         bool
+		find          string
+		replace       string
+		all           bool
+		caseSensitive bool
+		want          string
+		wantCount     int
+	}{
+		{"字面量 全部 区分大小写", false, "foo", "x", true, true, "Foo x FOO xd", 2},
+		{"字面量 首个 区分大小写", false, "foo", "x", false, true, "Foo x FOO food", 1},
+		{"字面量 全部 不区分大小写", false, "foo", "x", true, false, "x x x xd", 4},
+		{"字面量 首个 不区分大小写", false, "foo", "x", false, false, "x foo FOO food", 1},
+		{"字面量 特殊字符", false, "o.", "x", true, true, "Foo foo FOO food", 0},
+		{"字面量 无匹配", false, "bar", "x", false, false, content, 0},
+		{"正则 全部 区分大小写", true, `fo+\b`, "x", true, true, "Foo x FOO food", 1},
+		{"正则 首个 区分大小写", true, `[a-z]oo`, "x", false, true, "Foo x FOO food", 1},
+		{"正则 全部 不区分大小写", true, `f[o]+`, "x", true, false, "x x x xd", 4},
+		{"正则 首个 不区分大小写", true, `f[o]+`, "x", false, false, "x foo FOO food", 1},
+		{"正则 替换文本按字面处理", true, `(f)oo`, "$1", false, true, "Foo $1 FOO food", 1},
+		{"正则 无效表达式", true, `(`, "x", true, true, content, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			var count int
+			if tt.regex {
+				got, count = regexReplace(content, tt.find, tt.replace, tt.all, tt.caseSensitive)
+			} else {
+				got, count = findAndReplace(content, tt.find, tt.replace, tt.all, tt.caseSensitive)
+			}
+			if got != tt.want {
+				t.Errorf("结果 = %q, want %q", got, tt.want)
+			}
+			if count != tt.wantCount {
+				t.Errorf("替换次数 = %d, want %d", count, tt.wantCount)
+			}
+		})
+	}
+}
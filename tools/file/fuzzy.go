@@ -0,0 +1,250 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"opencode_nano/tools/core"
+)
+
+// FuzzyFindTool 按模糊匹配查找文件的工具，适合只记得部分文件名的情况
+type FuzzyFindTool struct {
+	*core.BaseTool
+}
+
+// NewFuzzyFindTool 创建模糊查找工具
+func NewFuzzyFindTool() *FuzzyFindTool {
+	tool := &FuzzyFindTool{
+		BaseTool: core.NewBaseTool("ff", "file", "Fuzzy find files by a partial or half-remembered name, ranked by match quality"),
+	}
+
+	tool.SetTags("file", "find", "fuzzy", "search")
+	tool.SetSchema(core.ParameterSchema{
+		Type: "object",
+		Properties: map[string]core.PropertySchema{
+			"query": {
+				Type:        "string",
+				Description: "Characters to match in order against the file path relative to path (case insensitive, e.g. 'agtst' matches agent/agent_test.go)",
+				MinLength:   1,
+			},
+			"path": {
+				Type:        "string",
+				Description: "Directory to search in",
+				Default:     ".",
+			},
+			"max_results": {
+				Type:        "integer",
+				Description: "Maximum number of results to return",
+				Default:     20,
+				Minimum:     core.Bound(1),
+				Maximum:     core.Bound(1000),
+			},
+			"respect_gitignore": {
+				Type:        "boolean",
+				Description: "Skip files ignored by .gitignore",
+				Default:     true,
+			},
+		},
+		Required: []string{"query"},
+	})
+
+	return tool
+}
+
+// FuzzyMatch 一个模糊匹配结果
+type FuzzyMatch struct {
+	Path  string `json:"path"`
+	Score int    `json:"score"`
+}
+
+// Execute 遍历目录并按匹配得分排序
+func (t *FuzzyFindTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	// 参数验证
+	if err := params.Validate(t.Schema()); err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+
+	query, err := params.GetString("query")
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, "invalid query parameter")
+	}
+	// 空白不参与匹配，便于输入 "agent test" 这样的查询
+	query = strings.Join(strings.Fields(query), "")
+	if query == "" {
+		return nil, core.ErrInvalidParams(t.Info().Name, "query must not be blank")
+	}
+
+	root := "."
+	if params.Has("path") {
+		root, _ = params.GetString("path")
+	}
+	root = filepath.Clean(root)
+
+	maxResults := 20
+	if params.Has("max_results") {
+		maxResults, _ = params.GetInt("max_results")
+	}
+
+	respectGitignore := true
+	if params.Has("respect_gitignore") {
+		respectGitignore, _ = params.GetBool("respect_gitignore")
+	}
+
+	var ignore *gitignoreMatcher
+	if respectGitignore {
+		ignore = newGitignoreMatcher(root)
+	}
+
+	var matches []FuzzyMatch
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil // 忽略无法访问的子目录
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if path == root {
+			return nil
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" || (ignore != nil && ignore.match(path, true)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ignore != nil && ignore.match(path, false) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		if score, ok := fuzzyScore(query, filepath.ToSlash(rel)); ok {
+			matches = append(matches, FuzzyMatch{Path: path, Score: score})
+		}
+		return nil
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, core.ErrCancelled(t.Info().Name)
+		}
+		return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+	}
+
+	// 得分高的在前；得分相同时路径短的在前
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		if len(matches[i].Path) != len(matches[j].Path) {
+			return len(matches[i].Path) < len(matches[j].Path)
+		}
+		return matches[i].Path < matches[j].Path
+	})
+
+	total := len(matches)
+	if len(matches) > maxResults {
+		matches = matches[:maxResults]
+	}
+
+	lines := make([]string, 0, len(matches))
+	for _, m := range matches {
+		lines = append(lines, fmt.Sprintf("%s (score %d)", m.Path, m.Score))
+	}
+	output := fmt.Sprintf("No files matching %q in %s", query, root)
+	if total > 0 {
+		output = fmt.Sprintf("Found %d files matching %q", total, query)
+		if total > len(matches) {
+			output += fmt.Sprintf(", showing top %d", len(matches))
+		}
+		output += "\n" + strings.Join(lines, "\n")
+	}
+
+	result := core.NewSimpleResult(output)
+	result.WithMetadata("query", query)
+	result.WithMetadata("path", root)
+	result.WithMetadata("matches", matches)
+	result.WithMetadata("count", len(matches))
+	result.WithMetadata("total_matches", total)
+	result.WithMetadata("truncated", total > len(matches))
+
+	return result, nil
+}
+
+const (
+	fuzzyMatchScore       = 1 // 每个匹配的字符
+	fuzzyConsecutiveBonus = 5 // 与上一个匹配字符相邻，且至少沿用这一段连续匹配开头的加分
+	fuzzySegmentBonus     = 8 // 位于路径段或单词开头（/ _ - . 之后，或驼峰的大写字母）
+	fuzzyGapPenalty       = 1 // 两个匹配字符之间每跳过一个字符
+)
+
+// fuzzyScore 判断 query 是否是 target 的子序列（不区分大小写）并计算得分。
+// 从 query 首字符在 target 中的每个出现位置开始贪心匹配，取最高分
+func fuzzyScore(query, target string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	original := []rune(target)
+	lower := make([]rune, len(original))
+	for i, r := range original {
+		lower[i] = unicode.ToLower(r)
+	}
+	if len(q) == 0 {
+		return 0, false
+	}
+
+	best, found := 0, false
+	for start := range lower {
+		if lower[start] != q[0] {
+			continue
+		}
+		score, prev, qi, chunkBonus := 0, -1, 0, 0
+		for i := start; i < len(lower) && qi < len(q); i++ {
+			if lower[i] != q[qi] {
+				continue
+			}
+			bonus := 0
+			if isSegmentStart(original, i) {
+				bonus = fuzzySegmentBonus
+			}
+			if prev >= 0 && i == prev+1 {
+				bonus = max(bonus, chunkBonus, fuzzyConsecutiveBonus)
+			} else {
+				if prev >= 0 {
+					score -= fuzzyGapPenalty * (i - prev - 1)
+				}
+				chunkBonus = bonus
+			}
+			score += fuzzyMatchScore + bonus
+			prev = i
+			qi++
+		}
+		if qi == len(q) && (!found || score > best) {
+			best, found = score, true
+		}
+	}
+	return best, found
+}
+
+// isSegmentStart 判断第 i 个字符是否位于路径段或单词的开头
+func isSegmentStart(s []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	switch s[i-1] {
+	case '/', '_', '-', '.', ' ':
+		return true
+	}
+	return unicode.IsLower(s[i-1]) && unicode.IsUpper(s[i])
+}
@@ -0,0 +1,136 @@
+package file
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+func TestFuzzyFindTool_Ranking(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{
+		"main.go",
+		"cmd/main_test.go",
+		"internal/domain/model.go",
+		"agent/manager.go",
+		"agent/agent_test.go",
+		"tools/file/search.go",
+		"README.md",
+		".git/main",
+	} {
+		writeTestFile(t, filepath.Join(root, name), "x\n")
+	}
+
+	tests := []struct {
+		name      string
+		params    map[string]any
+		want      []string
+		wantTotal int
+		wantErr   bool
+	}{
+		{
+			name:      "连续且位于段首的匹配排在前面",
+			params:    map[string]any{"query": "main"},
+			want:      []string{"main.go", "cmd/main_test.go", "internal/domain/model.go"},
+			wantTotal: 3,
+		},
+		{
+			name:      "段首匹配优先于分散匹配",
+			params:    map[string]any{"query": "agtst"},
+			want:      []string{"agent/agent_test.go"},
+			wantTotal: 1,
+		},
+		{
+			name:      "不区分大小写",
+			params:    map[string]any{"query": "readme"},
+			want:      []string{"README.md"},
+			wantTotal: 1,
+		},
+		{
+			name:      "跨路径分隔符匹配",
+			params:    map[string]any{"query": "tfs"},
+			want:      []string{"tools/file/search.go"},
+			wantTotal: 1,
+		},
+		{
+			name:      "max_results 只保留得分最高的结果",
+			params:    map[string]any{"query": "main", "max_results": 1},
+			want:      []string{"main.go"},
+			wantTotal: 3,
+		},
+		{
+			name:      "没有匹配",
+			params:    map[string]any{"query": "zzz"},
+			want:      []string{},
+			wantTotal: 0,
+		},
+		{
+			name:    "查询为空白",
+			params:  map[string]any{"query": "  "},
+			wantErr: true,
+		},
+	}
+
+	tool := NewFuzzyFindTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.params["path"] = root
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(tt.params))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			matches := result.Metadata()["matches"].([]FuzzyMatch)
+			if len(matches) != len(tt.want) {
+				t.Fatalf("matches = %v, want %v", matches, tt.want)
+			}
+			for i, want := range tt.want {
+				if matches[i].Path != filepath.Join(root, want) {
+					t.Errorf("第 %d 个结果 = %s, want %s", i, matches[i].Path, want)
+				}
+				if i > 0 && matches[i].Score > matches[i-1].Score {
+					t.Errorf("结果未按得分排序: %v", matches)
+				}
+			}
+			if result.Metadata()["total_matches"] != tt.wantTotal {
+				t.Errorf("total_matches = %v, want %d", result.Metadata()["total_matches"], tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		query  string
+		better string
+		worse  string
+	}{
+		{query: "ag", better: "agent.go", worse: "bag.go"},
+		{query: "rt", better: "read_test.go", worse: "format.go"},
+		{query: "fb", better: "fooBar.go", worse: "foobar.go"},
+		{query: "edit", better: "edit.go", worse: "e/d/i/t.go"},
+	}
+
+	for _, tt := range tests {
+		better, ok := fuzzyScore(tt.query, tt.better)
+		if !ok {
+			t.Fatalf("%q 应匹配 %q", tt.query, tt.better)
+		}
+		worse, ok := fuzzyScore(tt.query, tt.worse)
+		if !ok {
+			t.Fatalf("%q 应匹配 %q", tt.query, tt.worse)
+		}
+		if better <= worse {
+			t.Errorf("%q: %s 得分 %d 应高于 %s 得分 %d", tt.query, tt.better, better, tt.worse, worse)
+		}
+	}
+
+	if _, ok := fuzzyScore("xyz", "main.go"); ok {
+		t.Errorf("非子序列不应匹配")
+	}
+}
@@ -0,0 +1,253 @@
+package file
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// gitignoreRule 一条 .gitignore 规则
+type gitignoreRule struct {
+	base    string // 规则所在目录，相对于仓库根目录（斜杠分隔，根目录为空）
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// gitignoreMatcher 按目录逐级加载 .gitignore 并判断路径是否被忽略
+type gitignoreMatcher struct {
+	root   string // 仓库根目录（绝对路径），找不到 .git 时为搜索根目录
+	rules  []gitignoreRule
+	loaded map[string]bool
+}
+
+// newGitignoreMatcher 为搜索路径创建匹配器，会向上查找包含 .git 的仓库根目录
+func newGitignoreMatcher(searchPath string) *gitignoreMatcher {
+	abs, err := filepath.Abs(searchPath)
+	if err != nil {
+		abs = searchPath
+	}
+	if info, err := os.Stat(abs); err == nil && !info.IsDir() {
+		abs = filepath.Dir(abs)
+	}
+
+	root := abs
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			root = dir
+			break
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+
+	return &gitignoreMatcher{
+		root:   root,
+		loaded: make(map[string]bool),
+	}
+}
+
+// match 判断单个路径是否被忽略（不检查上级目录）
+func (m *gitignoreMatcher) match(path string, isDir bool) bool {
+	rel, ok := m.relPath(path)
+	if !ok {
+		return false
+	}
+	if isGitDir(rel) {
+		return true
+	}
+
+	// 确保沿途的 .gitignore 均已加载
+	m.ensureLoaded(parentDir(rel))
+
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+
+		target := rel
+		if rule.base != "" {
+			if !strings.HasPrefix(rel, rule.base+"/") {
+				continue
+			}
+			target = strings.TrimPrefix(rel, rule.base+"/")
+		}
+
+		// 后出现的规则优先，因此需要遍历全部规则
+		if rule.re.MatchString(target) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// matchWithParents 判断路径本身或任一上级目录是否被忽略
+func (m *gitignoreMatcher) matchWithParents(path string, isDir bool) bool {
+	rel, ok := m.relPath(path)
+	if !ok {
+		return false
+	}
+
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if m.match(filepath.Join(m.root, filepath.FromSlash(strings.Join(parts[:i], "/"))), true) {
+			return true
+		}
+	}
+	return m.match(path, isDir)
+}
+
+// relPath 返回相对仓库根目录的斜杠路径，路径不在仓库内时返回 false
+func (m *gitignoreMatcher) relPath(path string) (string, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(m.root, abs)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// ensureLoaded 加载从仓库根目录到 dir 沿途的 .gitignore
+func (m *gitignoreMatcher) ensureLoaded(dir string) {
+	m.loadDir("")
+	if dir == "" {
+		return
+	}
+	parts := strings.Split(dir, "/")
+	for i := 1; i <= len(parts); i++ {
+		m.loadDir(strings.Join(parts[:i], "/"))
+	}
+}
+
+// loadDir 读取目录下的 .gitignore，每个目录只加载一次
+func (m *gitignoreMatcher) loadDir(dir string) {
+	if m.loaded[dir] {
+		return
+	}
+	m.loaded[dir] = true
+
+	file, err := os.Open(filepath.Join(m.root, filepath.FromSlash(dir), ".gitignore"))
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if rule, ok := parseGitignoreLine(scanner.Text(), dir); ok {
+			m.rules = append(m.rules, rule)
+		}
+	}
+}
+
+// parseGitignoreLine 解析一行 .gitignore 规则
+func parseGitignoreLine(line, base string) (gitignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return gitignoreRule{}, false
+	}
+
+	rule := gitignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return gitignoreRule{}, false
+	}
+
+	// 包含斜杠的模式相对 .gitignore 所在目录锚定，否则匹配任意层级
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	expr := globToRegexp(line)
+	if anchored {
+		expr = "^" + expr + "$"
+	} else {
+		expr = "^(?:.*/)?" + expr + "$"
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return gitignoreRule{}, false
+	}
+	rule.re = re
+	return rule, true
+}
+
+// globToRegexp 将 gitignore 通配符转换为正则表达式
+func globToRegexp(pattern string) string {
+	var sb strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				// "**/" 匹配零或多级目录，其余 "**" 匹配任意内容
+				if i+2 < len(pattern) && pattern[i+2] == '/' {
+					sb.WriteString("(?:.*/)?")
+					i += 2
+				} else {
+					sb.WriteString(".*")
+					i++
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
+
+// isGitDir 判断路径是否位于 .git 目录中
+func isGitDir(rel string) bool {
+	for _, part := range strings.Split(rel, "/") {
+		if part == ".git" {
+			return true
+		}
+	}
+	return false
+}
+
+// parentDir 返回斜杠路径的上级目录，顶层路径返回空字符串
+func parentDir(rel string) string {
+	if i := strings.LastIndex(rel, "/"); i >= 0 {
+		return rel[:i]
+	}
+	return ""
+}
@@ -0,0 +1,187 @@
+package file
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+// setupGitignoreRepo 创建包含嵌套 .gitignore 的测试仓库
+func setupGitignoreRepo(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		".git/config":               "TODO",
+		".gitignore":                "# 依赖和构建产物\nnode_modules/\n*.log\n!keep.log\n/build\n",
+		"main.go":                   "TODO",
+		"app.log":                   "TODO",
+		"keep.log":                  "TODO",
+		"secret.txt":                "TODO",
+		"node_modules/lib/index.js": "TODO",
+		"build/out.go":              "TODO",
+		"sub/.gitignore":            "secret.txt\ndocs/**/draft.md\n",
+		"sub/secret.txt":            "TODO",
+		"sub/build/out.go":          "TODO",
+		"sub/docs/a/b/draft.md":     "TODO",
+		"sub/docs/final.md":         "TODO",
+	}
+	for rel, content := range files {
+		writeTestFile(t, filepath.Join(root, rel), content)
+	}
+	return root
+}
+
+func TestGitignoreMatcher(t *testing.T) {
+	root := setupGitignoreRepo(t)
+	matcher := newGitignoreMatcher(root)
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"main.go", false, false},
+		{"app.log", false, true},
+		{"keep.log", false, false},      // 取反规则
+		{"node_modules", true, true},    // 目录规则
+		{"node_modules", false, false},  // 目录规则不匹配同名文件
+		{"build", true, true},           // 锚定规则
+		{"sub/build", true, false},      // 锚定规则不匹配子目录
+		{"sub/secret.txt", false, true}, // 嵌套 .gitignore
+		{"secret.txt", false, false},    // 嵌套规则只作用于所在目录
+		{"sub/docs/a/b/draft.md", false, true},
+		{"sub/docs/draft.md", false, true}, // ** 匹配零级目录
+		{"sub/docs/final.md", false, false},
+		{".git", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got := matcher.match(filepath.Join(root, tt.path), tt.isDir)
+			if got != tt.want {
+				t.Errorf("match(%s, isDir=%v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+			}
+		})
+	}
+
+	// 上级目录被忽略时，其中的文件也被忽略
+	if !matcher.matchWithParents(filepath.Join(root, "node_modules/lib/index.js"), false) {
+		t.Error("matchWithParents 应忽略 node_modules 中的文件")
+	}
+}
+
+func TestSearchTool_RespectGitignore(t *testing.T) {
+	root := setupGitignoreRepo(t)
+
+	tests := []struct {
+		name    string
+		respect any // nil 表示使用默认值
+		want    []string
+	}{
+		{
+			name: "默认遵循 .gitignore",
+			want: []string{"keep.log", "main.go", "secret.txt", "sub/build/out.go", "sub/docs/final.md"},
+		},
+		{
+			name:    "关闭 .gitignore 仍跳过 .git",
+			respect: false,
+			want: []string{
+				"app.log", "build/out.go", "keep.log", "main.go", "node_modules/lib/index.js",
+				"secret.txt", "sub/build/out.go", "sub/docs/a/b/draft.md", "sub/docs/final.md", "sub/secret.txt",
+			},
+		},
+	}
+
+	tool := NewSearchTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := map[string]any{"pattern": "TODO", "path": root}
+			if tt.respect != nil {
+				params["respect_gitignore"] = tt.respect
+			}
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(params))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			var got []string
+			for _, match := range result.Metadata()["matches"].([]SearchMatch) {
+				rel, _ := filepath.Rel(root, match.File)
+				got = append(got, filepath.ToSlash(rel))
+			}
+			assertSameFiles(t, got, tt.want)
+		})
+	}
+}
+
+func TestGlobTool_RespectGitignore(t *testing.T) {
+	root := setupGitignoreRepo(t)
+
+	tests := []struct {
+		name    string
+		pattern string
+		respect any
+		want    []string
+	}{
+		{
+			name:    "递归匹配遵循 .gitignore",
+			pattern: "**/*.go",
+			want:    []string{"main.go", "sub/build/out.go"},
+		},
+		{
+			name:    "递归匹配关闭 .gitignore",
+			pattern: "**/*.go",
+			respect: false,
+			want:    []string{"build/out.go", "main.go", "sub/build/out.go"},
+		},
+		{
+			name:    "简单匹配遵循 .gitignore",
+			pattern: "*.log",
+			want:    []string{"keep.log"},
+		},
+		{
+			name:    "简单匹配忽略目录中的文件",
+			pattern: "node_modules/lib/*",
+			want:    nil,
+		},
+	}
+
+	tool := NewGlobTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := map[string]any{"pattern": tt.pattern, "path": root}
+			if tt.respect != nil {
+				params["respect_gitignore"] = tt.respect
+			}
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(params))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			var got []string
+			for _, file := range result.Metadata()["files"].([]string) {
+				rel, _ := filepath.Rel(root, file)
+				got = append(got, filepath.ToSlash(rel))
+			}
+			assertSameFiles(t, got, tt.want)
+		})
+	}
+}
+
+// assertSameFiles 忽略顺序比较文件列表
+func assertSameFiles(t *testing.T, got, want []string) {
+	t.Helper()
+	sort.Strings(got)
+	sort.Strings(want)
+	if len(got) != len(want) {
+		t.Fatalf("files = %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("files = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package file
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"opencode_nano/tools/core"
+)
+
+// GzipTool gzip 压缩和解压工具，以流式方式处理，不会把整个文件读入内存
+type GzipTool struct {
+	*core.BaseTool
+}
+
+// NewGzipTool 创建 gzip 工具
+func NewGzipTool() *GzipTool {
+	tool := &GzipTool{
+		BaseTool: core.NewBaseTool("gzip", "file", "Compress a file to .gz or decompress a .gz file"),
+	}
+
+	tool.SetRequiresPerm(true)
+	tool.SetTags("file", "gzip", "compress", "archive")
+	tool.SetSchema(core.ParameterSchema{
+		Type: "object",
+		Properties: map[string]core.PropertySchema{
+			"action": {
+				Type:        "string",
+				Description: "compress or decompress",
+				Enum:        []string{"compress", "decompress"},
+			},
+			"src": {
+				Type:        "string",
+				Description: "Source file",
+			},
+			"dst": {
+				Type:        "string",
+				Description: "Output file (default: src + .gz when compressing, src without .gz when decompressing)",
+			},
+			"level": {
+				Type:        "integer",
+				Description: "Compression level from 1 (fastest) to 9 (smallest)",
+				Minimum:     core.Bound(1),
+				Maximum:     core.Bound(9),
+			},
+			"overwrite": {
+				Type:        "boolean",
+				Description: "Overwrite the output file if it exists",
+				Default:     false,
+			},
+		},
+		Required: []string{"action", "src"},
+	})
+
+	return tool
+}
+
+// Execute 执行压缩或解压
+func (t *GzipTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	// 参数验证
+	if err := params.Validate(t.Schema()); err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+
+	action, err := params.GetString("action")
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, "invalid action parameter")
+	}
+
+	src, err := params.GetString("src")
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, "invalid src parameter")
+	}
+	src = filepath.Clean(src)
+
+	dst := ""
+	if params.Has("dst") {
+		dst, _ = params.GetString("dst")
+	}
+	if dst == "" {
+		dst, err = defaultGzipDst(action, src)
+		if err != nil {
+			return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+		}
+	}
+	dst = filepath.Clean(dst)
+
+	level := gzip.DefaultCompression
+	if params.Has("level") {
+		level, _ = params.GetInt("level")
+	}
+
+	overwrite := false
+	if params.Has("overwrite") {
+		overwrite, _ = params.GetBool("overwrite")
+	}
+
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("source not found: %s", src))
+		}
+		return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+	}
+	if srcInfo.IsDir() {
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("source is a directory: %s", src))
+	}
+	if dst == src {
+		return nil, core.ErrInvalidParams(t.Info().Name, "dst must differ from src")
+	}
+	if !overwrite {
+		if _, err := os.Stat(dst); err == nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name,
+				fmt.Sprintf("destination already exists: %s (set overwrite to replace)", dst))
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, core.ErrCancelled(t.Info().Name)
+	default:
+	}
+
+	var written int64
+	if action == "compress" {
+		written, err = writeStreamAtomic(dst, srcInfo.Mode().Perm(), func(w io.Writer) error {
+			return gzipFile(w, src, srcInfo, level)
+		})
+	} else {
+		written, err = writeStreamAtomic(dst, srcInfo.Mode().Perm(), func(w io.Writer) error {
+			return gunzipFile(w, src)
+		})
+	}
+	if err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to %s %s: %v", action, src, err))
+	}
+
+	// 压缩率 = 压缩后大小 / 原始大小
+	inputSize := srcInfo.Size()
+	compressed, original := written, inputSize
+	if action == "decompress" {
+		compressed, original = inputSize, written
+	}
+	ratio := 0.0
+	if original > 0 {
+		ratio = float64(compressed) / float64(original)
+	}
+
+	verb := "Compressed"
+	if action == "decompress" {
+		verb = "Decompressed"
+	}
+	result := core.NewSimpleResult(fmt.Sprintf("%s %s (%d bytes) to %s (%d bytes), compression ratio %.1f%%",
+		verb, src, inputSize, dst, written, ratio*100))
+	result.WithMetadata("action", action)
+	result.WithMetadata("src", src)
+	result.WithMetadata("dst", dst)
+	result.WithMetadata("input_size", inputSize)
+	result.WithMetadata("output_size", written)
+	result.WithMetadata("ratio", ratio)
+
+	return result, nil
+}
+
+// defaultGzipDst 返回默认的输出路径
+func defaultGzipDst(action, src string) (string, error) {
+	if action == "compress" {
+		return src + ".gz", nil
+	}
+	if strings.HasSuffix(src, ".tgz") {
+		return strings.TrimSuffix(src, ".tgz") + ".tar", nil
+	}
+	if strings.HasSuffix(src, ".gz") && len(src) > len(".gz") {
+		return strings.TrimSuffix(src, ".gz"), nil
+	}
+	return "", fmt.Errorf("src has no .gz suffix; dst is required")
+}
+
+// gzipFile 将 src 压缩写入 w，头部记录原文件名和修改时间
+func gzipFile(w io.Writer, src string, srcInfo os.FileInfo, level int) error {
+	source, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	zw, err := gzip.NewWriterLevel(w, level)
+	if err != nil {
+		return err
+	}
+	zw.Name = filepath.Base(src)
+	zw.ModTime = srcInfo.ModTime()
+	if _, err := io.Copy(zw, source); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// gunzipFile 将 gzip 文件 src 解压写入 w
+func gunzipFile(w io.Writer, src string) error {
+	source, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	zr, err := gzip.NewReader(source)
+	if err != nil {
+		return fmt.Errorf("not a gzip file: %v", err)
+	}
+	defer zr.Close()
+	_, err = io.Copy(w, zr)
+	return err
+}
@@ -0,0 +1,116 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+func TestGzipTool_RoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcPath := filepath.Join(tmpDir, "log.txt")
+	content := strings.Repeat("the same line again and again\n", 200)
+	writeTestFile(t, srcPath, content)
+
+	tool := NewGzipTool()
+	if !tool.Info().RequiresPerm {
+		t.Error("gzip 写入文件，应需要权限")
+	}
+
+	// 压缩到默认路径 log.txt.gz
+	result, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"action": "compress",
+		"src":    srcPath,
+		"level":  9,
+	}))
+	if err != nil {
+		t.Fatalf("compress error = %v", err)
+	}
+	gzPath := srcPath + ".gz"
+	metadata := result.Metadata()
+	if metadata["dst"] != gzPath {
+		t.Errorf("dst = %v, want %s", metadata["dst"], gzPath)
+	}
+	if metadata["input_size"] != int64(len(content)) {
+		t.Errorf("input_size = %v, want %d", metadata["input_size"], len(content))
+	}
+	info, err := os.Stat(gzPath)
+	if err != nil {
+		t.Fatalf("压缩文件不存在: %v", err)
+	}
+	if metadata["output_size"] != info.Size() {
+		t.Errorf("output_size = %v, want %d", metadata["output_size"], info.Size())
+	}
+	if ratio, _ := metadata["ratio"].(float64); ratio <= 0 || ratio >= 0.1 {
+		t.Errorf("重复内容的压缩率 = %v, 应远小于 1", ratio)
+	}
+	if !strings.Contains(result.String(), "compression ratio") {
+		t.Errorf("输出缺少压缩率: %s", result.String())
+	}
+
+	// 目标已存在且未设置 overwrite 时拒绝
+	if _, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"action": "decompress",
+		"src":    gzPath,
+	})); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("覆盖已有文件应报错, got %v", err)
+	}
+
+	// 解压到新路径后内容一致
+	outPath := filepath.Join(tmpDir, "out", "log.txt")
+	result, err = tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"action": "decompress",
+		"src":    gzPath,
+		"dst":    outPath,
+	}))
+	if err != nil {
+		t.Fatalf("decompress error = %v", err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != content {
+		t.Error("解压后的内容与原文件不一致")
+	}
+	if result.Metadata()["output_size"] != int64(len(content)) {
+		t.Errorf("output_size = %v, want %d", result.Metadata()["output_size"], len(content))
+	}
+}
+
+func TestGzipTool_Errors(t *testing.T) {
+	tmpDir := t.TempDir()
+	plainPath := filepath.Join(tmpDir, "plain.txt")
+	writeTestFile(t, plainPath, "not compressed")
+	fakeGz := filepath.Join(tmpDir, "fake.gz")
+	writeTestFile(t, fakeGz, "not compressed")
+
+	tests := []struct {
+		name    string
+		params  map[string]any
+		wantErr string
+	}{
+		{"没有 .gz 后缀需要指定 dst", map[string]any{"action": "decompress", "src": plainPath}, "dst is required"},
+		{"不是 gzip 文件", map[string]any{"action": "decompress", "src": fakeGz}, "not a gzip file"},
+		{"源文件不存在", map[string]any{"action": "compress", "src": filepath.Join(tmpDir, "missing")}, "source not found"},
+	}
+
+	tool := NewGzipTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tool.Execute(context.Background(), core.NewMapParameters(tt.params))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Execute() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// 解压失败时不留下输出文件
+	if _, err := os.Stat(filepath.Join(tmpDir, "fake")); !os.IsNotExist(err) {
+		t.Errorf("解压失败后不应留下输出文件: %v", err)
+	}
+}
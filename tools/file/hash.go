@@ -0,0 +1,137 @@
+package file
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"opencode_nano/tools/core"
+)
+
+// HashTool 文件校验和工具
+type HashTool struct {
+	*core.BaseTool
+}
+
+// NewHashTool 创建校验和工具
+func NewHashTool() *HashTool {
+	tool := &HashTool{
+		BaseTool: core.NewBaseTool("hash", "file", "Compute the checksum of a file"),
+	}
+
+	tool.SetTags("file", "hash", "checksum", "verify")
+	tool.SetSchema(core.ParameterSchema{
+		Type: "object",
+		Properties: map[string]core.PropertySchema{
+			"path": {
+				Type:        "string",
+				Description: "File path to hash",
+			},
+			"algorithm": {
+				Type:        "string",
+				Description: "Hash algorithm: md5, sha1, sha256",
+				Default:     "sha256",
+				Enum:        []string{"md5", "sha1", "sha256"},
+			},
+			"verify": {
+				Type:        "string",
+				Description: "Expected hex digest to compare against (optional)",
+			},
+		},
+		Required: []string{"path"},
+	})
+
+	return tool
+}
+
+// Execute 计算文件校验和
+func (t *HashTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	// 参数验证
+	if err := params.Validate(t.Schema()); err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+
+	filePath, err := params.GetString("path")
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, "invalid path parameter")
+	}
+	filePath = filepath.Clean(filePath)
+
+	algorithm := "sha256"
+	if params.Has("algorithm") {
+		algorithm, _ = params.GetString("algorithm")
+	}
+
+	expected := ""
+	if params.Has("verify") {
+		expected, _ = params.GetString("verify")
+		expected = strings.ToLower(strings.TrimSpace(expected))
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("file not found: %s", filePath))
+		}
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to open file: %v", err))
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+	}
+	if fileInfo.IsDir() {
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("path is a directory: %s", filePath))
+	}
+
+	hasher := newHasher(algorithm)
+
+	// 流式计算，避免将大文件整个读入内存
+	size, err := io.Copy(hasher, file)
+	if err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to read file: %v", err))
+	}
+	digest := hex.EncodeToString(hasher.Sum(nil))
+
+	output := fmt.Sprintf("%s  %s", digest, filePath)
+	if expected != "" {
+		if digest == expected {
+			output += "\nVerification: match"
+		} else {
+			output += fmt.Sprintf("\nVerification: MISMATCH (expected %s)", expected)
+		}
+	}
+
+	result := core.NewSimpleResult(output)
+	result.WithMetadata("path", filePath)
+	result.WithMetadata("algorithm", algorithm)
+	result.WithMetadata("digest", digest)
+	result.WithMetadata("size", size)
+	if expected != "" {
+		result.WithMetadata("expected", expected)
+		result.WithMetadata("match", digest == expected)
+	}
+
+	return result, nil
+}
+
+// newHasher 根据算法名创建哈希器，默认 sha256
+func newHasher(algorithm string) hash.Hash {
+	switch algorithm {
+	case "md5":
+		return md5.New()
+	case "sha1":
+		return sha1.New()
+	default:
+		return sha256.New()
+	}
+}
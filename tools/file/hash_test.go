@@ -0,0 +1,111 @@
+package file
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+func TestHashTool_Execute(t *testing.T) {
+	tmpDir := t.TempDir()
+	helloPath := filepath.Join(tmpDir, "hello.txt")
+	writeTestFile(t, helloPath, "hello world\n")
+	emptyPath := filepath.Join(tmpDir, "empty.txt")
+	writeTestFile(t, emptyPath, "")
+
+	tests := []struct {
+		name       string
+		params     map[string]any
+		wantDigest string
+		wantSize   int64
+		wantMatch  any // nil 表示未校验
+		wantErr    bool
+	}{
+		{
+			name:       "默认 sha256",
+			params:     map[string]any{"path": helloPath},
+			wantDigest: "a948904f2f0f479b8f8197694b30184b0d2ed1c1cd2a1ec0fb85d299a192a447",
+			wantSize:   12,
+		},
+		{
+			name:       "md5",
+			params:     map[string]any{"path": helloPath, "algorithm": "md5"},
+			wantDigest: "6f5902ac237024bdd0c176cb93063dc4",
+			wantSize:   12,
+		},
+		{
+			name:       "sha1",
+			params:     map[string]any{"path": helloPath, "algorithm": "sha1"},
+			wantDigest: "22596363b3de40b06f981fb85d82312e8c0ed511",
+			wantSize:   12,
+		},
+		{
+			name:       "空文件",
+			params:     map[string]any{"path": emptyPath},
+			wantDigest: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			wantSize:   0,
+		},
+		{
+			name:       "校验匹配（忽略大小写）",
+			params:     map[string]any{"path": helloPath, "algorithm": "md5", "verify": "6F5902AC237024BDD0C176CB93063DC4"},
+			wantDigest: "6f5902ac237024bdd0c176cb93063dc4",
+			wantSize:   12,
+			wantMatch:  true,
+		},
+		{
+			name:       "校验不匹配",
+			params:     map[string]any{"path": helloPath, "algorithm": "md5", "verify": "00000000000000000000000000000000"},
+			wantDigest: "6f5902ac237024bdd0c176cb93063dc4",
+			wantSize:   12,
+			wantMatch:  false,
+		},
+		{
+			name:    "不支持的算法",
+			params:  map[string]any{"path": helloPath, "algorithm": "crc32"},
+			wantErr: true,
+		},
+		{
+			name:    "文件不存在",
+			params:  map[string]any{"path": filepath.Join(tmpDir, "missing")},
+			wantErr: true,
+		},
+		{
+			name:    "路径为目录",
+			params:  map[string]any{"path": tmpDir},
+			wantErr: true,
+		},
+	}
+
+	tool := NewHashTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(tt.params))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			metadata := result.Metadata()
+			if metadata["digest"] != tt.wantDigest {
+				t.Errorf("digest = %v, want %s", metadata["digest"], tt.wantDigest)
+			}
+			if metadata["size"] != tt.wantSize {
+				t.Errorf("size = %v, want %d", metadata["size"], tt.wantSize)
+			}
+			if metadata["match"] != tt.wantMatch {
+				t.Errorf("match = %v, want %v", metadata["match"], tt.wantMatch)
+			}
+			if !strings.HasPrefix(result.String(), tt.wantDigest) {
+				t.Errorf("输出应以摘要开头: %s", result.String())
+			}
+			if tt.wantMatch == false && !strings.Contains(result.String(), "MISMATCH") {
+				t.Errorf("校验不匹配时输出应提示 MISMATCH: %s", result.String())
+			}
+		})
+	}
+}
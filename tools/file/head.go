@@ -0,0 +1,245 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"opencode_nano/tools/core"
+)
+
+// tailChunkSize TailTool 每次从文件末尾向前读取的字节数
+const tailChunkSize = 4096
+
+// tailMaxBytes TailTool 最多从文件末尾读取的字节数，避免没有换行的大文件被整个读入内存
+var tailMaxBytes int64 = 8 << 20
+
+// HeadTool 读取文件开头若干行的工具
+type HeadTool struct {
+	*core.BaseTool
+}
+
+// NewHeadTool 创建 head 工具
+func NewHeadTool() *HeadTool {
+	tool := &HeadTool{
+		BaseTool: core.NewBaseTool("head", "file", "Read the first N lines of a file"),
+	}
+
+	tool.SetTags("file", "read", "head")
+	tool.SetSchema(lineRangeSchema())
+
+	return tool
+}
+
+// Execute 读取文件开头的行
+func (t *HeadTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	filePath, lines, err := parseLineRangeParams(t.Info().Name, t.Schema(), params)
+	if err != nil {
+		return nil, err
+	}
+
+	file, fileInfo, err := openRegularFile(t.Info().Name, filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var selected []string
+	reachedEOF := false
+	for len(selected) < lines {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			selected = append(selected, strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"))
+		}
+		if err == io.EOF {
+			reachedEOF = true
+			break
+		}
+		if err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to read file: %v", err))
+		}
+	}
+
+	// 只有读到文件末尾时才知道总行数
+	if !reachedEOF {
+		if _, err := reader.Peek(1); err == io.EOF {
+			reachedEOF = true
+		}
+	}
+
+	result := core.NewSimpleResult(strings.Join(selected, "\n"))
+	result.WithMetadata("path", filePath)
+	result.WithMetadata("lines", len(selected))
+	result.WithMetadata("size", fileInfo.Size())
+	if reachedEOF {
+		result.WithMetadata("total_lines", len(selected))
+	}
+
+	return result, nil
+}
+
+// TailTool 读取文件末尾若干行的工具
+type TailTool struct {
+	*core.BaseTool
+}
+
+// NewTailTool 创建 tail 工具
+func NewTailTool() *TailTool {
+	tool := &TailTool{
+		BaseTool: core.NewBaseTool("tail", "file", "Read the last N lines of a file"),
+	}
+
+	tool.SetTags("file", "read", "tail", "log")
+	tool.SetSchema(lineRangeSchema())
+
+	return tool
+}
+
+// Execute 从文件末尾向前分块读取，避免加载整个文件
+func (t *TailTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	filePath, lines, err := parseLineRangeParams(t.Info().Name, t.Schema(), params)
+	if err != nil {
+		return nil, err
+	}
+
+	file, fileInfo, err := openRegularFile(t.Info().Name, filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// 逐块统计换行数，块按读取顺序（从后往前）保存，最后只拼接一次
+	size := fileInfo.Size()
+	pos := size
+	var chunks [][]byte
+	var read int64
+	newlines := 0
+	for pos > 0 && newlines < lines && read < tailMaxBytes {
+		select {
+		case <-ctx.Done():
+			return nil, core.ErrCancelled(t.Info().Name)
+		default:
+		}
+
+		readSize := min(int64(tailChunkSize), pos, tailMaxBytes-read)
+		pos -= readSize
+
+		chunk := make([]byte, readSize)
+		if _, err := file.ReadAt(chunk, pos); err != nil && err != io.EOF {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to read file: %v", err))
+		}
+		newlines += bytes.Count(chunk, []byte("\n"))
+		// 文件末尾的换行不算作分隔符
+		if read == 0 && chunk[len(chunk)-1] == '\n' {
+			newlines--
+		}
+		chunks = append(chunks, chunk)
+		read += readSize
+	}
+	// 达到字节上限仍没有足够的换行时，返回的第一行只是该行的末尾部分
+	truncated := pos > 0 && newlines < lines
+
+	data := make([]byte, 0, read)
+	for i := len(chunks) - 1; i >= 0; i-- {
+		data = append(data, chunks[i]...)
+	}
+
+	var all []string
+	if len(data) > 0 {
+		all = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+	// 读到文件开头时可以得到总行数
+	totalLines := -1
+	if pos == 0 {
+		totalLines = len(all)
+	}
+
+	selected := all
+	if len(selected) > lines {
+		selected = selected[len(selected)-lines:]
+	}
+	for i, line := range selected {
+		selected[i] = strings.TrimSuffix(line, "\r")
+	}
+
+	result := core.NewSimpleResult(strings.Join(selected, "\n"))
+	result.WithMetadata("path", filePath)
+	result.WithMetadata("lines", len(selected))
+	result.WithMetadata("size", size)
+	if totalLines >= 0 {
+		result.WithMetadata("total_lines", totalLines)
+	}
+	if truncated {
+		result.WithMetadata("truncated", true)
+	}
+
+	return result, nil
+}
+
+// lineRangeSchema head/tail 共用的参数 schema
+func lineRangeSchema() core.ParameterSchema {
+	return core.ParameterSchema{
+		Type: "object",
+		Properties: map[string]core.PropertySchema{
+			"path": {
+				Type:        "string",
+				Description: "File path to read",
+			},
+			"lines": {
+				Type:        "integer",
+				Description: "Number of lines to return (default: 10, at most 10000)",
+				Default:     10,
+				Maximum:     core.Bound(10000),
+			},
+		},
+		Required: []string{"path"},
+	}
+}
+
+// parseLineRangeParams 解析 head/tail 的参数
+func parseLineRangeParams(toolName string, schema core.ParameterSchema, params core.Parameters) (string, int, error) {
+	if err := params.Validate(schema); err != nil {
+		return "", 0, core.ErrInvalidParams(toolName, err.Error())
+	}
+
+	filePath, err := params.GetString("path")
+	if err != nil {
+		return "", 0, core.ErrInvalidParams(toolName, "invalid path parameter")
+	}
+
+	lines := 10
+	if params.Has("lines") {
+		lines, _ = params.GetInt("lines")
+	}
+	if lines <= 0 {
+		return "", 0, core.ErrInvalidParams(toolName, "lines must be positive")
+	}
+
+	return filepath.Clean(filePath), lines, nil
+}
+
+// openRegularFile 打开普通文件，路径不存在或为目录时返回工具错误
+func openRegularFile(toolName, filePath string) (*os.File, os.FileInfo, error) {
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, core.ErrExecutionFailed(toolName, fmt.Sprintf("file not found: %s", filePath))
+		}
+		return nil, nil, core.ErrExecutionFailed(toolName, err.Error())
+	}
+	if fileInfo.IsDir() {
+		return nil, nil, core.ErrExecutionFailed(toolName, fmt.Sprintf("path is a directory: %s", filePath))
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, core.ErrExecutionFailed(toolName, fmt.Sprintf("failed to open file: %v", err))
+	}
+	return file, fileInfo, nil
+}
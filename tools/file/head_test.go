@@ -0,0 +1,119 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+// numberedLines 生成 "line 1" 到 "line n" 的内容
+func numberedLines(n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i+1)
+	}
+	return lines
+}
+
+func TestHeadTailTools(t *testing.T) {
+	root := t.TempDir()
+	// 大文件跨越多个读取块
+	large := numberedLines(5000)
+	writeTestFile(t, filepath.Join(root, "large.log"), strings.Join(large, "\n")+"\n")
+	writeTestFile(t, filepath.Join(root, "small.log"), "a\nb\nc\n")
+	writeTestFile(t, filepath.Join(root, "no_newline.log"), "a\nb\nc")
+	writeTestFile(t, filepath.Join(root, "crlf.log"), "a\r\nb\r\n")
+	writeTestFile(t, filepath.Join(root, "empty.log"), "")
+
+	tests := []struct {
+		name      string
+		tool      core.Tool
+		file      string
+		lines     any
+		want      string
+		wantTotal any
+		wantErr   bool
+	}{
+		{"head 默认 10 行", NewHeadTool(), "large.log", nil, strings.Join(large[:10], "\n"), nil, false},
+		{"head 指定行数", NewHeadTool(), "large.log", 3, "line 1\nline 2\nline 3", nil, false},
+		{"head 文件行数少于请求", NewHeadTool(), "small.log", 10, "a\nb\nc", 3, false},
+		{"head 恰好读完文件", NewHeadTool(), "small.log", 3, "a\nb\nc", 3, false},
+		{"head CRLF", NewHeadTool(), "crlf.log", 5, "a\nb", 2, false},
+		{"tail 默认 10 行", NewTailTool(), "large.log", nil, strings.Join(large[4990:], "\n"), nil, false},
+		{"tail 跨块读取", NewTailTool(), "large.log", 1000, strings.Join(large[4000:], "\n"), nil, false},
+		{"tail 文件行数少于请求", NewTailTool(), "small.log", 10, "a\nb\nc", 3, false},
+		{"tail 无结尾换行", NewTailTool(), "no_newline.log", 2, "b\nc", 3, false},
+		{"tail CRLF", NewTailTool(), "crlf.log", 1, "b", 2, false},
+		{"tail 空文件", NewTailTool(), "empty.log", 5, "", nil, false},
+		{"行数非法", NewTailTool(), "small.log", 0, "", nil, true},
+		{"行数超过上限", NewTailTool(), "small.log", 10001, "", nil, true},
+		{"文件不存在", NewHeadTool(), "missing.log", 5, "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := map[string]any{"path": filepath.Join(root, tt.file)}
+			if tt.lines != nil {
+				params["lines"] = tt.lines
+			}
+			result, err := tt.tool.Execute(context.Background(), core.NewMapParameters(params))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if result.String() != tt.want {
+				t.Errorf("输出 = %q, want %q", result.String(), tt.want)
+			}
+			if tt.wantTotal != nil && result.Metadata()["total_lines"] != tt.wantTotal {
+				t.Errorf("total_lines = %v, want %v", result.Metadata()["total_lines"], tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestHeadTool_TotalLinesUnknown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "large.log")
+	writeTestFile(t, path, strings.Join(numberedLines(100), "\n")+"\n")
+
+	// 未读到文件末尾时不应给出总行数
+	result, err := NewHeadTool().Execute(context.Background(), core.NewMapParameters(map[string]any{"path": path, "lines": 5}))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if _, ok := result.Metadata()["total_lines"]; ok {
+		t.Errorf("total_lines 不应存在: %v", result.Metadata()["total_lines"])
+	}
+}
+
+func TestTailTool_MaxBytes(t *testing.T) {
+	oldMax := tailMaxBytes
+	tailMaxBytes = 16
+	defer func() {
+		tailMaxBytes = oldMax
+	}()
+
+	path := filepath.Join(t.TempDir(), "long.log")
+	writeTestFile(t, path, "a\n"+strings.Repeat("x", 100))
+
+	// 没有换行的长行只读取末尾的 tailMaxBytes 字节
+	result, err := NewTailTool().Execute(context.Background(), core.NewMapParameters(map[string]any{"path": path, "lines": 2}))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if want := strings.Repeat("x", 16); result.String() != want {
+		t.Errorf("输出 = %q, want %q", result.String(), want)
+	}
+	if result.Metadata()["truncated"] != true {
+		t.Errorf("truncated = %v, want true", result.Metadata()["truncated"])
+	}
+	if _, ok := result.Metadata()["total_lines"]; ok {
+		t.Errorf("total_lines 不应存在: %v", result.Metadata()["total_lines"])
+	}
+}
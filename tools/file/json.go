@@ -0,0 +1,289 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"opencode_nano/tools/core"
+)
+
+// JSONTool JSON 格式化、压缩和按路径取值的工具
+type JSONTool struct {
+	*core.BaseTool
+}
+
+// NewJSONTool 创建 JSON 工具
+func NewJSONTool() *JSONTool {
+	tool := &JSONTool{
+		BaseTool: core.NewBaseTool("json", "file", "Pretty-print, minify, or query JSON from a file or inline content"),
+	}
+
+	tool.SetTags("file", "json", "format", "query")
+	tool.SetSchema(core.ParameterSchema{
+		Type: "object",
+		Properties: map[string]core.PropertySchema{
+			"action": {
+				Type:        "string",
+				Description: "format: pretty-print; minify: remove whitespace; get: extract the value at query",
+				Enum:        []string{"format", "minify", "get"},
+			},
+			"path": {
+				Type:        "string",
+				Description: "JSON file to read (use either path or content)",
+			},
+			"content": {
+				Type:        "string",
+				Description: "Inline JSON content (use either path or content)",
+			},
+			"query": {
+				Type:        "string",
+				Description: "Dotted path for get, e.g. 'a.b[0].c' or '[2].name'",
+			},
+			"indent": {
+				Type:        "integer",
+				Description: "Spaces per indentation level for format",
+				Default:     2,
+				Minimum:     core.Bound(0),
+				Maximum:     core.Bound(8),
+			},
+		},
+		Required: []string{"action"},
+	})
+
+	return tool
+}
+
+// Execute 处理 JSON
+func (t *JSONTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	// 参数验证
+	if err := params.Validate(t.Schema()); err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+
+	action, err := params.GetString("action")
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, "invalid action parameter")
+	}
+
+	data, source, err := t.input(params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 先校验 JSON，出错时报告位置
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, describeJSONError(data, err))
+	}
+
+	var output string
+	metadata := map[string]any{}
+	switch action {
+	case "format":
+		indent := 2
+		if params.Has("indent") {
+			indent, _ = params.GetInt("indent")
+		}
+		var buf bytes.Buffer
+		json.Indent(&buf, compact.Bytes(), "", strings.Repeat(" ", indent))
+		output = buf.String()
+	case "minify":
+		output = compact.String()
+	case "get":
+		query := ""
+		if params.Has("query") {
+			query, _ = params.GetString("query")
+		}
+		value, err := queryJSON(compact.Bytes(), query)
+		if err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+		}
+		output, err = encodeJSONValue(value)
+		if err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+		}
+		metadata["query"] = query
+		metadata["type"] = jsonType(value)
+	}
+
+	result := core.NewSimpleResult(output)
+	result.WithMetadata("action", action)
+	result.WithMetadata("source", source)
+	result.WithMetadata("size", len(output))
+	for k, v := range metadata {
+		result.WithMetadata(k, v)
+	}
+	return result, nil
+}
+
+// input 读取 path 或 content 指定的 JSON
+func (t *JSONTool) input(params core.Parameters) ([]byte, string, error) {
+	hasPath, hasContent := params.Has("path"), params.Has("content")
+	if hasPath == hasContent {
+		return nil, "", core.ErrInvalidParams(t.Info().Name, "exactly one of path or content is required")
+	}
+
+	if hasContent {
+		content, err := params.GetString("content")
+		if err != nil {
+			return nil, "", core.ErrInvalidParams(t.Info().Name, "invalid content parameter")
+		}
+		return []byte(content), "content", nil
+	}
+
+	filePath, err := params.GetString("path")
+	if err != nil {
+		return nil, "", core.ErrInvalidParams(t.Info().Name, "invalid path parameter")
+	}
+	filePath = filepath.Clean(filePath)
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, "", core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("file not found: %s", filePath))
+		}
+		return nil, "", core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to read file: %v", err))
+	}
+	return data, filePath, nil
+}
+
+// describeJSONError 描述 JSON 解析错误，语法错误时带上偏移量和行列号
+func describeJSONError(data []byte, err error) string {
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return fmt.Sprintf("invalid JSON: %v", err)
+	}
+	// Offset 是出错时已读取的字节数，出错的字符位于 Offset-1
+	pos := int(syntaxErr.Offset) - 1
+	if pos > len(data) {
+		pos = len(data)
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	line := bytes.Count(data[:pos], []byte("\n")) + 1
+	column := pos - bytes.LastIndexByte(data[:pos], '\n')
+	return fmt.Sprintf("invalid JSON at offset %d (line %d, column %d): %v", syntaxErr.Offset, line, column, err)
+}
+
+// queryJSON 按 a.b[0].c 形式的路径取值，路径为空时返回整个文档
+func queryJSON(data []byte, query string) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+
+	steps, err := parseJSONQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	current := ""
+	for _, step := range steps {
+		if step.key != nil {
+			current = strings.TrimPrefix(current+"."+*step.key, ".")
+			object, ok := value.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("cannot get key %q at %s: value is %s, not an object", *step.key, current, jsonType(value))
+			}
+			next, ok := object[*step.key]
+			if !ok {
+				return nil, fmt.Errorf("key %q not found at %s", *step.key, current)
+			}
+			value = next
+			continue
+		}
+
+		current += fmt.Sprintf("[%d]", step.index)
+		array, ok := value.([]any)
+		if !ok {
+			return nil, fmt.Errorf("cannot index %s: value is %s, not an array", current, jsonType(value))
+		}
+		if step.index < 0 || step.index >= len(array) {
+			return nil, fmt.Errorf("index %d out of range at %s (length %d)", step.index, current, len(array))
+		}
+		value = array[step.index]
+	}
+	return value, nil
+}
+
+// jsonStep 路径中的一步：对象键或数组下标
+type jsonStep struct {
+	key   *string
+	index int
+}
+
+// parseJSONQuery 解析 a.b[0].c 形式的路径
+func parseJSONQuery(query string) ([]jsonStep, error) {
+	var steps []jsonStep
+	rest := strings.TrimSpace(query)
+	for rest != "" {
+		switch {
+		case rest[0] == '.':
+			rest = rest[1:]
+			if rest == "" || rest[0] == '.' || rest[0] == '[' {
+				return nil, fmt.Errorf("invalid query %q: empty key", query)
+			}
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid query %q: missing ]", query)
+			}
+			index, err := strconv.Atoi(strings.TrimSpace(rest[1:end]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid query %q: index must be an integer", query)
+			}
+			steps = append(steps, jsonStep{index: index})
+			rest = rest[end+1:]
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			steps = append(steps, jsonStep{key: &key})
+			rest = rest[end:]
+		}
+	}
+	return steps, nil
+}
+
+// encodeJSONValue 以缩进的 JSON 输出取到的值
+func encodeJSONValue(value any) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return "", fmt.Errorf("failed to encode value: %v", err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// jsonType 返回值的 JSON 类型名
+func jsonType(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
@@ -0,0 +1,103 @@
+package file
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+func TestJSONTool_Execute(t *testing.T) {
+	tmpDir := t.TempDir()
+	dataPath := filepath.Join(tmpDir, "data.json")
+	writeTestFile(t, dataPath, `{"a": {"b": [{"c": 1.50}, {"c": "<x>"}]}, "ok": true}`)
+
+	tests := []struct {
+		name    string
+		params  map[string]any
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "格式化文件",
+			params: map[string]any{"action": "format", "path": dataPath},
+			want:   "{\n  \"a\": {\n    \"b\": [\n      {\n        \"c\": 1.50\n      },\n      {\n        \"c\": \"<x>\"\n      }\n    ]\n  },\n  \"ok\": true\n}",
+		},
+		{
+			name:   "自定义缩进",
+			params: map[string]any{"action": "format", "content": `[1, {"k": null}]`, "indent": 4},
+			want:   "[\n    1,\n    {\n        \"k\": null\n    }\n]",
+		},
+		{
+			name:   "压缩",
+			params: map[string]any{"action": "minify", "content": "{\n  \"a\": [1, 2],\n  \"b\": \"x y\"\n}"},
+			want:   `{"a":[1,2],"b":"x y"}`,
+		},
+		{
+			name:   "按路径取数字",
+			params: map[string]any{"action": "get", "path": dataPath, "query": "a.b[0].c"},
+			want:   "1.50",
+		},
+		{
+			name:   "按路径取字符串",
+			params: map[string]any{"action": "get", "path": dataPath, "query": "a.b[1].c"},
+			want:   `"<x>"`,
+		},
+		{
+			name:   "以下标开头的路径",
+			params: map[string]any{"action": "get", "content": `[{"name": "n0"}, {"name": "n1"}]`, "query": "[1].name"},
+			want:   `"n1"`,
+		},
+		{
+			name:    "下标越界",
+			params:  map[string]any{"action": "get", "path": dataPath, "query": "a.b[5].c"},
+			wantErr: "index 5 out of range at a.b[5] (length 2)",
+		},
+		{
+			name:    "键不存在",
+			params:  map[string]any{"action": "get", "path": dataPath, "query": "a.missing"},
+			wantErr: `key "missing" not found at a.missing`,
+		},
+		{
+			name:    "对标量取下标",
+			params:  map[string]any{"action": "get", "path": dataPath, "query": "ok[0]"},
+			wantErr: "value is boolean, not an array",
+		},
+		{
+			name:    "非法 JSON 报告位置",
+			params:  map[string]any{"action": "format", "content": "{\n  \"a\": 1,\n  \"b\": }"},
+			wantErr: "invalid JSON at offset 20 (line 3, column 8)",
+		},
+		{
+			name:    "path 和 content 同时指定",
+			params:  map[string]any{"action": "minify", "path": dataPath, "content": "{}"},
+			wantErr: "exactly one of path or content is required",
+		},
+		{
+			name:    "文件不存在",
+			params:  map[string]any{"action": "format", "path": filepath.Join(tmpDir, "missing.json")},
+			wantErr: "file not found",
+		},
+	}
+
+	tool := NewJSONTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(tt.params))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Execute() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := result.String(); got != tt.want {
+				t.Errorf("Execute() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -58,11 +58,33 @@ func NewListTool() *ListTool {
 				Description: "Maximum depth for recursive listing",
 				Default:     10,
 			},
+			"max_entries": {
+				Type:        "integer",
+				Description: "Stop listing after this many entries (files and directories)",
+				Default:     5000,
+				Minimum:     core.Bound(1),
+			},
 			"include_details": {
 				Type:        "boolean",
 				Description: "Include file details (size, permissions, etc)",
 				Default:     true,
 			},
+			"entry_type": {
+				Type:        "string",
+				Description: "Only list files or only directories; with recursive, file lists every file under path by relative name",
+				Default:     "any",
+				Enum:        []string{"any", "file", "dir"},
+			},
+			"include": {
+				Type:        "array",
+				Description: "Only list files whose base name matches one of these globs (e.g. '*.go'); directories are still shown",
+				Items:       &core.PropertySchema{Type: "string"},
+			},
+			"exclude": {
+				Type:        "array",
+				Description: "Skip files and directories whose base name matches one of these globs (e.g. '*_test.go')",
+				Items:       &core.PropertySchema{Type: "string"},
+			},
 		},
 		Required: []string{},
 	})
@@ -126,6 +148,26 @@ func (t *ListTool) Execute(ctx context.Context, params core.Parameters) (core.Re
 		includeDetails, _ = params.GetBool("include_details")
 	}
 	
+	maxEntries := 5000
+	if params.Has("max_entries") {
+		maxEntries, _ = params.GetInt("max_entries")
+	}
+	limit := &listLimit{remaining: maxEntries}
+	
+	entryType := "any"
+	if params.Has("entry_type") {
+		entryType, _ = params.GetString("entry_type")
+	}
+	
+	filter := listFilter{showHidden: showHidden}
+	var err error
+	if filter.include, err = globListParam(params, "include"); err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+	if filter.exclude, err = globListParam(params, "exclude"); err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+	
 	// 规范化路径
 	path = filepath.Clean(path)
 	
@@ -142,12 +184,11 @@ func (t *ListTool) Execute(ctx context.Context, params core.Parameters) (core.Re
 	if info.IsDir() {
 		// 列出目录内容
 		if recursive {
-			rootInfo, err := t.listRecursive(ctx, path, showHidden, includeDetails, 0, maxDepth)
+			rootInfo, err := t.listRecursive(ctx, path, filter, includeDetails, 0, maxDepth, limit)
 			if err != nil {
 				return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
 			}
 			files = rootInfo.Children
-			t.countStats(rootInfo, &totalSize, &fileCount, &dirCount)
 		} else {
 			entries, err := os.ReadDir(path)
 			if err != nil {
@@ -155,24 +196,26 @@ func (t *ListTool) Execute(ctx context.Context, params core.Parameters) (core.Re
 			}
 			
 			for _, entry := range entries {
-				// 检查是否显示隐藏文件
-				if !showHidden && strings.HasPrefix(entry.Name(), ".") {
+				if !filter.keep(entry.Name(), entry.IsDir()) {
 					continue
 				}
+				if !limit.take() {
+					break
+				}
 				
 				fileInfo, err := t.getFileInfo(filepath.Join(path, entry.Name()), includeDetails)
 				if err == nil {
 					files = append(files, fileInfo)
-					totalSize += fileInfo.Size
-					if fileInfo.IsDir {
-						dirCount++
-					} else {
-						fileCount++
-					}
 				}
 			}
 		}
 		
+		// 按条目类型过滤后再统计，总数与列出的结果一致
+		files = filterEntryType(files, entryType, "")
+		for _, f := range files {
+			t.countStats(f, &totalSize, &fileCount, &dirCount)
+		}
+		
 		// 排序
 		t.sortFiles(files, sortBy, reverse)
 	} else {
@@ -191,6 +234,9 @@ func (t *ListTool) Execute(ctx context.Context, params core.Parameters) (core.Re
 	if info.IsDir() {
 		summary = fmt.Sprintf("Listed %d files and %d directories (total size: %s)", 
 			fileCount, dirCount, formatSize(totalSize))
+		if limit.truncated {
+			summary += fmt.Sprintf(" (truncated at %d entries, use max_entries to list more)", maxEntries)
+		}
 	} else {
 		summary = fmt.Sprintf("File info: %s (size: %s)", path, formatSize(totalSize))
 	}
@@ -201,6 +247,7 @@ func (t *ListTool) Execute(ctx context.Context, params core.Parameters) (core.Re
 	result.WithMetadata("total_dirs", dirCount)
 	result.WithMetadata("total_size", totalSize)
 	result.WithMetadata("path", path)
+	result.WithMetadata("truncated", limit.truncated)
 	
 	return result, nil
 }
@@ -235,8 +282,103 @@ func (t *ListTool) getFileInfo(path string, includeDetails bool) (FileInfo, erro
 	return fileInfo, nil
 }
 
+// globListParam 读取通配符数组参数并检查语法
+func globListParam(params core.Parameters, key string) ([]string, error) {
+	if !params.Has(key) {
+		return nil, nil
+	}
+	patterns, err := params.GetStringSlice(key)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an array of strings", key)
+	}
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid %s pattern %q: %v", key, pattern, err)
+		}
+	}
+	return patterns, nil
+}
+
+// filterEntryType 按条目类型过滤：dir 只保留目录（含子目录）；
+// file 只保留文件，递归结果中的文件被展开到同一层，名称为相对于列表根目录的路径
+func filterEntryType(files []FileInfo, entryType, prefix string) []FileInfo {
+	if entryType != "file" && entryType != "dir" {
+		return files
+	}
+	
+	filtered := make([]FileInfo, 0, len(files))
+	for _, f := range files {
+		if !f.IsDir {
+			if entryType == "file" {
+				f.Name = prefix + f.Name
+				filtered = append(filtered, f)
+			}
+			continue
+		}
+		if entryType == "file" {
+			filtered = append(filtered, filterEntryType(f.Children, entryType, prefix+f.Name+"/")...)
+			continue
+		}
+		if f.Children != nil {
+			f.Children = filterEntryType(f.Children, entryType, "")
+		}
+		filtered = append(filtered, f)
+	}
+	return filtered
+}
+
+// listLimit 列出条目数的上限，达到后停止遍历；nil 表示不限制
+type listLimit struct {
+	remaining int
+	truncated bool
+}
+
+// take 占用一个名额，名额用完时标记为截断并返回 false
+func (l *listLimit) take() bool {
+	if l == nil {
+		return true
+	}
+	if l.remaining <= 0 {
+		l.truncated = true
+		return false
+	}
+	l.remaining--
+	return true
+}
+
+// listFilter 按名称过滤列表条目
+type listFilter struct {
+	showHidden bool
+	include    []string // 只保留匹配的文件，不影响目录
+	exclude    []string // 跳过匹配的文件和目录
+}
+
+// keep 判断条目是否应列出
+func (f listFilter) keep(name string, isDir bool) bool {
+	if !f.showHidden && strings.HasPrefix(name, ".") {
+		return false
+	}
+	if matchAnyGlob(f.exclude, name) {
+		return false
+	}
+	if !isDir && len(f.include) > 0 && !matchAnyGlob(f.include, name) {
+		return false
+	}
+	return true
+}
+
+// matchAnyGlob 判断名称是否匹配任一通配符
+func matchAnyGlob(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
 // listRecursive 递归列出目录
-func (t *ListTool) listRecursive(ctx context.Context, path string, showHidden, includeDetails bool, depth, maxDepth int) (FileInfo, error) {
+func (t *ListTool) listRecursive(ctx context.Context, path string, filter listFilter, includeDetails bool, depth, maxDepth int, limit *listLimit) (FileInfo, error) {
 	if depth > maxDepth {
 		return FileInfo{}, fmt.Errorf("max depth exceeded")
 	}
@@ -262,15 +404,17 @@ func (t *ListTool) listRecursive(ctx context.Context, path string, showHidden, i
 		fileInfo.Children = make([]FileInfo, 0)
 		
 		for _, entry := range entries {
-			// 检查是否显示隐藏文件
-			if !showHidden && strings.HasPrefix(entry.Name(), ".") {
+			if !filter.keep(entry.Name(), entry.IsDir()) {
 				continue
 			}
+			if !limit.take() {
+				break
+			}
 			
 			childPath := filepath.Join(path, entry.Name())
 			if entry.IsDir() {
 				// 递归处理子目录
-				childInfo, err := t.listRecursive(ctx, childPath, showHidden, includeDetails, depth+1, maxDepth)
+				childInfo, err := t.listRecursive(ctx, childPath, filter, includeDetails, depth+1, maxDepth, limit)
 				if err == nil {
 					fileInfo.Children = append(fileInfo.Children, childInfo)
 				}
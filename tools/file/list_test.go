@@ -0,0 +1,247 @@
+package file
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+// listedNames 按 "目录/文件" 的相对路径形式列出结果中的全部条目
+func listedNames(files []FileInfo, prefix string) []string {
+	var names []string
+	for _, f := range files {
+		name := prefix + f.Name
+		if f.IsDir {
+			names = append(names, name+"/")
+			names = append(names, listedNames(f.Children, name+"/")...)
+		} else {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestListTool_IncludeExclude(t *testing.T) {
+	root := t.TempDir()
+	for _, rel := range []string{
+		"main.go",
+		"main_test.go",
+		"README.md",
+		"pkg/util.go",
+		"pkg/util_test.go",
+		"pkg/notes.txt",
+		"testdata/fixture.go",
+	} {
+		writeTestFile(t, filepath.Join(root, rel), "x")
+	}
+
+	tests := []struct {
+		name    string
+		params  map[string]any
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "不过滤",
+			params: map[string]any{},
+			want:   []string{"README.md", "main.go", "main_test.go", "pkg/", "testdata/"},
+		},
+		{
+			name:   "include 只保留匹配的文件，目录仍然显示",
+			params: map[string]any{"include": []any{"*.go"}},
+			want:   []string{"main.go", "main_test.go", "pkg/", "testdata/"},
+		},
+		{
+			name:   "include 与 exclude 组合",
+			params: map[string]any{"include": []any{"*.go"}, "exclude": []any{"*_test.go"}},
+			want:   []string{"main.go", "pkg/", "testdata/"},
+		},
+		{
+			name:   "递归时同样过滤",
+			params: map[string]any{"recursive": true, "include": []any{"*.go"}, "exclude": []any{"*_test.go"}},
+			want:   []string{"main.go", "pkg/", "pkg/util.go", "testdata/", "testdata/fixture.go"},
+		},
+		{
+			name:   "exclude 可以排除目录",
+			params: map[string]any{"recursive": true, "include": []any{"*.go"}, "exclude": []any{"*_test.go", "testdata"}},
+			want:   []string{"main.go", "pkg/", "pkg/util.go"},
+		},
+		{
+			name:   "多个 include 取并集",
+			params: map[string]any{"include": []any{"*.md", "main.go"}},
+			want:   []string{"README.md", "main.go", "pkg/", "testdata/"},
+		},
+		{
+			name:    "无效的通配符",
+			params:  map[string]any{"include": []any{"[*.go"}},
+			wantErr: true,
+		},
+	}
+
+	tool := NewListTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.params["path"] = root
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(tt.params))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got := listedNames(result.Metadata()["files"].([]FileInfo), "")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("列出的条目 = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListTool_EntryType(t *testing.T) {
+	root := t.TempDir()
+	for _, rel := range []string{
+		"go.mod",
+		"main.go",
+		"cmd/app/main.go",
+		"pkg/util.go",
+	} {
+		writeTestFile(t, filepath.Join(root, rel), "x")
+	}
+
+	tests := []struct {
+		name      string
+		params    map[string]any
+		want      []string
+		wantFiles int
+		wantDirs  int
+	}{
+		{
+			name:      "默认列出全部",
+			params:    map[string]any{},
+			want:      []string{"cmd/", "go.mod", "main.go", "pkg/"},
+			wantFiles: 2,
+			wantDirs:  2,
+		},
+		{
+			name:      "只列出文件",
+			params:    map[string]any{"entry_type": "file"},
+			want:      []string{"go.mod", "main.go"},
+			wantFiles: 2,
+		},
+		{
+			name:     "只列出目录",
+			params:   map[string]any{"entry_type": "dir"},
+			want:     []string{"cmd/", "pkg/"},
+			wantDirs: 2,
+		},
+		{
+			name:      "递归列出全部",
+			params:    map[string]any{"recursive": true, "entry_type": "any"},
+			want:      []string{"cmd/", "cmd/app/", "cmd/app/main.go", "go.mod", "main.go", "pkg/", "pkg/util.go"},
+			wantFiles: 4,
+			wantDirs:  3,
+		},
+		{
+			name:      "递归只列出文件时展开为相对路径",
+			params:    map[string]any{"recursive": true, "entry_type": "file"},
+			want:      []string{"cmd/app/main.go", "go.mod", "main.go", "pkg/util.go"},
+			wantFiles: 4,
+		},
+		{
+			name:     "递归只列出目录结构",
+			params:   map[string]any{"recursive": true, "entry_type": "dir"},
+			want:     []string{"cmd/", "cmd/app/", "pkg/"},
+			wantDirs: 3,
+		},
+	}
+
+	tool := NewListTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.params["path"] = root
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(tt.params))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			got := listedNames(result.Metadata()["files"].([]FileInfo), "")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("列出的条目 = %v, want %v", got, tt.want)
+			}
+			if result.Metadata()["total_files"] != tt.wantFiles {
+				t.Errorf("total_files = %v, want %d", result.Metadata()["total_files"], tt.wantFiles)
+			}
+			if result.Metadata()["total_dirs"] != tt.wantDirs {
+				t.Errorf("total_dirs = %v, want %d", result.Metadata()["total_dirs"], tt.wantDirs)
+			}
+		})
+	}
+}
+
+func TestListTool_MaxEntries(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"a", "b", "c"} {
+		for _, name := range []string{"1.txt", "2.txt", "3.txt", "4.txt", "5.txt"} {
+			writeTestFile(t, filepath.Join(root, dir, name), "x")
+		}
+	}
+
+	tests := []struct {
+		name          string
+		params        map[string]any
+		want          []string
+		wantTruncated bool
+	}{
+		{
+			name:          "递归达到上限后停止遍历",
+			params:        map[string]any{"recursive": true, "max_entries": 4},
+			want:          []string{"a/", "a/1.txt", "a/2.txt", "a/3.txt"},
+			wantTruncated: true,
+		},
+		{
+			name:          "非递归同样受上限约束",
+			params:        map[string]any{"max_entries": 2},
+			want:          []string{"a/", "b/"},
+			wantTruncated: true,
+		},
+		{
+			name:   "未超过上限不截断",
+			params: map[string]any{"recursive": true, "max_entries": 18},
+			want: []string{
+				"a/", "a/1.txt", "a/2.txt", "a/3.txt", "a/4.txt", "a/5.txt",
+				"b/", "b/1.txt", "b/2.txt", "b/3.txt", "b/4.txt", "b/5.txt",
+				"c/", "c/1.txt", "c/2.txt", "c/3.txt", "c/4.txt", "c/5.txt",
+			},
+		},
+	}
+
+	tool := NewListTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.params["path"] = root
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(tt.params))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			// 目录按名称顺序遍历，截断后不应再出现后面的目录
+			got := listedNames(result.Metadata()["files"].([]FileInfo), "")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("列出的条目 = %v, want %v", got, tt.want)
+			}
+			if result.Metadata()["truncated"] != tt.wantTruncated {
+				t.Errorf("truncated = %v, want %v", result.Metadata()["truncated"], tt.wantTruncated)
+			}
+			if tt.wantTruncated && !strings.Contains(result.String(), "truncated at") {
+				t.Errorf("输出应说明已截断: %s", result.String())
+			}
+		})
+	}
+}
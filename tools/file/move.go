@@ -0,0 +1,215 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"opencode_nano/tools/core"
+)
+
+// renameFile 重命名函数，测试中可替换以模拟跨文件系统移动
+var renameFile = os.Rename
+
+// MoveTool 文件移动/重命名工具
+type MoveTool struct {
+	*core.BaseTool
+}
+
+// NewMoveTool 创建移动工具
+func NewMoveTool() *MoveTool {
+	tool := &MoveTool{
+		BaseTool: core.NewBaseTool("move", "file", "Move or rename a file or directory"),
+	}
+
+	tool.SetRequiresPerm(true)
+	tool.SetTags("file", "move", "rename")
+	tool.SetSchema(core.ParameterSchema{
+		Type: "object",
+		Properties: map[string]core.PropertySchema{
+			"src": {
+				Type:        "string",
+				Description: "Source file or directory",
+			},
+			"dst": {
+				Type:        "string",
+				Description: "Destination path",
+			},
+			"overwrite": {
+				Type:        "boolean",
+				Description: "Overwrite existing destination",
+				Default:     false,
+			},
+		},
+		Required: []string{"src", "dst"},
+	})
+
+	return tool
+}
+
+// Execute 执行移动
+func (t *MoveTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	// 参数验证
+	if err := params.Validate(t.Schema()); err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+
+	src, err := params.GetString("src")
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, "invalid src parameter")
+	}
+
+	dst, err := params.GetString("dst")
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, "invalid dst parameter")
+	}
+
+	overwrite := false
+	if params.Has("overwrite") {
+		overwrite, _ = params.GetBool("overwrite")
+	}
+
+	src = filepath.Clean(src)
+	dst = filepath.Clean(dst)
+
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("source not found: %s", src))
+		}
+		return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+	}
+
+	// 目标与源相同、位于源目录内或包含源时拒绝，避免覆盖时删掉源
+	srcPath, err := resolveEntry(src)
+	if err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+	}
+	dstPath, err := resolveEntry(dst)
+	if err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+	}
+	if srcInfo.IsDir() && isWithin(dstPath, srcPath) {
+		return nil, core.ErrInvalidParams(t.Info().Name, fmt.Sprintf("cannot move %s into itself", src))
+	}
+
+	// 已存在的目标先移到一旁，移动成功后再删除，失败时恢复
+	var aside string
+	if dstInfo, err := os.Lstat(dst); err == nil {
+		if srcLinfo, err := os.Lstat(src); err == nil && os.SameFile(srcLinfo, dstInfo) {
+			return nil, core.ErrInvalidParams(t.Info().Name, fmt.Sprintf("destination is the same as source: %s", dst))
+		}
+		if isWithin(srcPath, dstPath) {
+			return nil, core.ErrInvalidParams(t.Info().Name, fmt.Sprintf("destination %s contains source %s", dst, src))
+		}
+		if !overwrite {
+			return nil, core.ErrExecutionFailed(t.Info().Name,
+				fmt.Sprintf("destination already exists: %s (set overwrite to replace)", dst))
+		}
+		if aside, err = moveAside(dst); err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to move destination aside: %v", err))
+		}
+	}
+
+	method, err := t.move(src, dst, srcInfo)
+	if aside != "" {
+		if err != nil {
+			os.Rename(filepath.Join(aside, filepath.Base(dst)), dst)
+		}
+		os.RemoveAll(aside)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := core.NewSimpleResult(fmt.Sprintf("Successfully moved %s to %s", src, dst))
+	result.WithMetadata("src", src)
+	result.WithMetadata("dst", dst)
+	result.WithMetadata("method", method)
+
+	return result, nil
+}
+
+// move 移动 src 到 dst，跨文件系统时退化为复制后删除，返回使用的方式
+func (t *MoveTool) move(src, dst string, srcInfo os.FileInfo) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return "", core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to create parent directory: %v", err))
+	}
+
+	if err := renameFile(src, dst); err != nil {
+		if !errors.Is(err, syscall.EXDEV) {
+			return "", core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to move: %v", err))
+		}
+		if err := moveByCopy(src, dst, srcInfo); err != nil {
+			return "", core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to move: %v", err))
+		}
+		return "copy", nil
+	}
+	return "rename", nil
+}
+
+// moveAside 把 path 移到同目录下新建的临时目录中，返回该临时目录
+func moveAside(path string) (string, error) {
+	aside, err := os.MkdirTemp(filepath.Dir(path), "."+filepath.Base(path)+".old*")
+	if err != nil {
+		return "", err
+	}
+	if err := os.Rename(path, filepath.Join(aside, filepath.Base(path))); err != nil {
+		os.Remove(aside)
+		return "", err
+	}
+	return aside, nil
+}
+
+// resolveEntry 返回绝对路径，并解析其父目录中的符号链接（最后一级不跟随）。
+// 父目录不存在时逐级向上解析已存在的部分
+func resolveEntry(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	dir, base := filepath.Dir(abs), filepath.Base(abs)
+	if dir == abs {
+		return abs, nil
+	}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		return filepath.Join(resolved, base), nil
+	}
+	parent, err := resolveEntry(dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(parent, base), nil
+}
+
+// isWithin 判断 path 是否等于 dir 或位于 dir 之下
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// moveByCopy 复制全部内容后删除源路径，保留原有权限
+func moveByCopy(src, dst string, srcInfo os.FileInfo) error {
+	entries, err := collectCopyEntries(src, dst, srcInfo)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.isDir {
+			if err := os.MkdirAll(entry.dst, entry.mode.Perm()); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := copyFileContents(entry.src, entry.dst, entry.mode.Perm()); err != nil {
+			return err
+		}
+	}
+
+	return os.RemoveAll(src)
+}
@@ -0,0 +1,209 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+func TestMoveTool_Execute(t *testing.T) {
+	tests := []struct {
+		name       string
+		dst        string // 相对临时目录
+		existing   bool   // 目标是否已存在
+		overwrite  bool
+		wantErr    bool
+		wantMethod string
+	}{
+		{
+			name:       "同目录重命名",
+			dst:        "renamed.txt",
+			wantMethod: "rename",
+		},
+		{
+			name:       "移动到子目录",
+			dst:        "sub/dir/moved.txt",
+			wantMethod: "rename",
+		},
+		{
+			name:     "目标已存在且未允许覆盖",
+			dst:      "exists.txt",
+			existing: true,
+			wantErr:  true,
+		},
+		{
+			name:       "允许覆盖",
+			dst:        "exists.txt",
+			existing:   true,
+			overwrite:  true,
+			wantMethod: "rename",
+		},
+	}
+
+	tool := NewMoveTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			src := filepath.Join(tmpDir, "src.txt")
+			dst := filepath.Join(tmpDir, tt.dst)
+			if err := os.WriteFile(src, []byte("content"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if tt.existing {
+				if err := os.WriteFile(dst, []byte("old"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+				"src":       src,
+				"dst":       dst,
+				"overwrite": tt.overwrite,
+			}))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				// 失败时源文件和目标文件都不应被修改
+				if _, err := os.Stat(src); err != nil {
+					t.Errorf("源文件不应被删除: %v", err)
+				}
+				data, _ := os.ReadFile(dst)
+				if string(data) != "old" {
+					t.Errorf("目标文件被修改: %q", data)
+				}
+				return
+			}
+
+			if _, err := os.Stat(src); !os.IsNotExist(err) {
+				t.Errorf("移动后源文件仍存在")
+			}
+			data, err := os.ReadFile(dst)
+			if err != nil || string(data) != "content" {
+				t.Errorf("目标文件内容 = %q, err = %v", data, err)
+			}
+			if result.Metadata()["method"] != tt.wantMethod {
+				t.Errorf("method = %v, want %s", result.Metadata()["method"], tt.wantMethod)
+			}
+		})
+	}
+}
+
+func TestMoveTool_CrossDeviceFallback(t *testing.T) {
+	// 模拟跨文件系统的 rename 失败
+	original := renameFile
+	renameFile = func(oldpath, newpath string) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+	}
+	defer func() { renameFile = original }()
+
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "src")
+	if err := os.MkdirAll(filepath.Join(src, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "sub", "a.txt"), []byte("aaa"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(tmpDir, "dst")
+	result, err := NewMoveTool().Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"src": src,
+		"dst": dst,
+	}))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if result.Metadata()["method"] != "copy" {
+		t.Errorf("method = %v, want copy", result.Metadata()["method"])
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Error("复制回退后源目录仍存在")
+	}
+
+	info, err := os.Stat(filepath.Join(dst, "sub", "a.txt"))
+	if err != nil {
+		t.Fatalf("目标文件不存在: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("目标文件权限 = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestMoveTool_RejectsOverlappingPaths(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("创建符号链接需要额外权限")
+	}
+
+	tests := []struct {
+		name string
+		src  string // 相对临时目录
+		dst  string
+	}{
+		{"目标是源的父目录", "proj/sub", "proj"},
+		{"目标与源相同", "proj", "proj/"},
+		{"目标经过符号链接的父目录指向源", "proj/sub", "link/sub"},
+		{"移动到自身的子目录", "proj", "proj/sub/inner"},
+	}
+
+	tool := NewMoveTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			keep := filepath.Join(tmpDir, "proj", "sub", "keep.txt")
+			writeTestFile(t, keep, "keep")
+			if err := os.Symlink(filepath.Join(tmpDir, "proj"), filepath.Join(tmpDir, "link")); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+				"src":       filepath.Join(tmpDir, tt.src),
+				"dst":       tmpDir + string(filepath.Separator) + tt.dst,
+				"overwrite": true,
+			}))
+			if core.GetErrorCode(err) != core.ErrCodeInvalidParams {
+				t.Errorf("error = %v, want invalid params", err)
+			}
+			if data, err := os.ReadFile(keep); err != nil || string(data) != "keep" {
+				t.Errorf("源目录内容被修改: %q, %v", data, err)
+			}
+		})
+	}
+}
+
+func TestMoveTool_RestoresDestinationOnFailure(t *testing.T) {
+	original := renameFile
+	renameFile = func(oldpath, newpath string) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EACCES}
+	}
+	defer func() { renameFile = original }()
+
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "src")
+	dst := filepath.Join(tmpDir, "dst")
+	writeTestFile(t, filepath.Join(src, "a.txt"), "new")
+	writeTestFile(t, filepath.Join(dst, "b.txt"), "old")
+
+	_, err := NewMoveTool().Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"src":       src,
+		"dst":       dst,
+		"overwrite": true,
+	}))
+	if err == nil {
+		t.Fatal("rename 失败时应返回错误")
+	}
+	if data, err := os.ReadFile(filepath.Join(dst, "b.txt")); err != nil || string(data) != "old" {
+		t.Errorf("移动失败后目标应恢复: %q, %v", data, err)
+	}
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 2 {
+		t.Errorf("临时目录应只有 src 和 dst, got %d 项", len(entries))
+	}
+}
@@ -0,0 +1,205 @@
+package file
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// hunkHeaderRe 匹配 unified diff 的 hunk 头，如 "@@ -1,3 +1,4 @@"
+var hunkHeaderRe = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// diffHunk unified diff 中的一个 hunk
+type diffHunk struct {
+	oldStart, oldLines int
+	newStart, newLines int
+	lines              []string // 带 ' '、'-'、'+' 前缀的行
+	// oldNoEOL/newNoEOL 旧、新内容的最后一行后带有 "\ No newline at end of file"
+	oldNoEOL, newNoEOL bool
+}
+
+// parseUnifiedDiff 解析 unified diff，忽略第一个 hunk 之前的文件头
+func parseUnifiedDiff(patch string) ([]diffHunk, error) {
+	var hunks []diffHunk
+	var current *diffHunk
+	oldSeen, newSeen := 0, 0
+
+	// finish 校验当前 hunk 的行数与头部声明一致
+	finish := func() error {
+		if current == nil {
+			return nil
+		}
+		if oldSeen != current.oldLines || newSeen != current.newLines {
+			return fmt.Errorf("hunk %d: header declares -%d/+%d lines but body has -%d/+%d",
+				len(hunks)+1, current.oldLines, current.newLines, oldSeen, newSeen)
+		}
+		hunks = append(hunks, *current)
+		current = nil
+		return nil
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(patch, "\n"), "\n") {
+		if m := hunkHeaderRe.FindStringSubmatch(line); m != nil {
+			if err := finish(); err != nil {
+				return nil, err
+			}
+			current = &diffHunk{
+				oldStart: atoiDefault(m[1], 0),
+				oldLines: atoiDefault(m[2], 1),
+				newStart: atoiDefault(m[3], 0),
+				newLines: atoiDefault(m[4], 1),
+			}
+			oldSeen, newSeen = 0, 0
+			continue
+		}
+
+		// hunk 之外的行（---、+++、diff、index 等文件头）直接跳过
+		if current == nil {
+			continue
+		}
+
+		// "\ No newline at end of file" 作用于前一行所在的一侧，可能出现在 hunk 读满之后
+		if strings.HasPrefix(line, "\\") {
+			if len(current.lines) == 0 {
+				return nil, fmt.Errorf("hunk %d: unexpected %q", len(hunks)+1, line)
+			}
+			switch current.lines[len(current.lines)-1][0] {
+			case ' ':
+				current.oldNoEOL, current.newNoEOL = true, true
+			case '-':
+				current.oldNoEOL = true
+			case '+':
+				current.newNoEOL = true
+			}
+			continue
+		}
+
+		// 当前 hunk 已读满，后续内容属于下一个文件头或尾部空行
+		if oldSeen == current.oldLines && newSeen == current.newLines {
+			if err := finish(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		switch {
+		case line == "":
+			// 部分编辑器会去掉空上下文行的前导空格
+			current.lines = append(current.lines, " ")
+			oldSeen++
+			newSeen++
+		case line[0] == ' ':
+			current.lines = append(current.lines, line)
+			oldSeen++
+			newSeen++
+		case line[0] == '-':
+			current.lines = append(current.lines, line)
+			oldSeen++
+		case line[0] == '+':
+			current.lines = append(current.lines, line)
+			newSeen++
+		default:
+			return nil, fmt.Errorf("hunk %d: invalid line %q", len(hunks)+1, line)
+		}
+	}
+
+	if err := finish(); err != nil {
+		return nil, err
+	}
+	if len(hunks) == 0 {
+		return nil, fmt.Errorf("no hunks found in patch")
+	}
+	return hunks, nil
+}
+
+// applyUnifiedDiff 将 unified diff 应用到内容上。
+// 任一 hunk 的上下文不匹配时返回错误，不产生部分应用的结果。
+func applyUnifiedDiff(content, patch string, reverse bool) (string, int, error) {
+	hunks, err := parseUnifiedDiff(patch)
+	if err != nil {
+		return "", 0, err
+	}
+
+	lines, trailingNewline := splitLines(content)
+	var out []string
+	cursor := 0
+
+	for i, hunk := range hunks {
+		start, count := hunk.oldStart, hunk.oldLines
+		removePrefix, addPrefix := byte('-'), byte('+')
+		oldNoEOL, newNoEOL := hunk.oldNoEOL, hunk.newNoEOL
+		if reverse {
+			start, count = hunk.newStart, hunk.newLines
+			removePrefix, addPrefix = '+', '-'
+			oldNoEOL, newNoEOL = newNoEOL, oldNoEOL
+		}
+
+		var oldLines, newLines []string
+		for _, line := range hunk.lines {
+			switch line[0] {
+			case ' ':
+				oldLines = append(oldLines, line[1:])
+				newLines = append(newLines, line[1:])
+			case removePrefix:
+				oldLines = append(oldLines, line[1:])
+			case addPrefix:
+				newLines = append(newLines, line[1:])
+			}
+		}
+
+		// 行号从 1 开始；count 为 0 时表示在第 start 行之后插入
+		pos := start - 1
+		if count == 0 {
+			pos = start
+		}
+		if pos < cursor || pos+len(oldLines) > len(lines) {
+			return "", 0, fmt.Errorf("hunk %d: line %d is out of range (file has %d lines)", i+1, start, len(lines))
+		}
+
+		for j, want := range oldLines {
+			if got := lines[pos+j]; got != want {
+				return "", 0, fmt.Errorf("hunk %d: context mismatch at line %d: expected %q, got %q", i+1, pos+j+1, want, got)
+			}
+		}
+
+		// 改到文件末尾且带有换行标记时，结尾换行以新内容为准；
+		// 没有标记的补丁保留原文件的结尾换行
+		if pos+len(oldLines) == len(lines) && (oldNoEOL || newNoEOL) {
+			trailingNewline = !newNoEOL
+		}
+
+		out = append(out, lines[cursor:pos]...)
+		out = append(out, newLines...)
+		cursor = pos + len(oldLines)
+	}
+	out = append(out, lines[cursor:]...)
+
+	result := strings.Join(out, "\n")
+	if trailingNewline && len(out) > 0 {
+		result += "\n"
+	}
+	return result, len(hunks), nil
+}
+
+// splitLines 按行拆分内容，并记录是否以换行结尾
+func splitLines(content string) ([]string, bool) {
+	if content == "" {
+		// 空文件视为以换行结尾，使新增内容保持标准格式
+		return nil, true
+	}
+	trailingNewline := strings.HasSuffix(content, "\n")
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n"), trailingNewline
+}
+
+// atoiDefault 解析整数，字符串为空时返回默认值
+func atoiDefault(s string, def int) int {
+	if s == "" {
+		return def
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return def
+	}
+	return n
+}
@@ -0,0 +1,274 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+const patchOriginal = `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("hello")
+}
+
+func helper() int {
+	return 1
+}
+`
+
+const patchModified = `package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	fmt.Println("hello, world")
+	os.Exit(0)
+}
+
+func helper() int {
+	return 2
+}
+`
+
+// patchMultiHunk 将 patchOriginal 修改为 patchModified
+const patchMultiHunk = `--- a/main.go
++++ b/main.go
+@@ -1,8 +1,12 @@
+ package main
+
+-import "fmt"
++import (
++	"fmt"
++	"os"
++)
+
+ func main() {
+-	fmt.Println("hello")
++	fmt.Println("hello, world")
++	os.Exit(0)
+ }
+
+@@ -9,3 +13,3 @@
+ func helper() int {
+-	return 1
++	return 2
+ }
+`
+
+func TestApplyUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		patch       string
+		reverse     bool
+		want        string
+		wantHunks   int
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:      "多个 hunk",
+			content:   patchOriginal,
+			patch:     patchMultiHunk,
+			want:      patchModified,
+			wantHunks: 2,
+		},
+		{
+			name:      "反向应用",
+			content:   patchModified,
+			patch:     patchMultiHunk,
+			reverse:   true,
+			want:      patchOriginal,
+			wantHunks: 2,
+		},
+		{
+			name:      "纯插入",
+			content:   "a\nb\n",
+			patch:     "@@ -1,0 +2,1 @@\n+inserted\n",
+			want:      "a\ninserted\nb\n",
+			wantHunks: 1,
+		},
+		{
+			name:      "删除结尾换行",
+			content:   "a\nb\n",
+			patch:     "@@ -1,2 +1,2 @@\n a\n-b\n+b\n\\ No newline at end of file\n",
+			want:      "a\nb",
+			wantHunks: 1,
+		},
+		{
+			name:      "补上结尾换行",
+			content:   "a\nb",
+			patch:     "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+			want:      "a\nb\n",
+			wantHunks: 1,
+		},
+		{
+			name:      "反向应用时交换结尾换行",
+			content:   "a\nb",
+			patch:     "@@ -1,2 +1,2 @@\n a\n-b\n+b\n\\ No newline at end of file\n",
+			reverse:   true,
+			want:      "a\nb\n",
+			wantHunks: 1,
+		},
+		{
+			name:      "两侧都没有结尾换行",
+			content:   "a\nb",
+			patch:     "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+			want:      "a\nc",
+			wantHunks: 1,
+		},
+		{
+			name:      "未改到文件末尾时保留结尾换行",
+			content:   "a\nb\nc",
+			patch:     "@@ -1 +1 @@\n-a\n+A\n",
+			want:      "A\nb\nc",
+			wantHunks: 1,
+		},
+		{
+			name:        "上下文不匹配",
+			content:     strings.Replace(patchOriginal, "return 1", "return 3", 1),
+			patch:       patchMultiHunk,
+			wantErr:     true,
+			errContains: "hunk 2: context mismatch at line 10",
+		},
+		{
+			name:        "行数与头部不一致",
+			content:     "a\nb\n",
+			patch:       "@@ -1,2 +1,2 @@\n-a\n+c\n",
+			wantErr:     true,
+			errContains: "header declares",
+		},
+		{
+			name:        "没有 hunk",
+			content:     "a\n",
+			patch:       "-a\n+b\n",
+			wantErr:     true,
+			errContains: "no hunks",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, hunks, err := applyUnifiedDiff(tt.content, tt.patch, tt.reverse)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyUnifiedDiff() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("错误信息 = %q, 应包含 %q", err.Error(), tt.errContains)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("applyUnifiedDiff() =\n%s\nwant:\n%s", got, tt.want)
+			}
+			if hunks != tt.wantHunks {
+				t.Errorf("hunks = %d, want %d", hunks, tt.wantHunks)
+			}
+		})
+	}
+}
+
+func TestPatchTool_MismatchLeavesFileUntouched(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "main.go")
+	content := strings.Replace(patchOriginal, "return 1", "return 3", 1)
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := NewPatchTool().Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"path":  filePath,
+		"patch": patchMultiHunk,
+	}))
+	if err == nil {
+		t.Fatal("上下文不匹配时应返回错误")
+	}
+
+	// 第一个 hunk 可以匹配，但不应被单独写入
+	got, _ := os.ReadFile(filePath)
+	if string(got) != content {
+		t.Errorf("应用失败后文件被修改:\n%s", got)
+	}
+}
+
+func TestPatchTool_Execute(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(filePath, []byte(patchOriginal), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tool := NewPatchTool()
+	result, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"path":  filePath,
+		"patch": patchMultiHunk,
+	}))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Metadata()["hunks_applied"] != 2 {
+		t.Errorf("hunks_applied = %v, want 2", result.Metadata()["hunks_applied"])
+	}
+
+	got, _ := os.ReadFile(filePath)
+	if string(got) != patchModified {
+		t.Errorf("文件内容 =\n%s\nwant:\n%s", got, patchModified)
+	}
+
+	// 反向应用后应恢复原内容
+	_, err = tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"path":    filePath,
+		"patch":   patchMultiHunk,
+		"reverse": true,
+	}))
+	if err != nil {
+		t.Fatalf("Execute(reverse) error = %v", err)
+	}
+	got, _ = os.ReadFile(filePath)
+	if string(got) != patchOriginal {
+		t.Errorf("反向应用后文件内容 =\n%s\nwant:\n%s", got, patchOriginal)
+	}
+}
+
+func TestPatchTool_KeepsModeAndSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("创建符号链接需要额外权限")
+	}
+
+	root := t.TempDir()
+	target := filepath.Join(root, "main.go")
+	if err := os.WriteFile(target, []byte(patchOriginal), 0600); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(root, "link.go")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewPatchTool().Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"path":  link,
+		"patch": patchMultiHunk,
+	})); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("符号链接被替换: %v", err)
+	}
+	if got, _ := os.ReadFile(target); string(got) != patchModified {
+		t.Errorf("目标文件内容 =\n%s\nwant:\n%s", got, patchModified)
+	}
+	if info, _ := os.Stat(target); info.Mode().Perm() != 0600 {
+		t.Errorf("文件权限 = %v, want 0600", info.Mode().Perm())
+	}
+}
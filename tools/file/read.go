@@ -2,12 +2,21 @@ package file
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
 
 	"opencode_nano/tools/core"
 )
@@ -33,8 +42,12 @@ func NewReadTool() *ReadTool {
 			},
 			"encoding": {
 				Type:        "string",
-				Description: "File encoding (default: utf-8)",
-				Default:     "utf-8",
+				Description: "File encoding: utf-8, utf-16le, utf-16be, latin1 (default: utf-8)",
+			},
+			"detect_encoding": {
+				Type:        "boolean",
+				Description: "Detect the encoding from the BOM and byte distribution and strip a detected BOM; an explicit encoding still takes precedence",
+				Default:     false,
 			},
 			"start_line": {
 				Type:        "integer",
@@ -51,6 +64,11 @@ func NewReadTool() *ReadTool {
 				Description: "Maximum file size in bytes (default: 10MB)",
 				Default:     10 * 1024 * 1024,
 			},
+			"follow_symlinks": {
+				Type:        "boolean",
+				Description: "Read the target when path is a symlink; when false, symlink paths are rejected",
+				Default:     true,
+			},
 		},
 		Required: []string{"path"},
 	})
@@ -90,6 +108,45 @@ func (t *ReadTool) Execute(ctx context.Context, params core.Parameters) (core.Re
 		maxSize, _ = params.GetInt("max_size")
 	}
 	
+	// encoding 不设 schema 默认值，以便区分显式指定和自动探测
+	encodingName := "utf-8"
+	explicitEncoding := params.Has("encoding")
+	if explicitEncoding {
+		encodingName, _ = params.GetString("encoding")
+	}
+	decoder, err := lookupEncoding(encodingName)
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+	
+	detect := false
+	if params.Has("detect_encoding") {
+		detect, _ = params.GetBool("detect_encoding")
+	}
+	
+	followSymlinks := true
+	if params.Has("follow_symlinks") {
+		followSymlinks, _ = params.GetBool("follow_symlinks")
+	}
+	
+	// 检查是否为符号链接，是则解析出最终指向的路径
+	isSymlink := false
+	target := ""
+	if linkInfo, err := os.Lstat(filePath); err == nil && linkInfo.Mode()&os.ModeSymlink != 0 {
+		isSymlink = true
+		if !followSymlinks {
+			return nil, core.ErrExecutionFailed(t.Info().Name,
+				fmt.Sprintf("path is a symlink: %s (set follow_symlinks to read its target)", filePath))
+		}
+		target, err = filepath.EvalSymlinks(filePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("symlink target not found: %s", filePath))
+			}
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to resolve symlink: %v", err))
+		}
+	}
+	
 	// 检查文件是否存在
 	fileInfo, err := os.Stat(filePath)
 	if err != nil {
@@ -117,19 +174,46 @@ func (t *ReadTool) Execute(ctx context.Context, params core.Parameters) (core.Re
 	}
 	defer file.Close()
 	
+	// 探测编码；显式指定了编码时以指定的为准，只在两者一致时去掉 BOM
+	detectedEncoding := ""
+	if detect {
+		head := make([]byte, binarySniffSize)
+		n, err := io.ReadFull(file, head)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to read file: %v", err))
+		}
+		var bomLen int
+		detectedEncoding, bomLen = detectEncoding(head[:n])
+		if !explicitEncoding {
+			encodingName = detectedEncoding
+			decoder, _ = lookupEncoding(detectedEncoding)
+		} else if detectedDecoder, _ := lookupEncoding(detectedEncoding); detectedDecoder != decoder {
+			bomLen = 0
+		}
+		if _, err := file.Seek(int64(bomLen), io.SeekStart); err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to read file: %v", err))
+		}
+	}
+	
+	// 按指定编码解码为 UTF-8
+	var reader io.Reader = file
+	if decoder != nil {
+		reader = transform.NewReader(file, decoder.NewDecoder())
+	}
+	
 	// 读取文件内容
 	var content string
 	var lineCount int
 	
 	if startLine > 0 || endLine > 0 {
 		// 按行读取
-		content, lineCount, err = t.readLines(file, startLine, endLine)
+		content, lineCount, err = t.readLines(reader, startLine, endLine)
 		if err != nil {
 			return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
 		}
 	} else {
 		// 读取全部内容
-		bytes, err := io.ReadAll(file)
+		bytes, err := io.ReadAll(reader)
 		if err != nil {
 			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to read file: %v", err))
 		}
@@ -143,6 +227,14 @@ func (t *ReadTool) Execute(ctx context.Context, params core.Parameters) (core.Re
 	result.WithMetadata("size", fileInfo.Size())
 	result.WithMetadata("lines", lineCount)
 	result.WithMetadata("mode", fileInfo.Mode().String())
+	result.WithMetadata("encoding", encodingName)
+	if detect {
+		result.WithMetadata("detected_encoding", detectedEncoding)
+	}
+	result.WithMetadata("is_symlink", isSymlink)
+	if isSymlink {
+		result.WithMetadata("target", target)
+	}
 	
 	if startLine > 0 || endLine > 0 {
 		result.WithMetadata("start_line", startLine)
@@ -152,9 +244,75 @@ func (t *ReadTool) Execute(ctx context.Context, params core.Parameters) (core.Re
 	return result, nil
 }
 
-// readLines 按行读取文件
-func (t *ReadTool) readLines(file *os.File, startLine, endLine int) (string, int, error) {
-	scanner := bufio.NewScanner(file)
+// lookupEncoding 根据名称查找编码，UTF-8 返回 nil 表示无需转换
+func lookupEncoding(name string) (encoding.Encoding, error) {
+	switch strings.ToLower(strings.ReplaceAll(name, "_", "-")) {
+	case "", "utf-8", "utf8", "ascii", "us-ascii":
+		return nil, nil
+	case "utf-16le", "utf16le":
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), nil
+	case "utf-16be", "utf16be":
+		return unicode.UTF16(unicode.BigEndian, unicode.UseBOM), nil
+	case "latin1", "latin-1", "iso-8859-1":
+		return charmap.ISO8859_1, nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding: %s (supported: utf-8, utf-16le, utf-16be, latin1)", name)
+	}
+}
+
+// detectEncoding 根据 BOM 和字节分布推测编码，返回编码名称和 BOM 的长度。
+// 没有 BOM 时：合法的 UTF-8 视为 utf-8（纯 ASCII 为 ascii），
+// 奇数或偶数位置大量为 0 时视为 UTF-16，其余按 latin1 处理
+func detectEncoding(data []byte) (string, int) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xef, 0xbb, 0xbf}):
+		return "utf-8", 3
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+		return "utf-16le", 2
+	case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		return "utf-16be", 2
+	}
+	
+	// 统计偶数和奇数位置上的 0 字节
+	var evenZeros, oddZeros int
+	for i, b := range data {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			evenZeros++
+		} else {
+			oddZeros++
+		}
+	}
+	half := len(data) / 2
+	if half > 0 {
+		// ASCII 范围的 UTF-16 文本中，每个字符的高位字节为 0
+		if oddZeros*10 >= half*3 && evenZeros*10 < half {
+			return "utf-16le", 0
+		}
+		if evenZeros*10 >= half*3 && oddZeros*10 < half {
+			return "utf-16be", 0
+		}
+	}
+	
+	if len(data) >= binarySniffSize {
+		data = trimIncompleteRune(data)
+	}
+	if !utf8.Valid(data) {
+		return "latin1", 0
+	}
+	for _, b := range data {
+		if b >= utf8.RuneSelf {
+			return "utf-8", 0
+		}
+	}
+	return "ascii", 0
+}
+
+// readLines 按行读取已解码的内容
+func (t *ReadTool) readLines(reader io.Reader, startLine, endLine int) (string, int, error) {
+	scanner := bufio.NewScanner(reader)
 	var lines []string
 	currentLine := 0
 	totalLines := 0
@@ -230,6 +388,90 @@ func NewReadBinaryTool() *ReadBinaryTool {
 
 // Execute 执行二进制读取
 func (t *ReadBinaryTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
-	// 这里简化实现，实际应该实现完整的二进制读取逻辑
-	return core.NewSimpleResult("binary read not implemented yet"), nil
-}
\ No newline at end of file
+	// 参数验证
+	if err := params.Validate(t.Schema()); err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+
+	filePath, err := params.GetString("path")
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, "invalid path parameter")
+	}
+	filePath = filepath.Clean(filePath)
+
+	offset := 0
+	if params.Has("offset") {
+		offset, _ = params.GetInt("offset")
+	}
+
+	length := 0
+	if params.Has("length") {
+		length, _ = params.GetInt("length")
+	}
+
+	encoding := "hex"
+	if params.Has("encoding") {
+		encoding, _ = params.GetString("encoding")
+	}
+
+	if offset < 0 || length < 0 {
+		return nil, core.ErrInvalidParams(t.Info().Name, "offset and length must be non-negative")
+	}
+
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("file not found: %s", filePath))
+		}
+		return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+	}
+
+	if fileInfo.IsDir() {
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("path is a directory: %s", filePath))
+	}
+
+	if int64(offset) > fileInfo.Size() {
+		return nil, core.ErrExecutionFailed(t.Info().Name,
+			fmt.Sprintf("offset %d is beyond end of file (size: %d bytes)", offset, fileInfo.Size()))
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to open file: %v", err))
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(int64(offset), io.SeekStart); err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to seek: %v", err))
+	}
+
+	// length 为 0 时读取到文件末尾
+	var reader io.Reader = file
+	if length > 0 {
+		reader = io.LimitReader(file, int64(length))
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to read file: %v", err))
+	}
+
+	var content string
+	switch encoding {
+	case "base64":
+		content = base64.StdEncoding.EncodeToString(data)
+	case "raw":
+		content = string(data)
+	default:
+		content = hex.EncodeToString(data)
+	}
+
+	result := core.NewSimpleResult(content)
+	result.WithMetadata("path", filePath)
+	result.WithMetadata("encoding", encoding)
+	result.WithMetadata("offset", offset)
+	result.WithMetadata("bytes_read", len(data))
+	result.WithMetadata("size", fileInfo.Size())
+
+	return result, nil
+}
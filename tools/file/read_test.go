@@ -0,0 +1,314 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+func TestReadBinaryTool_Execute(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "data.bin")
+	data := []byte{0x00, 0x01, 'h', 'i', 0xff, 0x10}
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		params    map[string]any
+		want      string
+		wantBytes int
+		wantErr   bool
+	}{
+		{
+			name:      "默认 hex 编码",
+			params:    map[string]any{"path": filePath},
+			want:      "00016869ff10",
+			wantBytes: 6,
+		},
+		{
+			name:      "base64 编码",
+			params:    map[string]any{"path": filePath, "encoding": "base64"},
+			want:      "AAFoaf8Q",
+			wantBytes: 6,
+		},
+		{
+			name:      "raw 编码",
+			params:    map[string]any{"path": filePath, "encoding": "raw", "offset": 2, "length": 2},
+			want:      "hi",
+			wantBytes: 2,
+		},
+		{
+			name:      "部分范围读取",
+			params:    map[string]any{"path": filePath, "offset": 1, "length": 3},
+			want:      "016869",
+			wantBytes: 3,
+		},
+		{
+			name:      "长度超出文件末尾",
+			params:    map[string]any{"path": filePath, "offset": 4, "length": 100},
+			want:      "ff10",
+			wantBytes: 2,
+		},
+		{
+			name:    "偏移超出文件末尾",
+			params:  map[string]any{"path": filePath, "offset": 7},
+			wantErr: true,
+		},
+		{
+			name:    "无效编码",
+			params:  map[string]any{"path": filePath, "encoding": "utf-16"},
+			wantErr: true,
+		},
+		{
+			name:    "文件不存在",
+			params:  map[string]any{"path": filepath.Join(tmpDir, "missing.bin")},
+			wantErr: true,
+		},
+	}
+
+	tool := NewReadBinaryTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(tt.params))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if result.String() != tt.want {
+				t.Errorf("Execute() = %q, want %q", result.String(), tt.want)
+			}
+
+			metadata := result.Metadata()
+			if metadata["bytes_read"] != tt.wantBytes {
+				t.Errorf("bytes_read = %v, want %d", metadata["bytes_read"], tt.wantBytes)
+			}
+			if metadata["size"] != int64(len(data)) {
+				t.Errorf("size = %v, want %d", metadata["size"], len(data))
+			}
+		})
+	}
+}
+
+func TestReadBinaryTool_OffsetError(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "small.bin")
+	if err := os.WriteFile(filePath, []byte("abc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := NewReadBinaryTool().Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"path":   filePath,
+		"offset": 10,
+	}))
+	if err == nil {
+		t.Fatal("偏移超出文件末尾时应返回错误")
+	}
+	if !strings.Contains(err.Error(), "beyond end of file") {
+		t.Errorf("错误信息 = %q, 应说明偏移超出文件末尾", err.Error())
+	}
+}
+
+func TestReadTool_Encoding(t *testing.T) {
+	tmpDir := t.TempDir()
+	// "héllo\n世界\nend" 的 UTF-16LE 编码，带 BOM
+	utf16le := []byte{0xff, 0xfe,
+		'h', 0, 0xe9, 0, 'l', 0, 'l', 0, 'o', 0, '\n', 0,
+		0x16, 0x4e, 0x4c, 0x75, '\n', 0,
+		'e', 0, 'n', 0, 'd', 0}
+	utf16be := []byte{0, 'h', 0, 'i', 0, '\n', 0x4e, 0x16}
+	// "café\nnaïve" 的 latin1 编码
+	latin1 := []byte{'c', 'a', 'f', 0xe9, '\n', 'n', 'a', 0xef, 'v', 'e'}
+
+	fixtures := map[string][]byte{
+		"utf16le.txt": utf16le,
+		"utf16be.txt": utf16be,
+		"latin1.txt":  latin1,
+		"utf8.txt":    []byte("你好\n世界"),
+	}
+	for name, data := range fixtures {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		params  map[string]any
+		want    string
+		wantErr bool
+	}{
+		{"UTF-16LE", "utf16le.txt", map[string]any{"encoding": "utf-16le"}, "héllo\n世界\nend", false},
+		{"UTF-16LE 按行读取", "utf16le.txt", map[string]any{"encoding": "utf-16le", "start_line": 2, "end_line": 3}, "世界\nend", false},
+		{"UTF-16BE", "utf16be.txt", map[string]any{"encoding": "utf-16be"}, "hi\n世", false},
+		{"latin1", "latin1.txt", map[string]any{"encoding": "latin1"}, "café\nnaïve", false},
+		{"latin1 按行读取", "latin1.txt", map[string]any{"encoding": "ISO-8859-1", "start_line": 2}, "naïve", false},
+		{"默认 UTF-8", "utf8.txt", map[string]any{}, "你好\n世界", false},
+		{"不支持的编码", "utf8.txt", map[string]any{"encoding": "ebcdic"}, "", true},
+	}
+
+	tool := NewReadTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.params["path"] = filepath.Join(tmpDir, tt.file)
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(tt.params))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !strings.Contains(err.Error(), "unsupported encoding") {
+					t.Errorf("错误信息 = %v, 应说明编码不受支持", err)
+				}
+				return
+			}
+			if result.String() != tt.want {
+				t.Errorf("内容 = %q, want %q", result.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestReadTool_Symlink(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, "real.txt")
+	if err := os.WriteFile(target, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(tmpDir, "link.txt")
+	if err := os.Symlink("real.txt", link); err != nil {
+		t.Skipf("无法创建符号链接: %v", err)
+	}
+	if err := os.Symlink("missing.txt", filepath.Join(tmpDir, "broken.txt")); err != nil {
+		t.Fatal(err)
+	}
+	// 临时目录本身可能位于符号链接之下（如 macOS 的 /var），以解析后的路径为准
+	wantTarget, err := filepath.EvalSymlinks(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		file        string
+		params      map[string]any
+		wantSymlink bool
+		wantErr     string
+	}{
+		{name: "普通文件", file: "real.txt", params: map[string]any{}},
+		{name: "默认跟随符号链接", file: "link.txt", params: map[string]any{}, wantSymlink: true},
+		{name: "不跟随符号链接时报错", file: "link.txt", params: map[string]any{"follow_symlinks": false}, wantErr: "path is a symlink"},
+		{name: "不跟随时普通文件不受影响", file: "real.txt", params: map[string]any{"follow_symlinks": false}},
+		{name: "目标不存在的符号链接", file: "broken.txt", params: map[string]any{}, wantErr: "symlink target not found"},
+	}
+
+	tool := NewReadTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.params["path"] = filepath.Join(tmpDir, tt.file)
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(tt.params))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Execute() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if result.String() != "hello" {
+				t.Errorf("内容 = %q, want %q", result.String(), "hello")
+			}
+
+			meta := result.Metadata()
+			if meta["is_symlink"] != tt.wantSymlink {
+				t.Errorf("is_symlink = %v, want %v", meta["is_symlink"], tt.wantSymlink)
+			}
+			gotTarget, hasTarget := meta["target"]
+			if tt.wantSymlink && gotTarget != wantTarget {
+				t.Errorf("target = %v, want %s", gotTarget, wantTarget)
+			}
+			if !tt.wantSymlink && hasTarget {
+				t.Errorf("普通文件不应有 target: %v", gotTarget)
+			}
+		})
+	}
+}
+
+func TestReadTool_DetectEncoding(t *testing.T) {
+	tmpDir := t.TempDir()
+	fixtures := map[string][]byte{
+		"utf8bom.txt": append([]byte{0xef, 0xbb, 0xbf}, "héllo\n世界"...),
+		// "hi\n世" 的 UTF-16LE 编码，带 BOM
+		"utf16le.txt": {0xff, 0xfe, 'h', 0, 'i', 0, '\n', 0, 0x16, 0x4e},
+		// 不带 BOM 的 UTF-16LE，只能根据 0 字节的分布判断
+		"utf16le-nobom.txt": {'h', 0, 'e', 0, 'l', 0, 'l', 0, 'o', 0},
+		"ascii.txt":         []byte("plain ascii\n"),
+		"latin1.txt":        {'c', 'a', 'f', 0xe9},
+	}
+	for name, data := range fixtures {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		file         string
+		params       map[string]any
+		want         string
+		wantDetected string
+		wantEncoding string
+	}{
+		{"UTF-8 BOM", "utf8bom.txt", map[string]any{}, "héllo\n世界", "utf-8", "utf-8"},
+		{"UTF-16LE BOM", "utf16le.txt", map[string]any{}, "hi\n世", "utf-16le", "utf-16le"},
+		{"UTF-16LE 按行读取", "utf16le.txt", map[string]any{"start_line": 2}, "世", "utf-16le", "utf-16le"},
+		{"无 BOM 的 UTF-16LE", "utf16le-nobom.txt", map[string]any{}, "hello", "utf-16le", "utf-16le"},
+		{"纯 ASCII", "ascii.txt", map[string]any{}, "plain ascii\n", "ascii", "ascii"},
+		{"非法 UTF-8 按 latin1 处理", "latin1.txt", map[string]any{}, "café", "latin1", "latin1"},
+		{"显式编码优先", "latin1.txt", map[string]any{"encoding": "utf-8"}, "caf\xe9", "latin1", "utf-8"},
+		{"显式编码与 BOM 一致时去掉 BOM", "utf8bom.txt", map[string]any{"encoding": "utf-8"}, "héllo\n世界", "utf-8", "utf-8"},
+	}
+
+	tool := NewReadTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.params["path"] = filepath.Join(tmpDir, tt.file)
+			tt.params["detect_encoding"] = true
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(tt.params))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if result.String() != tt.want {
+				t.Errorf("内容 = %q, want %q", result.String(), tt.want)
+			}
+			if got := result.Metadata()["detected_encoding"]; got != tt.wantDetected {
+				t.Errorf("detected_encoding = %v, want %s", got, tt.wantDetected)
+			}
+			if got := result.Metadata()["encoding"]; got != tt.wantEncoding {
+				t.Errorf("encoding = %v, want %s", got, tt.wantEncoding)
+			}
+		})
+	}
+
+	// 未开启探测时保持原有行为：BOM 原样保留，也没有 detected_encoding
+	result, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"path": filepath.Join(tmpDir, "utf8bom.txt"),
+	}))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.HasPrefix(result.String(), "\ufeff") {
+		t.Errorf("未开启探测时不应去掉 BOM: %q", result.String())
+	}
+	if _, ok := result.Metadata()["detected_encoding"]; ok {
+		t.Errorf("未开启探测时不应有 detected_encoding")
+	}
+}
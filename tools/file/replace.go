@@ -0,0 +1,290 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"opencode_nano/tools/core"
+)
+
+// binarySniffSize 判断二进制文件时检查的字节数
+const binarySniffSize = 8 * 1024
+
+// ReplaceTool 跨文件查找替换工具
+type ReplaceTool struct {
+	*core.BaseTool
+	searcher *SearchTool
+}
+
+// NewReplaceTool 创建替换工具
+func NewReplaceTool() *ReplaceTool {
+	tool := &ReplaceTool{
+		BaseTool: core.NewBaseTool("replace", "file", "Search and replace across files with regex support"),
+		searcher: NewSearchTool(),
+	}
+
+	tool.SetRequiresPerm(true)
+	tool.SetTags("file", "replace", "search", "refactor")
+	tool.SetSchema(core.ParameterSchema{
+		Type: "object",
+		Properties: map[string]core.PropertySchema{
+			"pattern": {
+				Type:        "string",
+				Description: "Regex pattern to search for (matched line by line)",
+			},
+			"replacement": {
+				Type:        "string",
+				Description: "Replacement text ($1 etc. refer to capture groups)",
+			},
+			"path": {
+				Type:        "string",
+				Description: "Directory or file path to process",
+				Default:     ".",
+			},
+			"file_pattern": {
+				Type:        "string",
+				Description: "File name pattern to match (e.g., '*.go')",
+				Default:     "*",
+			},
+			"recursive": {
+				Type:        "boolean",
+				Description: "Process subdirectories recursively",
+				Default:     true,
+			},
+			"dry_run": {
+				Type:        "boolean",
+				Description: "Only preview changes without writing files",
+				Default:     true,
+			},
+		},
+		Required: []string{"pattern", "replacement"},
+	})
+
+	return tool
+}
+
+// FileReplacement 单个文件的替换结果
+type FileReplacement struct {
+	Path    string `json:"path"`
+	Matches int    `json:"matches"`
+}
+
+// NeedsPermission dry_run 默认开启，只预览不写入时无需请求权限
+func (t *ReplaceTool) NeedsPermission(params core.Parameters) bool {
+	if params.Has("dry_run") {
+		dryRun, _ := params.GetBool("dry_run")
+		return !dryRun
+	}
+	return false
+}
+
+// Execute 执行查找替换
+func (t *ReplaceTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	// 参数验证
+	if err := params.Validate(t.Schema()); err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+
+	pattern, err := params.GetString("pattern")
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, "invalid pattern parameter")
+	}
+
+	replacement, err := params.GetString("replacement")
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, "invalid replacement parameter")
+	}
+
+	searchPath := "."
+	if params.Has("path") {
+		searchPath, _ = params.GetString("path")
+	}
+
+	filePattern := "*"
+	if params.Has("file_pattern") {
+		filePattern, _ = params.GetString("file_pattern")
+	}
+
+	recursive := true
+	if params.Has("recursive") {
+		recursive, _ = params.GetBool("recursive")
+	}
+
+	dryRun := true
+	if params.Has("dry_run") {
+		dryRun, _ = params.GetBool("dry_run")
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, fmt.Sprintf("invalid regex pattern: %v", err))
+	}
+
+	var files []FileReplacement
+	var preview strings.Builder
+	totalMatches := 0
+	skippedBinary := 0
+
+	err = t.searcher.searchFiles(ctx, searchPath, filePattern, recursive, newGitignoreMatcher(searchPath), func(path string) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil // 忽略单个文件的错误
+		}
+		if isBinaryContent(data) {
+			skippedBinary++
+			return nil
+		}
+
+		newContent, matches, diff := replaceLines(string(data), re, replacement)
+		if matches == 0 {
+			return nil
+		}
+
+		if !dryRun {
+			if err := writeFileAtomic(path, []byte(newContent)); err != nil {
+				return fmt.Errorf("failed to write %s: %v", path, err)
+			}
+		}
+
+		files = append(files, FileReplacement{Path: path, Matches: matches})
+		totalMatches += matches
+		fmt.Fprintf(&preview, "--- a/%s\n+++ b/%s\n%s", path, path, diff)
+		return nil
+	})
+	if err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+	}
+
+	var output string
+	if dryRun {
+		output = fmt.Sprintf("Dry run: %d matches in %d files would be replaced\n%s", totalMatches, len(files), preview.String())
+	} else {
+		output = fmt.Sprintf("Replaced %d matches in %d files", totalMatches, len(files))
+	}
+
+	result := core.NewSimpleResult(strings.TrimRight(output, "\n"))
+	result.WithMetadata("files", files)
+	result.WithMetadata("total_matches", totalMatches)
+	result.WithMetadata("files_changed", len(files))
+	result.WithMetadata("skipped_binary_count", skippedBinary)
+	result.WithMetadata("dry_run", dryRun)
+	result.WithMetadata("preview", preview.String())
+
+	return result, nil
+}
+
+// replaceLines 逐行替换，返回新内容、匹配数和变更预览
+func replaceLines(content string, re *regexp.Regexp, replacement string) (string, int, string) {
+	lines := strings.Split(content, "\n")
+	var diff strings.Builder
+	matches := 0
+
+	for i, line := range lines {
+		count := len(re.FindAllStringIndex(line, -1))
+		if count == 0 {
+			continue
+		}
+		matches += count
+
+		newLine := re.ReplaceAllString(line, replacement)
+		if newLine != line {
+			fmt.Fprintf(&diff, "@@ -%d +%d @@\n-%s\n+%s\n", i+1, i+1, line, newLine)
+			lines[i] = newLine
+		}
+	}
+
+	return strings.Join(lines, "\n"), matches, diff.String()
+}
+
+// writeFileAtomic 原子地替换已存在文件的内容，保留原文件权限
+func writeFileAtomic(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	_, err = writeStreamAtomic(path, info.Mode().Perm(), func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+	return err
+}
+
+// writeStreamAtomic 先把 write 的输出写入同目录的临时文件，成功后再重命名为 path，
+// 失败时不留下不完整的输出，返回写入的字节数。
+// 符号链接会先解析为目标文件，重命名替换的是目标而不是链接本身
+func writeStreamAtomic(path string, mode os.FileMode, write func(io.Writer) error) (int64, error) {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return 0, err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // 重命名成功后此调用无效
+
+	counter := &countingWriter{w: tmp}
+	if err := write(counter); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return 0, err
+	}
+	return counter.n, nil
+}
+
+// countingWriter 统计写入的字节数
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// isBinaryContent 根据前 8KB 判断内容是否为二进制：包含 NUL 字节或不是合法的 UTF-8
+func isBinaryContent(data []byte) bool {
+	if len(data) >= binarySniffSize {
+		// 截断处可能切断多字节字符，去掉末尾不完整的部分
+		data = trimIncompleteRune(data[:binarySniffSize])
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return true
+	}
+	return !utf8.Valid(data)
+}
+
+// trimIncompleteRune 去掉末尾被截断的多字节字符
+func trimIncompleteRune(data []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+		start := len(data) - i
+		if utf8.RuneStart(data[start]) {
+			if !utf8.FullRune(data[start:]) {
+				return data[:start]
+			}
+			return data
+		}
+	}
+	return data
+}
@@ -0,0 +1,199 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+// setupReplaceFixture 创建包含文本文件和二进制文件的测试目录
+func setupReplaceFixture(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "a.go"), "package a\n\nfunc OldName() {}\n\nvar x = OldName\n")
+	writeTestFile(t, filepath.Join(root, "sub", "b.go"), "package b\n\n// OldName OldName\n")
+	writeTestFile(t, filepath.Join(root, "c.txt"), "nothing here\n")
+	writeTestFile(t, filepath.Join(root, "d.bin"), "OldName\x00\x01\x02")
+	return root
+}
+
+func TestReplaceTool_DryRun(t *testing.T) {
+	root := setupReplaceFixture(t)
+	before, _ := os.ReadFile(filepath.Join(root, "a.go"))
+
+	result, err := NewReplaceTool().Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"pattern":     "OldName",
+		"replacement": "NewName",
+		"path":        root,
+	}))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	metadata := result.Metadata()
+	if metadata["total_matches"] != 4 {
+		t.Errorf("total_matches = %v, want 4", metadata["total_matches"])
+	}
+	if metadata["files_changed"] != 2 {
+		t.Errorf("files_changed = %v, want 2", metadata["files_changed"])
+	}
+	if metadata["skipped_binary_count"] != 1 {
+		t.Errorf("skipped_binary_count = %v, want 1", metadata["skipped_binary_count"])
+	}
+
+	counts := map[string]int{}
+	for _, f := range metadata["files"].([]FileReplacement) {
+		rel, _ := filepath.Rel(root, f.Path)
+		counts[filepath.ToSlash(rel)] = f.Matches
+	}
+	if counts["a.go"] != 2 || counts["sub/b.go"] != 2 {
+		t.Errorf("各文件匹配数 = %v, want a.go:2 sub/b.go:2", counts)
+	}
+
+	aPath := filepath.Join(root, "a.go")
+	wantPreview := "--- a/" + aPath + "\n+++ b/" + aPath + "\n" +
+		"@@ -3 +3 @@\n-func OldName() {}\n+func NewName() {}\n" +
+		"@@ -5 +5 @@\n-var x = OldName\n+var x = NewName\n"
+	if !strings.Contains(metadata["preview"].(string), wantPreview) {
+		t.Errorf("预览 =\n%s\n应包含:\n%s", metadata["preview"], wantPreview)
+	}
+
+	// dry run 不应修改文件
+	after, _ := os.ReadFile(aPath)
+	if string(after) != string(before) {
+		t.Error("dry run 修改了文件")
+	}
+}
+
+func TestReplaceTool_Apply(t *testing.T) {
+	root := setupReplaceFixture(t)
+	if err := os.Chmod(filepath.Join(root, "a.go"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewReplaceTool().Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"pattern":      `Old(\w+)`,
+		"replacement":  "New$1",
+		"path":         root,
+		"file_pattern": "*.go",
+		"dry_run":      false,
+	}))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Metadata()["total_matches"] != 4 {
+		t.Errorf("total_matches = %v, want 4", result.Metadata()["total_matches"])
+	}
+
+	want := map[string]string{
+		"a.go":     "package a\n\nfunc NewName() {}\n\nvar x = NewName\n",
+		"sub/b.go": "package b\n\n// NewName NewName\n",
+		"d.bin":    "OldName\x00\x01\x02",
+	}
+	for rel, content := range want {
+		data, _ := os.ReadFile(filepath.Join(root, rel))
+		if string(data) != content {
+			t.Errorf("%s = %q, want %q", rel, data, content)
+		}
+	}
+
+	// 原子写入应保留文件权限，且不留下临时文件
+	info, _ := os.Stat(filepath.Join(root, "a.go"))
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("a.go 权限 = %v, want 0600", info.Mode().Perm())
+	}
+	entries, _ := os.ReadDir(root)
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".tmp") {
+			t.Errorf("残留临时文件: %s", entry.Name())
+		}
+	}
+}
+
+func TestIsBinaryContent(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"纯文本", []byte("hello\nworld\n"), false},
+		{"UTF-8 中文", []byte("你好，世界"), false},
+		{"包含 NUL", []byte("abc\x00def"), true},
+		{"非法 UTF-8", []byte{0xff, 0xfe, 'a'}, true},
+		{"截断处的多字节字符", append([]byte(strings.Repeat("a", binarySniffSize-1)), "中"...), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isBinaryContent(tt.data); got != tt.want {
+				t.Errorf("isBinaryContent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteFileAtomic_Symlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("创建符号链接需要额外权限")
+	}
+
+	op := []any{map[string]any{"type": "replace", "find": "old", "replace": "new"}}
+	tests := []struct {
+		name   string
+		tool   core.Tool
+		params func(link string) map[string]any
+	}{
+		{"edit", NewEditTool(), func(link string) map[string]any {
+			return map[string]any{"path": link, "operations": op}
+		}},
+		{"multi_edit 回滚模式", NewMultiEditTool(), func(link string) map[string]any {
+			return map[string]any{"edits": []any{map[string]any{"path": link, "operations": op}}, "rollback": true}
+		}},
+		{"replace", NewReplaceTool(), func(link string) map[string]any {
+			return map[string]any{"pattern": "old", "replacement": "new", "path": link, "dry_run": false}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			target := filepath.Join(root, "real", "target.txt")
+			writeTestFile(t, target, "old\n")
+			if err := os.Chmod(target, 0600); err != nil {
+				t.Fatal(err)
+			}
+			link := filepath.Join(root, "link.txt")
+			if err := os.Symlink(target, link); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := tt.tool.Execute(context.Background(), core.NewMapParameters(tt.params(link))); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			// 链接保持不变，修改写入链接指向的文件
+			info, err := os.Lstat(link)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode()&os.ModeSymlink == 0 {
+				t.Errorf("符号链接被替换为普通文件")
+			}
+			if data, _ := os.ReadFile(target); string(data) != "new\n" {
+				t.Errorf("目标文件 = %q, want %q", data, "new\n")
+			}
+			if info, _ := os.Stat(target); info.Mode().Perm() != 0600 {
+				t.Errorf("目标文件权限 = %v, want 0600", info.Mode().Perm())
+			}
+			entries, _ := os.ReadDir(filepath.Dir(target))
+			if len(entries) != 1 {
+				t.Errorf("目标目录应只有原文件, got %d 项", len(entries))
+			}
+		})
+	}
+}
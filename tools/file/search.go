@@ -3,7 +3,10 @@ package file
 import (
 	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -31,6 +34,16 @@ func NewSearchTool() *SearchTool {
 				Type:        "string",
 				Description: "Search pattern (regex supported)",
 			},
+			"patterns": {
+				Type:        "array",
+				Description: "Multiple search patterns; a line matches if any pattern matches",
+				Items:       &core.PropertySchema{Type: "string"},
+			},
+			"word_boundary": {
+				Type:        "boolean",
+				Description: "Match whole words only",
+				Default:     false,
+			},
 			"path": {
 				Type:        "string",
 				Description: "Directory or file path to search in",
@@ -55,19 +68,53 @@ func NewSearchTool() *SearchTool {
 				Type:        "integer",
 				Description: "Maximum number of results to return",
 				Default:     100,
+				Minimum:     core.Bound(1),
+				Maximum:     core.Bound(10000),
 			},
 			"context_lines": {
 				Type:        "integer",
 				Description: "Number of context lines before and after match",
 				Default:     0,
+				Minimum:     core.Bound(0),
+				Maximum:     core.Bound(100),
+			},
+			"respect_gitignore": {
+				Type:        "boolean",
+				Description: "Skip files ignored by .gitignore",
+				Default:     true,
+			},
+			"include_binary": {
+				Type:        "boolean",
+				Description: "Also search binary files",
+				Default:     false,
+			},
+			"count_only": {
+				Type:        "boolean",
+				Description: "Only count matching lines per file instead of returning each match; max_results and context_lines are ignored",
+				Default:     false,
+			},
+			"format": {
+				Type:        "string",
+				Description: "Result text format: grep-style text (file:line: text) or json; structured matches are always in metadata",
+				Enum:        []string{"text", "json"},
+				Default:     "text",
 			},
 		},
-		Required: []string{"pattern"},
+		Required: []string{},
 	})
 	
 	return tool
 }
 
+// errBinaryFile 跳过二进制文件时返回的错误
+var errBinaryFile = errors.New("binary file")
+
+// searchPattern 编译后的搜索模式
+type searchPattern struct {
+	source string
+	re     *regexp.Regexp
+}
+
 // Execute 执行搜索
 func (t *SearchTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
 	// 参数验证
@@ -75,10 +122,33 @@ func (t *SearchTool) Execute(ctx context.Context, params core.Parameters) (core.
 		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
 	}
 	
-	// 获取参数
-	pattern, err := params.GetString("pattern")
-	if err != nil {
-		return nil, core.ErrInvalidParams(t.Info().Name, "invalid pattern parameter")
+	// 获取参数，pattern 与 patterns 至少提供一个
+	var sources []string
+	pattern := ""
+	if params.Has("pattern") {
+		pattern, _ = params.GetString("pattern")
+		if pattern != "" {
+			sources = append(sources, pattern)
+		}
+	}
+	if params.Has("patterns") {
+		extra, err := params.GetStringSlice("patterns")
+		if err != nil {
+			return nil, core.ErrInvalidParams(t.Info().Name, "invalid patterns parameter")
+		}
+		for _, p := range extra {
+			if p != "" {
+				sources = append(sources, p)
+			}
+		}
+	}
+	if len(sources) == 0 {
+		return nil, core.ErrInvalidParams(t.Info().Name, "pattern or patterns is required")
+	}
+	
+	wordBoundary := false
+	if params.Has("word_boundary") {
+		wordBoundary, _ = params.GetBool("word_boundary")
 	}
 	
 	searchPath := "."
@@ -111,29 +181,83 @@ func (t *SearchTool) Execute(ctx context.Context, params core.Parameters) (core.
 		contextLines, _ = params.GetInt("context_lines")
 	}
 	
-	// 编译正则表达式
-	var re *regexp.Regexp
-	if caseSensitive {
-		re, err = regexp.Compile(pattern)
-	} else {
-		re, err = regexp.Compile("(?i)" + pattern)
+	respectGitignore := true
+	if params.Has("respect_gitignore") {
+		respectGitignore, _ = params.GetBool("respect_gitignore")
 	}
-	if err != nil {
-		return nil, core.ErrInvalidParams(t.Info().Name, fmt.Sprintf("invalid regex pattern: %v", err))
+	
+	includeBinary := false
+	if params.Has("include_binary") {
+		includeBinary, _ = params.GetBool("include_binary")
+	}
+	
+	countOnly := false
+	if params.Has("count_only") {
+		countOnly, _ = params.GetBool("count_only")
+	}
+	
+	format := "text"
+	if params.Has("format") {
+		format, _ = params.GetString("format")
+	}
+	
+	// 编译正则表达式
+	patterns := make([]searchPattern, 0, len(sources))
+	for _, source := range sources {
+		expr := source
+		if wordBoundary {
+			expr = `\b(?:` + expr + `)\b`
+		}
+		if !caseSensitive {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, core.ErrInvalidParams(t.Info().Name, fmt.Sprintf("invalid regex pattern %q: %v", source, err))
+		}
+		patterns = append(patterns, searchPattern{source: source, re: re})
 	}
 	
 	// 搜索文件
 	matches := make([]SearchMatch, 0)
+	counts := make([]SearchFileCount, 0)
 	matchCount := 0
 	fileCount := 0
+	skippedBinary := 0
 	
-	err = t.searchFiles(ctx, searchPath, filePattern, recursive, func(path string) error {
+	var ignore *gitignoreMatcher
+	if respectGitignore {
+		ignore = newGitignoreMatcher(searchPath)
+	}
+	
+	// 达到最大结果数时遍历会以错误提前结束，这里不作为失败处理
+	_ = t.searchFiles(ctx, searchPath, filePattern, recursive, ignore, func(path string) error {
+		// 只计数时不构建 SearchMatch，也不受 max_results 限制
+		if countOnly {
+			count, err := t.countInFile(path, patterns, includeBinary)
+			if err != nil {
+				if errors.Is(err, errBinaryFile) {
+					skippedBinary++
+				}
+				return nil
+			}
+			if count > 0 {
+				fileCount++
+				matchCount += count
+				counts = append(counts, SearchFileCount{File: path, Count: count})
+			}
+			return nil
+		}
+		
 		if matchCount >= maxResults {
 			return fmt.Errorf("max results reached")
 		}
 		
-		fileMatches, err := t.searchInFile(path, re, contextLines, maxResults-matchCount)
+		fileMatches, err := t.searchInFile(path, patterns, contextLines, maxResults-matchCount, includeBinary)
 		if err != nil {
+			if errors.Is(err, errBinaryFile) {
+				skippedBinary++
+			}
 			return nil // 忽略单个文件的错误
 		}
 		
@@ -147,11 +271,37 @@ func (t *SearchTool) Execute(ctx context.Context, params core.Parameters) (core.
 	})
 	
 	// 创建结果
-	result := core.NewSimpleResult(fmt.Sprintf("Found %d matches in %d files", matchCount, fileCount))
-	result.WithMetadata("matches", matches)
+	output := fmt.Sprintf("Found %d matches in %d files", matchCount, fileCount)
+	var structured any = matches
+	if countOnly {
+		structured = counts
+	}
+	if format == "json" {
+		data, err := json.MarshalIndent(structured, "", "  ")
+		if err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to encode matches: %v", err))
+		}
+		output = string(data)
+	} else if countOnly {
+		for _, c := range counts {
+			output += fmt.Sprintf("\n%s: %d", c.File, c.Count)
+		}
+	} else if len(matches) > 0 {
+		output += "\n" + renderSearchMatches(matches, contextLines)
+	}
+	
+	result := core.NewSimpleResult(output)
+	if countOnly {
+		result.WithMetadata("counts", counts)
+		result.WithMetadata("count_only", true)
+	} else {
+		result.WithMetadata("matches", matches)
+	}
 	result.WithMetadata("total_matches", matchCount)
 	result.WithMetadata("files_with_matches", fileCount)
 	result.WithMetadata("pattern", pattern)
+	result.WithMetadata("patterns", sources)
+	result.WithMetadata("skipped_binary_count", skippedBinary)
 	
 	return result, nil
 }
@@ -162,12 +312,86 @@ type SearchMatch struct {
 	Line       int      `json:"line"`
 	Column     int      `json:"column"`
 	Match      string   `json:"match"`
+	Pattern    string   `json:"pattern"` // 命中的搜索模式
 	Context    []string `json:"context,omitempty"`
 	LineText   string   `json:"line_text"`
 }
 
-// searchFiles 搜索文件
-func (t *SearchTool) searchFiles(ctx context.Context, searchPath, filePattern string, recursive bool, handler func(string) error) error {
+// SearchFileCount 只计数模式下单个文件的匹配行数
+type SearchFileCount struct {
+	File  string `json:"file"`
+	Count int    `json:"count"`
+}
+
+// renderSearchMatches 以 grep 风格渲染匹配结果：匹配行为 file:line: text，匹配内容用 ** 标出；
+// 上下文行为 file-line- text，相邻的上下文合并输出，不相连的片段之间用 -- 分隔
+func renderSearchMatches(matches []SearchMatch, contextLines int) string {
+	matchLines := make(map[string]map[int]SearchMatch)
+	for _, m := range matches {
+		if matchLines[m.File] == nil {
+			matchLines[m.File] = make(map[int]SearchMatch)
+		}
+		matchLines[m.File][m.Line] = m
+	}
+	
+	var sb strings.Builder
+	lastFile, lastLine := "", 0
+	for _, m := range matches {
+		lines, start := m.Context, m.Line-contextLines
+		if start < 1 {
+			start = 1
+		}
+		if len(lines) == 0 {
+			lines, start = []string{m.LineText}, m.Line
+		}
+		
+		// 与上一段不相连时输出分隔符，相连或重叠时只输出新的行
+		contiguous := m.File == lastFile && start <= lastLine+1
+		if !contiguous && sb.Len() > 0 && contextLines > 0 {
+			sb.WriteString("--\n")
+		}
+		
+		for i, text := range lines {
+			lineNum := start + i
+			if m.File == lastFile && lineNum <= lastLine {
+				continue
+			}
+			if hit, ok := matchLines[m.File][lineNum]; ok {
+				fmt.Fprintf(&sb, "%s:%d: %s\n", m.File, lineNum, highlightMatch(hit))
+			} else {
+				fmt.Fprintf(&sb, "%s-%d- %s\n", m.File, lineNum, text)
+			}
+			lastFile, lastLine = m.File, lineNum
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// highlightMatch 用 ** 标出行中匹配的部分
+func highlightMatch(m SearchMatch) string {
+	start := m.Column - 1
+	end := start + len(m.Match)
+	if m.Match == "" || start < 0 || end > len(m.LineText) {
+		return m.LineText
+	}
+	return m.LineText[:start] + "**" + m.Match + "**" + m.LineText[end:]
+}
+
+// findMatch 返回行中最靠前的匹配位置及对应的模式
+func findMatch(line string, patterns []searchPattern) ([]int, string) {
+	var best []int
+	bestPattern := ""
+	for _, p := range patterns {
+		if loc := p.re.FindStringIndex(line); loc != nil && (best == nil || loc[0] < best[0]) {
+			best = loc
+			bestPattern = p.source
+		}
+	}
+	return best, bestPattern
+}
+
+// searchFiles 搜索文件，ignore 为 nil 时不过滤 .gitignore，但始终跳过 .git 目录
+func (t *SearchTool) searchFiles(ctx context.Context, searchPath, filePattern string, recursive bool, ignore *gitignoreMatcher, handler func(string) error) error {
 	// 检查是否为单个文件
 	info, err := os.Stat(searchPath)
 	if err != nil {
@@ -198,6 +422,16 @@ func (t *SearchTool) searchFiles(ctx context.Context, searchPath, filePattern st
 			}
 			
 			if info.IsDir() {
+				if path == searchPath {
+					return nil
+				}
+				if info.Name() == ".git" || (ignore != nil && ignore.match(path, true)) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			
+			if ignore != nil && ignore.match(path, false) {
 				return nil
 			}
 			
@@ -220,6 +454,10 @@ func (t *SearchTool) searchFiles(ctx context.Context, searchPath, filePattern st
 				continue
 			}
 			
+			if ignore != nil && ignore.match(filepath.Join(searchPath, entry.Name()), false) {
+				continue
+			}
+			
 			matched, _ := filepath.Match(filePattern, entry.Name())
 			if matched || filePattern == "*" {
 				if err := handler(filepath.Join(searchPath, entry.Name())); err != nil {
@@ -232,14 +470,62 @@ func (t *SearchTool) searchFiles(ctx context.Context, searchPath, filePattern st
 	}
 }
 
+// countInFile 统计文件中匹配的行数
+func (t *SearchTool) countInFile(filePath string, patterns []searchPattern, includeBinary bool) (int, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	
+	if !includeBinary {
+		if err := checkNotBinary(file); err != nil {
+			return 0, err
+		}
+	}
+	
+	count := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		for _, p := range patterns {
+			if p.re.MatchString(line) {
+				count++
+				break
+			}
+		}
+	}
+	return count, scanner.Err()
+}
+
+// checkNotBinary 根据文件开头判断是否为二进制文件，是则返回 errBinaryFile，否则将读取位置重置到开头
+func checkNotBinary(file *os.File) error {
+	head := make([]byte, binarySniffSize)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	if isBinaryContent(head[:n]) {
+		return errBinaryFile
+	}
+	_, err = file.Seek(0, io.SeekStart)
+	return err
+}
+
 // searchInFile 在文件中搜索
-func (t *SearchTool) searchInFile(filePath string, re *regexp.Regexp, contextLines, maxMatches int) ([]SearchMatch, error) {
+func (t *SearchTool) searchInFile(filePath string, patterns []searchPattern, contextLines, maxMatches int, includeBinary bool) ([]SearchMatch, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
 	
+	if !includeBinary {
+		if err := checkNotBinary(file); err != nil {
+			return nil, err
+		}
+	}
+	
 	matches := make([]SearchMatch, 0)
 	scanner := bufio.NewScanner(file)
 	lineNum := 0
@@ -250,7 +536,6 @@ func (t *SearchTool) searchInFile(filePath string, re *regexp.Regexp, contextLin
 		for scanner.Scan() {
 			lines = append(lines, scanner.Text())
 		}
-		scanner = nil
 	}
 	
 	// 搜索匹配
@@ -261,12 +546,13 @@ func (t *SearchTool) searchInFile(filePath string, re *regexp.Regexp, contextLin
 				break
 			}
 			
-			if loc := re.FindStringIndex(line); loc != nil {
+			if loc, pattern := findMatch(line, patterns); loc != nil {
 				match := SearchMatch{
 					File:     filePath,
 					Line:     i + 1,
 					Column:   loc[0] + 1,
 					Match:    line[loc[0]:loc[1]],
+					Pattern:  pattern,
 					LineText: line,
 				}
 				
@@ -294,12 +580,13 @@ func (t *SearchTool) searchInFile(filePath string, re *regexp.Regexp, contextLin
 				break
 			}
 			
-			if loc := re.FindStringIndex(line); loc != nil {
+			if loc, pattern := findMatch(line, patterns); loc != nil {
 				matches = append(matches, SearchMatch{
 					File:     filePath,
 					Line:     lineNum,
 					Column:   loc[0] + 1,
 					Match:    line[loc[0]:loc[1]],
+					Pattern:  pattern,
 					LineText: line,
 				})
 			}
@@ -337,6 +624,7 @@ func NewGlobTool() *GlobTool {
 				Type:        "array",
 				Description: "Patterns to exclude",
 				Default:     []string{},
+				Items:       &core.PropertySchema{Type: "string"},
 			},
 			"include_dirs": {
 				Type:        "boolean",
@@ -352,6 +640,12 @@ func NewGlobTool() *GlobTool {
 				Type:        "integer",
 				Description: "Maximum number of results",
 				Default:     1000,
+				Minimum:     core.Bound(1),
+			},
+			"respect_gitignore": {
+				Type:        "boolean",
+				Description: "Skip files ignored by .gitignore",
+				Default:     true,
 			},
 		},
 		Required: []string{"pattern"},
@@ -401,12 +695,22 @@ func (t *GlobTool) Execute(ctx context.Context, params core.Parameters) (core.Re
 		maxResults, _ = params.GetInt("max_results")
 	}
 	
+	respectGitignore := true
+	if params.Has("respect_gitignore") {
+		respectGitignore, _ = params.GetBool("respect_gitignore")
+	}
+	
+	var ignore *gitignoreMatcher
+	if respectGitignore {
+		ignore = newGitignoreMatcher(basePath)
+	}
+	
 	// 执行通配符匹配
 	matches := []string{}
 	
 	// 处理 ** 模式
 	if strings.Contains(pattern, "**") {
-		err = t.globRecursive(ctx, basePath, pattern, excludePatterns, includeDirs, maxResults, &matches)
+		err = t.globRecursive(ctx, basePath, pattern, excludePatterns, includeDirs, maxResults, ignore, &matches)
 	} else {
 		// 简单匹配
 		globPattern := filepath.Join(basePath, pattern)
@@ -428,9 +732,13 @@ func (t *GlobTool) Execute(ctx context.Context, params core.Parameters) (core.Re
 				
 				if !excluded {
 					info, err := os.Stat(file)
-					if err == nil && (includeDirs || !info.IsDir()) {
-						matches = append(matches, file)
+					if err != nil || (!includeDirs && info.IsDir()) {
+						continue
 					}
+					if isGitDir(filepath.ToSlash(file)) || (ignore != nil && ignore.matchWithParents(file, info.IsDir())) {
+						continue
+					}
+					matches = append(matches, file)
 				}
 			}
 		}
@@ -445,8 +753,8 @@ func (t *GlobTool) Execute(ctx context.Context, params core.Parameters) (core.Re
 	return result, nil
 }
 
-// globRecursive 递归通配符匹配
-func (t *GlobTool) globRecursive(ctx context.Context, basePath, pattern string, excludes []string, includeDirs bool, maxResults int, matches *[]string) error {
+// globRecursive 递归通配符匹配，ignore 为 nil 时不过滤 .gitignore，但始终跳过 .git 目录
+func (t *GlobTool) globRecursive(ctx context.Context, basePath, pattern string, excludes []string, includeDirs bool, maxResults int, ignore *gitignoreMatcher, matches *[]string) error {
 	// 分解 ** 模式
 	parts := strings.Split(pattern, "**")
 	if len(parts) != 2 {
@@ -456,7 +764,8 @@ func (t *GlobTool) globRecursive(ctx context.Context, basePath, pattern string,
 	prefix := strings.TrimSuffix(parts[0], "/")
 	suffix := strings.TrimPrefix(parts[1], "/")
 	
-	return filepath.Walk(filepath.Join(basePath, prefix), func(path string, info os.FileInfo, err error) error {
+	walkRoot := filepath.Join(basePath, prefix)
+	return filepath.Walk(walkRoot, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // 忽略错误
 		}
@@ -472,9 +781,26 @@ func (t *GlobTool) globRecursive(ctx context.Context, basePath, pattern string,
 			return fmt.Errorf("max results reached")
 		}
 		
-		// 检查是否匹配后缀
-		relPath, _ := filepath.Rel(filepath.Join(basePath, prefix), path)
-		if matched, _ := filepath.Match(suffix, relPath); matched {
+		// 跳过 .git 目录和被 .gitignore 忽略的路径
+		if path != walkRoot {
+			if info.IsDir() && info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			if ignore != nil && ignore.match(path, info.IsDir()) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+		
+		// 检查是否匹配后缀；后缀不含路径分隔符时按文件名匹配任意层级
+		relPath, _ := filepath.Rel(walkRoot, path)
+		target := relPath
+		if !strings.Contains(suffix, "/") {
+			target = filepath.Base(path)
+		}
+		if matched, _ := filepath.Match(suffix, target); matched {
 			// 检查排除模式
 			excluded := false
 			for _, exclude := range excludes {
@@ -0,0 +1,308 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+func TestSearchTool_Patterns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "code.go")
+	writeTestFile(t, path, `func handleError(err error) {}
+// TODO: refactor
+var errCount = 0
+log.Fatal(err)
+// FIXME later
+`)
+
+	tests := []struct {
+		name         string
+		params       map[string]any
+		wantLines    []int
+		wantPatterns []string
+		wantErr      bool
+	}{
+		{
+			name:         "单个 pattern 保持兼容",
+			params:       map[string]any{"pattern": "TODO"},
+			wantLines:    []int{2},
+			wantPatterns: []string{"TODO"},
+		},
+		{
+			name:         "多个模式取并集",
+			params:       map[string]any{"patterns": []any{"TODO", "FIXME"}},
+			wantLines:    []int{2, 5},
+			wantPatterns: []string{"TODO", "FIXME"},
+		},
+		{
+			name:         "pattern 与 patterns 同时使用",
+			params:       map[string]any{"pattern": "Fatal", "patterns": []string{"FIXME"}},
+			wantLines:    []int{4, 5},
+			wantPatterns: []string{"Fatal", "FIXME"},
+		},
+		{
+			name:         "不限制单词边界",
+			params:       map[string]any{"pattern": "err"},
+			wantLines:    []int{1, 3, 4},
+			wantPatterns: []string{"err", "err", "err"},
+		},
+		{
+			name:         "单词边界",
+			params:       map[string]any{"pattern": "err", "word_boundary": true},
+			wantLines:    []int{1, 4},
+			wantPatterns: []string{"err", "err"},
+		},
+		{
+			name:         "单词边界与多模式和大小写组合",
+			params:       map[string]any{"patterns": []any{"todo", "fixme|err"}, "word_boundary": true, "case_sensitive": false},
+			wantLines:    []int{1, 2, 4, 5},
+			wantPatterns: []string{"fixme|err", "todo", "fixme|err", "fixme|err"},
+		},
+		{
+			name:    "缺少模式",
+			params:  map[string]any{},
+			wantErr: true,
+		},
+		{
+			name:    "无效正则",
+			params:  map[string]any{"patterns": []any{"ok", "("}},
+			wantErr: true,
+		},
+		{
+			name:    "max_results 小于最小值",
+			params:  map[string]any{"pattern": "TODO", "max_results": 0},
+			wantErr: true,
+		},
+		{
+			name:    "context_lines 大于最大值",
+			params:  map[string]any{"pattern": "TODO", "context_lines": 1000},
+			wantErr: true,
+		},
+	}
+
+	tool := NewSearchTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.params["path"] = path
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(tt.params))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			matches := result.Metadata()["matches"].([]SearchMatch)
+			if len(matches) != len(tt.wantLines) {
+				t.Fatalf("匹配数 = %d, want %d: %+v", len(matches), len(tt.wantLines), matches)
+			}
+			for i, match := range matches {
+				if match.Line != tt.wantLines[i] {
+					t.Errorf("第 %d 个匹配行号 = %d, want %d", i, match.Line, tt.wantLines[i])
+				}
+				if match.Pattern != tt.wantPatterns[i] {
+					t.Errorf("第 %d 个匹配模式 = %q, want %q", i, match.Pattern, tt.wantPatterns[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSearchTool_SkipBinary(t *testing.T) {
+	root := t.TempDir()
+	// 最小的 PNG 文件头，中间夹带可被匹配的文本
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00needle\x1f\x15\xc4\x89")
+	writeTestFile(t, filepath.Join(root, "image.png"), string(png))
+	writeTestFile(t, filepath.Join(root, "notes.txt"), "a needle in text\n")
+
+	tests := []struct {
+		name          string
+		includeBinary any
+		wantFiles     []string
+		wantSkipped   int
+	}{
+		{
+			name:        "默认跳过二进制文件",
+			wantFiles:   []string{"notes.txt"},
+			wantSkipped: 1,
+		},
+		{
+			name:          "包含二进制文件",
+			includeBinary: true,
+			wantFiles:     []string{"image.png", "notes.txt"},
+			wantSkipped:   0,
+		},
+	}
+
+	tool := NewSearchTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := map[string]any{"pattern": "needle", "path": root}
+			if tt.includeBinary != nil {
+				params["include_binary"] = tt.includeBinary
+			}
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(params))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			var got []string
+			for _, match := range result.Metadata()["matches"].([]SearchMatch) {
+				got = append(got, filepath.Base(match.File))
+			}
+			assertSameFiles(t, got, tt.wantFiles)
+
+			if result.Metadata()["skipped_binary_count"] != tt.wantSkipped {
+				t.Errorf("skipped_binary_count = %v, want %d", result.Metadata()["skipped_binary_count"], tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestSearchTool_Format(t *testing.T) {
+	root := t.TempDir()
+	pathA := filepath.Join(root, "a.txt")
+	pathB := filepath.Join(root, "b.txt")
+	writeTestFile(t, pathA, "one\ntwo\nneedle here\nthree\nfour\nfive\nsix\nanother needle\n")
+	writeTestFile(t, pathB, "needle\n")
+
+	tests := []struct {
+		name   string
+		params map[string]any
+		want   []string
+		absent []string
+	}{
+		{
+			name:   "默认 text 格式包含文件和行号",
+			params: map[string]any{"pattern": "needle", "path": root},
+			want:   []string{"Found 3 matches in 2 files", pathA + ":3: **needle** here", pathA + ":8: another **needle**", pathB + ":1: **needle**"},
+			absent: []string{"--"},
+		},
+		{
+			name:   "上下文行带行号，不相连的片段用 -- 分隔",
+			params: map[string]any{"pattern": "needle", "path": pathA, "context_lines": 1},
+			want:   []string{pathA + "-2- two\n" + pathA + ":3: **needle** here\n" + pathA + "-4- three\n--\n" + pathA + "-7- six\n" + pathA + ":8: another **needle**"},
+		},
+		{
+			name:   "重叠的上下文只输出一次",
+			params: map[string]any{"pattern": "needle|three", "path": pathA, "context_lines": 1},
+			want:   []string{pathA + "-2- two\n" + pathA + ":3: **needle** here\n" + pathA + ":4: **three**\n" + pathA + "-5- four\n--\n"},
+		},
+		{
+			name:   "json 格式",
+			params: map[string]any{"pattern": "needle", "path": pathB, "format": "json"},
+			want:   []string{`"file": "` + pathB + `"`, `"line": 1`},
+			absent: []string{"Found", "**"},
+		},
+	}
+
+	tool := NewSearchTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(tt.params))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(result.String(), want) {
+					t.Errorf("输出缺少 %q:\n%s", want, result.String())
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(result.String(), absent) {
+					t.Errorf("输出不应包含 %q:\n%s", absent, result.String())
+				}
+			}
+			if _, ok := result.Metadata()["matches"].([]SearchMatch); !ok {
+				t.Errorf("metadata 中缺少结构化的 matches")
+			}
+		})
+	}
+}
+
+func TestSearchTool_CountOnly(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "a.go"), "needle\nhay\nneedle needle\n")
+	writeTestFile(t, filepath.Join(root, "b.txt"), "needle\n")
+	writeTestFile(t, filepath.Join(root, "sub", "c.go"), "hay\nNEEDLE\nneedle\n")
+
+	tests := []struct {
+		name      string
+		params    map[string]any
+		wantTotal int
+		wantFiles int
+	}{
+		{
+			name:      "递归搜索全部文件",
+			params:    map[string]any{"pattern": "needle"},
+			wantTotal: 4,
+			wantFiles: 3,
+		},
+		{
+			name:      "遵循 file_pattern",
+			params:    map[string]any{"pattern": "needle", "file_pattern": "*.go"},
+			wantTotal: 3,
+			wantFiles: 2,
+		},
+		{
+			name:      "遵循 recursive",
+			params:    map[string]any{"pattern": "needle", "recursive": false},
+			wantTotal: 3,
+			wantFiles: 2,
+		},
+		{
+			name:      "不区分大小写",
+			params:    map[string]any{"pattern": "needle", "case_sensitive": false},
+			wantTotal: 5,
+			wantFiles: 3,
+		},
+	}
+
+	tool := NewSearchTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.params["path"] = root
+			full, err := tool.Execute(context.Background(), core.NewMapParameters(tt.params))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			tt.params["count_only"] = true
+			counted, err := tool.Execute(context.Background(), core.NewMapParameters(tt.params))
+			if err != nil {
+				t.Fatalf("Execute(count_only) error = %v", err)
+			}
+
+			if got := counted.Metadata()["total_matches"]; got != tt.wantTotal || got != full.Metadata()["total_matches"] {
+				t.Errorf("total_matches = %v, 完整搜索 = %v, want %d", got, full.Metadata()["total_matches"], tt.wantTotal)
+			}
+			if got := counted.Metadata()["files_with_matches"]; got != tt.wantFiles {
+				t.Errorf("files_with_matches = %v, want %d", got, tt.wantFiles)
+			}
+			if _, ok := counted.Metadata()["matches"]; ok {
+				t.Errorf("count_only 不应返回 matches")
+			}
+
+			counts := counted.Metadata()["counts"].([]SearchFileCount)
+			perFile := make(map[string]int)
+			for _, m := range full.Metadata()["matches"].([]SearchMatch) {
+				perFile[m.File]++
+			}
+			if len(counts) != len(perFile) {
+				t.Fatalf("counts = %v, want %v", counts, perFile)
+			}
+			for _, c := range counts {
+				if perFile[c.File] != c.Count {
+					t.Errorf("%s 计数 = %d, want %d", c.File, c.Count, perFile[c.File])
+				}
+				if !strings.Contains(counted.String(), fmt.Sprintf("%s: %d", c.File, c.Count)) {
+					t.Errorf("输出缺少 %s 的计数:\n%s", c.File, counted.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package file
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"opencode_nano/tools/core"
+)
+
+// StatTool 统计文件行数、单词数、字节数和字符数的工具
+type StatTool struct {
+	*core.BaseTool
+	searcher *SearchTool
+}
+
+// NewStatTool 创建统计工具
+func NewStatTool() *StatTool {
+	tool := &StatTool{
+		BaseTool: core.NewBaseTool("stat", "file", "Count lines, words, bytes and characters of files"),
+		searcher: NewSearchTool(),
+	}
+
+	tool.SetTags("file", "stat", "count", "wc")
+	tool.SetSchema(core.ParameterSchema{
+		Type: "object",
+		Properties: map[string]core.PropertySchema{
+			"path": {
+				Type:        "string",
+				Description: "File or directory path",
+			},
+			"file_pattern": {
+				Type:        "string",
+				Description: "File name pattern when path is a directory (e.g., '*.go')",
+				Default:     "*",
+			},
+		},
+		Required: []string{"path"},
+	})
+
+	return tool
+}
+
+// FileStats 单个文件的统计结果
+type FileStats struct {
+	Path   string `json:"path"`
+	Lines  int    `json:"lines"`
+	Words  int    `json:"words"`
+	Bytes  int64  `json:"bytes"`
+	Chars  int    `json:"chars"`
+	Binary bool   `json:"binary"`
+}
+
+// Execute 执行统计
+func (t *StatTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	// 参数验证
+	if err := params.Validate(t.Schema()); err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+
+	statPath, err := params.GetString("path")
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, "invalid path parameter")
+	}
+	statPath = filepath.Clean(statPath)
+
+	filePattern := "*"
+	if params.Has("file_pattern") {
+		filePattern, _ = params.GetString("file_pattern")
+	}
+
+	info, err := os.Stat(statPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("path not found: %s", statPath))
+		}
+		return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+	}
+
+	// 单个文件
+	if !info.IsDir() {
+		stats, err := countFile(statPath)
+		if err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+		}
+
+		result := core.NewSimpleResult(formatFileStats(stats))
+		result.WithMetadata("path", stats.Path)
+		result.WithMetadata("lines", stats.Lines)
+		result.WithMetadata("words", stats.Words)
+		result.WithMetadata("bytes", stats.Bytes)
+		result.WithMetadata("chars", stats.Chars)
+		result.WithMetadata("binary", stats.Binary)
+		return result, nil
+	}
+
+	// 目录：汇总所有匹配的文件
+	total := FileStats{Path: "total"}
+	var files []FileStats
+	binaryFiles := 0
+	err = t.searcher.searchFiles(ctx, statPath, filePattern, true, newGitignoreMatcher(statPath), func(path string) error {
+		stats, err := countFile(path)
+		if err != nil {
+			return nil // 忽略单个文件的错误
+		}
+		files = append(files, stats)
+		total.Lines += stats.Lines
+		total.Words += stats.Words
+		total.Bytes += stats.Bytes
+		total.Chars += stats.Chars
+		if stats.Binary {
+			binaryFiles++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+	}
+
+	lines := make([]string, 0, len(files)+1)
+	for _, stats := range files {
+		lines = append(lines, formatFileStats(stats))
+	}
+	lines = append(lines, formatFileStats(total))
+
+	result := core.NewSimpleResult(strings.Join(lines, "\n"))
+	result.WithMetadata("path", statPath)
+	result.WithMetadata("files", files)
+	result.WithMetadata("file_count", len(files))
+	result.WithMetadata("binary_files", binaryFiles)
+	result.WithMetadata("lines", total.Lines)
+	result.WithMetadata("words", total.Words)
+	result.WithMetadata("bytes", total.Bytes)
+	result.WithMetadata("chars", total.Chars)
+
+	return result, nil
+}
+
+// countFile 流式统计单个文件，不一次性读入内存。
+// 行数按换行符计数，最后一行没有换行时也计为一行
+func countFile(path string) (FileStats, error) {
+	stats := FileStats{Path: path}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return stats, fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReaderSize(file, binarySniffSize)
+	head, err := reader.Peek(binarySniffSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return stats, fmt.Errorf("failed to read file: %v", err)
+	}
+	stats.Binary = isBinaryContent(head)
+
+	inWord := false
+	var last rune
+	for {
+		r, size, err := reader.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return stats, fmt.Errorf("failed to read file: %v", err)
+		}
+
+		stats.Bytes += int64(size)
+		stats.Chars++
+		if r == '\n' {
+			stats.Lines++
+		}
+		if unicode.IsSpace(r) {
+			inWord = false
+		} else if !inWord {
+			inWord = true
+			stats.Words++
+		}
+		last = r
+	}
+
+	if stats.Bytes > 0 && last != '\n' {
+		stats.Lines++
+	}
+	return stats, nil
+}
+
+// formatFileStats 按 wc 风格格式化统计结果
+func formatFileStats(stats FileStats) string {
+	line := fmt.Sprintf("%8d %8d %8d %8d %s", stats.Lines, stats.Words, stats.Bytes, stats.Chars, stats.Path)
+	if stats.Binary {
+		line += " (binary)"
+	}
+	return line
+}
@@ -0,0 +1,113 @@
+package file
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+func TestStatTool_File(t *testing.T) {
+	root := t.TempDir()
+	fixtures := map[string]string{
+		"ascii.txt":   "hello world\nfoo bar baz\n",
+		"utf8.txt":    "你好 世界\nhéllo\n",
+		"no_eol.txt":  "one two\nthree",
+		"empty.txt":   "",
+		"spaces.txt":  "  \t \n\n",
+		"binary.bin":  "ab\x00cd",
+		"invalid.txt": "a\xffb",
+	}
+	for name, content := range fixtures {
+		writeTestFile(t, filepath.Join(root, name), content)
+	}
+
+	tests := []struct {
+		file       string
+		wantLines  int
+		wantWords  int
+		wantBytes  int64
+		wantChars  int
+		wantBinary bool
+	}{
+		{"ascii.txt", 2, 5, 24, 24, false},
+		{"utf8.txt", 2, 3, 21, 12, false},
+		{"no_eol.txt", 2, 3, 13, 13, false},
+		{"empty.txt", 0, 0, 0, 0, false},
+		{"spaces.txt", 2, 0, 6, 6, false},
+		{"binary.bin", 1, 1, 5, 5, true},
+		{"invalid.txt", 1, 1, 3, 3, true},
+	}
+
+	tool := NewStatTool()
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+				"path": filepath.Join(root, tt.file),
+			}))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			metadata := result.Metadata()
+			if metadata["lines"] != tt.wantLines {
+				t.Errorf("lines = %v, want %d", metadata["lines"], tt.wantLines)
+			}
+			if metadata["words"] != tt.wantWords {
+				t.Errorf("words = %v, want %d", metadata["words"], tt.wantWords)
+			}
+			if metadata["bytes"] != tt.wantBytes {
+				t.Errorf("bytes = %v, want %d", metadata["bytes"], tt.wantBytes)
+			}
+			if metadata["chars"] != tt.wantChars {
+				t.Errorf("chars = %v, want %d", metadata["chars"], tt.wantChars)
+			}
+			if metadata["binary"] != tt.wantBinary {
+				t.Errorf("binary = %v, want %v", metadata["binary"], tt.wantBinary)
+			}
+		})
+	}
+}
+
+func TestStatTool_Directory(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "a.go"), "package a\n")
+	writeTestFile(t, filepath.Join(root, "sub", "b.go"), "package b\n\nfunc B() {}\n")
+	writeTestFile(t, filepath.Join(root, "notes.md"), "# 标题\n")
+
+	tests := []struct {
+		name      string
+		pattern   any
+		wantFiles int
+		wantLines int
+		wantWords int
+		wantChars int
+	}{
+		{"全部文件", nil, 3, 5, 9, 38},
+		{"按模式过滤", "*.go", 2, 4, 7, 33},
+	}
+
+	tool := NewStatTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := map[string]any{"path": root}
+			if tt.pattern != nil {
+				params["file_pattern"] = tt.pattern
+			}
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(params))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			metadata := result.Metadata()
+			if metadata["file_count"] != tt.wantFiles {
+				t.Errorf("file_count = %v, want %d", metadata["file_count"], tt.wantFiles)
+			}
+			if metadata["lines"] != tt.wantLines || metadata["words"] != tt.wantWords || metadata["chars"] != tt.wantChars {
+				t.Errorf("lines/words/chars = %v/%v/%v, want %d/%d/%d",
+					metadata["lines"], metadata["words"], metadata["chars"], tt.wantLines, tt.wantWords, tt.wantChars)
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package file
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"opencode_nano/tools/core"
+)
+
+// TarTool .tar.gz 归档的创建和解压工具，保留文件权限
+type TarTool struct {
+	*core.BaseTool
+}
+
+// NewTarTool 创建 tar 工具
+func NewTarTool() *TarTool {
+	tool := &TarTool{
+		BaseTool: core.NewBaseTool("tar", "file", "Create a .tar.gz archive from files or directories, or extract a .tar.gz archive"),
+	}
+
+	tool.SetRequiresPerm(true)
+	tool.SetTags("file", "tar", "archive", "compress", "extract")
+	tool.SetSchema(core.ParameterSchema{
+		Type: "object",
+		Properties: map[string]core.PropertySchema{
+			"action": {
+				Type:        "string",
+				Description: "create or extract",
+				Enum:        []string{"create", "extract"},
+			},
+			"archive": {
+				Type:        "string",
+				Description: "Path of the .tar.gz archive to create or extract",
+			},
+			"paths": {
+				Type:        "array",
+				Description: "Files or directories to add when creating; entries are named relative to each path's parent",
+				Items:       &core.PropertySchema{Type: "string"},
+			},
+			"dir": {
+				Type:        "string",
+				Description: "Target directory for extract (default: current directory)",
+				Default:     ".",
+			},
+			"strip_components": {
+				Type:        "integer",
+				Description: "Strip this many leading path components from entry names when extracting",
+				Default:     0,
+				Minimum:     core.Bound(0),
+			},
+			"overwrite": {
+				Type:        "boolean",
+				Description: "Overwrite the archive or extracted files if they exist",
+				Default:     false,
+			},
+		},
+		Required: []string{"action", "archive"},
+	})
+
+	return tool
+}
+
+// Execute 创建或解压 tar.gz 归档
+func (t *TarTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	// 参数验证
+	if err := params.Validate(t.Schema()); err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+
+	action, err := params.GetString("action")
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, "invalid action parameter")
+	}
+
+	archive, err := params.GetString("archive")
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, "invalid archive parameter")
+	}
+	archive = filepath.Clean(archive)
+
+	overwrite := false
+	if params.Has("overwrite") {
+		overwrite, _ = params.GetBool("overwrite")
+	}
+
+	if action == "create" {
+		var paths []string
+		if params.Has("paths") {
+			paths, err = params.GetStringSlice("paths")
+			if err != nil {
+				return nil, core.ErrInvalidParams(t.Info().Name, "paths must be an array of strings")
+			}
+		}
+		if len(paths) == 0 {
+			return nil, core.ErrInvalidParams(t.Info().Name, "paths is required for create")
+		}
+		return t.create(ctx, archive, paths, overwrite)
+	}
+
+	dir := "."
+	if params.Has("dir") {
+		dir, _ = params.GetString("dir")
+	}
+	strip := 0
+	if params.Has("strip_components") {
+		strip, _ = params.GetInt("strip_components")
+	}
+	return t.extract(ctx, archive, filepath.Clean(dir), strip, overwrite)
+}
+
+// create 将 paths 打包为 archive
+func (t *TarTool) create(ctx context.Context, archive string, paths []string, overwrite bool) (core.Result, error) {
+	if !overwrite {
+		if _, err := os.Stat(archive); err == nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name,
+				fmt.Sprintf("archive already exists: %s (set overwrite to replace)", archive))
+		}
+	}
+
+	// 先收集条目，避免把正在写入的归档本身打包进去
+	var entries []archiveEntry
+	for _, path := range paths {
+		collected, err := collectArchiveEntries(filepath.Clean(path))
+		if err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+		}
+		entries = append(entries, collected...)
+	}
+
+	files := 0
+	var totalSize int64
+	archiveSize, err := writeStreamAtomic(archive, 0644, func(w io.Writer) error {
+		zw := gzip.NewWriter(w)
+		tw := tar.NewWriter(zw)
+		for _, entry := range entries {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+
+			header, err := tar.FileInfoHeader(entry.info, "")
+			if err != nil {
+				return err
+			}
+			header.Name = entry.name
+			if entry.isDir {
+				header.Name += "/"
+			}
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			if entry.isDir {
+				continue
+			}
+
+			n, err := copyFileTo(tw, entry.path)
+			if err != nil {
+				return fmt.Errorf("failed to add %s: %v", entry.path, err)
+			}
+			files++
+			totalSize += n
+		}
+		if err := tw.Close(); err != nil {
+			return err
+		}
+		return zw.Close()
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, core.ErrCancelled(t.Info().Name)
+		}
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to create archive: %v", err))
+	}
+
+	result := core.NewSimpleResult(fmt.Sprintf("Created %s with %d files (%d bytes, archive %d bytes)", archive, files, totalSize, archiveSize))
+	result.WithMetadata("action", "create")
+	result.WithMetadata("archive", archive)
+	result.WithMetadata("files", files)
+	result.WithMetadata("total_size", totalSize)
+	result.WithMetadata("archive_size", archiveSize)
+
+	return result, nil
+}
+
+// extract 将 archive 解压到 dir。先完整读一遍检查所有条目的路径，通过后再写入
+func (t *TarTool) extract(ctx context.Context, archive, dir string, strip int, overwrite bool) (core.Result, error) {
+	err := walkTarGz(archive, func(header *tar.Header, _ io.Reader) error {
+		name, ok := stripComponents(header.Name, strip)
+		if !ok {
+			return nil
+		}
+		target, err := safeExtractPath(dir, name)
+		if err != nil {
+			return err
+		}
+		if !overwrite && header.Typeflag == tar.TypeReg {
+			if _, err := os.Stat(target); err == nil {
+				return fmt.Errorf("destination already exists: %s (set overwrite to replace)", target)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("archive not found: %s", archive))
+		}
+		return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+	}
+
+	files := 0
+	var totalSize int64
+	// 目录权限最后设置，避免只读目录导致其中的文件无法写入
+	dirModes := make(map[string]os.FileMode)
+	err = walkTarGz(archive, func(header *tar.Header, content io.Reader) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		name, ok := stripComponents(header.Name, strip)
+		if !ok {
+			return nil
+		}
+		target, err := safeExtractPath(dir, name)
+		if err != nil {
+			return err
+		}
+		mode := os.FileMode(header.Mode).Perm()
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("failed to create directory: %v", err)
+			}
+			dirModes[target] = mode
+		case tar.TypeReg:
+			n, err := extractTarFile(content, target, mode)
+			if err != nil {
+				return fmt.Errorf("failed to extract %s: %v", header.Name, err)
+			}
+			files++
+			totalSize += n
+		}
+		// 跳过符号链接、硬链接等其他类型，避免解压出指向目标目录之外的链接
+		return nil
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, core.ErrCancelled(t.Info().Name)
+		}
+		return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+	}
+	for target, mode := range dirModes {
+		if err := os.Chmod(target, mode); err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to set mode of %s: %v", target, err))
+		}
+	}
+
+	result := core.NewSimpleResult(fmt.Sprintf("Extracted %d files (%d bytes) from %s to %s", files, totalSize, archive, dir))
+	result.WithMetadata("action", "extract")
+	result.WithMetadata("archive", archive)
+	result.WithMetadata("dir", dir)
+	result.WithMetadata("files", files)
+	result.WithMetadata("total_size", totalSize)
+
+	return result, nil
+}
+
+// walkTarGz 依次读取 tar.gz 归档中的条目
+func walkTarGz(archive string, fn func(header *tar.Header, content io.Reader) error) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("not a gzip file: %v", err)
+	}
+	defer zr.Close()
+
+	tr := tar.NewReader(zr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %v", err)
+		}
+		if err := fn(header, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// stripComponents 去掉条目名开头的 n 级路径，剩余部分为空时返回 false
+func stripComponents(name string, n int) (string, bool) {
+	if n == 0 {
+		return name, true
+	}
+	parts := strings.Split(strings.Trim(strings.ReplaceAll(name, "\\", "/"), "/"), "/")
+	if len(parts) <= n {
+		return "", false
+	}
+	return strings.Join(parts[n:], "/"), true
+}
+
+// extractTarFile 写入单个文件并设置权限
+func extractTarFile(content io.Reader, target string, mode os.FileMode) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return 0, err
+	}
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(out, content)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return n, err
+	}
+	return n, os.Chmod(target, mode)
+}
@@ -0,0 +1,159 @@
+package file
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+func TestTarTool_RoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "release")
+	scriptPath := filepath.Join(srcDir, "bin", "run.sh")
+	secretPath := filepath.Join(srcDir, "secret.env")
+	writeTestFile(t, scriptPath, "#!/bin/sh\necho hi\n")
+	writeTestFile(t, secretPath, "TOKEN=x\n")
+	if err := os.Chmod(scriptPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(secretPath, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tool := NewTarTool()
+	if !tool.Info().RequiresPerm {
+		t.Error("tar 写入文件，应需要权限")
+	}
+
+	archive := filepath.Join(tmpDir, "release.tar.gz")
+	result, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"action":  "create",
+		"archive": archive,
+		"paths":   []any{srcDir},
+	}))
+	if err != nil {
+		t.Fatalf("create error = %v", err)
+	}
+	if result.Metadata()["files"] != 2 {
+		t.Errorf("files = %v, want 2", result.Metadata()["files"])
+	}
+
+	tests := []struct {
+		name   string
+		strip  int
+		script string
+		secret string
+	}{
+		{"保留顶层目录", 0, "release/bin/run.sh", "release/secret.env"},
+		{"strip_components=1", 1, "bin/run.sh", "secret.env"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extractDir := t.TempDir()
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+				"action":           "extract",
+				"archive":          archive,
+				"dir":              extractDir,
+				"strip_components": tt.strip,
+			}))
+			if err != nil {
+				t.Fatalf("extract error = %v", err)
+			}
+			if result.Metadata()["files"] != 2 {
+				t.Errorf("files = %v, want 2", result.Metadata()["files"])
+			}
+
+			script := filepath.Join(extractDir, filepath.FromSlash(tt.script))
+			data, err := os.ReadFile(script)
+			if err != nil {
+				t.Fatalf("读取 %s 失败: %v", tt.script, err)
+			}
+			if string(data) != "#!/bin/sh\necho hi\n" {
+				t.Errorf("%s 内容 = %q", tt.script, data)
+			}
+
+			if runtime.GOOS == "windows" {
+				return
+			}
+			wantModes := map[string]os.FileMode{tt.script: 0755, tt.secret: 0600}
+			for name, want := range wantModes {
+				info, err := os.Stat(filepath.Join(extractDir, filepath.FromSlash(name)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if info.Mode().Perm() != want {
+					t.Errorf("%s 权限 = %v, want %v", name, info.Mode().Perm(), want)
+				}
+			}
+		})
+	}
+}
+
+// writeTarGz 写入包含指定条目的 tar.gz 文件
+func writeTarGz(t *testing.T, path string, names ...string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	zw := gzip.NewWriter(file)
+	tw := tar.NewWriter(zw)
+	for _, name := range names {
+		content := []byte("payload")
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write(content)
+	}
+	tw.Close()
+	zw.Close()
+}
+
+func TestTarTool_PathTraversal(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry string
+		strip int
+	}{
+		{"上级目录", "../evil.txt", 0},
+		{"嵌套的上级目录", "safe/../../evil.txt", 0},
+		{"绝对路径", "/tmp/evil.txt", 0},
+		{"去掉一级后仍然逃逸", "pkg/../../evil.txt", 1},
+	}
+
+	tool := NewTarTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			archive := filepath.Join(tmpDir, "evil.tar.gz")
+			// 合法条目排在前面，确认检查失败时不会写入任何文件
+			writeTarGz(t, archive, "pkg/ok.txt", tt.entry)
+
+			extractDir := filepath.Join(tmpDir, "target")
+			_, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+				"action":           "extract",
+				"archive":          archive,
+				"dir":              extractDir,
+				"strip_components": tt.strip,
+			}))
+			if err == nil || !strings.Contains(err.Error(), "illegal") {
+				t.Fatalf("Execute() error = %v, want illegal path", err)
+			}
+			if _, err := os.Stat(extractDir); !os.IsNotExist(err) {
+				t.Error("检查失败时不应解压任何文件")
+			}
+			if _, err := os.Stat(filepath.Join(tmpDir, "evil.txt")); !os.IsNotExist(err) {
+				t.Error("恶意条目被写到了目标目录之外")
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"opencode_nano/tools/core"
+)
+
+func TestTouchTool_Execute(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+
+	tests := []struct {
+		name        string
+		existing    string // 非空时预先创建文件并设置为旧的修改时间
+		path        string
+		params      map[string]any
+		wantCreated bool
+		wantContent string
+		wantErr     bool
+	}{
+		{
+			name:        "文件不存在时创建空文件",
+			path:        "new.txt",
+			wantCreated: true,
+		},
+		{
+			name:        "自动创建父目录",
+			path:        "a/b/new.txt",
+			wantCreated: true,
+		},
+		{
+			name:    "不创建父目录时报错",
+			path:    "missing/new.txt",
+			params:  map[string]any{"create_dirs": false},
+			wantErr: true,
+		},
+		{
+			name:        "文件存在时只更新修改时间",
+			existing:    "keep me",
+			path:        "old.txt",
+			wantContent: "keep me",
+		},
+	}
+
+	tool := NewTouchTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.path)
+			if tt.existing != "" {
+				writeTestFile(t, path, tt.existing)
+				if err := os.Chtimes(path, old, old); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			params := map[string]any{"path": path}
+			for k, v := range tt.params {
+				params[k] = v
+			}
+			before := time.Now().Add(-time.Second)
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(params))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if _, err := os.Stat(path); !os.IsNotExist(err) {
+					t.Errorf("失败时不应创建文件")
+				}
+				return
+			}
+
+			if result.Metadata()["created"] != tt.wantCreated {
+				t.Errorf("created = %v, want %v", result.Metadata()["created"], tt.wantCreated)
+			}
+			if result.Metadata()["updated"] != !tt.wantCreated {
+				t.Errorf("updated = %v, want %v", result.Metadata()["updated"], !tt.wantCreated)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.wantContent {
+				t.Errorf("内容 = %q, want %q", data, tt.wantContent)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.ModTime().Before(before) {
+				t.Errorf("修改时间 = %v, 应不早于 %v", info.ModTime(), before)
+			}
+		})
+	}
+}
+
+func TestTouchTool_RequiresPermission(t *testing.T) {
+	if !NewTouchTool().Info().RequiresPerm {
+		t.Error("touch 会修改文件系统，应需要权限")
+	}
+}
@@ -0,0 +1,169 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"opencode_nano/tools/core"
+)
+
+// TreeTool 目录树展示工具
+type TreeTool struct {
+	*core.BaseTool
+	lister *ListTool
+}
+
+// NewTreeTool 创建目录树工具
+func NewTreeTool() *TreeTool {
+	tool := &TreeTool{
+		BaseTool: core.NewBaseTool("tree", "file", "Render a directory as an indented tree"),
+		lister:   NewListTool(),
+	}
+
+	tool.SetTags("file", "tree", "list", "dir")
+	tool.SetSchema(core.ParameterSchema{
+		Type: "object",
+		Properties: map[string]core.PropertySchema{
+			"path": {
+				Type:        "string",
+				Description: "Directory path to render",
+				Default:     ".",
+			},
+			"show_hidden": {
+				Type:        "boolean",
+				Description: "Show hidden files (starting with .)",
+				Default:     false,
+			},
+			"max_depth": {
+				Type:        "integer",
+				Description: "Maximum depth to descend (1 shows only direct children)",
+				Default:     10,
+			},
+			"dirs_only": {
+				Type:        "boolean",
+				Description: "Only show directories",
+				Default:     false,
+			},
+		},
+		Required: []string{},
+	})
+
+	return tool
+}
+
+// Execute 渲染目录树
+func (t *TreeTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	// 参数验证
+	if err := params.Validate(t.Schema()); err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+
+	path := "."
+	if params.Has("path") {
+		path, _ = params.GetString("path")
+	}
+
+	showHidden := false
+	if params.Has("show_hidden") {
+		showHidden, _ = params.GetBool("show_hidden")
+	}
+
+	maxDepth := 10
+	if params.Has("max_depth") {
+		maxDepth, _ = params.GetInt("max_depth")
+	}
+
+	dirsOnly := false
+	if params.Has("dirs_only") {
+		dirsOnly, _ = params.GetBool("dirs_only")
+	}
+
+	path = filepath.Clean(path)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to stat path: %v", err))
+	}
+	if !info.IsDir() {
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("path is not a directory: %s", path))
+	}
+
+	// 复用 ListTool 的递归收集逻辑
+	root, err := t.lister.listRecursive(ctx, path, listFilter{showHidden: showHidden}, true, 0, maxDepth, nil)
+	if err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+	}
+	t.lister.sortFiles(root.Children, "name", false)
+
+	// 裁剪为与渲染结果一致的结构
+	files := pruneTree(root.Children, 1, maxDepth, dirsOnly)
+
+	var sb strings.Builder
+	sb.WriteString(path)
+	sb.WriteString("\n")
+	dirCount, fileCount := renderTree(&sb, files, "")
+	if dirsOnly {
+		fmt.Fprintf(&sb, "\n%d directories", dirCount)
+	} else {
+		fmt.Fprintf(&sb, "\n%d directories, %d files", dirCount, fileCount)
+	}
+
+	result := core.NewSimpleResult(sb.String())
+	result.WithMetadata("path", path)
+	result.WithMetadata("files", files)
+	result.WithMetadata("total_dirs", dirCount)
+	result.WithMetadata("total_files", fileCount)
+
+	return result, nil
+}
+
+// pruneTree 按深度和 dirs_only 过滤节点，depth 为当前层级（从 1 开始）
+func pruneTree(files []FileInfo, depth, maxDepth int, dirsOnly bool) []FileInfo {
+	var pruned []FileInfo
+	for _, f := range files {
+		if dirsOnly && !f.IsDir {
+			continue
+		}
+		if depth < maxDepth {
+			f.Children = pruneTree(f.Children, depth+1, maxDepth, dirsOnly)
+		} else {
+			f.Children = nil
+		}
+		pruned = append(pruned, f)
+	}
+	return pruned
+}
+
+// renderTree 使用 ├── / └── 连接符渲染目录树，返回目录数和文件数
+func renderTree(sb *strings.Builder, files []FileInfo, prefix string) (int, int) {
+	dirCount, fileCount := 0, 0
+	for i, f := range files {
+		connector, childPrefix := "├── ", "│   "
+		if i == len(files)-1 {
+			connector, childPrefix = "└── ", "    "
+		}
+
+		name := f.Name
+		if f.IsDir {
+			name += "/"
+			dirCount++
+		} else {
+			fileCount++
+		}
+		if f.IsSymlink && f.Target != "" {
+			name += " -> " + f.Target
+		}
+
+		sb.WriteString(prefix + connector + name + "\n")
+
+		if len(f.Children) > 0 {
+			dirs, files := renderTree(sb, f.Children, prefix+childPrefix)
+			dirCount += dirs
+			fileCount += files
+		}
+	}
+	return dirCount, fileCount
+}
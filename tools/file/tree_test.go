@@ -0,0 +1,100 @@
+package file
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+func TestTreeTool_Execute(t *testing.T) {
+	root := t.TempDir()
+	for _, rel := range []string{
+		"a.txt",
+		"src/main.go",
+		"src/util/helper.go",
+		"docs/readme.md",
+		".hidden/secret.txt",
+	} {
+		writeTestFile(t, filepath.Join(root, rel), "x")
+	}
+
+	tests := []struct {
+		name   string
+		params map[string]any
+		want   string
+	}{
+		{
+			name:   "默认",
+			params: map[string]any{},
+			want: `├── a.txt
+├── docs/
+│   └── readme.md
+└── src/
+    ├── main.go
+    └── util/
+        └── helper.go
+
+3 directories, 4 files`,
+		},
+		{
+			name:   "限制深度",
+			params: map[string]any{"max_depth": 1},
+			want: `├── a.txt
+├── docs/
+└── src/
+
+2 directories, 1 files`,
+		},
+		{
+			name:   "仅目录",
+			params: map[string]any{"dirs_only": true},
+			want: `├── docs/
+└── src/
+    └── util/
+
+3 directories`,
+		},
+		{
+			name:   "显示隐藏文件",
+			params: map[string]any{"show_hidden": true, "max_depth": 2, "dirs_only": true},
+			want: `├── .hidden/
+├── docs/
+└── src/
+    └── util/
+
+4 directories`,
+		},
+	}
+
+	tool := NewTreeTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.params["path"] = root
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(tt.params))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			want := root + "\n" + tt.want
+			if result.String() != want {
+				t.Errorf("Execute() =\n%s\nwant:\n%s", result.String(), want)
+			}
+
+			if _, ok := result.Metadata()["files"].([]FileInfo); !ok {
+				t.Errorf("metadata files 类型 = %T, want []FileInfo", result.Metadata()["files"])
+			}
+		})
+	}
+}
+
+func TestTreeTool_NotDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	writeTestFile(t, path, "x")
+
+	_, err := NewTreeTool().Execute(context.Background(), core.NewMapParameters(map[string]any{"path": path}))
+	if err == nil {
+		t.Error("路径不是目录时应返回错误")
+	}
+}
@@ -0,0 +1,232 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"opencode_nano/tools/core"
+)
+
+// watchPollInterval 轮询文件状态的间隔，测试中可调小
+var watchPollInterval = 250 * time.Millisecond
+
+// 支持的监听事件
+const (
+	WatchEventCreate = "create"
+	WatchEventWrite  = "write"
+	WatchEventRemove = "remove"
+)
+
+// WatchEvent 一次文件变更事件
+type WatchEvent struct {
+	Path string    `json:"path"`
+	Op   string    `json:"op"`
+	Time time.Time `json:"time"`
+}
+
+// fileState 轮询时记录的文件状态
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// WatchTool 通过轮询修改时间监听文件或目录变化的工具
+type WatchTool struct {
+	*core.BaseTool
+}
+
+// NewWatchTool 创建监听工具
+func NewWatchTool() *WatchTool {
+	tool := &WatchTool{
+		BaseTool: core.NewBaseTool("watch", "file", "Watch a file or directory and report changes"),
+	}
+
+	tool.SetTags("file", "watch", "monitor")
+	tool.SetSchema(core.ParameterSchema{
+		Type: "object",
+		Properties: map[string]core.PropertySchema{
+			"path": {
+				Type:        "string",
+				Description: "File or directory to watch",
+			},
+			"timeout_seconds": {
+				Type:        "integer",
+				Description: "Maximum time to wait for changes (default: 30)",
+				Default:     30,
+			},
+			"events": {
+				Type:        "array",
+				Description: "Event types to report: create, write, remove (default: all)",
+				Items: &core.PropertySchema{
+					Type: "string",
+					Enum: []string{WatchEventCreate, WatchEventWrite, WatchEventRemove},
+				},
+			},
+		},
+		Required: []string{"path"},
+	})
+
+	return tool
+}
+
+// Execute 监听变化，观察到事件后立即返回；超时则返回空列表
+func (t *WatchTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	// 参数验证
+	if err := params.Validate(t.Schema()); err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+
+	watchPath, err := params.GetString("path")
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, "invalid path parameter")
+	}
+	watchPath = filepath.Clean(watchPath)
+
+	timeoutSeconds := 30
+	if params.Has("timeout_seconds") {
+		timeoutSeconds, _ = params.GetInt("timeout_seconds")
+	}
+	if timeoutSeconds <= 0 {
+		return nil, core.ErrInvalidParams(t.Info().Name, "timeout_seconds must be positive")
+	}
+
+	wanted := map[string]bool{WatchEventCreate: true, WatchEventWrite: true, WatchEventRemove: true}
+	if params.Has("events") {
+		events, err := params.GetStringSlice("events")
+		if err != nil {
+			return nil, core.ErrInvalidParams(t.Info().Name, "events must be an array of strings")
+		}
+		if len(events) > 0 {
+			wanted = make(map[string]bool)
+			for _, event := range events {
+				switch event {
+				case WatchEventCreate, WatchEventWrite, WatchEventRemove:
+					wanted[event] = true
+				default:
+					return nil, core.ErrInvalidParams(t.Info().Name, fmt.Sprintf("unsupported event: %s", event))
+				}
+			}
+		}
+	}
+
+	previous, err := snapshotPath(watchPath)
+	if err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+	}
+
+	timer := time.NewTimer(time.Duration(timeoutSeconds) * time.Second)
+	defer timer.Stop()
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	var events []WatchEvent
+	timedOut := false
+	for len(events) == 0 && !timedOut {
+		select {
+		case <-ctx.Done():
+			return nil, core.ErrCancelled(t.Info().Name)
+		case <-timer.C:
+			timedOut = true
+		case <-ticker.C:
+			current, err := snapshotPath(watchPath)
+			if err != nil {
+				return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+			}
+			for _, event := range diffSnapshots(previous, current) {
+				if wanted[event.Op] {
+					events = append(events, event)
+				}
+			}
+			previous = current
+		}
+	}
+
+	var output string
+	if len(events) == 0 {
+		output = fmt.Sprintf("No changes in %s within %ds", watchPath, timeoutSeconds)
+	} else {
+		lines := make([]string, len(events))
+		for i, event := range events {
+			lines[i] = fmt.Sprintf("%s %s", event.Op, event.Path)
+		}
+		output = strings.Join(lines, "\n")
+	}
+
+	if events == nil {
+		events = []WatchEvent{}
+	}
+
+	result := core.NewSimpleResult(output)
+	result.WithMetadata("path", watchPath)
+	result.WithMetadata("events", events)
+	result.WithMetadata("count", len(events))
+	result.WithMetadata("timed_out", timedOut)
+
+	return result, nil
+}
+
+// snapshotPath 记录路径下所有文件的状态；路径不存在时返回空快照
+func snapshotPath(root string) (map[string]fileState, error) {
+	snapshot := make(map[string]fileState)
+
+	info, err := os.Stat(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return snapshot, nil
+		}
+		return nil, err
+	}
+	if !info.IsDir() {
+		snapshot[root] = fileState{modTime: info.ModTime(), size: info.Size()}
+		return snapshot, nil
+	}
+
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil // 文件可能在遍历期间被删除
+		}
+		if info.IsDir() {
+			if path != root && isGitDir(info.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		snapshot[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	return snapshot, err
+}
+
+// diffSnapshots 比较两次快照，按路径排序返回事件
+func diffSnapshots(previous, current map[string]fileState) []WatchEvent {
+	now := time.Now()
+	var events []WatchEvent
+
+	for path, state := range current {
+		old, ok := previous[path]
+		switch {
+		case !ok:
+			events = append(events, WatchEvent{Path: path, Op: WatchEventCreate, Time: now})
+		case !state.modTime.Equal(old.modTime) || state.size != old.size:
+			events = append(events, WatchEvent{Path: path, Op: WatchEventWrite, Time: now})
+		}
+	}
+	for path := range previous {
+		if _, ok := current[path]; !ok {
+			events = append(events, WatchEvent{Path: path, Op: WatchEventRemove, Time: now})
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Path != events[j].Path {
+			return events[i].Path < events[j].Path
+		}
+		return events[i].Op < events[j].Op
+	})
+	return events
+}
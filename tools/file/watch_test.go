@@ -0,0 +1,168 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"opencode_nano/tools/core"
+)
+
+// withFastPolling 测试期间缩短轮询间隔
+func withFastPolling(t *testing.T) {
+	t.Helper()
+	old := watchPollInterval
+	watchPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { watchPollInterval = old })
+}
+
+func TestWatchTool_ReportsChanges(t *testing.T) {
+	withFastPolling(t)
+
+	tests := []struct {
+		name    string
+		events  []any
+		change  func(t *testing.T, root string)
+		wantOps []string
+	}{
+		{
+			name: "写入文件",
+			change: func(t *testing.T, root string) {
+				writeTestFile(t, filepath.Join(root, "watched.txt"), "changed content")
+			},
+			wantOps: []string{WatchEventWrite},
+		},
+		{
+			name: "创建文件",
+			change: func(t *testing.T, root string) {
+				writeTestFile(t, filepath.Join(root, "new.txt"), "new")
+			},
+			wantOps: []string{WatchEventCreate},
+		},
+		{
+			name: "删除文件",
+			change: func(t *testing.T, root string) {
+				if err := os.Remove(filepath.Join(root, "watched.txt")); err != nil {
+					t.Error(err)
+				}
+			},
+			wantOps: []string{WatchEventRemove},
+		},
+		{
+			name:   "按事件类型过滤",
+			events: []any{"create"},
+			change: func(t *testing.T, root string) {
+				writeTestFile(t, filepath.Join(root, "watched.txt"), "changed content")
+				writeTestFile(t, filepath.Join(root, "new.txt"), "new")
+			},
+			wantOps: []string{WatchEventCreate},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeTestFile(t, filepath.Join(root, "watched.txt"), "original")
+
+			params := map[string]any{"path": root, "timeout_seconds": 5}
+			if tt.events != nil {
+				params["events"] = tt.events
+			}
+
+			change := tt.change
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				time.Sleep(50 * time.Millisecond)
+				change(t, root)
+			}()
+
+			result, err := NewWatchTool().Execute(context.Background(), core.NewMapParameters(params))
+			<-done
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			events := result.Metadata()["events"].([]WatchEvent)
+			if len(events) != len(tt.wantOps) {
+				t.Fatalf("事件 = %+v, want ops %v", events, tt.wantOps)
+			}
+			for i, event := range events {
+				if event.Op != tt.wantOps[i] {
+					t.Errorf("第 %d 个事件 = %s, want %s", i, event.Op, tt.wantOps[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWatchTool_SingleFile(t *testing.T) {
+	withFastPolling(t)
+	path := filepath.Join(t.TempDir(), "watched.txt")
+	writeTestFile(t, path, "original")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		time.Sleep(50 * time.Millisecond)
+		writeTestFile(t, path, "changed content")
+	}()
+
+	result, err := NewWatchTool().Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"path":            path,
+		"timeout_seconds": 5,
+	}))
+	<-done
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	events := result.Metadata()["events"].([]WatchEvent)
+	if len(events) != 1 || events[0].Path != path || events[0].Op != WatchEventWrite {
+		t.Errorf("事件 = %+v, want 一次 %s 写入", events, path)
+	}
+}
+
+func TestWatchTool_Timeout(t *testing.T) {
+	withFastPolling(t)
+
+	result, err := NewWatchTool().Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"path":            t.TempDir(),
+		"timeout_seconds": 1,
+	}))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if events := result.Metadata()["events"].([]WatchEvent); len(events) != 0 {
+		t.Errorf("超时应返回空列表, got %+v", events)
+	}
+	if result.Metadata()["timed_out"] != true {
+		t.Error("timed_out 应为 true")
+	}
+}
+
+func TestWatchTool_Cancel(t *testing.T) {
+	withFastPolling(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	_, err := NewWatchTool().Execute(ctx, core.NewMapParameters(map[string]any{
+		"path":            t.TempDir(),
+		"timeout_seconds": 30,
+	}))
+
+	var toolErr *core.ToolError
+	if !errors.As(err, &toolErr) || toolErr.Code != core.ErrCodeCancelled {
+		t.Fatalf("Execute() error = %v, want 取消错误", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("取消后 %v 才返回", elapsed)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"opencode_nano/tools/core"
 )
@@ -55,6 +56,12 @@ func NewWriteTool() *WriteTool {
 				Description: "File permissions (e.g., '0644')",
 				Default:     "0644",
 			},
+			"line_ending": {
+				Type:        "string",
+				Description: "Line ending normalization: keep, lf, crlf",
+				Default:     "keep",
+				Enum:        []string{"keep", "lf", "crlf"},
+			},
 		},
 		Required: []string{"path", "content"},
 	})
@@ -99,6 +106,14 @@ func (t *WriteTool) Execute(ctx context.Context, params core.Parameters) (core.R
 		backup, _ = params.GetBool("backup")
 	}
 	
+	lineEnding := "keep"
+	if params.Has("line_ending") {
+		lineEnding, _ = params.GetString("line_ending")
+	}
+	normalized := normalizeLineEndings(content, lineEnding)
+	lineEndingsChanged := normalized != content
+	content = normalized
+	
 	// 检查文件是否存在
 	fileExists := false
 	if fileInfo, err := os.Stat(filePath); err == nil {
@@ -152,6 +167,8 @@ func (t *WriteTool) Execute(ctx context.Context, params core.Parameters) (core.R
 	result.WithMetadata("path", filePath)
 	result.WithMetadata("size", len(content))
 	result.WithMetadata("mode", mode)
+	result.WithMetadata("line_ending", lineEnding)
+	result.WithMetadata("line_endings_changed", lineEndingsChanged)
 	if fileInfo != nil {
 		result.WithMetadata("file_size", fileInfo.Size())
 	}
@@ -162,6 +179,18 @@ func (t *WriteTool) Execute(ctx context.Context, params core.Parameters) (core.R
 	return result, nil
 }
 
+// normalizeLineEndings 统一换行符：lf 去掉 \r，crlf 将每个 \n 转为 \r\n 且不重复已有的 \r
+func normalizeLineEndings(content, lineEnding string) string {
+	switch lineEnding {
+	case "lf":
+		return strings.ReplaceAll(content, "\r\n", "\n")
+	case "crlf":
+		return strings.ReplaceAll(strings.ReplaceAll(content, "\r\n", "\n"), "\n", "\r\n")
+	default:
+		return content
+	}
+}
+
 // writeFile 写入文件（覆盖模式）
 func (t *WriteTool) writeFile(path, content string) error {
 	// 使用原子写入：先写入临时文件，然后重命名
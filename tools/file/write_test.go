@@ -0,0 +1,61 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+func TestWriteTool_LineEnding(t *testing.T) {
+	mixed := "a\r\nb\nc\r\n\nd"
+
+	tests := []struct {
+		name        string
+		lineEnding  any
+		content     string
+		want        string
+		wantChanged bool
+		wantErr     bool
+	}{
+		{"默认保持原样", nil, mixed, mixed, false, false},
+		{"keep", "keep", mixed, mixed, false, false},
+		{"混合换行转 lf", "lf", mixed, "a\nb\nc\n\nd", true, false},
+		{"混合换行转 crlf", "crlf", mixed, "a\r\nb\r\nc\r\n\r\nd", true, false},
+		{"已是 crlf 不重复", "crlf", "a\r\nb\r\n", "a\r\nb\r\n", false, false},
+		{"已是 lf", "lf", "a\nb\n", "a\nb\n", false, false},
+		{"无效取值", "cr", mixed, "", false, true},
+	}
+
+	tool := NewWriteTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "out.txt")
+			params := map[string]any{"path": path, "content": tt.content}
+			if tt.lineEnding != nil {
+				params["line_ending"] = tt.lineEnding
+			}
+
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(params))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			data, _ := os.ReadFile(path)
+			if string(data) != tt.want {
+				t.Errorf("写入内容 = %q, want %q", data, tt.want)
+			}
+			if result.Metadata()["line_endings_changed"] != tt.wantChanged {
+				t.Errorf("line_endings_changed = %v, want %v", result.Metadata()["line_endings_changed"], tt.wantChanged)
+			}
+			if result.Metadata()["size"] != len(tt.want) {
+				t.Errorf("size = %v, want %d", result.Metadata()["size"], len(tt.want))
+			}
+		})
+	}
+}
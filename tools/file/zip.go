@@ -0,0 +1,344 @@
+package file
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"opencode_nano/tools/core"
+)
+
+// ZipTool zip 归档的创建和解压工具
+type ZipTool struct {
+	*core.BaseTool
+}
+
+// NewZipTool 创建 zip 工具
+func NewZipTool() *ZipTool {
+	tool := &ZipTool{
+		BaseTool: core.NewBaseTool("zip", "file", "Create a zip archive from files or directories, or extract a zip archive"),
+	}
+
+	tool.SetRequiresPerm(true)
+	tool.SetTags("file", "zip", "archive", "compress", "extract")
+	tool.SetSchema(core.ParameterSchema{
+		Type: "object",
+		Properties: map[string]core.PropertySchema{
+			"action": {
+				Type:        "string",
+				Description: "create or extract",
+				Enum:        []string{"create", "extract"},
+			},
+			"archive": {
+				Type:        "string",
+				Description: "Path of the zip archive to create or extract",
+			},
+			"paths": {
+				Type:        "array",
+				Description: "Files or directories to add when creating; entries are named relative to each path's parent",
+				Items:       &core.PropertySchema{Type: "string"},
+			},
+			"dir": {
+				Type:        "string",
+				Description: "Target directory for extract (default: current directory)",
+				Default:     ".",
+			},
+			"overwrite": {
+				Type:        "boolean",
+				Description: "Overwrite the archive or extracted files if they exist",
+				Default:     false,
+			},
+		},
+		Required: []string{"action", "archive"},
+	})
+
+	return tool
+}
+
+// archiveEntry 待加入归档（zip、tar）的单个条目
+type archiveEntry struct {
+	path  string
+	name  string
+	info  fs.FileInfo
+	isDir bool
+}
+
+// Execute 创建或解压 zip 归档
+func (t *ZipTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	// 参数验证
+	if err := params.Validate(t.Schema()); err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+
+	action, err := params.GetString("action")
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, "invalid action parameter")
+	}
+
+	archive, err := params.GetString("archive")
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, "invalid archive parameter")
+	}
+	archive = filepath.Clean(archive)
+
+	overwrite := false
+	if params.Has("overwrite") {
+		overwrite, _ = params.GetBool("overwrite")
+	}
+
+	if action == "create" {
+		var paths []string
+		if params.Has("paths") {
+			paths, err = params.GetStringSlice("paths")
+			if err != nil {
+				return nil, core.ErrInvalidParams(t.Info().Name, "paths must be an array of strings")
+			}
+		}
+		if len(paths) == 0 {
+			return nil, core.ErrInvalidParams(t.Info().Name, "paths is required for create")
+		}
+		return t.create(ctx, archive, paths, overwrite)
+	}
+
+	dir := "."
+	if params.Has("dir") {
+		dir, _ = params.GetString("dir")
+	}
+	return t.extract(ctx, archive, filepath.Clean(dir), overwrite)
+}
+
+// create 将 paths 打包为 archive
+func (t *ZipTool) create(ctx context.Context, archive string, paths []string, overwrite bool) (core.Result, error) {
+	if !overwrite {
+		if _, err := os.Stat(archive); err == nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name,
+				fmt.Sprintf("archive already exists: %s (set overwrite to replace)", archive))
+		}
+	}
+
+	// 先收集条目，避免把正在写入的归档本身打包进去
+	var entries []archiveEntry
+	for _, path := range paths {
+		collected, err := collectArchiveEntries(filepath.Clean(path))
+		if err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+		}
+		entries = append(entries, collected...)
+	}
+
+	files := 0
+	var totalSize int64
+	archiveSize, err := writeStreamAtomic(archive, 0644, func(w io.Writer) error {
+		zw := zip.NewWriter(w)
+		for _, entry := range entries {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+
+			header, err := zip.FileInfoHeader(entry.info)
+			if err != nil {
+				return err
+			}
+			header.Name = entry.name
+			if entry.isDir {
+				header.Name += "/"
+				if _, err := zw.CreateHeader(header); err != nil {
+					return err
+				}
+				continue
+			}
+
+			header.Method = zip.Deflate
+			writer, err := zw.CreateHeader(header)
+			if err != nil {
+				return err
+			}
+			n, err := copyFileTo(writer, entry.path)
+			if err != nil {
+				return fmt.Errorf("failed to add %s: %v", entry.path, err)
+			}
+			files++
+			totalSize += n
+		}
+		return zw.Close()
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, core.ErrCancelled(t.Info().Name)
+		}
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to create archive: %v", err))
+	}
+
+	result := core.NewSimpleResult(fmt.Sprintf("Created %s with %d files (%d bytes, archive %d bytes)", archive, files, totalSize, archiveSize))
+	result.WithMetadata("action", "create")
+	result.WithMetadata("archive", archive)
+	result.WithMetadata("files", files)
+	result.WithMetadata("total_size", totalSize)
+	result.WithMetadata("archive_size", archiveSize)
+
+	return result, nil
+}
+
+// extract 将 archive 解压到 dir，所有条目都通过路径检查后才开始写入
+func (t *ZipTool) extract(ctx context.Context, archive, dir string, overwrite bool) (core.Result, error) {
+	reader, err := zip.OpenReader(archive)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("archive not found: %s", archive))
+		}
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to open archive: %v", err))
+	}
+	defer reader.Close()
+
+	targets := make([]string, len(reader.File))
+	for i, f := range reader.File {
+		target, err := safeExtractPath(dir, f.Name)
+		if err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+		}
+		if !overwrite && !f.FileInfo().IsDir() {
+			if _, err := os.Stat(target); err == nil {
+				return nil, core.ErrExecutionFailed(t.Info().Name,
+					fmt.Sprintf("destination already exists: %s (set overwrite to replace)", target))
+			}
+		}
+		targets[i] = target
+	}
+
+	files := 0
+	var totalSize int64
+	for i, f := range reader.File {
+		select {
+		case <-ctx.Done():
+			return nil, core.ErrCancelled(t.Info().Name)
+		default:
+		}
+
+		mode := f.Mode()
+		if mode.IsDir() {
+			if err := os.MkdirAll(targets[i], 0755); err != nil {
+				return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to create directory: %v", err))
+			}
+			continue
+		}
+		// 跳过符号链接等非常规文件，避免解压出指向目标目录之外的链接
+		if !mode.IsRegular() {
+			continue
+		}
+
+		n, err := extractZipFile(f, targets[i])
+		if err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to extract %s: %v", f.Name, err))
+		}
+		files++
+		totalSize += n
+	}
+
+	result := core.NewSimpleResult(fmt.Sprintf("Extracted %d files (%d bytes) from %s to %s", files, totalSize, archive, dir))
+	result.WithMetadata("action", "extract")
+	result.WithMetadata("archive", archive)
+	result.WithMetadata("dir", dir)
+	result.WithMetadata("files", files)
+	result.WithMetadata("total_size", totalSize)
+
+	return result, nil
+}
+
+// collectArchiveEntries 列出 path 下要打包的条目，条目名相对于 path 的父目录
+func collectArchiveEntries(path string) ([]archiveEntry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("path not found: %s", path)
+		}
+		return nil, err
+	}
+	base := filepath.Dir(path)
+	if !info.IsDir() {
+		return []archiveEntry{{path: path, name: filepath.Base(path), info: info}}, nil
+	}
+
+	var entries []archiveEntry
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		// 跳过符号链接、设备文件等非常规文件
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(base, p)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, archiveEntry{
+			path:  p,
+			name:  filepath.ToSlash(rel),
+			info:  info,
+			isDir: info.IsDir(),
+		})
+		return nil
+	})
+	return entries, err
+}
+
+// safeExtractPath 返回条目在 dir 中的目标路径，拒绝绝对路径和通过 ../ 逃出 dir 的条目（zip slip）
+func safeExtractPath(dir, name string) (string, error) {
+	slashed := strings.ReplaceAll(name, "\\", "/")
+	if strings.HasPrefix(slashed, "/") || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("illegal absolute path in archive: %s", name)
+	}
+	target := filepath.Join(dir, filepath.FromSlash(slashed))
+	rel, err := filepath.Rel(dir, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("illegal path in archive escapes target directory: %s", name)
+	}
+	return target, nil
+}
+
+// extractZipFile 解压单个文件
+func extractZipFile(f *zip.File, target string) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return 0, err
+	}
+	source, err := f.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer source.Close()
+
+	mode := f.Mode().Perm()
+	if mode == 0 {
+		mode = 0644
+	}
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(out, source)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return n, err
+}
+
+// copyFileTo 将文件内容写入 w
+func copyFileTo(w io.Writer, path string) (int64, error) {
+	source, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer source.Close()
+	return io.Copy(w, source)
+}
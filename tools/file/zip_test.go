@@ -0,0 +1,133 @@
+package file
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+func TestZipTool_RoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "project")
+	writeTestFile(t, filepath.Join(srcDir, "main.go"), "package main\n")
+	writeTestFile(t, filepath.Join(srcDir, "pkg", "util.go"), "package pkg\n")
+	readmePath := filepath.Join(tmpDir, "README.md")
+	writeTestFile(t, readmePath, "# readme\n")
+
+	tool := NewZipTool()
+	if !tool.Info().RequiresPerm {
+		t.Error("zip 写入文件，应需要权限")
+	}
+
+	archive := filepath.Join(tmpDir, "out", "bundle.zip")
+	result, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"action":  "create",
+		"archive": archive,
+		"paths":   []any{srcDir, readmePath},
+	}))
+	if err != nil {
+		t.Fatalf("create error = %v", err)
+	}
+	metadata := result.Metadata()
+	if metadata["files"] != 3 {
+		t.Errorf("files = %v, want 3", metadata["files"])
+	}
+	if metadata["total_size"] != int64(len("package main\n")+len("package pkg\n")+len("# readme\n")) {
+		t.Errorf("total_size = %v", metadata["total_size"])
+	}
+
+	extractDir := filepath.Join(tmpDir, "extracted")
+	result, err = tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"action":  "extract",
+		"archive": archive,
+		"dir":     extractDir,
+	}))
+	if err != nil {
+		t.Fatalf("extract error = %v", err)
+	}
+	if result.Metadata()["files"] != 3 {
+		t.Errorf("extract files = %v, want 3", result.Metadata()["files"])
+	}
+
+	want := map[string]string{
+		"project/main.go":     "package main\n",
+		"project/pkg/util.go": "package pkg\n",
+		"README.md":           "# readme\n",
+	}
+	for name, content := range want {
+		data, err := os.ReadFile(filepath.Join(extractDir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Errorf("读取 %s 失败: %v", name, err)
+			continue
+		}
+		if string(data) != content {
+			t.Errorf("%s 内容 = %q, want %q", name, data, content)
+		}
+	}
+
+	// 再次解压到同一目录时需要 overwrite
+	_, err = tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"action":  "extract",
+		"archive": archive,
+		"dir":     extractDir,
+	}))
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("覆盖已有文件应报错, got %v", err)
+	}
+}
+
+func TestZipTool_ZipSlip(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry string
+	}{
+		{"上级目录", "../evil.txt"},
+		{"嵌套的上级目录", "safe/../../evil.txt"},
+		{"绝对路径", "/tmp/evil.txt"},
+		{"反斜杠路径", "..\\evil.txt"},
+	}
+
+	tool := NewZipTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			archive := filepath.Join(tmpDir, "evil.zip")
+			file, err := os.Create(archive)
+			if err != nil {
+				t.Fatal(err)
+			}
+			zw := zip.NewWriter(file)
+			// 合法条目排在前面，确认检查失败时不会写入任何文件
+			for _, name := range []string{"ok.txt", tt.entry} {
+				w, err := zw.Create(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				w.Write([]byte("payload"))
+			}
+			zw.Close()
+			file.Close()
+
+			extractDir := filepath.Join(tmpDir, "target")
+			_, err = tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+				"action":  "extract",
+				"archive": archive,
+				"dir":     extractDir,
+			}))
+			if err == nil || !strings.Contains(err.Error(), "illegal") {
+				t.Fatalf("Execute() error = %v, want illegal path", err)
+			}
+			if _, err := os.Stat(filepath.Join(extractDir, "ok.txt")); !os.IsNotExist(err) {
+				t.Error("检查失败时不应解压任何文件")
+			}
+			if _, err := os.Stat(filepath.Join(tmpDir, "evil.txt")); !os.IsNotExist(err) {
+				t.Error("恶意条目被写到了目标目录之外")
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"fmt"
+	"path/filepath"
+	"plugin"
+	"sort"
+
+	"opencode_nano/tools/core"
+)
+
+// PluginSymbol 插件必须导出的构造函数名，签名为 func() core.Tool
+const PluginSymbol = "NewTool"
+
+// LoadPlugins 加载 dir 下所有 *.so 插件并注册到 registry，返回成功注册的工具名。
+// 单个插件打开、查找符号、构造或注册失败时记录警告并跳过，不影响其他插件和内置工具
+func LoadPlugins(registry *core.ToolRegistry, dir string, logger core.Logger) []string {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.so"))
+	if err != nil {
+		logger.Warn("plugin directory invalid", "dir", dir, "error", err)
+		return nil
+	}
+	sort.Strings(paths)
+
+	var loaded []string
+	for _, path := range paths {
+		tool, err := openPlugin(path)
+		if err != nil {
+			logger.Warn("plugin skipped", "path", path, "error", err)
+			continue
+		}
+		name := tool.Info().Name
+		if err := registry.Register(tool); err != nil {
+			logger.Warn("plugin skipped", "path", path, "error", err)
+			continue
+		}
+		// 被 disabled_tools 禁用的插件工具不会注册
+		if _, err := registry.Get(name); err != nil {
+			logger.Info("plugin skipped", "path", path, "error", err)
+			continue
+		}
+		logger.Info("plugin loaded", "path", path, "tool", name)
+		loaded = append(loaded, name)
+	}
+	return loaded
+}
+
+// openPlugin 打开插件并调用其导出的构造函数
+func openPlugin(path string) (tool core.Tool, err error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	sym, err := p.Lookup(PluginSymbol)
+	if err != nil {
+		return nil, err
+	}
+	newTool, ok := sym.(func() core.Tool)
+	if !ok {
+		return nil, fmt.Errorf("symbol %s has type %T, want func() core.Tool", PluginSymbol, sym)
+	}
+
+	// 插件代码不受控制，构造时的 panic 也只跳过该插件
+	defer func() {
+		if r := recover(); r != nil {
+			tool, err = nil, fmt.Errorf("%s panicked: %v", PluginSymbol, r)
+		}
+	}()
+	if tool = newTool(); tool == nil {
+		return nil, fmt.Errorf("%s returned nil", PluginSymbol)
+	}
+	return tool, nil
+}
+
+// warnOnlyLogger 丢弃 Debug 和 Info 日志，只保留警告和错误
+type warnOnlyLogger struct {
+	core.Logger
+}
+
+func (warnOnlyLogger) Debug(msg string, fields ...any) {}
+func (warnOnlyLogger) Info(msg string, fields ...any)  {}
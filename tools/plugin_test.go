@@ -0,0 +1,159 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+const testPluginSource = `package main
+
+import (
+	"context"
+
+	"opencode_nano/tools/core"
+)
+
+type helloTool struct {
+	*core.BaseTool
+}
+
+func (t *helloTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	return core.NewSimpleResult("hello from plugin"), nil
+}
+
+func NewTool() core.Tool {
+	return &helloTool{BaseTool: core.NewBaseTool("hello_plugin", "plugin", "Say hello from a plugin")}
+}
+`
+
+// buildTestPlugin 在临时模块中编译一个最简单的插件，返回插件所在目录。
+// 不支持 plugin 包或无法使用 cgo 的环境会跳过测试
+func buildTestPlugin(t *testing.T) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("编译插件较慢，-short 时跳过")
+	}
+	switch runtime.GOOS {
+	case "linux", "darwin", "freebsd":
+	default:
+		t.Skipf("plugin 包不支持 %s", runtime.GOOS)
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("找不到 go 命令")
+	}
+	if out, err := exec.Command(goBin, "env", "CGO_ENABLED").Output(); err != nil || strings.TrimSpace(string(out)) != "1" {
+		t.Skip("编译插件需要启用 cgo")
+	}
+
+	root, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := t.TempDir()
+	goMod := "module testplugin\n\ngo 1.21\n\nrequire opencode_nano v0.0.0\n\nreplace opencode_nano => " + root + "\n"
+	writePluginFile(t, filepath.Join(src, "go.mod"), goMod)
+	writePluginFile(t, filepath.Join(src, "main.go"), testPluginSource)
+	// 复用主模块的 go.sum，避免联网解析依赖
+	if data, err := os.ReadFile(filepath.Join(root, "go.sum")); err == nil {
+		writePluginFile(t, filepath.Join(src, "go.sum"), string(data))
+	}
+
+	dir := t.TempDir()
+	cmd := exec.Command(goBin, "build", "-buildmode=plugin", "-o", filepath.Join(dir, "hello.so"), ".")
+	cmd.Dir = src
+	cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("编译插件失败: %v\n%s", err, out)
+	}
+	return dir
+}
+
+func writePluginFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadPlugins(t *testing.T) {
+	dir := buildTestPlugin(t)
+	// 无效的插件只记录警告并跳过
+	writePluginFile(t, filepath.Join(dir, "broken.so"), "not a plugin")
+
+	t.Run("加载并注册插件工具", func(t *testing.T) {
+		registry, err := InitializeRegistry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var logs bytes.Buffer
+		loaded := LoadPlugins(registry, dir, core.NewWriterLogger(&logs))
+		if len(loaded) != 1 || loaded[0] != "hello_plugin" {
+			t.Fatalf("loaded = %v, want [hello_plugin]\n%s", loaded, logs.String())
+		}
+		if !strings.Contains(logs.String(), "[WARN] plugin skipped") || !strings.Contains(logs.String(), "broken.so") {
+			t.Errorf("无效插件应记录警告:\n%s", logs.String())
+		}
+
+		tool, err := registry.Get("hello_plugin")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		result, err := tool.Execute(context.Background(), core.NewMapParameters(nil))
+		if err != nil || result.String() != "hello from plugin" {
+			t.Errorf("Execute() = %v, %v", result, err)
+		}
+	})
+
+	t.Run("禁用的插件工具不注册", func(t *testing.T) {
+		registry, err := InitializeRegistry("hello_plugin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loaded := LoadPlugins(registry, dir, core.NopLogger{}); len(loaded) != 0 {
+			t.Errorf("loaded = %v, want none", loaded)
+		}
+		if _, err := registry.Get("hello_plugin"); core.GetErrorCode(err) != core.ErrCodeToolDisabled {
+			t.Errorf("Get() error = %v, want tool disabled", err)
+		}
+	})
+}
+
+func TestLoadPlugins_MissingDir(t *testing.T) {
+	registry, err := InitializeRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := len(registry.All())
+	if loaded := LoadPlugins(registry, filepath.Join(t.TempDir(), "missing"), core.NopLogger{}); len(loaded) != 0 {
+		t.Errorf("loaded = %v, want none", loaded)
+	}
+	if len(registry.All()) != before {
+		t.Errorf("插件目录不存在时不应改变注册表")
+	}
+}
+
+func TestWarnOnlyLogger(t *testing.T) {
+	var logs bytes.Buffer
+	logger := warnOnlyLogger{core.NewWriterLogger(&logs)}
+	logger.Debug("debug message")
+	logger.Info("plugin loaded", "tool", "hello_plugin")
+	logger.Warn("plugin skipped", "path", "broken.so")
+	logger.Error("error message")
+
+	got := logs.String()
+	if strings.Contains(got, "debug message") || strings.Contains(got, "plugin loaded") {
+		t.Errorf("Debug 和 Info 日志应被丢弃:\n%s", got)
+	}
+	if !strings.Contains(got, "[WARN] plugin skipped") || !strings.Contains(got, "[ERROR] error message") {
+		t.Errorf("应保留警告和错误:\n%s", got)
+	}
+}
@@ -1,47 +1,66 @@
 package tools
 
 import (
+	"context"
 	"fmt"
 	"os"
-)
 
-type ReadTool struct{}
+	"opencode_nano/tools/core"
+)
 
-func NewReadTool() *ReadTool {
-	return &ReadTool{}
+// ReadTool 旧版 read_file 工具，通过 CoreToolAdapter 调用 core.Tool 实现
+//
+// Deprecated: 使用 CreateToolSet 或 file.NewReadTool，保留以兼容旧接口
+type ReadTool struct {
+	*CoreToolAdapter
 }
 
-func (t *ReadTool) Name() string {
-	return "read_file"
+func NewReadTool() *ReadTool {
+	return &ReadTool{CoreToolAdapter: NewCoreToolAdapter(newReadFileTool(), nil)}
 }
 
-func (t *ReadTool) Description() string {
-	return "Read the contents of a file"
+// readFileTool read_file 的 core.Tool 实现
+type readFileTool struct {
+	*core.BaseTool
 }
 
-func (t *ReadTool) Parameters() map[string]any {
-	return map[string]any{
-		"type": "object",
-		"properties": map[string]any{
-			"file_path": map[string]any{
-				"type":        "string",
-				"description": "Path to the file to read",
+func newReadFileTool() *readFileTool {
+	tool := &readFileTool{
+		BaseTool: core.NewBaseTool("read_file", "file", "Read the contents of a file"),
+	}
+	tool.SetSchema(core.ParameterSchema{
+		Type: "object",
+		Properties: map[string]core.PropertySchema{
+			"file_path": {
+				Type:        "string",
+				Description: "Path to the file to read",
 			},
 		},
-		"required": []string{"file_path"},
-	}
+		Required: []string{"file_path"},
+	})
+	return tool
 }
 
-func (t *ReadTool) Execute(params map[string]any) (string, error) {
-	filePath, ok := params["file_path"].(string)
+func (t *readFileTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	filePath, ok := stringParam(params, "file_path")
 	if !ok {
-		return "", fmt.Errorf("file_path parameter is required and must be a string")
+		return nil, core.ErrInvalidParams(t.Info().Name, "file_path parameter is required and must be a string")
 	}
 
 	content, err := os.ReadFile(filePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read file %s: %v", filePath, err)
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to read file %s: %v", filePath, err))
 	}
 
-	return fmt.Sprintf("File content of %s:\n%s", filePath, string(content)), nil
-}
\ No newline at end of file
+	return core.NewSimpleResult(fmt.Sprintf("File content of %s:\n%s", filePath, string(content))), nil
+}
+
+// stringParam 获取字符串类型的参数，缺失或类型不符时返回 false
+func stringParam(params core.Parameters, key string) (string, bool) {
+	value, err := params.Get(key)
+	if err != nil {
+		return "", false
+	}
+	s, ok := value.(string)
+	return s, ok
+}
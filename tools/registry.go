@@ -1,6 +1,9 @@
 package tools
 
 import (
+	"encoding/json"
+	"fmt"
+
 	"opencode_nano/tools/core"
 	"opencode_nano/tools/file"
 	"opencode_nano/tools/system"
@@ -10,9 +13,42 @@ import (
 // DefaultRegistry 默认工具注册表
 var DefaultRegistry *core.ToolRegistry
 
-// InitializeRegistry 初始化工具注册表
-func InitializeRegistry() (*core.ToolRegistry, error) {
+// InitializeRegistry 初始化工具注册表，disabled 中的工具（名称或别名）不会被注册
+func InitializeRegistry(disabled ...string) (*core.ToolRegistry, error) {
+	return InitializeRegistryWithMetrics(nil, disabled...)
+}
+
+// InitializeRegistryWithMetrics 初始化工具注册表，collector 非 nil 时记录所有工具的执行指标
+func InitializeRegistryWithMetrics(collector core.MetricsCollector, disabled ...string) (*core.ToolRegistry, error) {
+	registry, err := newRegistry(collector, disabled...)
+	if err != nil {
+		return nil, err
+	}
+	
+	DefaultRegistry = registry
+	return registry, nil
+}
+
+// DisabledToolNames 将配置中禁用的工具（名称或别名）展开为对应工具的名称和全部别名，
+// 不影响 DefaultRegistry
+func DisabledToolNames(disabled ...string) ([]string, error) {
+	if len(disabled) == 0 {
+		return nil, nil
+	}
+	registry, err := newRegistry(nil, disabled...)
+	if err != nil {
+		return nil, err
+	}
+	return registry.Disabled(), nil
+}
+
+// newRegistry 创建并注册所有内置工具的注册表
+func newRegistry(collector core.MetricsCollector, disabled ...string) (*core.ToolRegistry, error) {
 	registry := core.NewRegistry()
+	if collector != nil {
+		registry.SetMetrics(collector)
+	}
+	registry.SetDisabled(disabled...)
 	
 	// 注册文件操作工具
 	if err := registerFileTools(registry); err != nil {
@@ -29,7 +65,6 @@ func InitializeRegistry() (*core.ToolRegistry, error) {
 		return nil, err
 	}
 	
-	DefaultRegistry = registry
 	return registry, nil
 }
 
@@ -60,26 +95,109 @@ func registerFileTools(registry *core.ToolRegistry) error {
 		return err
 	}
 	
+	// 差异比较工具
+	if err := registry.Register(file.NewDiffTool()); err != nil {
+		return err
+	}
+	
+	// 复制工具
+	if err := registry.Register(file.NewCopyTool(), "cp"); err != nil {
+		return err
+	}
+	
+	// 移动工具
+	if err := registry.Register(file.NewMoveTool(), "mv"); err != nil {
+		return err
+	}
+	
+	// 删除工具
+	if err := registry.Register(file.NewDeleteTool(), "rm"); err != nil {
+		return err
+	}
+	
+	// touch 工具
+	if err := registry.Register(file.NewTouchTool()); err != nil {
+		return err
+	}
+	
 	// 搜索工具
 	if err := registry.Register(file.NewSearchTool(), "s", "grep", "find"); err != nil {
 		return err
 	}
 	
+	// 查找替换工具
+	if err := registry.Register(file.NewReplaceTool()); err != nil {
+		return err
+	}
+	
 	// 通配符工具
 	if err := registry.Register(file.NewGlobTool(), "g", "glob"); err != nil {
 		return err
 	}
 	
+	// 模糊查找工具
+	if err := registry.Register(file.NewFuzzyFindTool(), "fuzzy_find"); err != nil {
+		return err
+	}
+	
 	// 列表工具
 	if err := registry.Register(file.NewListTool(), "ls", "dir"); err != nil {
 		return err
 	}
 	
+	// 目录树工具
+	if err := registry.Register(file.NewTreeTool()); err != nil {
+		return err
+	}
+	
+	// head/tail 工具
+	if err := registry.Register(file.NewHeadTool()); err != nil {
+		return err
+	}
+	if err := registry.Register(file.NewTailTool()); err != nil {
+		return err
+	}
+	
+	// 文件监听工具
+	if err := registry.Register(file.NewWatchTool()); err != nil {
+		return err
+	}
+	
 	// 二进制读取工具
 	if err := registry.Register(file.NewReadBinaryTool()); err != nil {
 		return err
 	}
 	
+	// 统计工具
+	if err := registry.Register(file.NewStatTool(), "wc"); err != nil {
+		return err
+	}
+	
+	// 校验和工具
+	if err := registry.Register(file.NewHashTool(), "checksum"); err != nil {
+		return err
+	}
+	
+	// JSON 工具
+	if err := registry.Register(file.NewJSONTool(), "jq"); err != nil {
+		return err
+	}
+	
+	// gzip 压缩工具
+	if err := registry.Register(file.NewGzipTool(), "gunzip"); err != nil {
+		return err
+	}
+	
+	// zip 归档工具
+	if err := registry.Register(file.NewZipTool(), "unzip"); err != nil {
+		return err
+	}
+	
+	// tar.gz 归档工具
+	if err := registry.Register(file.NewTarTool(), "tarball"); err != nil {
+		return err
+	}
+	
 	return nil
 }
 
@@ -105,6 +223,31 @@ func registerSystemTools(registry *core.ToolRegistry) error {
 		return err
 	}
 	
+	// 系统信息工具
+	if err := registry.Register(system.NewSysInfoTool(), "system_info", "uname"); err != nil {
+		return err
+	}
+	
+	// 可执行文件查找工具
+	if err := registry.Register(system.NewWhichTool(), "lookpath"); err != nil {
+		return err
+	}
+	
+	// HTTP 获取工具
+	if err := registry.Register(system.NewFetchTool(), "http_get"); err != nil {
+		return err
+	}
+	
+	// Go 语法检查工具
+	if err := registry.Register(system.NewGoParseTool(), "gofmt_check"); err != nil {
+		return err
+	}
+	
+	// Go 符号查找工具
+	if err := registry.Register(system.NewGoSymbolsTool(), "go_def", "find_definition"); err != nil {
+		return err
+	}
+	
 	return nil
 }
 
@@ -155,4 +298,23 @@ func SearchTools(query string) []core.Tool {
 	}
 	
 	return DefaultRegistry.Find(query)
-}
\ No newline at end of file
+}
+
+// ExportSchemas 导出所有已注册工具的 JSON Schema，键为工具名
+func ExportSchemas() (map[string]json.RawMessage, error) {
+	if DefaultRegistry == nil {
+		if _, err := InitializeRegistry(); err != nil {
+			return nil, err
+		}
+	}
+	
+	schemas := make(map[string]json.RawMessage)
+	for _, tool := range DefaultRegistry.All() {
+		data, err := core.MarshalSchema(tool)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export schema for %s: %v", tool.Info().Name, err)
+		}
+		schemas[tool.Info().Name] = data
+	}
+	return schemas, nil
+}
@@ -0,0 +1,139 @@
+package tools
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"opencode_nano/config"
+	"opencode_nano/tools/core"
+)
+
+func TestExportSchemas(t *testing.T) {
+	schemas, err := ExportSchemas()
+	if err != nil {
+		t.Fatalf("ExportSchemas() error = %v", err)
+	}
+	if len(schemas) != len(ListTools()) {
+		t.Errorf("导出 %d 个 schema, want %d", len(schemas), len(ListTools()))
+	}
+
+	var todo struct {
+		Schema     string   `json:"$schema"`
+		Title      string   `json:"title"`
+		Type       string   `json:"type"`
+		Required   []string `json:"required"`
+		Properties map[string]struct {
+			Type string   `json:"type"`
+			Enum []string `json:"enum"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(schemas["todo"], &todo); err != nil {
+		t.Fatalf("todo schema 不是有效的 JSON: %v", err)
+	}
+
+	if todo.Schema == "" || todo.Title != "todo" || todo.Type != "object" {
+		t.Errorf("schema 头部 = %q, %q, %q", todo.Schema, todo.Title, todo.Type)
+	}
+	if len(todo.Required) != 1 || todo.Required[0] != "action" {
+		t.Errorf("required = %v, want [action]", todo.Required)
+	}
+	action := todo.Properties["action"]
+	if action.Type != "string" || len(action.Enum) != 8 || action.Enum[0] != "list" {
+		t.Errorf("action = %+v, want enum [list add update delete clear search]", action)
+	}
+
+	// 数值范围也应导出
+	var bash map[string]any
+	if err := json.Unmarshal(schemas["bash"], &bash); err != nil {
+		t.Fatal(err)
+	}
+	timeout := bash["properties"].(map[string]any)["timeout"].(map[string]any)
+	if timeout["minimum"] != float64(0) || timeout["maximum"] != float64(3600) {
+		t.Errorf("timeout 范围 = %v..%v, want 0..3600", timeout["minimum"], timeout["maximum"])
+	}
+}
+
+func TestInitializeRegistry_DisabledTools(t *testing.T) {
+	registry, err := InitializeRegistry("bash", "ps")
+	if err != nil {
+		t.Fatalf("InitializeRegistry() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		wantCode string
+	}{
+		{"bash", core.ErrCodeToolDisabled},
+		{"sh", core.ErrCodeToolDisabled}, // 禁用工具的别名同样不可用
+		{"process", core.ErrCodeToolDisabled},
+		{"ps", core.ErrCodeToolDisabled}, // 通过别名禁用
+		{"no_such_tool", core.ErrCodeToolNotFound},
+		{"read", ""},
+	}
+	for _, tt := range tests {
+		_, err := registry.Get(tt.name)
+		if tt.wantCode == "" {
+			if err != nil {
+				t.Errorf("Get(%q) error = %v", tt.name, err)
+			}
+			continue
+		}
+		if core.GetErrorCode(err) != tt.wantCode {
+			t.Errorf("Get(%q) error = %v, want code %s", tt.name, err, tt.wantCode)
+		}
+	}
+
+	for _, tool := range registry.All() {
+		if name := tool.Info().Name; name == "bash" || name == "process" {
+			t.Errorf("禁用的工具 %s 不应注册", name)
+		}
+	}
+	if len(registry.Find("bash")) != 0 {
+		t.Errorf("搜索不应返回禁用的工具")
+	}
+}
+
+func TestDisabledToolNames(t *testing.T) {
+	oldRegistry := DefaultRegistry
+	names, err := DisabledToolNames("rm", "no_such_tool")
+	if err != nil {
+		t.Fatalf("DisabledToolNames() error = %v", err)
+	}
+	// 通过别名禁用的工具展开为名称和全部别名，未知名称原样保留
+	want := []string{"delete", "no_such_tool", "rm"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("DisabledToolNames() = %v, want %v", names, want)
+	}
+	if DefaultRegistry != oldRegistry {
+		t.Error("DisabledToolNames() 不应替换 DefaultRegistry")
+	}
+}
+
+func TestCreateToolSet_DisabledTools(t *testing.T) {
+	cfg := &config.Config{DisabledTools: []string{"bash"}}
+	perm := &MockPermissionManager{shouldAllow: true}
+
+	for _, create := range []struct {
+		name string
+		fn   func() ([]Tool, error)
+	}{
+		{"CreateToolSet", func() ([]Tool, error) { return CreateToolSet(perm, cfg) }},
+		{"CreateFullToolSet", func() ([]Tool, error) { return CreateFullToolSet(perm, cfg) }},
+	} {
+		t.Run(create.name, func(t *testing.T) {
+			toolSet, err := create.fn()
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if len(toolSet) == 0 {
+				t.Fatal("工具集为空")
+			}
+			for _, tool := range toolSet {
+				if tool.Name() == "bash" {
+					t.Errorf("工具集中不应包含禁用的 bash")
+				}
+			}
+		})
+	}
+}
@@ -13,9 +13,21 @@ import (
 	"opencode_nano/tools/core"
 )
 
+// defaultMaxOutputBytes 默认保留的最大输出字节数
+const defaultMaxOutputBytes = 100 * 1024
+
 // BashTool 增强版 bash 执行工具
 type BashTool struct {
 	*core.BaseTool
+	policy CommandPolicy
+}
+
+// CommandPolicy 命令安全策略，在内置危险命令列表的基础上扩展
+type CommandPolicy struct {
+	// DenyPatterns 额外禁止的命令片段（不区分大小写）
+	DenyPatterns []string
+	// AllowPrefixes 以这些前缀开头且不含命令连接符、重定向的命令跳过危险模式检查，内置危险命令仍然禁止
+	AllowPrefixes []string
 }
 
 // NewBashTool 创建 bash 工具
@@ -45,8 +57,10 @@ func NewBashTool() *BashTool {
 			},
 			"timeout": {
 				Type:        "integer",
-				Description: "Timeout in seconds (0 for no timeout)",
+				Description: "Timeout in seconds (0 for no timeout, at most 3600)",
 				Default:     300, // 5 minutes default
+				Minimum:     core.Bound(0),
+				Maximum:     core.Bound(3600),
 			},
 			"shell": {
 				Type:        "string",
@@ -63,6 +77,16 @@ func NewBashTool() *BashTool {
 				Description: "Combine stdout and stderr",
 				Default:     true,
 			},
+			"stdin": {
+				Type:        "string",
+				Description: "Input passed to the command's stdin",
+				Default:     "",
+			},
+			"max_output_bytes": {
+				Type:        "integer",
+				Description: "Maximum bytes of captured output to keep per stream (default: 100KB)",
+				Default:     defaultMaxOutputBytes,
+			},
 		},
 		Required: []string{"command"},
 	})
@@ -72,20 +96,29 @@ func NewBashTool() *BashTool {
 
 // Execute 执行命令
 func (t *BashTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	result, _, err := t.run(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// run 执行命令，同时返回捕获标准输出的缓冲区，供管道把原始输出传给下一个命令
+func (t *BashTool) run(ctx context.Context, params core.Parameters) (*core.SimpleResult, *cappedBuffer, error) {
 	// 参数验证
 	if err := params.Validate(t.Schema()); err != nil {
-		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+		return nil, nil, core.ErrInvalidParams(t.Info().Name, err.Error())
 	}
 	
 	// 获取参数
 	command, err := params.GetString("command")
 	if err != nil {
-		return nil, core.ErrInvalidParams(t.Info().Name, "invalid command parameter")
+		return nil, nil, core.ErrInvalidParams(t.Info().Name, "invalid command parameter")
 	}
 	
 	// 安全检查
 	if err := t.checkCommandSafety(command); err != nil {
-		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("unsafe command: %v", err))
+		return nil, nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("unsafe command: %v", err))
 	}
 	
 	// 获取可选参数
@@ -95,7 +128,7 @@ func (t *BashTool) Execute(ctx context.Context, params core.Parameters) (core.Re
 		// 验证目录存在
 		if cwd != "" {
 			if info, err := os.Stat(cwd); err != nil || !info.IsDir() {
-				return nil, core.ErrInvalidParams(t.Info().Name, "invalid working directory")
+				return nil, nil, core.ErrInvalidParams(t.Info().Name, "invalid working directory")
 			}
 		}
 	}
@@ -135,6 +168,14 @@ func (t *BashTool) Execute(ctx context.Context, params core.Parameters) (core.Re
 		combineOutput, _ = params.GetBool("combine_output")
 	}
 	
+	maxOutputBytes := defaultMaxOutputBytes
+	if params.Has("max_output_bytes") {
+		maxOutputBytes, _ = params.GetInt("max_output_bytes")
+	}
+	if maxOutputBytes <= 0 {
+		return nil, nil, core.ErrInvalidParams(t.Info().Name, "max_output_bytes must be positive")
+	}
+	
 	// 创建命令
 	var cmd *exec.Cmd
 	if timeout > 0 {
@@ -157,17 +198,24 @@ func (t *BashTool) Execute(ctx context.Context, params core.Parameters) (core.Re
 		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
 	}
 	
-	// 执行命令
-	var stdout, stderr bytes.Buffer
+	// 设置标准输入
+	if params.Has("stdin") {
+		stdin, _ := params.GetString("stdin")
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	
+	// 执行命令，读取输出时即按上限截断，内存占用有界
+	stdout := newCappedBuffer(maxOutputBytes)
+	stderr := newCappedBuffer(maxOutputBytes)
 	startTime := time.Now()
 	
 	if captureOutput {
 		if combineOutput {
-			cmd.Stdout = &stdout
-			cmd.Stderr = &stdout
+			cmd.Stdout = stdout
+			cmd.Stderr = stdout
 		} else {
-			cmd.Stdout = &stdout
-			cmd.Stderr = &stderr
+			cmd.Stdout = stdout
+			cmd.Stderr = stderr
 		}
 	}
 	
@@ -199,23 +247,24 @@ func (t *BashTool) Execute(ctx context.Context, params core.Parameters) (core.Re
 	result.WithMetadata("duration_ms", duration.Milliseconds())
 	
 	if captureOutput {
-		result.WithMetadata("stdout", stdout.String())
-		if !combineOutput {
-			result.WithMetadata("stderr", stderr.String())
-		}
-		
 		// 添加输出到结果数据
-		if combineOutput || stderr.Len() == 0 {
+		if combineOutput || stderr.Total() == 0 {
 			result = core.NewSimpleResult(stdout.String())
 		} else {
 			result = core.NewSimpleResult(fmt.Sprintf("stdout:\n%s\nstderr:\n%s", stdout.String(), stderr.String()))
 		}
 		
 		// 重新添加元数据
+		result.WithMetadata("stdout", stdout.String())
+		if !combineOutput {
+			result.WithMetadata("stderr", stderr.String())
+		}
 		result.WithMetadata("command", command)
 		result.WithMetadata("exit_code", exitCode)
 		result.WithMetadata("duration_ms", duration.Milliseconds())
 		result.WithMetadata("success", err == nil)
+		result.WithMetadata("truncated", stdout.Truncated() || stderr.Truncated())
+		result.WithMetadata("original_size", stdout.Total()+stderr.Total())
 	}
 	
 	if cwd != "" {
@@ -226,7 +275,61 @@ func (t *BashTool) Execute(ctx context.Context, params core.Parameters) (core.Re
 		result.WithMetadata("env", env)
 	}
 	
-	return result, nil
+	return result, stdout, nil
+}
+
+// cappedBuffer 只保留前 limit 字节的输出缓冲区，超出部分只计数不保存
+type cappedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+	total int64
+}
+
+// newCappedBuffer 创建有上限的输出缓冲区
+func newCappedBuffer(limit int) *cappedBuffer {
+	return &cappedBuffer{limit: limit}
+}
+
+// Write 实现 io.Writer，总是报告写入成功以免命令因管道关闭而失败
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.total += int64(len(p))
+	if remaining := b.limit - b.buf.Len(); remaining > 0 {
+		if len(p) > remaining {
+			b.buf.Write(p[:remaining])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// Truncated 是否有输出被丢弃
+func (b *cappedBuffer) Truncated() bool {
+	return b.total > int64(b.buf.Len())
+}
+
+// Total 返回原始输出的总字节数
+func (b *cappedBuffer) Total() int64 {
+	return b.total
+}
+
+// Bytes 返回保留的输出，不含截断标记
+func (b *cappedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+// String 返回保留的输出，截断时追加标记
+func (b *cappedBuffer) String() string {
+	if !b.Truncated() {
+		return b.buf.String()
+	}
+	return fmt.Sprintf("%s\n...[truncated %d bytes]", b.buf.String(), b.total-int64(b.buf.Len()))
+}
+
+// SetCommandPolicy 设置命令安全策略
+func (t *BashTool) SetCommandPolicy(policy CommandPolicy) *BashTool {
+	t.policy = policy
+	return t
 }
 
 // getShell 获取默认 shell
@@ -278,13 +381,25 @@ func (t *BashTool) checkCommandSafety(command string) error {
 	// 转换为小写进行比较
 	lowerCommand := strings.ToLower(command)
 	
-	// 检查危险命令
+	// 检查危险命令，受信任的前缀也不能跳过
 	for _, dangerous := range dangerousCommands {
 		if strings.Contains(lowerCommand, strings.ToLower(dangerous)) {
 			return fmt.Errorf("potentially dangerous command detected: %s", dangerous)
 		}
 	}
 	
+	// 受信任的前缀跳过危险模式检查；含有命令连接符或重定向时仍需检查，
+	// 避免借前缀夹带其他命令或写入设备文件
+	trimmed := strings.TrimSpace(command)
+	for _, prefix := range t.policy.AllowPrefixes {
+		if strings.HasPrefix(trimmed, prefix) && !hasCommandChaining(trimmed) && !strings.ContainsAny(trimmed, "<>") {
+			return nil
+		}
+	}
+	
+	// 用户配置的禁止片段
+	dangerousPatterns = append(dangerousPatterns, t.policy.DenyPatterns...)
+	
 	// 检查危险模式
 	for _, pattern := range dangerousPatterns {
 		if strings.Contains(lowerCommand, strings.ToLower(pattern)) {
@@ -296,6 +411,11 @@ func (t *BashTool) checkCommandSafety(command string) error {
 	return nil
 }
 
+// hasCommandChaining 检查命令是否包含 ;、&&、|、反引号、$( 或换行等可以串联其他命令的符号
+func hasCommandChaining(command string) bool {
+	return strings.ContainsAny(command, ";&|`\n") || strings.Contains(command, "$(")
+}
+
 // PipelineTool 管道执行工具
 type PipelineTool struct {
 	*core.BaseTool
@@ -317,6 +437,8 @@ func NewPipelineTool() *PipelineTool {
 			"commands": {
 				Type:        "array",
 				Description: "List of commands to execute in sequence",
+				Items:       &core.PropertySchema{Type: "string", MinLength: 1},
+				MinItems:    1,
 			},
 			"stop_on_error": {
 				Type:        "boolean",
@@ -328,6 +450,11 @@ func NewPipelineTool() *PipelineTool {
 				Description: "Execute commands in parallel",
 				Default:     false,
 			},
+			"pipe_stdin": {
+				Type:        "boolean",
+				Description: "Pass each command's stdout to the next command's stdin (sequential only)",
+				Default:     false,
+			},
 			"cwd": {
 				Type:        "string",
 				Description: "Working directory for all commands",
@@ -340,8 +467,15 @@ func NewPipelineTool() *PipelineTool {
 			},
 			"timeout": {
 				Type:        "integer",
-				Description: "Timeout for each command in seconds",
+				Description: "Timeout for each command in seconds (0 for no timeout, at most 3600)",
 				Default:     300,
+				Minimum:     core.Bound(0),
+				Maximum:     core.Bound(3600),
+			},
+			"max_output_bytes": {
+				Type:        "integer",
+				Description: "Maximum bytes of captured output to keep per stream of each command (default: 100KB)",
+				Default:     defaultMaxOutputBytes,
 			},
 		},
 		Required: []string{"commands"},
@@ -350,6 +484,12 @@ func NewPipelineTool() *PipelineTool {
 	return tool
 }
 
+// SetCommandPolicy 设置管道中每个命令使用的安全策略
+func (t *PipelineTool) SetCommandPolicy(policy CommandPolicy) *PipelineTool {
+	t.bashTool.SetCommandPolicy(policy)
+	return t
+}
+
 // Execute 执行管道
 func (t *PipelineTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
 	// 参数验证
@@ -379,6 +519,14 @@ func (t *PipelineTool) Execute(ctx context.Context, params core.Parameters) (cor
 		parallel, _ = params.GetBool("parallel")
 	}
 	
+	pipeStdin := false
+	if params.Has("pipe_stdin") {
+		pipeStdin, _ = params.GetBool("pipe_stdin")
+	}
+	if pipeStdin && parallel {
+		return nil, core.ErrInvalidParams(t.Info().Name, "pipe_stdin cannot be used with parallel execution")
+	}
+	
 	// 获取公共参数
 	commonParams := core.NewMapParameters(make(map[string]any))
 	if params.Has("cwd") {
@@ -396,9 +544,17 @@ func (t *PipelineTool) Execute(ctx context.Context, params core.Parameters) (cor
 			commonParams.Set("timeout", timeout)
 		}
 	}
+	if params.Has("max_output_bytes") {
+		maxOutputBytes, _ := params.GetInt("max_output_bytes")
+		if maxOutputBytes <= 0 {
+			return nil, core.ErrInvalidParams(t.Info().Name, "max_output_bytes must be positive")
+		}
+		commonParams.Set("max_output_bytes", maxOutputBytes)
+	}
 	
 	// 执行命令
 	var results []map[string]interface{}
+	truncated := false
 	successCount := 0
 	failCount := 0
 	
@@ -407,7 +563,7 @@ func (t *PipelineTool) Execute(ctx context.Context, params core.Parameters) (cor
 		results = t.executeParallel(ctx, commands, commonParams)
 	} else {
 		// 顺序执行
-		results = t.executeSequential(ctx, commands, commonParams, stopOnError)
+		results, truncated = t.executeSequential(ctx, commands, commonParams, stopOnError, pipeStdin)
 	}
 	
 	// 统计结果
@@ -419,14 +575,29 @@ func (t *PipelineTool) Execute(ctx context.Context, params core.Parameters) (cor
 		}
 	}
 	
-	// 创建结果
-	result := core.NewSimpleResult(fmt.Sprintf("Executed %d commands: %d succeeded, %d failed", 
-		len(commands), successCount, failCount))
+	// 创建结果，管道模式下输出最后一个命令的标准输出
+	summary := fmt.Sprintf("Executed %d commands: %d succeeded, %d failed", len(commands), successCount, failCount)
+	var result *core.SimpleResult
+	if pipeStdin {
+		finalOutput := ""
+		if len(results) > 0 {
+			if metadata, ok := results[len(results)-1]["metadata"].(map[string]any); ok {
+				finalOutput, _ = metadata["stdout"].(string)
+			}
+		}
+		result = core.NewSimpleResult(finalOutput)
+		result.WithMetadata("summary", summary)
+		result.WithMetadata("final_output", finalOutput)
+		result.WithMetadata("truncated", truncated)
+	} else {
+		result = core.NewSimpleResult(summary)
+	}
 	result.WithMetadata("results", results)
 	result.WithMetadata("total_commands", len(commands))
 	result.WithMetadata("success_count", successCount)
 	result.WithMetadata("fail_count", failCount)
 	result.WithMetadata("parallel", parallel)
+	result.WithMetadata("pipe_stdin", pipeStdin)
 	
 	return result, nil
 }
@@ -457,15 +628,24 @@ func (t *PipelineTool) parseCommands(raw interface{}) ([]string, error) {
 	return commands, nil
 }
 
-// executeSequential 顺序执行命令
-func (t *PipelineTool) executeSequential(ctx context.Context, commands []string, commonParams core.Parameters, stopOnError bool) []map[string]interface{} {
-	results := make([]map[string]interface{}, 0, len(commands))
+// executeSequential 顺序执行命令，pipeStdin 时将上一个命令的标准输出作为下一个命令的标准输入。
+// 传入管道的是捕获的原始输出，不含截断标记；truncated 表示是否有命令的标准输出被截断
+func (t *PipelineTool) executeSequential(ctx context.Context, commands []string, commonParams core.Parameters, stopOnError, pipeStdin bool) (results []map[string]interface{}, truncated bool) {
+	results = make([]map[string]interface{}, 0, len(commands))
+	previousStdout := ""
 	
 	for i, cmd := range commands {
 		// 创建命令参数
 		cmdParams := core.NewMapParameters(map[string]any{
 			"command": cmd,
 		})
+		if pipeStdin {
+			// 只传递标准输出，标准错误不进入管道
+			cmdParams.Set("combine_output", false)
+			if i > 0 {
+				cmdParams.Set("stdin", previousStdout)
+			}
+		}
 		
 		// 复制公共参数
 		if cwd, err := commonParams.GetString("cwd"); err == nil {
@@ -477,9 +657,12 @@ func (t *PipelineTool) executeSequential(ctx context.Context, commands []string,
 		if timeout, err := commonParams.GetInt("timeout"); err == nil {
 			cmdParams.Set("timeout", timeout)
 		}
+		if maxOutputBytes, err := commonParams.GetInt("max_output_bytes"); err == nil {
+			cmdParams.Set("max_output_bytes", maxOutputBytes)
+		}
 		
 		// 执行命令
-		result, err := t.bashTool.Execute(ctx, cmdParams)
+		result, stdout, err := t.bashTool.run(ctx, cmdParams)
 		
 		cmdResult := map[string]interface{}{
 			"index":   i,
@@ -490,6 +673,7 @@ func (t *PipelineTool) executeSequential(ctx context.Context, commands []string,
 			cmdResult["success"] = false
 			cmdResult["error"] = err.Error()
 			results = append(results, cmdResult)
+			previousStdout = ""
 			
 			if stopOnError {
 				break
@@ -499,10 +683,12 @@ func (t *PipelineTool) executeSequential(ctx context.Context, commands []string,
 			cmdResult["output"] = result.Data()
 			cmdResult["metadata"] = result.Metadata()
 			results = append(results, cmdResult)
+			previousStdout = string(stdout.Bytes())
+			truncated = truncated || stdout.Truncated()
 		}
 	}
 	
-	return results
+	return results, truncated
 }
 
 // executeParallel 并行执行命令
@@ -529,6 +715,9 @@ func (t *PipelineTool) executeParallel(ctx context.Context, commands []string, c
 			if timeout, err := commonParams.GetInt("timeout"); err == nil {
 				cmdParams.Set("timeout", timeout)
 			}
+			if maxOutputBytes, err := commonParams.GetInt("max_output_bytes"); err == nil {
+				cmdParams.Set("max_output_bytes", maxOutputBytes)
+			}
 			
 			// 执行命令
 			result, err := t.bashTool.Execute(ctx, cmdParams)
@@ -0,0 +1,269 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+func TestPipelineTool_PipeStdin(t *testing.T) {
+	tests := []struct {
+		name       string
+		params     map[string]any
+		wantOutput string
+		wantFails  int
+		wantErr    bool
+	}{
+		{
+			name: "标准输出传给下一个命令",
+			params: map[string]any{
+				"commands":   []any{"echo hello", "tr a-z A-Z"},
+				"pipe_stdin": true,
+			},
+			wantOutput: "HELLO",
+		},
+		{
+			name: "多级管道",
+			params: map[string]any{
+				"commands":   []any{"printf 'b\\na\\nc\\n'", "sort", "head -n 2"},
+				"pipe_stdin": true,
+			},
+			wantOutput: "a\nb",
+		},
+		{
+			name: "标准错误不进入管道",
+			params: map[string]any{
+				"commands":   []any{"echo out; echo err >&2", "cat"},
+				"pipe_stdin": true,
+			},
+			wantOutput: "out",
+		},
+		{
+			name: "不能与并行同时使用",
+			params: map[string]any{
+				"commands":   []any{"echo hello", "cat"},
+				"pipe_stdin": true,
+				"parallel":   true,
+			},
+			wantErr: true,
+		},
+	}
+
+	tool := NewPipelineTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(tt.params))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if got := strings.TrimSpace(result.String()); got != tt.wantOutput {
+				t.Errorf("输出 = %q, want %q", got, tt.wantOutput)
+			}
+			if result.Metadata()["fail_count"] != tt.wantFails {
+				t.Errorf("fail_count = %v, want %d", result.Metadata()["fail_count"], tt.wantFails)
+			}
+		})
+	}
+}
+
+func TestPipelineTool_PipeTruncatedOutput(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxBytes      int
+		wantOutput    string
+		wantTruncated bool
+	}{
+		// seq 1000 的前 100 字节包含 36 个完整行，截断标记不应进入下一个命令
+		{"超过上限时只传递保留的原始输出", 100, "36", true},
+		{"未超过上限", 10000, "1000", false},
+	}
+
+	tool := NewPipelineTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+				"commands":         []any{"seq 1000", "wc -l"},
+				"pipe_stdin":       true,
+				"max_output_bytes": tt.maxBytes,
+			}))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			if got := strings.TrimSpace(result.String()); got != tt.wantOutput {
+				t.Errorf("输出 = %q, want %q", got, tt.wantOutput)
+			}
+			if result.Metadata()["truncated"] != tt.wantTruncated {
+				t.Errorf("truncated = %v, want %v", result.Metadata()["truncated"], tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestPipelineTool_WithoutPipe(t *testing.T) {
+	// 未开启 pipe_stdin 时命令之间互不影响
+	result, err := NewPipelineTool().Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"commands": []any{"echo hello", "cat"},
+	}))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	results := result.Metadata()["results"].([]map[string]interface{})
+	if got := results[1]["output"]; got != "" {
+		t.Errorf("第二个命令的输出 = %q, want 空", got)
+	}
+	if !strings.HasPrefix(result.String(), "Executed 2 commands") {
+		t.Errorf("结果 = %q", result.String())
+	}
+}
+
+func TestBashTool_Stdin(t *testing.T) {
+	result, err := NewBashTool().Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"command": "wc -l",
+		"stdin":   "a\nb\nc\n",
+	}))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got := strings.TrimSpace(result.String()); got != "3" {
+		t.Errorf("输出 = %q, want %q", got, "3")
+	}
+}
+
+func TestBashTool_MaxOutputBytes(t *testing.T) {
+	tests := []struct {
+		name          string
+		params        map[string]any
+		wantTruncated bool
+		wantOriginal  int64
+		wantPrefix    string
+	}{
+		{
+			name:          "超出上限时截断",
+			params:        map[string]any{"command": "head -c 50000 /dev/zero | tr '\\0' a", "max_output_bytes": 1000},
+			wantTruncated: true,
+			wantOriginal:  50000,
+			wantPrefix:    strings.Repeat("a", 1000) + "\n...[truncated 49000 bytes]",
+		},
+		{
+			name:         "未超出上限",
+			params:       map[string]any{"command": "printf hello", "max_output_bytes": 1000},
+			wantOriginal: 5,
+			wantPrefix:   "hello",
+		},
+		{
+			name:          "默认上限 100KB",
+			params:        map[string]any{"command": "head -c 200000 /dev/zero | tr '\\0' a"},
+			wantTruncated: true,
+			wantOriginal:  200000,
+			wantPrefix:    strings.Repeat("a", 100*1024) + "\n...[truncated",
+		},
+	}
+
+	tool := NewBashTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(tt.params))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			if !strings.HasPrefix(result.String(), tt.wantPrefix) {
+				t.Errorf("输出前缀不符, 长度 = %d, 结尾 = %q", len(result.String()), result.String()[max(0, len(result.String())-40):])
+			}
+			if len(result.String()) > len(tt.wantPrefix)+40 {
+				t.Errorf("输出长度 %d 超出上限", len(result.String()))
+			}
+			if result.Metadata()["truncated"] != tt.wantTruncated {
+				t.Errorf("truncated = %v, want %v", result.Metadata()["truncated"], tt.wantTruncated)
+			}
+			if result.Metadata()["original_size"] != tt.wantOriginal {
+				t.Errorf("original_size = %v, want %d", result.Metadata()["original_size"], tt.wantOriginal)
+			}
+		})
+	}
+}
+
+func TestCappedBuffer(t *testing.T) {
+	buf := newCappedBuffer(5)
+	for _, chunk := range []string{"abc", "defg", "hij"} {
+		n, err := buf.Write([]byte(chunk))
+		if err != nil || n != len(chunk) {
+			t.Fatalf("Write(%q) = %d, %v", chunk, n, err)
+		}
+	}
+
+	if got := buf.String(); got != "abcde\n...[truncated 5 bytes]" {
+		t.Errorf("String() = %q", got)
+	}
+	if buf.Total() != 10 || !buf.Truncated() {
+		t.Errorf("Total() = %d, Truncated() = %v", buf.Total(), buf.Truncated())
+	}
+}
+
+func TestBashTool_CommandPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  CommandPolicy
+		command string
+		wantErr bool
+	}{
+		{"内置规则默认生效", CommandPolicy{}, "chown -R user /srv", true},
+		{"普通命令放行", CommandPolicy{}, "echo ok", false},
+		{"用户添加的禁止片段", CommandPolicy{DenyPatterns: []string{"git push --force"}}, "git push --force origin main", true},
+		{"禁止片段不区分大小写", CommandPolicy{DenyPatterns: []string{"DROP TABLE"}}, "echo drop table users", true},
+		{"允许前缀绕过内置规则", CommandPolicy{AllowPrefixes: []string{"chown -R"}}, "chown -R nobody /nonexistent-dir", false},
+		{"允许前缀不放行串联命令", CommandPolicy{AllowPrefixes: []string{"echo"}}, "echo hi; chown -R user /srv", true},
+		{"允许前缀不放行命令替换", CommandPolicy{AllowPrefixes: []string{"echo"}}, "echo $(mkfs /dev/sdz)", true},
+		{"允许前缀不放行重定向", CommandPolicy{AllowPrefixes: []string{"echo"}}, "echo x > /dev/sda", true},
+		{"允许前缀不跳过内置危险命令", CommandPolicy{AllowPrefixes: []string{"rm"}}, "rm -rf /", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := NewBashTool().SetCommandPolicy(tt.policy)
+			err := tool.checkCommandSafety(tt.command)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkCommandSafety(%q) error = %v, wantErr %v", tt.command, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBashTool_TimeoutRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout any
+		wantErr bool
+	}{
+		{"不限制超时", 0, false},
+		{"范围内", 60, false},
+		{"负数", -1, true},
+		{"超过上限", 3601, true},
+	}
+
+	tool := NewBashTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+				"command": "true",
+				"timeout": tt.timeout,
+			}))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var toolErr *core.ToolError
+			if tt.wantErr && (!errors.As(err, &toolErr) || toolErr.Code != core.ErrCodeInvalidParams) {
+				t.Errorf("error = %v, want invalid params", err)
+			}
+		})
+	}
+}
@@ -6,29 +6,40 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"sync"
 
+	"opencode_nano/config"
 	"opencode_nano/tools/core"
 )
 
 // EnvTool 环境变量管理工具
 type EnvTool struct {
 	*core.BaseTool
+
+	mu      sync.Mutex
+	envFile string            // 持久化文件路径
+	custom  map[string]string // 通过本工具设置或从持久化文件加载的变量
+	loaded  bool
 }
 
 // NewEnvTool 创建环境变量工具
 func NewEnvTool() *EnvTool {
 	tool := &EnvTool{
 		BaseTool: core.NewBaseTool("env", "system", "Manage environment variables"),
+		envFile:  config.EnvFilePath(),
+		custom:   make(map[string]string),
 	}
 	
+	// 持久化的变量会在之后每次启动时载入（可能改变 API 地址、PATH 等），需要权限
+	tool.SetRequiresPerm(true)
 	tool.SetTags("system", "environment", "config")
 	tool.SetSchema(core.ParameterSchema{
 		Type: "object",
 		Properties: map[string]core.PropertySchema{
 			"action": {
 				Type:        "string",
-				Description: "Action to perform: get, set, list, delete",
-				Enum:        []string{"get", "set", "list", "delete"},
+				Description: "Action to perform: get, set, list, delete, persist, dump",
+				Enum:        []string{"get", "set", "list", "delete", "persist", "dump"},
 			},
 			"name": {
 				Type:        "string",
@@ -43,6 +54,11 @@ func NewEnvTool() *EnvTool {
 				Description: "Pattern to filter variables (for list action)",
 				Default:     "*",
 			},
+			"persist": {
+				Type:        "boolean",
+				Description: "Also save the change to ~/.opencode_nano/env (for set and delete actions)",
+				Default:     false,
+			},
 		},
 		Required: []string{"action"},
 	})
@@ -50,6 +66,67 @@ func NewEnvTool() *EnvTool {
 	return tool
 }
 
+// SetEnvFile 设置持久化文件路径
+func (t *EnvTool) SetEnvFile(path string) *EnvTool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.envFile = path
+	t.loaded = false
+	t.custom = make(map[string]string)
+	return t
+}
+
+// ensureLoaded 首次使用时加载持久化文件中的变量，调用方需持有锁
+func (t *EnvTool) ensureLoaded() error {
+	if t.loaded || t.envFile == "" {
+		return nil
+	}
+	vars, err := config.ReadEnvFile(t.envFile)
+	if err != nil {
+		return err
+	}
+	for name, value := range vars {
+		if _, ok := t.custom[name]; !ok {
+			t.custom[name] = value
+		}
+	}
+	t.loaded = true
+	return nil
+}
+
+// saveLocked 将自定义变量写入持久化文件，调用方需持有锁
+func (t *EnvTool) saveLocked() error {
+	if t.envFile == "" {
+		return fmt.Errorf("no env file path available")
+	}
+	if err := t.ensureLoaded(); err != nil {
+		return err
+	}
+	return config.WriteEnvFile(t.envFile, t.custom)
+}
+
+// NeedsPermission 只有写入持久化文件的调用需要权限：persist 操作，
+// 以及 persist 为 true 的 set 和 delete
+func (t *EnvTool) NeedsPermission(params core.Parameters) bool {
+	action, _ := params.GetString("action")
+	switch action {
+	case "persist":
+		return true
+	case "set", "delete":
+		persist := false
+		if params.Has("persist") {
+			persist, _ = params.GetBool("persist")
+		}
+		return persist
+	}
+	return false
+}
+
+// Mutates 写入持久化文件的调用在 dry run 时只报告不执行
+func (t *EnvTool) Mutates(params core.Parameters) bool {
+	return t.NeedsPermission(params)
+}
+
 // Execute 执行环境变量操作
 func (t *EnvTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
 	// 参数验证
@@ -72,6 +149,10 @@ func (t *EnvTool) Execute(ctx context.Context, params core.Parameters) (core.Res
 		return t.listEnv(params)
 	case "delete":
 		return t.deleteEnv(params)
+	case "persist":
+		return t.persistEnv()
+	case "dump":
+		return t.dumpEnv()
 	default:
 		return nil, core.ErrInvalidParams(t.Info().Name, fmt.Sprintf("unknown action: %s", action))
 	}
@@ -105,14 +186,33 @@ func (t *EnvTool) setEnv(params core.Parameters) (core.Result, error) {
 		return nil, core.ErrInvalidParams(t.Info().Name, "value parameter required for set action")
 	}
 	
+	if !config.ValidEnvName(name) {
+		return nil, core.ErrInvalidParams(t.Info().Name, fmt.Sprintf("invalid environment variable name: %s", name))
+	}
+	
+	persist := false
+	if params.Has("persist") {
+		persist, _ = params.GetBool("persist")
+	}
+	
 	// 设置环境变量
 	if err := os.Setenv(name, value); err != nil {
 		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to set environment variable: %v", err))
 	}
 	
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.custom[name] = value
+	if persist {
+		if err := t.saveLocked(); err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to persist environment variable: %v", err))
+		}
+	}
+	
 	result := core.NewSimpleResult(fmt.Sprintf("Set %s=%s", name, value))
 	result.WithMetadata("name", name)
 	result.WithMetadata("value", value)
+	result.WithMetadata("persisted", persist)
 	
 	return result, nil
 }
@@ -161,14 +261,35 @@ func (t *EnvTool) deleteEnv(params core.Parameters) (core.Result, error) {
 	oldValue := os.Getenv(name)
 	exists := oldValue != ""
 	
+	persist := false
+	if params.Has("persist") {
+		persist, _ = params.GetBool("persist")
+	}
+	
 	// 删除环境变量
 	if err := os.Unsetenv(name); err != nil {
 		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to delete environment variable: %v", err))
 	}
 	
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if persist {
+		// 先加载文件，确保删除的变量不会在保存时被写回
+		if err := t.ensureLoaded(); err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to load env file: %v", err))
+		}
+	}
+	delete(t.custom, name)
+	if persist {
+		if err := t.saveLocked(); err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to persist environment variables: %v", err))
+		}
+	}
+	
 	result := core.NewSimpleResult(fmt.Sprintf("Deleted environment variable: %s", name))
 	result.WithMetadata("name", name)
 	result.WithMetadata("existed", exists)
+	result.WithMetadata("persisted", persist)
 	if exists {
 		result.WithMetadata("old_value", oldValue)
 	}
@@ -176,6 +297,37 @@ func (t *EnvTool) deleteEnv(params core.Parameters) (core.Result, error) {
 	return result, nil
 }
 
+// persistEnv 将所有自定义变量写入持久化文件
+func (t *EnvTool) persistEnv() (core.Result, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	
+	if err := t.saveLocked(); err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to persist environment variables: %v", err))
+	}
+	
+	result := core.NewSimpleResult(fmt.Sprintf("Persisted %d environment variables to %s", len(t.custom), t.envFile))
+	result.WithMetadata("path", t.envFile)
+	result.WithMetadata("count", len(t.custom))
+	
+	return result, nil
+}
+
+// dumpEnv 以 .env 格式导出所有自定义变量
+func (t *EnvTool) dumpEnv() (core.Result, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	
+	if err := t.ensureLoaded(); err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to load env file: %v", err))
+	}
+	
+	result := core.NewSimpleResult(config.FormatEnv(t.custom))
+	result.WithMetadata("count", len(t.custom))
+	
+	return result, nil
+}
+
 // ProcessTool 进程管理工具
 type ProcessTool struct {
 	*core.BaseTool
@@ -203,8 +355,8 @@ func NewProcessTool() *ProcessTool {
 			},
 			"signal": {
 				Type:        "string",
-				Description: "Signal to send (for kill action)",
-				Default:     "TERM",
+				Description: "Signal to send (for kill action): TERM, KILL, HUP, INT, QUIT, USR1, USR2, STOP, CONT",
+				Default:     defaultSignalName,
 			},
 			"pattern": {
 				Type:        "string",
@@ -218,6 +370,12 @@ func NewProcessTool() *ProcessTool {
 	return tool
 }
 
+// Mutates 只有 kill 会影响进程，list 和 info 在 dry run 时照常执行
+func (t *ProcessTool) Mutates(params core.Parameters) bool {
+	action, _ := params.GetString("action")
+	return action == "kill"
+}
+
 // Execute 执行进程操作
 func (t *ProcessTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
 	// 参数验证
@@ -277,7 +435,12 @@ func (t *ProcessTool) getProcessInfo(params core.Parameters) (core.Result, error
 	return result, nil
 }
 
-// killProcess 终止进程
+// normalizeSignalName 统一信号名格式，如 "sigterm" 转为 "TERM"
+func normalizeSignalName(name string) string {
+	return strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(name)), "SIG")
+}
+
+// killProcess 向进程发送信号
 func (t *ProcessTool) killProcess(params core.Parameters) (core.Result, error) {
 	pid, err := params.GetInt("pid")
 	if err != nil {
@@ -295,36 +458,23 @@ func (t *ProcessTool) killProcess(params core.Parameters) (core.Result, error) {
 		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("process not found: %v", err))
 	}
 	
-	// 发送信号（在 Windows 上总是发送 Kill 信号）
-	var signal os.Signal
-	if runtime.GOOS == "windows" {
-		err = process.Kill()
-	} else {
-		// Unix 系统可以发送不同的信号
-		signalName := "TERM"
-		if params.Has("signal") {
-			signalName, _ = params.GetString("signal")
-		}
-		
-		// 这里简化处理，只支持 TERM 和 KILL
-		switch signalName {
-		case "KILL":
-			err = process.Kill()
-		default:
-			err = process.Signal(os.Interrupt)
-		}
-		signal = os.Interrupt
+	// 发送信号，Windows 上只支持 KILL，未指定信号时默认使用 KILL
+	signalName := defaultSignalName
+	if params.Has("signal") {
+		signalName, _ = params.GetString("signal")
 	}
-	
+	signal, err := lookupSignal(signalName)
 	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+	
+	if err := process.Signal(signal); err != nil {
 		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to kill process: %v", err))
 	}
 	
 	result := core.NewSimpleResult(fmt.Sprintf("Successfully sent signal to process %d", pid))
 	result.WithMetadata("pid", pid)
-	if signal != nil {
-		result.WithMetadata("signal", signal.String())
-	}
+	result.WithMetadata("signal", signal.String())
 	
 	return result, nil
 }
\ No newline at end of file
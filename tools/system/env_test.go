@@ -0,0 +1,178 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"opencode_nano/config"
+	"opencode_nano/tools/core"
+)
+
+func TestProcessTool_KillSignals(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix 信号测试")
+	}
+
+	tests := []struct {
+		name       string
+		signal     any
+		wantState  string
+		wantSignal string
+	}{
+		{"默认 TERM", nil, "signal: terminated", "terminated"},
+		{"TERM", "TERM", "signal: terminated", "terminated"},
+		{"带 SIG 前缀的小写名称", "sighup", "signal: hangup", "hangup"},
+		{"USR1", "USR1", "signal: user defined signal 1", "user defined signal 1"},
+		{"KILL", "KILL", "signal: killed", "killed"},
+	}
+
+	tool := NewProcessTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("sleep", "30")
+			if err := cmd.Start(); err != nil {
+				t.Fatalf("启动子进程失败: %v", err)
+			}
+			waitErr := make(chan error, 1)
+			go func() { waitErr <- cmd.Wait() }()
+
+			params := map[string]any{"action": "kill", "pid": cmd.Process.Pid}
+			if tt.signal != nil {
+				params["signal"] = tt.signal
+			}
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(params))
+			if err != nil {
+				cmd.Process.Kill()
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if result.Metadata()["signal"] != tt.wantSignal {
+				t.Errorf("signal = %v, want %s", result.Metadata()["signal"], tt.wantSignal)
+			}
+
+			select {
+			case <-waitErr:
+				if got := cmd.ProcessState.String(); got != tt.wantState {
+					t.Errorf("进程状态 = %q, want %q", got, tt.wantState)
+				}
+			case <-time.After(5 * time.Second):
+				cmd.Process.Kill()
+				t.Fatal("子进程未在信号后退出")
+			}
+		})
+	}
+}
+
+func TestProcessTool_UnsupportedSignal(t *testing.T) {
+	_, err := NewProcessTool().Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"action": "kill",
+		"pid":    99999999,
+		"signal": "BOGUS",
+	}))
+	var toolErr *core.ToolError
+	if !errors.As(err, &toolErr) || toolErr.Code != core.ErrCodeInvalidParams || !strings.Contains(err.Error(), "BOGUS") {
+		t.Errorf("Execute() error = %v, want 不支持的信号错误", err)
+	}
+}
+
+func TestEnvTool_PersistAndDump(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), ".opencode_nano", "env")
+	t.Setenv("OPENCODE_NANO_TEST_A", "")
+	t.Setenv("OPENCODE_NANO_TEST_B", "")
+
+	tool := NewEnvTool().SetEnvFile(envFile)
+	exec := func(tool *EnvTool, params map[string]any) core.Result {
+		t.Helper()
+		result, err := tool.Execute(context.Background(), core.NewMapParameters(params))
+		if err != nil {
+			t.Fatalf("Execute(%v) error = %v", params, err)
+		}
+		return result
+	}
+
+	exec(tool, map[string]any{"action": "set", "name": "OPENCODE_NANO_TEST_A", "value": "one \"1\"", "persist": true})
+	exec(tool, map[string]any{"action": "set", "name": "OPENCODE_NANO_TEST_B", "value": "two"})
+
+	// 未设置 persist 的变量只在 dump 中出现，不写入文件
+	persisted, err := config.ReadEnvFile(envFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"OPENCODE_NANO_TEST_A": "one \"1\""}
+	if !reflect.DeepEqual(persisted, want) {
+		t.Errorf("持久化文件 = %v, want %v", persisted, want)
+	}
+
+	dump := exec(tool, map[string]any{"action": "dump"}).String()
+	dumped, err := config.ParseEnv(dump)
+	if err != nil {
+		t.Fatalf("dump 输出无法解析: %v\n%s", err, dump)
+	}
+	want = map[string]string{"OPENCODE_NANO_TEST_A": "one \"1\"", "OPENCODE_NANO_TEST_B": "two"}
+	if !reflect.DeepEqual(dumped, want) {
+		t.Errorf("dump = %v, want %v", dumped, want)
+	}
+
+	// persist 动作写入全部自定义变量，新的工具实例可以读回
+	exec(tool, map[string]any{"action": "persist"})
+	reloaded := NewEnvTool().SetEnvFile(envFile)
+	dumped, _ = config.ParseEnv(exec(reloaded, map[string]any{"action": "dump"}).String())
+	if !reflect.DeepEqual(dumped, want) {
+		t.Errorf("重新加载后 dump = %v, want %v", dumped, want)
+	}
+
+	// 删除并持久化后文件中不再包含该变量
+	exec(reloaded, map[string]any{"action": "delete", "name": "OPENCODE_NANO_TEST_A", "persist": true})
+	persisted, _ = config.ReadEnvFile(envFile)
+	want = map[string]string{"OPENCODE_NANO_TEST_B": "two"}
+	if !reflect.DeepEqual(persisted, want) {
+		t.Errorf("删除后持久化文件 = %v, want %v", persisted, want)
+	}
+}
+
+func TestEnvTool_SetInvalidName(t *testing.T) {
+	tool := NewEnvTool().SetEnvFile(filepath.Join(t.TempDir(), "env"))
+	_, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+		"action": "set", "name": "BAD-NAME", "value": "x",
+	}))
+	var toolErr *core.ToolError
+	if !errors.As(err, &toolErr) || toolErr.Code != core.ErrCodeInvalidParams {
+		t.Errorf("error = %v, want invalid params", err)
+	}
+}
+
+func TestEnvTool_NeedsPermission(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]any
+		want   bool
+	}{
+		{"get", map[string]any{"action": "get", "name": "HOME"}, false},
+		{"list", map[string]any{"action": "list"}, false},
+		{"dump", map[string]any{"action": "dump"}, false},
+		{"仅设置当前进程", map[string]any{"action": "set", "name": "A", "value": "1"}, false},
+		{"persist 为 false", map[string]any{"action": "set", "name": "A", "value": "1", "persist": false}, false},
+		{"持久化设置", map[string]any{"action": "set", "name": "A", "value": "1", "persist": true}, true},
+		{"持久化删除", map[string]any{"action": "delete", "name": "A", "persist": true}, true},
+		{"persist 动作", map[string]any{"action": "persist"}, true},
+	}
+
+	tool := NewEnvTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := core.NewMapParameters(tt.params)
+			if got := core.NeedsPermission(tool, params); got != tt.want {
+				t.Errorf("NeedsPermission() = %v, want %v", got, tt.want)
+			}
+			if got := core.Mutates(tool, params); got != tt.want {
+				t.Errorf("Mutates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
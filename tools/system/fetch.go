@@ -0,0 +1,251 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"opencode_nano/tools/core"
+)
+
+const (
+	// defaultFetchTimeout 默认的请求超时（秒）
+	defaultFetchTimeout = 30
+	// defaultFetchMaxBytes 默认保留的最大响应字节数
+	defaultFetchMaxBytes = 1024 * 1024
+	// maxFetchRedirects 最多跟随的重定向次数
+	maxFetchRedirects = 10
+)
+
+// FetchTool 通过 HTTP GET 获取 URL 内容的工具。默认拒绝访问本机、内网和云元数据地址
+type FetchTool struct {
+	*core.BaseTool
+	allowPrivate bool
+}
+
+// NewFetchTool 创建 fetch 工具
+func NewFetchTool() *FetchTool {
+	tool := &FetchTool{
+		BaseTool: core.NewBaseTool("fetch", "system", "Fetch the content of an http(s) URL with a GET request"),
+	}
+
+	tool.SetRequiresPerm(true)
+	tool.SetTags("system", "http", "fetch", "url", "download")
+	tool.SetSchema(core.ParameterSchema{
+		Type: "object",
+		Properties: map[string]core.PropertySchema{
+			"url": {
+				Type:        "string",
+				Description: "http or https URL to fetch",
+			},
+			"headers": {
+				Type:        "object",
+				Description: "Extra request headers (e.g., {\"Accept\": \"application/json\"})",
+			},
+			"timeout": {
+				Type:        "integer",
+				Description: "Timeout in seconds (at most 300)",
+				Default:     defaultFetchTimeout,
+				Minimum:     core.Bound(1),
+				Maximum:     core.Bound(300),
+			},
+			"max_bytes": {
+				Type:        "integer",
+				Description: "Maximum number of body bytes to return; the rest is truncated",
+				Default:     defaultFetchMaxBytes,
+				Minimum:     core.Bound(1),
+			},
+		},
+		Required: []string{"url"},
+	})
+
+	return tool
+}
+
+// SetAllowPrivateHosts 设置是否允许访问本机和内网地址
+func (t *FetchTool) SetAllowPrivateHosts(allow bool) {
+	t.allowPrivate = allow
+}
+
+// Mutates GET 请求只读取内容，dry run 时照常执行
+func (t *FetchTool) Mutates(params core.Parameters) bool {
+	return false
+}
+
+// Execute 获取 URL 内容
+func (t *FetchTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	// 参数验证
+	if err := params.Validate(t.Schema()); err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+
+	rawURL, err := params.GetString("url")
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, "invalid url parameter")
+	}
+	target, err := t.checkURL(rawURL)
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+
+	headers := make(map[string]string)
+	if params.Has("headers") {
+		raw, _ := params.Get("headers")
+		headerMap, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, core.ErrInvalidParams(t.Info().Name, "headers must be an object")
+		}
+		for k, v := range headerMap {
+			s, ok := v.(string)
+			if !ok {
+				return nil, core.ErrInvalidParams(t.Info().Name, fmt.Sprintf("header %s must be a string", k))
+			}
+			headers[k] = s
+		}
+	}
+
+	timeout := defaultFetchTimeout
+	if params.Has("timeout") {
+		timeout, _ = params.GetInt("timeout")
+	}
+
+	maxBytes := defaultFetchMaxBytes
+	if params.Has("max_bytes") {
+		maxBytes, _ = params.GetInt("max_bytes")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, fmt.Sprintf("invalid request: %v", err))
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := t.client().Do(req)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, core.ErrTimeout(t.Info().Name)
+		}
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("request failed: %v", err))
+	}
+	defer resp.Body.Close()
+
+	// 多读一个字节用于判断是否超出上限
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(maxBytes)+1))
+	if err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to read response: %v", err))
+	}
+	truncated := len(body) > maxBytes
+	if truncated {
+		body = trimToValidUTF8(body[:maxBytes])
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	var content string
+	if utf8.Valid(body) {
+		content = string(body)
+		if truncated {
+			content += fmt.Sprintf("\n... (truncated at %d bytes)", maxBytes)
+		}
+	} else {
+		content = fmt.Sprintf("[binary content: %d bytes, content-type %q]", len(body), contentType)
+	}
+	// 非 2xx 响应在内容前标明状态，便于模型判断
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		content = fmt.Sprintf("HTTP %s\n\n%s", resp.Status, content)
+	}
+
+	result := core.NewSimpleResult(content)
+	result.WithMetadata("url", resp.Request.URL.String())
+	result.WithMetadata("status_code", resp.StatusCode)
+	result.WithMetadata("content_type", contentType)
+	result.WithMetadata("bytes", len(body))
+	result.WithMetadata("truncated", truncated)
+
+	return result, nil
+}
+
+// checkURL 校验 URL：只允许 http/https，默认拒绝本机和内网的字面地址
+func (t *FetchTool) checkURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported url scheme %q: only http and https are allowed", u.Scheme)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return nil, fmt.Errorf("url has no host")
+	}
+	if t.allowPrivate {
+		return u, nil
+	}
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return nil, fmt.Errorf("refusing to fetch local address %s", host)
+	}
+	if ip := net.ParseIP(host); ip != nil && isPrivateIP(ip) {
+		return nil, fmt.Errorf("refusing to fetch private address %s", host)
+	}
+	return u, nil
+}
+
+// client 创建 HTTP 客户端。连接时检查解析后的实际地址，防止通过域名或重定向访问内网
+func (t *FetchTool) client() *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if !t.allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isPrivateIP(ip) {
+				return fmt.Errorf("refusing to connect to private address %s", host)
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxFetchRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxFetchRedirects)
+			}
+			if _, err := t.checkURL(req.URL.String()); err != nil {
+				return fmt.Errorf("redirect blocked: %v", err)
+			}
+			return nil
+		},
+	}
+}
+
+// isPrivateIP 判断是否为本机、内网、链路本地（含 169.254.169.254 云元数据）或未指定地址
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
+
+// trimToValidUTF8 去掉截断时被切开的末尾多字节字符
+func trimToValidUTF8(b []byte) []byte {
+	for i := 0; i < utf8.UTFMax && len(b) > 0 && !utf8.Valid(b); i++ {
+		b = b[:len(b)-1]
+	}
+	return b
+}
@@ -0,0 +1,115 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+func TestFetchTool(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/spec":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			fmt.Fprint(w, "hello spec")
+		case "/headers":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"token":%q}`, r.Header.Get("X-Token"))
+		case "/large":
+			fmt.Fprint(w, strings.Repeat("a", 100))
+		case "/redirect":
+			http.Redirect(w, r, "/spec", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tool := NewFetchTool()
+	if !tool.Info().RequiresPerm {
+		t.Error("fetch 应需要权限")
+	}
+	// httptest 服务监听在 127.0.0.1，测试中允许访问本机
+	tool.SetAllowPrivateHosts(true)
+
+	tests := []struct {
+		name          string
+		params        map[string]any
+		wantContent   string
+		wantStatus    int
+		wantTruncated bool
+	}{
+		{"获取文本", map[string]any{"url": server.URL + "/spec"}, "hello spec", 200, false},
+		{"自定义请求头", map[string]any{"url": server.URL + "/headers", "headers": map[string]any{"X-Token": "abc"}}, `{"token":"abc"}`, 200, false},
+		{"超出大小上限时截断", map[string]any{"url": server.URL + "/large", "max_bytes": 10}, "aaaaaaaaaa\n... (truncated at 10 bytes)", 200, true},
+		{"跟随重定向", map[string]any{"url": server.URL + "/redirect"}, "hello spec", 200, false},
+		{"非 2xx 标明状态", map[string]any{"url": server.URL + "/missing"}, "HTTP 404 Not Found\n\n404 page not found\n", 404, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(tt.params))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := result.String(); got != tt.wantContent {
+				t.Errorf("内容 = %q, want %q", got, tt.wantContent)
+			}
+			metadata := result.Metadata()
+			if metadata["status_code"] != tt.wantStatus {
+				t.Errorf("status_code = %v, want %d", metadata["status_code"], tt.wantStatus)
+			}
+			if metadata["truncated"] != tt.wantTruncated {
+				t.Errorf("truncated = %v, want %v", metadata["truncated"], tt.wantTruncated)
+			}
+			if _, ok := metadata["content_type"].(string); !ok {
+				t.Errorf("缺少 content_type: %v", metadata)
+			}
+		})
+	}
+}
+
+func TestFetchTool_Guard(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "secret")
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"file 协议", "file:///etc/passwd", "unsupported url scheme"},
+		{"ftp 协议", "ftp://example.com/a.txt", "unsupported url scheme"},
+		{"localhost", "http://localhost:8080/", "refusing to fetch local address"},
+		{"回环地址", server.URL, "refusing to fetch private address"},
+		{"云元数据地址", "http://169.254.169.254/latest/meta-data/", "refusing to fetch private address"},
+		{"内网地址", "http://10.0.0.1/", "refusing to fetch private address"},
+		{"IPv6 回环", "http://[::1]/", "refusing to fetch private address"},
+	}
+
+	tool := NewFetchTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{"url": tt.url}))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Execute(%s) error = %v, want %q", tt.url, err, tt.want)
+			}
+		})
+	}
+
+	// 绕过 URL 检查（如域名解析到本机地址）时，连接阶段仍会拒绝
+	resp, err := tool.client().Get(server.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("连接本机地址应被拒绝")
+	}
+	if !strings.Contains(err.Error(), "refusing to connect to private address") {
+		t.Errorf("连接错误 = %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package system
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"opencode_nano/tools/core"
+)
+
+// GoParseTool 用 go/parser 检查 Go 源码语法的工具，不启动外部进程
+type GoParseTool struct {
+	*core.BaseTool
+}
+
+// NewGoParseTool 创建 go_parse 工具
+func NewGoParseTool() *GoParseTool {
+	tool := &GoParseTool{
+		BaseTool: core.NewBaseTool("go_parse", "system", "Check Go source for syntax errors without compiling, optionally returning gofmt-formatted source"),
+	}
+
+	tool.SetTags("system", "go", "syntax", "gofmt", "check")
+	tool.SetSchema(core.ParameterSchema{
+		Type: "object",
+		Properties: map[string]core.PropertySchema{
+			"path": {
+				Type:        "string",
+				Description: "Go source file to check (use either path or content)",
+			},
+			"content": {
+				Type:        "string",
+				Description: "Inline Go source to check (use either path or content)",
+			},
+			"format": {
+				Type:        "boolean",
+				Description: "Return the gofmt-formatted source when the syntax is valid",
+				Default:     false,
+			},
+		},
+	})
+
+	return tool
+}
+
+// Execute 解析 Go 源码并报告语法错误
+func (t *GoParseTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	// 参数验证
+	if err := params.Validate(t.Schema()); err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+
+	hasPath, hasContent := params.Has("path"), params.Has("content")
+	if hasPath == hasContent {
+		return nil, core.ErrInvalidParams(t.Info().Name, "exactly one of path or content is required")
+	}
+
+	var src []byte
+	filename := "input.go"
+	if hasPath {
+		path, err := params.GetString("path")
+		if err != nil {
+			return nil, core.ErrInvalidParams(t.Info().Name, "invalid path parameter")
+		}
+		filename = filepath.Clean(path)
+		src, err = os.ReadFile(filename)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("file not found: %s", filename))
+			}
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to read file: %v", err))
+		}
+	} else {
+		content, err := params.GetString("content")
+		if err != nil {
+			return nil, core.ErrInvalidParams(t.Info().Name, "invalid content parameter")
+		}
+		src = []byte(content)
+	}
+
+	formatSource := false
+	if params.Has("format") {
+		formatSource, _ = params.GetBool("format")
+	}
+
+	fset := token.NewFileSet()
+	_, err := parser.ParseFile(fset, filename, src, parser.AllErrors|parser.ParseComments)
+	if err != nil {
+		syntaxErrors := goSyntaxErrors(err)
+		lines := make([]string, 0, len(syntaxErrors))
+		for _, e := range syntaxErrors {
+			lines = append(lines, fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message))
+		}
+
+		result := core.NewSimpleResult(fmt.Sprintf("%d syntax error(s):\n%s", len(syntaxErrors), strings.Join(lines, "\n")))
+		result.WithMetadata("path", filename)
+		result.WithMetadata("valid", false)
+		result.WithMetadata("errors", syntaxErrors)
+		return result, nil
+	}
+
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to format source: %v", err))
+	}
+	needsFormat := !bytes.Equal(formatted, src)
+
+	output := "ok"
+	if needsFormat {
+		output = "ok (not gofmt-formatted)"
+	}
+	if formatSource {
+		output = string(formatted)
+	}
+
+	result := core.NewSimpleResult(output)
+	result.WithMetadata("path", filename)
+	result.WithMetadata("valid", true)
+	result.WithMetadata("needs_format", needsFormat)
+
+	return result, nil
+}
+
+// GoSyntaxError 一条语法错误
+type GoSyntaxError struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+// goSyntaxErrors 将解析错误转换为带行列号的错误列表
+func goSyntaxErrors(err error) []GoSyntaxError {
+	var list scanner.ErrorList
+	if !errors.As(err, &list) {
+		return []GoSyntaxError{{Message: err.Error()}}
+	}
+	// 同一行只保留第一条，避免后续的连锁错误淹没真正的问题
+	list.Sort()
+	list.RemoveMultiples()
+	result := make([]GoSyntaxError, 0, len(list))
+	for _, e := range list {
+		result = append(result, GoSyntaxError{
+			File:    e.Pos.Filename,
+			Line:    e.Pos.Line,
+			Column:  e.Pos.Column,
+			Message: e.Msg,
+		})
+	}
+	return result
+}
@@ -0,0 +1,82 @@
+package system
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+func TestGoParseTool(t *testing.T) {
+	tmpDir := t.TempDir()
+	validPath := filepath.Join(tmpDir, "valid.go")
+	os.WriteFile(validPath, []byte("package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n"), 0644)
+	brokenPath := filepath.Join(tmpDir, "broken.go")
+	os.WriteFile(brokenPath, []byte("package main\n\nfunc main() {\n\tx := \n}\n\nfunc f( {\n}\n"), 0644)
+
+	tests := []struct {
+		name       string
+		params     map[string]any
+		want       string
+		wantValid  bool
+		wantErrors []string
+	}{
+		{
+			name:      "语法正确",
+			params:    map[string]any{"path": validPath},
+			want:      "ok",
+			wantValid: true,
+		},
+		{
+			name:      "未格式化的源码",
+			params:    map[string]any{"content": "package p\nfunc  f()  {}\n"},
+			want:      "ok (not gofmt-formatted)",
+			wantValid: true,
+		},
+		{
+			name:      "返回格式化结果",
+			params:    map[string]any{"content": "package p\nfunc  f()  {}\n", "format": true},
+			want:      "package p\n\nfunc f() {}\n",
+			wantValid: true,
+		},
+		{
+			name:       "语法错误带行列号",
+			params:     map[string]any{"path": brokenPath},
+			wantValid:  false,
+			wantErrors: []string{"2 syntax error(s)", brokenPath + ":5:1: expected operand", brokenPath + ":8:3: expected ';'"},
+		},
+	}
+
+	tool := NewGoParseTool()
+	if tool.Info().RequiresPerm {
+		t.Error("go_parse 只读，不应需要权限")
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(tt.params))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if result.Metadata()["valid"] != tt.wantValid {
+				t.Errorf("valid = %v, want %v", result.Metadata()["valid"], tt.wantValid)
+			}
+			output := result.String()
+			if tt.want != "" && output != tt.want {
+				t.Errorf("Execute() = %q, want %q", output, tt.want)
+			}
+			for _, want := range tt.wantErrors {
+				if !strings.Contains(output, want) {
+					t.Errorf("输出缺少 %q:\n%s", want, output)
+				}
+			}
+		})
+	}
+
+	if _, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{})); err == nil {
+		t.Error("缺少 path 和 content 时应返回错误")
+	}
+}
@@ -0,0 +1,264 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"opencode_nano/tools/core"
+)
+
+// GoSymbolsTool 用 go/ast 列出或定位 Go 声明（函数、方法、类型、变量、常量）的工具
+type GoSymbolsTool struct {
+	*core.BaseTool
+}
+
+// NewGoSymbolsTool 创建 go_symbols 工具
+func NewGoSymbolsTool() *GoSymbolsTool {
+	tool := &GoSymbolsTool{
+		BaseTool: core.NewBaseTool("go_symbols", "system", "List Go declarations (funcs, methods, types, vars, consts) in a file or package directory, or find where a symbol is defined"),
+	}
+
+	tool.SetTags("system", "go", "symbol", "definition", "navigate")
+	tool.SetSchema(core.ParameterSchema{
+		Type: "object",
+		Properties: map[string]core.PropertySchema{
+			"path": {
+				Type:        "string",
+				Description: "Go file or package directory (not recursive)",
+				Default:     ".",
+			},
+			"symbol": {
+				Type:        "string",
+				Description: "Only return declarations with this name; use Type.Method for a specific method",
+			},
+			"kind": {
+				Type:        "string",
+				Description: "Only return declarations of this kind",
+				Enum:        []string{"func", "method", "type", "var", "const"},
+			},
+			"include_tests": {
+				Type:        "boolean",
+				Description: "Include _test.go files when path is a directory",
+				Default:     false,
+			},
+		},
+	})
+
+	return tool
+}
+
+// GoSymbol 一个顶层声明
+type GoSymbol struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind"`
+	Receiver string `json:"receiver,omitempty"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// QualifiedName 返回带接收者类型的名称，如 Agent.RunOnce
+func (s GoSymbol) QualifiedName() string {
+	if s.Receiver == "" {
+		return s.Name
+	}
+	return strings.TrimPrefix(s.Receiver, "*") + "." + s.Name
+}
+
+// Execute 列出或查找声明
+func (t *GoSymbolsTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	// 参数验证
+	if err := params.Validate(t.Schema()); err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+
+	path := "."
+	if params.Has("path") {
+		path, _ = params.GetString("path")
+	}
+	path = filepath.Clean(path)
+
+	symbol := ""
+	if params.Has("symbol") {
+		symbol, _ = params.GetString("symbol")
+		symbol = strings.TrimSpace(symbol)
+	}
+
+	kind := ""
+	if params.Has("kind") {
+		kind, _ = params.GetString("kind")
+	}
+
+	includeTests := false
+	if params.Has("include_tests") {
+		includeTests, _ = params.GetBool("include_tests")
+	}
+
+	files, err := goSourceFiles(path, includeTests)
+	if err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+	}
+
+	var symbols []GoSymbol
+	var parseErrors []string
+	fset := token.NewFileSet()
+	for _, file := range files {
+		select {
+		case <-ctx.Done():
+			return nil, core.ErrCancelled(t.Info().Name)
+		default:
+		}
+
+		node, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			// 单个文件时直接报错；目录中的文件解析失败时跳过并记录
+			if len(files) == 1 {
+				return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to parse %s: %v", file, err))
+			}
+			parseErrors = append(parseErrors, err.Error())
+			continue
+		}
+		for _, s := range fileSymbols(fset, file, node) {
+			if matchSymbol(s, symbol, kind) {
+				symbols = append(symbols, s)
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(symbols))
+	for _, s := range symbols {
+		lines = append(lines, fmt.Sprintf("%s:%d %s %s", s.File, s.Line, s.Kind, s.QualifiedName()))
+	}
+	output := strings.Join(lines, "\n")
+	if len(symbols) == 0 {
+		if symbol != "" {
+			output = fmt.Sprintf("No declaration found for %s in %s", symbol, path)
+		} else {
+			output = fmt.Sprintf("No declarations found in %s", path)
+		}
+	}
+	if len(parseErrors) > 0 {
+		output += fmt.Sprintf("\n(skipped %d file(s) with syntax errors)", len(parseErrors))
+	}
+
+	result := core.NewSimpleResult(output)
+	result.WithMetadata("path", path)
+	result.WithMetadata("symbols", symbols)
+	result.WithMetadata("count", len(symbols))
+	if symbol != "" {
+		result.WithMetadata("found", len(symbols) > 0)
+	}
+	if len(parseErrors) > 0 {
+		result.WithMetadata("parse_errors", parseErrors)
+	}
+
+	return result, nil
+}
+
+// goSourceFiles 返回 path 对应的 Go 源文件；path 为目录时列出其中的 .go 文件（不递归）
+func goSourceFiles(path string, includeTests bool) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("path not found: %s", path)
+		}
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") {
+			continue
+		}
+		if !includeTests && strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		files = append(files, filepath.Join(path, name))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// fileSymbols 列出文件中的顶层声明
+func fileSymbols(fset *token.FileSet, file string, node *ast.File) []GoSymbol {
+	var symbols []GoSymbol
+	add := func(name *ast.Ident, kind, receiver string) {
+		if name.Name == "_" {
+			return
+		}
+		symbols = append(symbols, GoSymbol{
+			Name:     name.Name,
+			Kind:     kind,
+			Receiver: receiver,
+			File:     file,
+			Line:     fset.Position(name.Pos()).Line,
+		})
+	}
+
+	for _, decl := range node.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				add(d.Name, "method", receiverType(d.Recv.List[0].Type))
+			} else {
+				add(d.Name, "func", "")
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					add(s.Name, "type", "")
+				case *ast.ValueSpec:
+					kind := "var"
+					if d.Tok == token.CONST {
+						kind = "const"
+					}
+					for _, name := range s.Names {
+						add(name, kind, "")
+					}
+				}
+			}
+		}
+	}
+	return symbols
+}
+
+// receiverType 返回接收者的类型名，如 *Agent、List[T] 记为 List
+func receiverType(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return "*" + receiverType(e.X)
+	case *ast.Ident:
+		return e.Name
+	case *ast.IndexExpr:
+		return receiverType(e.X)
+	case *ast.IndexListExpr:
+		return receiverType(e.X)
+	}
+	return ""
+}
+
+// matchSymbol 判断声明是否符合名称和类别过滤条件
+func matchSymbol(s GoSymbol, symbol, kind string) bool {
+	if kind != "" && s.Kind != kind {
+		return false
+	}
+	if symbol == "" {
+		return true
+	}
+	return s.Name == symbol || s.QualifiedName() == strings.TrimPrefix(symbol, "*")
+}
@@ -0,0 +1,161 @@
+package system
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+// writeSymbolFixture 写入一个小的测试包
+func writeSymbolFixture(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"store.go": `package store
+
+const DefaultLimit = 10
+
+var ErrNotFound, errClosed = errNotFound(), error(nil)
+
+type Store struct{ items map[string]string }
+
+type List[T any] []T
+
+func New() *Store { return &Store{} }
+
+func (s *Store) Get(key string) string { return s.items[key] }
+
+func (l List[T]) Len() int { return len(l) }
+`,
+		"cache.go": `package store
+
+type Cache struct{}
+
+func (c Cache) Get(key string) string { return "" }
+
+func errNotFound() error { return nil }
+`,
+		"store_test.go": `package store
+
+func helperForTests() {}
+`,
+		"broken.go": `package store
+
+func broken( {
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestGoSymbolsTool(t *testing.T) {
+	dir := writeSymbolFixture(t)
+	storeFile := filepath.Join(dir, "store.go")
+	cacheFile := filepath.Join(dir, "cache.go")
+
+	tests := []struct {
+		name   string
+		params map[string]any
+		want   []GoSymbol
+	}{
+		{
+			name:   "列出单个文件的声明",
+			params: map[string]any{"path": storeFile},
+			want: []GoSymbol{
+				{Name: "DefaultLimit", Kind: "const", File: storeFile, Line: 3},
+				{Name: "ErrNotFound", Kind: "var", File: storeFile, Line: 5},
+				{Name: "errClosed", Kind: "var", File: storeFile, Line: 5},
+				{Name: "Store", Kind: "type", File: storeFile, Line: 7},
+				{Name: "List", Kind: "type", File: storeFile, Line: 9},
+				{Name: "New", Kind: "func", File: storeFile, Line: 11},
+				{Name: "Get", Kind: "method", Receiver: "*Store", File: storeFile, Line: 13},
+				{Name: "Len", Kind: "method", Receiver: "List", File: storeFile, Line: 15},
+			},
+		},
+		{
+			name:   "在包目录中按名称查找",
+			params: map[string]any{"path": dir, "symbol": "Get"},
+			want: []GoSymbol{
+				{Name: "Get", Kind: "method", Receiver: "Cache", File: cacheFile, Line: 5},
+				{Name: "Get", Kind: "method", Receiver: "*Store", File: storeFile, Line: 13},
+			},
+		},
+		{
+			name:   "按接收者限定方法",
+			params: map[string]any{"path": dir, "symbol": "Store.Get"},
+			want: []GoSymbol{
+				{Name: "Get", Kind: "method", Receiver: "*Store", File: storeFile, Line: 13},
+			},
+		},
+		{
+			name:   "按类别过滤",
+			params: map[string]any{"path": dir, "kind": "type"},
+			want: []GoSymbol{
+				{Name: "Cache", Kind: "type", File: cacheFile, Line: 3},
+				{Name: "Store", Kind: "type", File: storeFile, Line: 7},
+				{Name: "List", Kind: "type", File: storeFile, Line: 9},
+			},
+		},
+		{
+			name:   "包含测试文件",
+			params: map[string]any{"path": dir, "symbol": "helperForTests", "include_tests": true},
+			want: []GoSymbol{
+				{Name: "helperForTests", Kind: "func", File: filepath.Join(dir, "store_test.go"), Line: 3},
+			},
+		},
+	}
+
+	tool := NewGoSymbolsTool()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(tt.params))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			got := result.Metadata()["symbols"].([]GoSymbol)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("symbols = %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoSymbolsTool_Output(t *testing.T) {
+	dir := writeSymbolFixture(t)
+	tool := NewGoSymbolsTool()
+
+	result, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{"path": dir, "symbol": "New"}))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	output := result.String()
+	if !strings.HasPrefix(output, filepath.Join(dir, "store.go")+":11 func New") {
+		t.Errorf("输出 = %q", output)
+	}
+	// 目录中有语法错误的文件会被跳过并提示
+	if !strings.Contains(output, "skipped 1 file(s) with syntax errors") {
+		t.Errorf("输出缺少跳过提示: %q", output)
+	}
+
+	result, err = tool.Execute(context.Background(), core.NewMapParameters(map[string]any{"path": dir, "symbol": "Missing"}))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Metadata()["found"] != false || !strings.HasPrefix(result.String(), "No declaration found for Missing") {
+		t.Errorf("未找到时的结果 = %q, %v", result.String(), result.Metadata()["found"])
+	}
+
+	// 单个文件有语法错误时返回错误
+	if _, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{"path": filepath.Join(dir, "broken.go")})); err == nil {
+		t.Error("解析失败的文件应返回错误")
+	}
+}
@@ -0,0 +1,58 @@
+package system
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// readMemoryInfo 通过 sysctl 和 vm_stat 读取总内存和可用内存（字节）
+func readMemoryInfo() (total, available uint64, err error) {
+	output, err := exec.Command("sysctl", "-n", "hw.memsize").Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to run sysctl: %v", err)
+	}
+	total, err = strconv.ParseUint(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to parse hw.memsize: %v", err)
+	}
+
+	output, err = exec.Command("vm_stat").Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to run vm_stat: %v", err)
+	}
+	return total, parseVMStat(string(output)), nil
+}
+
+// parseVMStat 解析 vm_stat 输出，以空闲、非活跃和可回收页之和作为可用内存
+func parseVMStat(output string) uint64 {
+	lines := strings.Split(output, "\n")
+	pageSize := uint64(4096)
+	// 首行形如 "Mach Virtual Memory Statistics: (page size of 16384 bytes)"
+	if len(lines) > 0 {
+		if _, rest, ok := strings.Cut(lines[0], "page size of "); ok {
+			if fields := strings.Fields(rest); len(fields) > 0 {
+				if size, err := strconv.ParseUint(fields[0], 10, 64); err == nil {
+					pageSize = size
+				}
+			}
+		}
+	}
+
+	var pages uint64
+	for _, line := range lines[1:] {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(name) {
+		case "Pages free", "Pages inactive", "Pages speculative", "Pages purgeable":
+			n, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimSpace(value), "."), 10, 64)
+			if err == nil {
+				pages += n
+			}
+		}
+	}
+	return pages * pageSize
+}
@@ -0,0 +1,54 @@
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// readMemoryInfo 从 /proc/meminfo 读取总内存和可用内存（字节）
+func readMemoryInfo() (total, available uint64, err error) {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read /proc/meminfo: %v", err)
+	}
+	defer file.Close()
+
+	values := make(map[string]uint64)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// 格式如 "MemTotal:       16318412 kB"
+		name, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		if len(fields) > 1 && fields[1] == "kB" {
+			value *= 1024
+		}
+		values[name] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, fmt.Errorf("failed to read /proc/meminfo: %v", err)
+	}
+
+	total, ok := values["MemTotal"]
+	if !ok {
+		return 0, 0, fmt.Errorf("MemTotal not found in /proc/meminfo")
+	}
+	available, ok = values["MemAvailable"]
+	if !ok {
+		// 旧内核没有 MemAvailable，用空闲内存和缓存近似
+		available = values["MemFree"] + values["Buffers"] + values["Cached"]
+	}
+	return total, available, nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin
+
+package system
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// readMemoryInfo 其他平台暂不支持读取内存信息
+func readMemoryInfo() (total, available uint64, err error) {
+	return 0, 0, fmt.Errorf("memory info not supported on %s", runtime.GOOS)
+}
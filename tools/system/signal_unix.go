@@ -0,0 +1,34 @@
+//go:build !windows
+
+package system
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// defaultSignalName 未指定信号时使用的信号
+const defaultSignalName = "TERM"
+
+// unixSignals 支持发送的信号
+var unixSignals = map[string]syscall.Signal{
+	"TERM": syscall.SIGTERM,
+	"KILL": syscall.SIGKILL,
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+	"STOP": syscall.SIGSTOP,
+	"CONT": syscall.SIGCONT,
+}
+
+// lookupSignal 根据信号名（如 TERM、SIGHUP）查找信号
+func lookupSignal(name string) (os.Signal, error) {
+	sig, ok := unixSignals[normalizeSignalName(name)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported signal: %s", name)
+	}
+	return sig, nil
+}
@@ -0,0 +1,19 @@
+//go:build windows
+
+package system
+
+import (
+	"fmt"
+	"os"
+)
+
+// defaultSignalName 未指定信号时使用的信号
+const defaultSignalName = "KILL"
+
+// lookupSignal Windows 上只支持 KILL
+func lookupSignal(name string) (os.Signal, error) {
+	if normalizeSignalName(name) != "KILL" {
+		return nil, fmt.Errorf("signal %s is not supported on Windows (only KILL)", name)
+	}
+	return os.Kill, nil
+}
@@ -0,0 +1,85 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"opencode_nano/tools/core"
+)
+
+// SysInfoTool 系统信息工具，帮助选择适合当前平台的命令
+type SysInfoTool struct {
+	*core.BaseTool
+}
+
+// NewSysInfoTool 创建系统信息工具
+func NewSysInfoTool() *SysInfoTool {
+	tool := &SysInfoTool{
+		BaseTool: core.NewBaseTool("sysinfo", "system", "Show OS, architecture, CPU count, Go version, hostname, working directory and memory"),
+	}
+
+	tool.SetTags("system", "info", "platform")
+	tool.SetSchema(core.ParameterSchema{
+		Type:       "object",
+		Properties: map[string]core.PropertySchema{},
+	})
+
+	return tool
+}
+
+// Execute 收集系统信息
+func (t *SysInfoTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	hostname, _ := os.Hostname()
+	workDir, _ := os.Getwd()
+
+	lines := []string{
+		fmt.Sprintf("os: %s", runtime.GOOS),
+		fmt.Sprintf("arch: %s", runtime.GOARCH),
+		fmt.Sprintf("num_cpu: %d", runtime.NumCPU()),
+		fmt.Sprintf("go_version: %s", runtime.Version()),
+		fmt.Sprintf("hostname: %s", hostname),
+		fmt.Sprintf("working_dir: %s", workDir),
+	}
+
+	// 内存信息依赖平台，获取失败时省略
+	total, available, err := readMemoryInfo()
+	if err == nil {
+		lines = append(lines,
+			fmt.Sprintf("memory_total: %s", formatMemorySize(total)),
+			fmt.Sprintf("memory_available: %s", formatMemorySize(available)),
+		)
+	} else {
+		lines = append(lines, fmt.Sprintf("memory: unavailable (%v)", err))
+	}
+
+	result := core.NewSimpleResult(strings.Join(lines, "\n"))
+	result.WithMetadata("os", runtime.GOOS)
+	result.WithMetadata("arch", runtime.GOARCH)
+	result.WithMetadata("num_cpu", runtime.NumCPU())
+	result.WithMetadata("go_version", runtime.Version())
+	result.WithMetadata("hostname", hostname)
+	result.WithMetadata("working_dir", workDir)
+	if err == nil {
+		result.WithMetadata("memory_total", total)
+		result.WithMetadata("memory_available", available)
+	}
+
+	return result, nil
+}
+
+// formatMemorySize 将字节数格式化为易读的形式
+func formatMemorySize(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := uint64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,43 @@
+package system
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+func TestSysInfoTool(t *testing.T) {
+	tool := NewSysInfoTool()
+	if tool.Info().RequiresPerm {
+		t.Error("sysinfo 只读取信息，不应需要权限")
+	}
+
+	result, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{}))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	metadata := result.Metadata()
+	for _, key := range []string{"os", "arch", "num_cpu", "go_version", "hostname", "working_dir"} {
+		if _, ok := metadata[key]; !ok {
+			t.Errorf("缺少元数据 %s", key)
+		}
+	}
+	if metadata["os"] != runtime.GOOS || metadata["arch"] != runtime.GOARCH {
+		t.Errorf("os/arch = %v/%v", metadata["os"], metadata["arch"])
+	}
+
+	// Linux 和 macOS 上应能读取内存信息
+	if runtime.GOOS == "linux" || runtime.GOOS == "darwin" {
+		total, ok := metadata["memory_total"].(uint64)
+		if !ok || total == 0 {
+			t.Errorf("memory_total = %v", metadata["memory_total"])
+		}
+		available, ok := metadata["memory_available"].(uint64)
+		if !ok || available > total {
+			t.Errorf("memory_available = %v, total = %v", metadata["memory_available"], total)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"opencode_nano/tools/core"
+)
+
+// WhichTool 查找可执行文件路径的工具
+type WhichTool struct {
+	*core.BaseTool
+}
+
+// NewWhichTool 创建 which 工具
+func NewWhichTool() *WhichTool {
+	tool := &WhichTool{
+		BaseTool: core.NewBaseTool("which", "system", "Check whether executables are available in PATH and resolve their absolute paths"),
+	}
+
+	tool.SetTags("system", "which", "path", "executable")
+	tool.SetSchema(core.ParameterSchema{
+		Type: "object",
+		Properties: map[string]core.PropertySchema{
+			"name": {
+				Type:        "string",
+				Description: "Executable name to look up (e.g., 'go')",
+			},
+			"names": {
+				Type:        "array",
+				Description: "Several executable names to look up at once",
+				Items:       &core.PropertySchema{Type: "string"},
+			},
+		},
+	})
+
+	return tool
+}
+
+// WhichResult 单个可执行文件的查找结果
+type WhichResult struct {
+	Name  string `json:"name"`
+	Path  string `json:"path"`
+	Found bool   `json:"found"`
+}
+
+// Execute 查找可执行文件
+func (t *WhichTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	// 参数验证
+	if err := params.Validate(t.Schema()); err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+
+	var names []string
+	if params.Has("name") {
+		name, err := params.GetString("name")
+		if err != nil {
+			return nil, core.ErrInvalidParams(t.Info().Name, "invalid name parameter")
+		}
+		names = append(names, name)
+	}
+	if params.Has("names") {
+		extra, err := params.GetStringSlice("names")
+		if err != nil {
+			return nil, core.ErrInvalidParams(t.Info().Name, "names must be an array of strings")
+		}
+		names = append(names, extra...)
+	}
+	if len(names) == 0 {
+		return nil, core.ErrInvalidParams(t.Info().Name, "name or names parameter required")
+	}
+
+	results := make([]WhichResult, 0, len(names))
+	lines := make([]string, 0, len(names))
+	foundCount := 0
+	for _, name := range names {
+		result := lookupExecutable(name)
+		results = append(results, result)
+		if result.Found {
+			foundCount++
+			lines = append(lines, fmt.Sprintf("%s: %s", name, result.Path))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s: not found", name))
+		}
+	}
+
+	result := core.NewSimpleResult(strings.Join(lines, "\n"))
+	result.WithMetadata("results", results)
+	result.WithMetadata("found_count", foundCount)
+	result.WithMetadata("found", foundCount == len(results))
+	if len(results) == 1 {
+		result.WithMetadata("name", results[0].Name)
+		result.WithMetadata("path", results[0].Path)
+	}
+
+	return result, nil
+}
+
+// lookupExecutable 在 PATH 中查找可执行文件并返回绝对路径
+func lookupExecutable(name string) WhichResult {
+	result := WhichResult{Name: name}
+	if strings.TrimSpace(name) == "" {
+		return result
+	}
+
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return result
+	}
+	if absPath, err := filepath.Abs(path); err == nil {
+		path = absPath
+	}
+
+	result.Path = path
+	result.Found = true
+	return result
+}
@@ -0,0 +1,66 @@
+package system
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"opencode_nano/tools/core"
+)
+
+func TestWhichTool(t *testing.T) {
+	present := "sh"
+	if runtime.GOOS == "windows" {
+		present = "cmd"
+	}
+	const missing = "opencode-nano-definitely-missing-binary"
+
+	tests := []struct {
+		name      string
+		params    map[string]any
+		wantFound bool
+		wantCount int
+		wantErr   bool
+	}{
+		{"存在的命令", map[string]any{"name": present}, true, 1, false},
+		{"不存在的命令", map[string]any{"name": missing}, false, 0, false},
+		{"同时查找多个", map[string]any{"names": []any{present, missing}}, false, 1, false},
+		{"name 与 names 合并", map[string]any{"name": present, "names": []any{present}}, true, 2, false},
+		{"缺少参数", map[string]any{}, false, 0, true},
+	}
+
+	tool := NewWhichTool()
+	if tool.Info().RequiresPerm {
+		t.Error("which 不应需要权限")
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(tt.params))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			metadata := result.Metadata()
+			if metadata["found"] != tt.wantFound {
+				t.Errorf("found = %v, want %v", metadata["found"], tt.wantFound)
+			}
+			if metadata["found_count"] != tt.wantCount {
+				t.Errorf("found_count = %v, want %d", metadata["found_count"], tt.wantCount)
+			}
+
+			for _, r := range metadata["results"].([]WhichResult) {
+				if r.Found && !filepath.IsAbs(r.Path) {
+					t.Errorf("%s 的路径不是绝对路径: %s", r.Name, r.Path)
+				}
+				if !r.Found && r.Path != "" {
+					t.Errorf("%s 未找到却返回了路径: %s", r.Name, r.Path)
+				}
+			}
+		})
+	}
+}
@@ -2,8 +2,10 @@ package task
 
 import (
 	"context"
+	"encoding/csv"
 	"fmt"
 	"strings"
+	"time"
 
 	"opencode_nano/session"
 	"opencode_nano/tools/core"
@@ -22,12 +24,20 @@ func NewTaskTool() (*TaskTool, error) {
 	if err != nil {
 		return nil, err
 	}
-	
+	return newTaskTool(storage)
+}
+
+// newTaskTool 使用指定存储创建任务工具，并载入已保存的任务，
+// 保证列表与存储一致，clear 等整体保存不会丢掉之前会话的任务
+func newTaskTool(storage session.Storage) (*TaskTool, error) {
 	// 创建管理器
 	manager := session.NewTodoManager(storage)
+	if err := manager.Load(); err != nil {
+		return nil, err
+	}
 	
 	tool := &TaskTool{
-		BaseTool: core.NewBaseTool("todo", "development", "Manage session todo list. Support operations: list, add, update."),
+		BaseTool: core.NewBaseTool("todo", "development", "Manage session todo list. Support operations: list, add, update, delete, clear, search, export, bulk_update."),
 		manager:  manager,
 	}
 	
@@ -38,11 +48,20 @@ func NewTaskTool() (*TaskTool, error) {
 			"action": {
 				Type:        "string",
 				Description: "Action to perform",
-				Enum:        []string{"list", "add", "update"},
+				Enum:        []string{"list", "add", "update", "delete", "clear", "search", "export", "bulk_update"},
 			},
 			"id": {
 				Type:        "string",
-				Description: "Task ID (required for update)",
+				Description: "Task ID (required for update and delete)",
+			},
+			"ids": {
+				Type:        "array",
+				Description: "Task IDs for bulk_update (array or comma-separated string)",
+			},
+			"filter_status": {
+				Type:        "string",
+				Description: "Select tasks with this status for bulk_update when ids is omitted",
+				Enum:        []string{"pending", "in_progress", "completed"},
 			},
 			"content": {
 				Type:        "string",
@@ -50,15 +69,58 @@ func NewTaskTool() (*TaskTool, error) {
 			},
 			"status": {
 				Type:        "string",
-				Description: "Task status",
+				Description: "Task status (for update; new tasks start as pending)",
 				Enum:        []string{"pending", "in_progress", "completed"},
-				Default:     "pending",
 			},
 			"priority": {
 				Type:        "string",
-				Description: "Task priority",
+				Description: "Task priority (defaults to medium for add; unchanged on update if omitted)",
 				Enum:        []string{"low", "medium", "high"},
-				Default:     "medium",
+			},
+			"due": {
+				Type:        "string",
+				Description: "Due date for add/update: RFC3339, YYYY-MM-DD, or relative like +30m, +2h, +3d, +1w (use \"none\" to clear on update)",
+			},
+			"tags": {
+				Type:        "array",
+				Description: "Tags for add/update, as an array or comma-separated string (empty to clear on update)",
+			},
+			"filter_tag": {
+				Type:        "array",
+				Description: "Only list tasks having all of these tags (array or comma-separated string)",
+			},
+			"progress": {
+				Type:        "integer",
+				Description: "Completion percentage 0-100 (update only; completing a task sets it to 100)",
+				Minimum:     core.Bound(0),
+				Maximum:     core.Bound(100),
+			},
+			"notes": {
+				Type:        "string",
+				Description: "Free-form notes for update (empty to clear)",
+			},
+			"parent": {
+				Type:        "string",
+				Description: "Parent task ID to create a subtask (add/update; use \"none\" to detach on update)",
+			},
+			"tree": {
+				Type:        "boolean",
+				Description: "List subtasks indented under their parent",
+				Default:     false,
+			},
+			"query": {
+				Type:        "string",
+				Description: "Text to search for in task content and tags (required for search)",
+			},
+			"format": {
+				Type:        "string",
+				Description: "Output format for export (default: markdown)",
+				Enum:        []string{"markdown", "csv"},
+			},
+			"sort_by": {
+				Type:        "string",
+				Description: "Sort order for list (default: priority)",
+				Enum:        []string{"priority", "due"},
 			},
 		},
 		Required: []string{"action"},
@@ -67,6 +129,16 @@ func NewTaskTool() (*TaskTool, error) {
 	return tool, nil
 }
 
+// Mutates 除 list、search、export 外的操作都会写入存储，dry run 时只报告不执行
+func (t *TaskTool) Mutates(params core.Parameters) bool {
+	action, _ := params.GetString("action")
+	switch action {
+	case "list", "search", "export":
+		return false
+	}
+	return true
+}
+
 // Execute 执行任务操作
 func (t *TaskTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
 	// 参数验证
@@ -87,6 +159,16 @@ func (t *TaskTool) Execute(ctx context.Context, params core.Parameters) (core.Re
 		return t.addTask(params)
 	case "update":
 		return t.updateTask(params)
+	case "delete":
+		return t.deleteTask(params)
+	case "clear":
+		return t.clearTasks()
+	case "search":
+		return t.searchTasks(params)
+	case "export":
+		return t.exportTasks(params)
+	case "bulk_update":
+		return t.bulkUpdateTasks(params)
 	default:
 		return nil, core.ErrInvalidParams(t.Info().Name, fmt.Sprintf("unknown action: %s", action))
 	}
@@ -95,6 +177,19 @@ func (t *TaskTool) Execute(ctx context.Context, params core.Parameters) (core.Re
 // listTasks 列出任务
 func (t *TaskTool) listTasks(params core.Parameters) (core.Result, error) {
 	todos := t.manager.List()
+	if sortBy, _ := params.GetString("sort_by"); sortBy == "due" {
+		todos = t.manager.ListByDue()
+	}
+	if params.Has("filter_tag") {
+		wanted := session.NormalizeTags(listParam(params, "filter_tag"))
+		filtered := todos[:0]
+		for _, todo := range todos {
+			if todo.HasTags(wanted...) {
+				filtered = append(filtered, todo)
+			}
+		}
+		todos = filtered
+	}
 	
 	if len(todos) == 0 {
 		return core.NewSimpleResult("No todos found."), nil
@@ -105,7 +200,146 @@ func (t *TaskTool) listTasks(params core.Parameters) (core.Result, error) {
 	output.WriteString("📋 Todo List:\n")
 	output.WriteString("================\n")
 	
-	for i, todo := range todos {
+	// 树形模式下子任务缩进显示在父任务之下
+	tree, _ := params.GetBool("tree")
+	writeTodos(&output, todos, tree)
+	
+	// 统计信息
+	counts := t.manager.Count()
+	output.WriteString("\n📊 Summary:\n")
+	output.WriteString(fmt.Sprintf("• Pending: %d\n", counts[session.StatusPending]))
+	output.WriteString(fmt.Sprintf("• In Progress: %d\n", counts[session.StatusInProgress]))
+	output.WriteString(fmt.Sprintf("• Completed: %d\n", counts[session.StatusCompleted]))
+	
+	return core.NewSimpleResult(output.String()), nil
+}
+
+// searchTasks 按内容和标签搜索任务
+func (t *TaskTool) searchTasks(params core.Parameters) (core.Result, error) {
+	query, _ := params.GetString("query")
+	if strings.TrimSpace(query) == "" {
+		return nil, core.ErrInvalidParams(t.Info().Name, "query parameter required")
+	}
+	
+	todos := t.manager.Search(query)
+	
+	var output strings.Builder
+	if len(todos) == 0 {
+		output.WriteString(fmt.Sprintf("No todos matching %q.", query))
+	} else {
+		output.WriteString(fmt.Sprintf("🔍 %d todo(s) matching %q:\n", len(todos), query))
+		writeTodos(&output, todos, false)
+	}
+	
+	result := core.NewSimpleResult(output.String())
+	result.WithMetadata("count", len(todos))
+	
+	return result, nil
+}
+
+// exportTasks 将任务导出为 Markdown 或 CSV
+func (t *TaskTool) exportTasks(params core.Parameters) (core.Result, error) {
+	format, _ := params.GetString("format")
+	todos := t.manager.List()
+	
+	var output string
+	switch format {
+	case "", "markdown":
+		format = "markdown"
+		output = exportMarkdown(todos)
+	case "csv":
+		data, err := exportCSV(todos)
+		if err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to export: %v", err))
+		}
+		output = data
+	default:
+		return nil, core.ErrInvalidParams(t.Info().Name, fmt.Sprintf("unknown format: %s", format))
+	}
+	
+	result := core.NewSimpleResult(output)
+	result.WithMetadata("format", format)
+	result.WithMetadata("count", len(todos))
+	
+	return result, nil
+}
+
+// exportMarkdown 按状态分组输出 Markdown 清单，组内保持 List 的顺序
+func exportMarkdown(todos []*session.TodoItem) string {
+	groups := []struct {
+		status session.TodoStatus
+		title  string
+	}{
+		{session.StatusInProgress, "In Progress"},
+		{session.StatusPending, "Pending"},
+		{session.StatusCompleted, "Completed"},
+	}
+	
+	var output strings.Builder
+	output.WriteString("# Todos\n")
+	for _, group := range groups {
+		var lines []string
+		for _, todo := range todos {
+			if todo.Status != group.status {
+				continue
+			}
+			checkbox := "[ ]"
+			if todo.Status == session.StatusCompleted {
+				checkbox = "[x]"
+			}
+			lines = append(lines, fmt.Sprintf("- %s %s (%s, `%s`)", checkbox, todo.Content, todo.Priority, todo.ID))
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		output.WriteString(fmt.Sprintf("\n## %s\n\n", group.title))
+		output.WriteString(strings.Join(lines, "\n") + "\n")
+	}
+	
+	return output.String()
+}
+
+// exportCSV 输出 id,content,status,priority,created_at 列
+func exportCSV(todos []*session.TodoItem) (string, error) {
+	var output strings.Builder
+	writer := csv.NewWriter(&output)
+	
+	if err := writer.Write([]string{"id", "content", "status", "priority", "created_at"}); err != nil {
+		return "", err
+	}
+	for _, todo := range todos {
+		record := []string{
+			todo.ID,
+			todo.Content,
+			string(todo.Status),
+			string(todo.Priority),
+			todo.CreatedAt.Format(time.RFC3339),
+		}
+		if err := writer.Write(record); err != nil {
+			return "", err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", err
+	}
+	
+	return output.String(), nil
+}
+
+// writeTodos 输出编号的任务列表，tree 为 true 时子任务缩进显示
+func writeTodos(output *strings.Builder, todos []*session.TodoItem, tree bool) {
+	nodes := make([]session.TreeNode, len(todos))
+	if tree {
+		nodes = session.Tree(todos)
+	} else {
+		for i, todo := range todos {
+			nodes[i] = session.TreeNode{Item: todo}
+		}
+	}
+	
+	for i, node := range nodes {
+		todo := node.Item
 		statusSymbol := map[session.TodoStatus]string{
 			session.StatusPending:    "⏳",
 			session.StatusInProgress: "🔄",
@@ -118,18 +352,21 @@ func (t *TaskTool) listTasks(params core.Parameters) (core.Result, error) {
 			session.PriorityLow:    "🟢",
 		}[todo.Priority]
 		
-		output.WriteString(fmt.Sprintf("%d. %s %s [%s] %s\n", 
-			i+1, statusSymbol, prioritySymbol, todo.ID, todo.Content))
+		line := fmt.Sprintf("%s%d. %s %s [%s] %s", strings.Repeat("  ", node.Depth), i+1, statusSymbol, prioritySymbol, todo.ID, todo.Content)
+		if progress := todo.ProgressLabel(); progress != "" {
+			line += " " + progress
+		}
+		if due := todo.DueLabel(); due != "" {
+			line += " " + due
+		}
+		if tags := todo.TagsLabel(); tags != "" {
+			line += " " + tags
+		}
+		output.WriteString(line + "\n")
+		if todo.Notes != "" {
+			output.WriteString(fmt.Sprintf("%s   📝 %s\n", strings.Repeat("  ", node.Depth), todo.Notes))
+		}
 	}
-	
-	// 统计信息
-	counts := t.manager.Count()
-	output.WriteString("\n📊 Summary:\n")
-	output.WriteString(fmt.Sprintf("• Pending: %d\n", counts[session.StatusPending]))
-	output.WriteString(fmt.Sprintf("• In Progress: %d\n", counts[session.StatusInProgress]))
-	output.WriteString(fmt.Sprintf("• Completed: %d\n", counts[session.StatusCompleted]))
-	
-	return core.NewSimpleResult(output.String()), nil
 }
 
 // addTask 添加任务
@@ -147,15 +384,39 @@ func (t *TaskTool) addTask(params core.Parameters) (core.Result, error) {
 		}
 	}
 	
+	// 获取截止时间（可选）
+	due, err := t.parseDue(params)
+	if err != nil {
+		return nil, err
+	}
+	
+	// 获取父任务（可选）
+	parentID, _ := params.GetString("parent")
+	if parentID != "" {
+		if _, err := t.manager.Get(parentID); err != nil {
+			return nil, core.ErrInvalidParams(t.Info().Name, fmt.Sprintf("parent task not found: %s", parentID))
+		}
+	}
+	
 	// 创建任务
 	todo, err := t.manager.Add(content, priority)
 	if err != nil {
 		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to add task: %v", err))
 	}
-	
-	// 保存
-	if err := t.manager.Save(); err != nil {
-		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to save: %v", err))
+	if due != nil {
+		if todo, err = t.manager.SetDue(todo.ID, due); err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+		}
+	}
+	if params.Has("tags") {
+		if todo, err = t.manager.SetTags(todo.ID, listParam(params, "tags")); err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+		}
+	}
+	if parentID != "" {
+		if todo, err = t.manager.SetParent(todo.ID, parentID); err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+		}
 	}
 	
 	result := core.NewSimpleResult(fmt.Sprintf("✅ Todo added successfully:\n%s", todo.String()))
@@ -199,19 +460,190 @@ func (t *TaskTool) updateTask(params core.Parameters) (core.Result, error) {
 		content, _ = params.GetString("content")
 	}
 	
+	due, err := t.parseDue(params)
+	if err != nil {
+		return nil, err
+	}
+	
+	// 先检查父任务，避免形成环时其他字段已被修改
+	if params.Has("parent") {
+		parentID, _ := params.GetString("parent")
+		if parentID == "none" {
+			parentID = ""
+		}
+		if _, err := t.manager.SetParent(id, parentID); err != nil {
+			return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+		}
+	}
+	
+	// 进度在状态之前设置，以便 status=completed 时进度为 100
+	if params.Has("progress") {
+		progress, err := params.GetInt("progress")
+		if err != nil {
+			return nil, core.ErrInvalidParams(t.Info().Name, "progress must be an integer")
+		}
+		if _, err := t.manager.SetProgress(id, progress); err != nil {
+			return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+		}
+	}
+	
 	// 执行更新
 	updatedTodo, err := t.manager.Update(id, status, content, priority)
 	if err != nil {
 		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to update task: %v", err))
 	}
+	if params.Has("due") {
+		if updatedTodo, err = t.manager.SetDue(id, due); err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+		}
+	}
+	if params.Has("tags") {
+		if updatedTodo, err = t.manager.SetTags(id, listParam(params, "tags")); err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+		}
+	}
+	if params.Has("notes") {
+		notes, _ := params.GetString("notes")
+		if updatedTodo, err = t.manager.SetNotes(id, notes); err != nil {
+			return nil, core.ErrExecutionFailed(t.Info().Name, err.Error())
+		}
+	}
 	
-	// 保存
-	if err := t.manager.Save(); err != nil {
-		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to save: %v", err))
+	message := fmt.Sprintf("✅ Todo updated successfully:\n%s", updatedTodo.String())
+	if updatedTodo.Notes != "" {
+		message += "\n📝 " + updatedTodo.Notes
+	}
+	var incomplete []*session.TodoItem
+	if updatedTodo.Status == session.StatusCompleted {
+		incomplete = t.manager.IncompleteDescendants(id)
+	}
+	if len(incomplete) > 0 {
+		message += fmt.Sprintf("\n⚠️ %d subtask(s) are not completed yet:", len(incomplete))
+		for _, child := range incomplete {
+			message += "\n  " + child.String()
+		}
+	}
+	
+	result := core.NewSimpleResult(message)
+	result.WithMetadata("id", id)
+	result.WithMetadata("incomplete_subtasks", len(incomplete))
+	
+	return result, nil
+}
+
+// parseDue 解析可选的 due 参数，未设置或为 "none" 时返回 nil
+func (t *TaskTool) parseDue(params core.Parameters) (*time.Time, error) {
+	if !params.Has("due") {
+		return nil, nil
+	}
+	
+	value, _ := params.GetString("due")
+	if value = strings.TrimSpace(value); value == "" || value == "none" {
+		return nil, nil
 	}
 	
-	result := core.NewSimpleResult(fmt.Sprintf("✅ Todo updated successfully:\n%s", updatedTodo.String()))
+	due, err := session.ParseDue(value, time.Now())
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, err.Error())
+	}
+	return &due, nil
+}
+
+// listParam 获取列表参数，支持数组或逗号分隔的字符串，忽略空项
+func listParam(params core.Parameters, key string) []string {
+	var values []string
+	if value, _ := params.Get(key); value != nil {
+		if s, ok := value.(string); ok {
+			values = strings.Split(s, ",")
+		}
+	}
+	if values == nil {
+		values, _ = params.GetStringSlice(key)
+	}
+	
+	var result []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// bulkUpdateTasks 批量更新任务状态或优先级，按 ids 或 filter_status 选择任务
+func (t *TaskTool) bulkUpdateTasks(params core.Parameters) (core.Result, error) {
+	status, _ := params.GetString("status")
+	priority, _ := params.GetString("priority")
+	if status == "" && priority == "" {
+		return nil, core.ErrInvalidParams(t.Info().Name, "status or priority parameter required")
+	}
+	
+	var ids []string
+	if params.Has("ids") {
+		ids = listParam(params, "ids")
+	} else if filter, _ := params.GetString("filter_status"); filter != "" {
+		for _, todo := range t.manager.ListByStatus(session.TodoStatus(filter)) {
+			ids = append(ids, todo.ID)
+		}
+	} else {
+		return nil, core.ErrInvalidParams(t.Info().Name, "ids or filter_status parameter required")
+	}
+	
+	updated, missing, err := t.manager.UpdateMany(ids, session.TodoStatus(status), session.TodoPriority(priority))
+	if err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to update tasks: %v", err))
+	}
+	
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("✅ Updated %d todo(s)", len(updated)))
+	if len(updated) > 0 {
+		output.WriteString(":\n")
+		writeTodos(&output, updated, false)
+	} else {
+		output.WriteString("\n")
+	}
+	if len(missing) > 0 {
+		output.WriteString(fmt.Sprintf("⚠️ Not found: %s\n", strings.Join(missing, ", ")))
+	}
+	
+	result := core.NewSimpleResult(strings.TrimRight(output.String(), "\n"))
+	result.WithMetadata("updated", len(updated))
+	result.WithMetadata("not_found", missing)
+	
+	return result, nil
+}
+
+// deleteTask 删除任务
+func (t *TaskTool) deleteTask(params core.Parameters) (core.Result, error) {
+	id, err := params.GetString("id")
+	if err != nil {
+		return nil, core.ErrInvalidParams(t.Info().Name, "id parameter required")
+	}
+	
+	todo, err := t.manager.Get(id)
+	if err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("task not found: %s", id))
+	}
+	
+	if err := t.manager.Delete(id); err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to delete task: %v", err))
+	}
+	
+	result := core.NewSimpleResult(fmt.Sprintf("🗑️ Todo deleted successfully:\n%s", todo.String()))
 	result.WithMetadata("id", id)
 	
 	return result, nil
-}
\ No newline at end of file
+}
+
+// clearTasks 清空所有任务
+func (t *TaskTool) clearTasks() (core.Result, error) {
+	count := len(t.manager.List())
+	if err := t.manager.Clear(); err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to save: %v", err))
+	}
+	
+	result := core.NewSimpleResult(fmt.Sprintf("🧹 Cleared %d todos", count))
+	result.WithMetadata("cleared", count)
+	
+	return result, nil
+}
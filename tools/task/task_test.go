@@ -2,6 +2,7 @@ package task
 
 import (
 	"context"
+	"encoding/csv"
 	"opencode_nano/session"
 	"opencode_nano/tools/core"
 	"os"
@@ -235,7 +236,7 @@ func TestTaskTool(t *testing.T) {
 		
 		// Invalid action
 		params = core.NewMapParameters(map[string]any{
-			"action": "delete", // Not supported anymore
+			"action": "archive",
 		})
 		_, err = tool.Execute(context.Background(), params)
 		if err == nil {
@@ -324,7 +325,482 @@ func TestTaskTool(t *testing.T) {
 		}
 	})
 
-	// Test 9: Schema validation
+	// Test 9: Delete and clear todos
+	t.Run("DeleteAndClear", func(t *testing.T) {
+		addResult, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+			"action":  "add",
+			"content": "Task to delete",
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		todoID := addResult.Metadata()["id"].(string)
+		
+		// Delete the todo
+		result, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+			"action": "delete",
+			"id":     todoID,
+		}))
+		if err != nil {
+			t.Fatalf("Failed to delete todo: %v", err)
+		}
+		if !strings.Contains(result.String(), "Task to delete") {
+			t.Errorf("Delete result should contain the deleted todo, got: %s", result.String())
+		}
+		if _, err := tool.manager.Get(todoID); err == nil {
+			t.Error("Todo still exists after delete")
+		}
+		
+		// Delete again: non-existent ID
+		_, err = tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+			"action": "delete",
+			"id":     todoID,
+		}))
+		if err == nil {
+			t.Error("Expected error for deleting non-existent ID")
+		}
+		
+		// Delete without ID
+		_, err = tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+			"action": "delete",
+		}))
+		if err == nil {
+			t.Error("Expected error for delete without ID")
+		}
+		
+		// Clear all todos
+		remaining := len(tool.manager.List())
+		result, err = tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+			"action": "clear",
+		}))
+		if err != nil {
+			t.Fatalf("Failed to clear todos: %v", err)
+		}
+		if result.Metadata()["cleared"] != remaining {
+			t.Errorf("Cleared count = %v, want %d", result.Metadata()["cleared"], remaining)
+		}
+		
+		// The cleared state should be persisted
+		reloaded := session.NewTodoManager(session.NewFileStorage(storagePath))
+		if err := reloaded.Load(); err != nil {
+			t.Fatal(err)
+		}
+		if len(reloaded.List()) != 0 {
+			t.Errorf("Expected no todos after clear, got %d", len(reloaded.List()))
+		}
+	})
+
+	// Test 10: Due dates
+	t.Run("DueDates", func(t *testing.T) {
+		addResult, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+			"action":  "add",
+			"content": "Task with due",
+			"due":     "+2d",
+		}))
+		if err != nil {
+			t.Fatalf("Failed to add todo with due: %v", err)
+		}
+		if !strings.Contains(addResult.String(), "⏰ due") {
+			t.Errorf("Add result should show due date, got: %s", addResult.String())
+		}
+		todoID := addResult.Metadata()["id"].(string)
+		
+		tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+			"action":  "add",
+			"content": "Task without due",
+		}))
+		
+		// sort_by=due puts tasks with due dates first
+		result, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+			"action":  "list",
+			"sort_by": "due",
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		output := result.String()
+		if strings.Index(output, "Task with due") > strings.Index(output, "Task without due") {
+			t.Errorf("Tasks with due dates should be listed first:\n%s", output)
+		}
+		
+		// Clear the due date
+		result, err = tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+			"action": "update",
+			"id":     todoID,
+			"due":    "none",
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(result.String(), "⏰") {
+			t.Errorf("Due date should be cleared, got: %s", result.String())
+		}
+		
+		// Invalid due date
+		_, err = tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+			"action":  "add",
+			"content": "Bad due",
+			"due":     "someday",
+		}))
+		if err == nil {
+			t.Error("Expected error for invalid due date")
+		}
+	})
+
+	// Test 11: Tags
+	t.Run("Tags", func(t *testing.T) {
+		tool.manager.Clear()
+		
+		add := func(content string, tags any) string {
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+				"action":  "add",
+				"content": content,
+				"tags":    tags,
+			}))
+			if err != nil {
+				t.Fatalf("Failed to add todo with tags: %v", err)
+			}
+			return result.String()
+		}
+		
+		if out := add("Build API", "backend, urgent"); !strings.Contains(out, "#backend #urgent") {
+			t.Errorf("Add result should show tags, got: %s", out)
+		}
+		add("Migrate DB", []any{"backend"})
+		add("Write docs", []any{"docs", "urgent"})
+		
+		list := func(filter any) string {
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+				"action":     "list",
+				"filter_tag": filter,
+			}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return result.String()
+		}
+		
+		output := list("backend")
+		if !strings.Contains(output, "Build API") || !strings.Contains(output, "Migrate DB") || strings.Contains(output, "Write docs") {
+			t.Errorf("filter_tag=backend returned:\n%s", output)
+		}
+		
+		// Multiple tags are ANDed
+		output = list([]any{"backend", "urgent"})
+		if !strings.Contains(output, "Build API") || strings.Contains(output, "Migrate DB") || strings.Contains(output, "Write docs") {
+			t.Errorf("filter_tag=[backend urgent] returned:\n%s", output)
+		}
+		
+		if output = list("frontend"); !strings.Contains(output, "No todos found") {
+			t.Errorf("filter_tag=frontend returned:\n%s", output)
+		}
+	})
+
+	// Test 12: Subtasks
+	t.Run("Subtasks", func(t *testing.T) {
+		tool.manager.Clear()
+		
+		add := func(params map[string]any) string {
+			params["action"] = "add"
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(params))
+			if err != nil {
+				t.Fatalf("Failed to add todo: %v", err)
+			}
+			return result.Metadata()["id"].(string)
+		}
+		
+		parentID := add(map[string]any{"content": "Parent task", "priority": "low"})
+		childID := add(map[string]any{"content": "Child task", "priority": "high", "parent": parentID})
+		add(map[string]any{"content": "Other task", "priority": "medium"})
+		
+		result, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+			"action": "list",
+			"tree":   true,
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		output := result.String()
+		parentLine := strings.Index(output, "Parent task")
+		childLine := strings.Index(output, "  3. ")
+		if parentLine < 0 || childLine < parentLine || !strings.Contains(output[childLine:], "Child task") {
+			t.Errorf("Child should be indented under parent:\n%s", output)
+		}
+		
+		// Completing a parent warns about incomplete subtasks
+		result, err = tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+			"action": "update",
+			"id":     parentID,
+			"status": "completed",
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Metadata()["incomplete_subtasks"] != 1 || !strings.Contains(result.String(), "Child task") {
+			t.Errorf("Expected warning about incomplete subtask, got: %s", result.String())
+		}
+		
+		// Cycle guard
+		_, err = tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+			"action": "update",
+			"id":     parentID,
+			"parent": childID,
+		}))
+		if err == nil {
+			t.Error("Expected error when making a task its own ancestor")
+		}
+		
+		// Unknown parent
+		_, err = tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+			"action":  "add",
+			"content": "Orphan",
+			"parent":  "non-existent-id",
+		}))
+		if err == nil {
+			t.Error("Expected error for non-existent parent")
+		}
+	})
+
+	// Test 13: Search
+	t.Run("Search", func(t *testing.T) {
+		tool.manager.Clear()
+		for _, params := range []map[string]any{
+			{"content": "Fix login bug", "tags": "backend"},
+			{"content": "Update README", "tags": "docs"},
+			{"content": "Refactor Login page", "tags": "frontend"},
+		} {
+			params["action"] = "add"
+			if _, err := tool.Execute(context.Background(), core.NewMapParameters(params)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		
+		tests := []struct {
+			query string
+			want  []string
+		}{
+			{"LOGIN", []string{"Fix login bug", "Refactor Login page"}},
+			{"docs", []string{"Update README"}},
+			{"#front", []string{"Refactor Login page"}},
+			{"deploy", nil},
+		}
+		for _, tt := range tests {
+			result, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+				"action": "search",
+				"query":  tt.query,
+			}))
+			if err != nil {
+				t.Fatalf("search %q failed: %v", tt.query, err)
+			}
+			if result.Metadata()["count"] != len(tt.want) {
+				t.Errorf("search %q count = %v, want %d", tt.query, result.Metadata()["count"], len(tt.want))
+			}
+			for _, content := range tt.want {
+				if !strings.Contains(result.String(), content) {
+					t.Errorf("search %q result missing %q:\n%s", tt.query, content, result.String())
+				}
+			}
+			if len(tt.want) == 0 && !strings.Contains(result.String(), "No todos matching") {
+				t.Errorf("search %q should report no matches, got: %s", tt.query, result.String())
+			}
+		}
+		
+		// Search without query
+		_, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+			"action": "search",
+		}))
+		if err == nil {
+			t.Error("Expected error for search without query")
+		}
+	})
+
+	// Test 14: Progress and notes
+	t.Run("ProgressNotes", func(t *testing.T) {
+		tool.manager.Clear()
+		todo, _ := tool.manager.Add("Write report", session.PriorityMedium)
+		
+		result, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+			"action":   "update",
+			"id":       todo.ID,
+			"progress": 60,
+			"notes":    "Waiting on numbers",
+		}))
+		if err != nil {
+			t.Fatalf("Update progress failed: %v", err)
+		}
+		if !strings.Contains(result.String(), "60%") || !strings.Contains(result.String(), "Waiting on numbers") {
+			t.Errorf("Update result should show progress and notes, got: %s", result.String())
+		}
+		
+		for _, progress := range []int{-5, 150} {
+			_, err = tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+				"action":   "update",
+				"id":       todo.ID,
+				"progress": progress,
+			}))
+			if err == nil {
+				t.Errorf("Expected error for progress %d", progress)
+			}
+		}
+		if todo.Progress != 60 {
+			t.Errorf("Invalid progress should not change the task, got %d", todo.Progress)
+		}
+		
+		_, err = tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+			"action": "update",
+			"id":     todo.ID,
+			"status": "completed",
+		}))
+		if err != nil {
+			t.Fatalf("Complete failed: %v", err)
+		}
+		if todo.Progress != 100 {
+			t.Errorf("Completing should set progress to 100, got %d", todo.Progress)
+		}
+		
+		result, _ = tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+			"action": "list",
+		}))
+		if !strings.Contains(result.String(), "📝 Waiting on numbers") {
+			t.Errorf("List should show notes, got: %s", result.String())
+		}
+	})
+
+	// Test 15: Bulk update
+	t.Run("BulkUpdate", func(t *testing.T) {
+		tool.manager.Clear()
+		a, _ := tool.manager.Add("Task A", session.PriorityLow)
+		b, _ := tool.manager.Add("Task B", session.PriorityLow)
+		c, _ := tool.manager.Add("Task C", session.PriorityLow)
+		
+		// By id list, with one unknown id
+		result, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+			"action": "bulk_update",
+			"ids":    []any{a.ID, b.ID, "missing-id"},
+			"status": "in_progress",
+		}))
+		if err != nil {
+			t.Fatalf("Bulk update by ids failed: %v", err)
+		}
+		if result.Metadata()["updated"] != 2 {
+			t.Errorf("Expected 2 updated, got %v", result.Metadata()["updated"])
+		}
+		if !strings.Contains(result.String(), "missing-id") {
+			t.Errorf("Result should list the missing id, got: %s", result.String())
+		}
+		if a.Status != session.StatusInProgress || b.Status != session.StatusInProgress || c.Status != session.StatusPending {
+			t.Errorf("Unexpected statuses: %s %s %s", a.Status, b.Status, c.Status)
+		}
+		
+		// By status filter
+		result, err = tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+			"action":        "bulk_update",
+			"filter_status": "in_progress",
+			"status":        "completed",
+			"priority":      "high",
+		}))
+		if err != nil {
+			t.Fatalf("Bulk update by filter failed: %v", err)
+		}
+		if result.Metadata()["updated"] != 2 {
+			t.Errorf("Expected 2 updated, got %v", result.Metadata()["updated"])
+		}
+		for _, todo := range []*session.TodoItem{a, b} {
+			if todo.Status != session.StatusCompleted || todo.Priority != session.PriorityHigh {
+				t.Errorf("Task %s = %s/%s, want completed/high", todo.ID, todo.Status, todo.Priority)
+			}
+		}
+		if c.Status != session.StatusPending || c.Priority != session.PriorityLow {
+			t.Error("Task outside the filter should be unchanged")
+		}
+		
+		// Missing selection or target
+		for _, params := range []map[string]any{
+			{"action": "bulk_update", "status": "completed"},
+			{"action": "bulk_update", "ids": []any{a.ID}},
+		} {
+			if _, err := tool.Execute(context.Background(), core.NewMapParameters(params)); err == nil {
+				t.Errorf("Expected error for %v", params)
+			}
+		}
+	})
+
+	// Test 16: Export
+	t.Run("Export", func(t *testing.T) {
+		tool.manager.Clear()
+		done, _ := tool.manager.Add("Write docs", session.PriorityLow)
+		tool.manager.Update(done.ID, session.StatusCompleted, "", "")
+		active, _ := tool.manager.Add("Fix bug", session.PriorityHigh)
+		tool.manager.Update(active.ID, session.StatusInProgress, "", "")
+		tricky, _ := tool.manager.Add(`Review "auth, session" changes`, session.PriorityMedium)
+		
+		result, err := tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+			"action": "export",
+		}))
+		if err != nil {
+			t.Fatalf("Export markdown failed: %v", err)
+		}
+		markdown := result.String()
+		for _, want := range []string{
+			"- [x] Write docs",
+			"- [ ] Fix bug",
+			`- [ ] Review "auth, session" changes`,
+			"## In Progress",
+			"## Pending",
+			"## Completed",
+		} {
+			if !strings.Contains(markdown, want) {
+				t.Errorf("Markdown export missing %q:\n%s", want, markdown)
+			}
+		}
+		if strings.Index(markdown, "## In Progress") > strings.Index(markdown, "## Completed") {
+			t.Errorf("Markdown export should list in-progress before completed:\n%s", markdown)
+		}
+		
+		result, err = tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+			"action": "export",
+			"format": "csv",
+		}))
+		if err != nil {
+			t.Fatalf("Export csv failed: %v", err)
+		}
+		records, err := csv.NewReader(strings.NewReader(result.String())).ReadAll()
+		if err != nil {
+			t.Fatalf("Export csv is not valid CSV: %v\n%s", err, result.String())
+		}
+		if len(records) != 4 {
+			t.Fatalf("Expected header and 3 rows, got %d", len(records))
+		}
+		if strings.Join(records[0], ",") != "id,content,status,priority,created_at" {
+			t.Errorf("Unexpected CSV header: %v", records[0])
+		}
+		found := false
+		for _, record := range records[1:] {
+			if record[0] == tricky.ID {
+				found = true
+				if record[1] != tricky.Content {
+					t.Errorf("CSV content = %q, want %q", record[1], tricky.Content)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("CSV export missing %s", tricky.ID)
+		}
+		if !strings.Contains(result.String(), `"Review ""auth, session"" changes"`) {
+			t.Errorf("CSV export should quote content with commas and quotes:\n%s", result.String())
+		}
+		
+		// Unknown format
+		_, err = tool.Execute(context.Background(), core.NewMapParameters(map[string]any{
+			"action": "export",
+			"format": "xml",
+		}))
+		if err == nil {
+			t.Error("Expected error for unknown export format")
+		}
+	})
+
+	// Test 17: Schema validation
 	t.Run("SchemaValidation", func(t *testing.T) {
 		schema := tool.Schema()
 		
@@ -335,14 +811,19 @@ func TestTaskTool(t *testing.T) {
 		
 		// Check action enum
 		actionProp := schema.Properties["action"]
-		if len(actionProp.Enum) != 3 {
-			t.Error("Action should have exactly 3 options")
+		if len(actionProp.Enum) != 8 {
+			t.Error("Action should have exactly 8 options")
 		}
 		
 		expectedActions := map[string]bool{
-			"list":   true,
-			"add":    true,
-			"update": true,
+			"list":        true,
+			"add":         true,
+			"update":      true,
+			"delete":      true,
+			"clear":       true,
+			"search":      true,
+			"export":      true,
+			"bulk_update": true,
 		}
 		
 		for _, action := range actionProp.Enum {
@@ -364,7 +845,7 @@ func TestTaskTool(t *testing.T) {
 		}
 	})
 
-	// Test 10: Tool info
+	// Test 18: Tool info
 	t.Run("ToolInfo", func(t *testing.T) {
 		info := tool.Info()
 		
@@ -376,8 +857,8 @@ func TestTaskTool(t *testing.T) {
 			t.Errorf("Expected category 'development', got '%s'", info.Category)
 		}
 		
-		if !strings.Contains(info.Description, "list, add, update") {
-			t.Error("Description should mention the three supported operations")
+		if !strings.Contains(info.Description, "list, add, update, delete, clear") {
+			t.Error("Description should mention the supported operations")
 		}
 		
 		// Check tags
@@ -394,4 +875,104 @@ func TestTaskTool(t *testing.T) {
 			}
 		}
 	})
-}
\ No newline at end of file
+}
+func TestNewTaskTool_LoadsSavedTodos(t *testing.T) {
+	storagePath := filepath.Join(t.TempDir(), "todos.json")
+	storage := session.NewFileStorage(storagePath)
+	previous := session.NewTodoManager(storage)
+	if _, err := previous.Add("earlier session task", session.PriorityHigh); err != nil {
+		t.Fatal(err)
+	}
+
+	tool, err := newTaskTool(session.NewFileStorage(storagePath))
+	if err != nil {
+		t.Fatalf("newTaskTool() error = %v", err)
+	}
+	exec := func(params map[string]any) string {
+		t.Helper()
+		result, err := tool.Execute(context.Background(), core.NewMapParameters(params))
+		if err != nil {
+			t.Fatalf("Execute(%v) error = %v", params, err)
+		}
+		return result.String()
+	}
+
+	// 之前会话保存的任务可以列出
+	if out := exec(map[string]any{"action": "list"}); !strings.Contains(out, "earlier session task") {
+		t.Errorf("list 应包含之前保存的任务:\n%s", out)
+	}
+
+	// 存储文件与列表保持一致
+	exec(map[string]any{"action": "add", "content": "new task"})
+	items, err := storage.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || len(tool.manager.List()) != 2 {
+		t.Errorf("存储中有 %d 项、列表中有 %d 项, want 2", len(items), len(tool.manager.List()))
+	}
+}
+
+func TestNewTaskTool_InvalidStorage(t *testing.T) {
+	storagePath := filepath.Join(t.TempDir(), "todos.json")
+	if err := os.WriteFile(storagePath, []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newTaskTool(session.NewFileStorage(storagePath)); err == nil {
+		t.Error("存储文件无法解析时应返回错误，避免之后的保存覆盖它")
+	}
+}
+
+func TestTaskTool_DryRun(t *testing.T) {
+	storagePath := filepath.Join(t.TempDir(), "todos.json")
+	storage := session.NewFileStorage(storagePath)
+	existing, err := session.NewTodoManager(storage).Add("keep me", session.PriorityMedium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.ReadFile(storagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tool, err := newTaskTool(storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dryRun := core.WithDryRun(tool)
+
+	tests := []struct {
+		name       string
+		params     map[string]any
+		wantDryRun bool
+	}{
+		{"add", map[string]any{"action": "add", "content": "new task"}, true},
+		{"update", map[string]any{"action": "update", "id": existing.ID, "status": "completed"}, true},
+		{"delete", map[string]any{"action": "delete", "id": existing.ID}, true},
+		{"bulk_update", map[string]any{"action": "bulk_update", "ids": []any{existing.ID}, "status": "completed"}, true},
+		{"clear", map[string]any{"action": "clear"}, true},
+		{"list 照常执行", map[string]any{"action": "list"}, false},
+		{"search 照常执行", map[string]any{"action": "search", "query": "keep"}, false},
+		{"export 照常执行", map[string]any{"action": "export"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := dryRun.Execute(context.Background(), core.NewMapParameters(tt.params))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := result.Metadata()["dry_run"] == true; got != tt.wantDryRun {
+				t.Errorf("dry_run = %v, want %v", got, tt.wantDryRun)
+			}
+			after, _ := os.ReadFile(storagePath)
+			if string(after) != string(before) {
+				t.Errorf("dry run 修改了存储文件:\n%s", after)
+			}
+		})
+	}
+
+	if len(tool.manager.List()) != 1 {
+		t.Errorf("dry run 后任务数 = %d, want 1", len(tool.manager.List()))
+	}
+}
@@ -1,65 +1,80 @@
 package tools
 
 import (
+	"context"
 	"fmt"
 	"os"
 
 	"opencode_nano/permission"
+	"opencode_nano/tools/core"
 )
 
+// WriteTool 旧版 write_file 工具，权限检查由 CoreToolAdapter 完成
+//
+// Deprecated: 使用 CreateToolSet 或 file.NewWriteTool，保留以兼容旧接口
 type WriteTool struct {
-	perm permission.Manager
+	*CoreToolAdapter
 }
 
 func NewWriteTool(perm permission.Manager) *WriteTool {
-	return &WriteTool{perm: perm}
+	return &WriteTool{CoreToolAdapter: NewCoreToolAdapter(newWriteFileTool(), perm)}
 }
 
-func (t *WriteTool) Name() string {
-	return "write_file"
+// writeFileTool write_file 的 core.Tool 实现
+type writeFileTool struct {
+	*core.BaseTool
 }
 
-func (t *WriteTool) Description() string {
-	return "Write content to a file"
-}
-
-func (t *WriteTool) Parameters() map[string]any {
-	return map[string]any{
-		"type": "object",
-		"properties": map[string]any{
-			"file_path": map[string]any{
-				"type":        "string",
-				"description": "Path to the file to write",
+func newWriteFileTool() *writeFileTool {
+	tool := &writeFileTool{
+		BaseTool: core.NewBaseTool("write_file", "file", "Write content to a file"),
+	}
+	tool.SetRequiresPerm(true)
+	tool.SetSchema(core.ParameterSchema{
+		Type: "object",
+		Properties: map[string]core.PropertySchema{
+			"file_path": {
+				Type:        "string",
+				Description: "Path to the file to write",
 			},
-			"content": map[string]any{
-				"type":        "string",
-				"description": "Content to write to the file",
+			"content": {
+				Type:        "string",
+				Description: "Content to write to the file",
 			},
 		},
-		"required": []string{"file_path", "content"},
-	}
+		Required: []string{"file_path", "content"},
+	})
+	return tool
 }
 
-func (t *WriteTool) Execute(params map[string]any) (string, error) {
-	filePath, ok := params["file_path"].(string)
-	if !ok {
-		return "", fmt.Errorf("file_path parameter is required and must be a string")
-	}
+// NeedsPermission 参数无效时直接由 Execute 报错，不请求权限
+func (t *writeFileTool) NeedsPermission(params core.Parameters) bool {
+	_, _, err := t.parseParams(params)
+	return err == nil
+}
 
-	content, ok := params["content"].(string)
-	if !ok {
-		return "", fmt.Errorf("content parameter is required and must be a string")
+func (t *writeFileTool) Execute(ctx context.Context, params core.Parameters) (core.Result, error) {
+	filePath, content, err := t.parseParams(params)
+	if err != nil {
+		return nil, err
 	}
 
-	// 请求权限
-	if !t.perm.Request("write_file", fmt.Sprintf("Write to file: %s", filePath)) {
-		return "", fmt.Errorf("permission denied for writing to file: %s", filePath)
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		return nil, core.ErrExecutionFailed(t.Info().Name, fmt.Sprintf("failed to write file %s: %v", filePath, err))
 	}
 
-	err := os.WriteFile(filePath, []byte(content), 0644)
-	if err != nil {
-		return "", fmt.Errorf("failed to write file %s: %v", filePath, err)
-	}
+	return core.NewSimpleResult(fmt.Sprintf("Successfully wrote content to file: %s", filePath)), nil
+}
 
-	return fmt.Sprintf("Successfully wrote content to file: %s", filePath), nil
-}
\ No newline at end of file
+// parseParams 获取文件路径和内容
+func (t *writeFileTool) parseParams(params core.Parameters) (string, string, error) {
+	filePath, ok := stringParam(params, "file_path")
+	if !ok {
+		return "", "", core.ErrInvalidParams(t.Info().Name, "file_path parameter is required and must be a string")
+	}
+	content, ok := stringParam(params, "content")
+	if !ok {
+		return "", "", core.ErrInvalidParams(t.Info().Name, "content parameter is required and must be a string")
+	}
+	return filePath, content, nil
+}